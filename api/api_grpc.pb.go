@@ -428,10 +428,36 @@ type CoordinatorServiceClient interface {
 	Inference(ctx context.Context, in *InferenceRequest, opts ...grpc.CallOption) (*InferenceResponse, error)
 	// query a batch of data from coordinator & submit batch result from previous round
 	QueryData(ctx context.Context, in *QueryDataRequest, opts ...grpc.CallOption) (*QueryDataResponse, error)
+	// long-lived stream of a worker, batches are pushed to the worker as they are available & outputs streamed back
+	Dispatch(ctx context.Context, opts ...grpc.CallOption) (CoordinatorService_DispatchClient, error)
+	// stop a job and write its partial results to SDFS
+	CancelJob(ctx context.Context, in *JobControlRequest, opts ...grpc.CallOption) (*JobControlResponse, error)
+	// stop scheduling workers to a job, batch states are kept
+	PauseJob(ctx context.Context, in *JobControlRequest, opts ...grpc.CallOption) (*JobControlResponse, error)
+	// resume scheduling workers to a paused job
+	ResumeJob(ctx context.Context, in *JobControlRequest, opts ...grpc.CallOption) (*JobControlResponse, error)
+	// submit a graph of jobs, downstream jobs start when their upstream job completes
+	SubmitGraph(ctx context.Context, in *SubmitGraphRequest, opts ...grpc.CallOption) (*SubmitGraphResponse, error)
+	// evaluate a few inputs synchronously on a worker already serving the model
+	Predict(ctx context.Context, in *PredictRequest, opts ...grpc.CallOption) (*PredictResponse, error)
+	// add a model to the registry, or a new version of a registered model
+	RegisterModel(ctx context.Context, in *RegisterModelRequest, opts ...grpc.CallOption) (*RegisterModelResponse, error)
+	// list registered models & the datasets they are trained on
+	ListModels(ctx context.Context, in *ListModelsRequest, opts ...grpc.CallOption) (*ListModelsResponse, error)
+	// make a trained version of a model the one served by default
+	PromoteModel(ctx context.Context, in *PromoteModelRequest, opts ...grpc.CallOption) (*PromoteModelResponse, error)
+	// add the schema of a dataset to the registry, or replace it
+	RegisterDataset(ctx context.Context, in *RegisterDatasetRequest, opts ...grpc.CallOption) (*RegisterDatasetResponse, error)
+	// list registered datasets
+	ListDatasets(ctx context.Context, in *ListDatasetsRequest, opts ...grpc.CallOption) (*ListDatasetsResponse, error)
 	// get real-time updates on workers & jobs status
 	IDunnoStatus(ctx context.Context, in *IDunnoStatusRequest, opts ...grpc.CallOption) (*IDunnoStatusResponse, error)
-	// backup coordinator state
+	// install a snapshot of coordinator state on a standby
 	Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (*BackupResponse, error)
+	// replicate coordinator log entries to a standby
+	AppendLog(ctx context.Context, in *AppendLogRequest, opts ...grpc.CallOption) (*AppendLogResponse, error)
+	// fetch current state of a standby, i.e. when it is about to be promoted
+	FetchSnapshot(ctx context.Context, in *FetchSnapshotRequest, opts ...grpc.CallOption) (*FetchSnapshotResponse, error)
 }
 
 type coordinatorServiceClient struct {
@@ -469,6 +495,127 @@ func (c *coordinatorServiceClient) QueryData(ctx context.Context, in *QueryDataR
 	return out, nil
 }
 
+func (c *coordinatorServiceClient) Dispatch(ctx context.Context, opts ...grpc.CallOption) (CoordinatorService_DispatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &CoordinatorService_ServiceDesc.Streams[0], "/api.CoordinatorService/Dispatch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &coordinatorServiceDispatchClient{stream}
+	return x, nil
+}
+
+type CoordinatorService_DispatchClient interface {
+	Send(*QueryDataRequest) error
+	Recv() (*QueryDataResponse, error)
+	grpc.ClientStream
+}
+
+type coordinatorServiceDispatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *coordinatorServiceDispatchClient) Send(m *QueryDataRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *coordinatorServiceDispatchClient) Recv() (*QueryDataResponse, error) {
+	m := new(QueryDataResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *coordinatorServiceClient) CancelJob(ctx context.Context, in *JobControlRequest, opts ...grpc.CallOption) (*JobControlResponse, error) {
+	out := new(JobControlResponse)
+	err := c.cc.Invoke(ctx, "/api.CoordinatorService/CancelJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coordinatorServiceClient) PauseJob(ctx context.Context, in *JobControlRequest, opts ...grpc.CallOption) (*JobControlResponse, error) {
+	out := new(JobControlResponse)
+	err := c.cc.Invoke(ctx, "/api.CoordinatorService/PauseJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coordinatorServiceClient) ResumeJob(ctx context.Context, in *JobControlRequest, opts ...grpc.CallOption) (*JobControlResponse, error) {
+	out := new(JobControlResponse)
+	err := c.cc.Invoke(ctx, "/api.CoordinatorService/ResumeJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coordinatorServiceClient) SubmitGraph(ctx context.Context, in *SubmitGraphRequest, opts ...grpc.CallOption) (*SubmitGraphResponse, error) {
+	out := new(SubmitGraphResponse)
+	err := c.cc.Invoke(ctx, "/api.CoordinatorService/SubmitGraph", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coordinatorServiceClient) Predict(ctx context.Context, in *PredictRequest, opts ...grpc.CallOption) (*PredictResponse, error) {
+	out := new(PredictResponse)
+	err := c.cc.Invoke(ctx, "/api.CoordinatorService/Predict", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coordinatorServiceClient) RegisterModel(ctx context.Context, in *RegisterModelRequest, opts ...grpc.CallOption) (*RegisterModelResponse, error) {
+	out := new(RegisterModelResponse)
+	err := c.cc.Invoke(ctx, "/api.CoordinatorService/RegisterModel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coordinatorServiceClient) ListModels(ctx context.Context, in *ListModelsRequest, opts ...grpc.CallOption) (*ListModelsResponse, error) {
+	out := new(ListModelsResponse)
+	err := c.cc.Invoke(ctx, "/api.CoordinatorService/ListModels", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coordinatorServiceClient) PromoteModel(ctx context.Context, in *PromoteModelRequest, opts ...grpc.CallOption) (*PromoteModelResponse, error) {
+	out := new(PromoteModelResponse)
+	err := c.cc.Invoke(ctx, "/api.CoordinatorService/PromoteModel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coordinatorServiceClient) RegisterDataset(ctx context.Context, in *RegisterDatasetRequest, opts ...grpc.CallOption) (*RegisterDatasetResponse, error) {
+	out := new(RegisterDatasetResponse)
+	err := c.cc.Invoke(ctx, "/api.CoordinatorService/RegisterDataset", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coordinatorServiceClient) ListDatasets(ctx context.Context, in *ListDatasetsRequest, opts ...grpc.CallOption) (*ListDatasetsResponse, error) {
+	out := new(ListDatasetsResponse)
+	err := c.cc.Invoke(ctx, "/api.CoordinatorService/ListDatasets", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *coordinatorServiceClient) IDunnoStatus(ctx context.Context, in *IDunnoStatusRequest, opts ...grpc.CallOption) (*IDunnoStatusResponse, error) {
 	out := new(IDunnoStatusResponse)
 	err := c.cc.Invoke(ctx, "/api.CoordinatorService/IDunnoStatus", in, out, opts...)
@@ -487,6 +634,24 @@ func (c *coordinatorServiceClient) Backup(ctx context.Context, in *BackupRequest
 	return out, nil
 }
 
+func (c *coordinatorServiceClient) AppendLog(ctx context.Context, in *AppendLogRequest, opts ...grpc.CallOption) (*AppendLogResponse, error) {
+	out := new(AppendLogResponse)
+	err := c.cc.Invoke(ctx, "/api.CoordinatorService/AppendLog", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coordinatorServiceClient) FetchSnapshot(ctx context.Context, in *FetchSnapshotRequest, opts ...grpc.CallOption) (*FetchSnapshotResponse, error) {
+	out := new(FetchSnapshotResponse)
+	err := c.cc.Invoke(ctx, "/api.CoordinatorService/FetchSnapshot", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CoordinatorServiceServer is the server API for CoordinatorService service.
 // All implementations must embed UnimplementedCoordinatorServiceServer
 // for forward compatibility
@@ -497,10 +662,36 @@ type CoordinatorServiceServer interface {
 	Inference(context.Context, *InferenceRequest) (*InferenceResponse, error)
 	// query a batch of data from coordinator & submit batch result from previous round
 	QueryData(context.Context, *QueryDataRequest) (*QueryDataResponse, error)
+	// long-lived stream of a worker, batches are pushed to the worker as they are available & outputs streamed back
+	Dispatch(CoordinatorService_DispatchServer) error
+	// stop a job and write its partial results to SDFS
+	CancelJob(context.Context, *JobControlRequest) (*JobControlResponse, error)
+	// stop scheduling workers to a job, batch states are kept
+	PauseJob(context.Context, *JobControlRequest) (*JobControlResponse, error)
+	// resume scheduling workers to a paused job
+	ResumeJob(context.Context, *JobControlRequest) (*JobControlResponse, error)
+	// submit a graph of jobs, downstream jobs start when their upstream job completes
+	SubmitGraph(context.Context, *SubmitGraphRequest) (*SubmitGraphResponse, error)
+	// evaluate a few inputs synchronously on a worker already serving the model
+	Predict(context.Context, *PredictRequest) (*PredictResponse, error)
+	// add a model to the registry, or a new version of a registered model
+	RegisterModel(context.Context, *RegisterModelRequest) (*RegisterModelResponse, error)
+	// list registered models & the datasets they are trained on
+	ListModels(context.Context, *ListModelsRequest) (*ListModelsResponse, error)
+	// make a trained version of a model the one served by default
+	PromoteModel(context.Context, *PromoteModelRequest) (*PromoteModelResponse, error)
+	// add the schema of a dataset to the registry, or replace it
+	RegisterDataset(context.Context, *RegisterDatasetRequest) (*RegisterDatasetResponse, error)
+	// list registered datasets
+	ListDatasets(context.Context, *ListDatasetsRequest) (*ListDatasetsResponse, error)
 	// get real-time updates on workers & jobs status
 	IDunnoStatus(context.Context, *IDunnoStatusRequest) (*IDunnoStatusResponse, error)
-	// backup coordinator state
+	// install a snapshot of coordinator state on a standby
 	Backup(context.Context, *BackupRequest) (*BackupResponse, error)
+	// replicate coordinator log entries to a standby
+	AppendLog(context.Context, *AppendLogRequest) (*AppendLogResponse, error)
+	// fetch current state of a standby, i.e. when it is about to be promoted
+	FetchSnapshot(context.Context, *FetchSnapshotRequest) (*FetchSnapshotResponse, error)
 	mustEmbedUnimplementedCoordinatorServiceServer()
 }
 
@@ -517,12 +708,51 @@ func (UnimplementedCoordinatorServiceServer) Inference(context.Context, *Inferen
 func (UnimplementedCoordinatorServiceServer) QueryData(context.Context, *QueryDataRequest) (*QueryDataResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method QueryData not implemented")
 }
+func (UnimplementedCoordinatorServiceServer) Dispatch(CoordinatorService_DispatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Dispatch not implemented")
+}
+func (UnimplementedCoordinatorServiceServer) CancelJob(context.Context, *JobControlRequest) (*JobControlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelJob not implemented")
+}
+func (UnimplementedCoordinatorServiceServer) PauseJob(context.Context, *JobControlRequest) (*JobControlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseJob not implemented")
+}
+func (UnimplementedCoordinatorServiceServer) ResumeJob(context.Context, *JobControlRequest) (*JobControlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeJob not implemented")
+}
+func (UnimplementedCoordinatorServiceServer) SubmitGraph(context.Context, *SubmitGraphRequest) (*SubmitGraphResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitGraph not implemented")
+}
+func (UnimplementedCoordinatorServiceServer) Predict(context.Context, *PredictRequest) (*PredictResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Predict not implemented")
+}
+func (UnimplementedCoordinatorServiceServer) RegisterModel(context.Context, *RegisterModelRequest) (*RegisterModelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterModel not implemented")
+}
+func (UnimplementedCoordinatorServiceServer) ListModels(context.Context, *ListModelsRequest) (*ListModelsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListModels not implemented")
+}
+func (UnimplementedCoordinatorServiceServer) PromoteModel(context.Context, *PromoteModelRequest) (*PromoteModelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PromoteModel not implemented")
+}
+func (UnimplementedCoordinatorServiceServer) RegisterDataset(context.Context, *RegisterDatasetRequest) (*RegisterDatasetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterDataset not implemented")
+}
+func (UnimplementedCoordinatorServiceServer) ListDatasets(context.Context, *ListDatasetsRequest) (*ListDatasetsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDatasets not implemented")
+}
 func (UnimplementedCoordinatorServiceServer) IDunnoStatus(context.Context, *IDunnoStatusRequest) (*IDunnoStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method IDunnoStatus not implemented")
 }
 func (UnimplementedCoordinatorServiceServer) Backup(context.Context, *BackupRequest) (*BackupResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Backup not implemented")
 }
+func (UnimplementedCoordinatorServiceServer) AppendLog(context.Context, *AppendLogRequest) (*AppendLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AppendLog not implemented")
+}
+func (UnimplementedCoordinatorServiceServer) FetchSnapshot(context.Context, *FetchSnapshotRequest) (*FetchSnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchSnapshot not implemented")
+}
 func (UnimplementedCoordinatorServiceServer) mustEmbedUnimplementedCoordinatorServiceServer() {}
 
 // UnsafeCoordinatorServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -590,6 +820,212 @@ func _CoordinatorService_QueryData_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CoordinatorService_Dispatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CoordinatorServiceServer).Dispatch(&coordinatorServiceDispatchServer{stream})
+}
+
+type CoordinatorService_DispatchServer interface {
+	Send(*QueryDataResponse) error
+	Recv() (*QueryDataRequest, error)
+	grpc.ServerStream
+}
+
+type coordinatorServiceDispatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *coordinatorServiceDispatchServer) Send(m *QueryDataResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *coordinatorServiceDispatchServer) Recv() (*QueryDataRequest, error) {
+	m := new(QueryDataRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _CoordinatorService_CancelJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobControlRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoordinatorServiceServer).CancelJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.CoordinatorService/CancelJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoordinatorServiceServer).CancelJob(ctx, req.(*JobControlRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoordinatorService_PauseJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobControlRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoordinatorServiceServer).PauseJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.CoordinatorService/PauseJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoordinatorServiceServer).PauseJob(ctx, req.(*JobControlRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoordinatorService_ResumeJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobControlRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoordinatorServiceServer).ResumeJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.CoordinatorService/ResumeJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoordinatorServiceServer).ResumeJob(ctx, req.(*JobControlRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoordinatorService_SubmitGraph_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitGraphRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoordinatorServiceServer).SubmitGraph(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.CoordinatorService/SubmitGraph",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoordinatorServiceServer).SubmitGraph(ctx, req.(*SubmitGraphRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoordinatorService_Predict_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PredictRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoordinatorServiceServer).Predict(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.CoordinatorService/Predict",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoordinatorServiceServer).Predict(ctx, req.(*PredictRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoordinatorService_RegisterModel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterModelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoordinatorServiceServer).RegisterModel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.CoordinatorService/RegisterModel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoordinatorServiceServer).RegisterModel(ctx, req.(*RegisterModelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoordinatorService_ListModels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListModelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoordinatorServiceServer).ListModels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.CoordinatorService/ListModels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoordinatorServiceServer).ListModels(ctx, req.(*ListModelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoordinatorService_PromoteModel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PromoteModelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoordinatorServiceServer).PromoteModel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.CoordinatorService/PromoteModel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoordinatorServiceServer).PromoteModel(ctx, req.(*PromoteModelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoordinatorService_RegisterDataset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterDatasetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoordinatorServiceServer).RegisterDataset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.CoordinatorService/RegisterDataset",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoordinatorServiceServer).RegisterDataset(ctx, req.(*RegisterDatasetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoordinatorService_ListDatasets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDatasetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoordinatorServiceServer).ListDatasets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.CoordinatorService/ListDatasets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoordinatorServiceServer).ListDatasets(ctx, req.(*ListDatasetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _CoordinatorService_IDunnoStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(IDunnoStatusRequest)
 	if err := dec(in); err != nil {
@@ -626,6 +1062,42 @@ func _CoordinatorService_Backup_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CoordinatorService_AppendLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AppendLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoordinatorServiceServer).AppendLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.CoordinatorService/AppendLog",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoordinatorServiceServer).AppendLog(ctx, req.(*AppendLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoordinatorService_FetchSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoordinatorServiceServer).FetchSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.CoordinatorService/FetchSnapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoordinatorServiceServer).FetchSnapshot(ctx, req.(*FetchSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CoordinatorService_ServiceDesc is the grpc.ServiceDesc for CoordinatorService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -645,6 +1117,46 @@ var CoordinatorService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "QueryData",
 			Handler:    _CoordinatorService_QueryData_Handler,
 		},
+		{
+			MethodName: "CancelJob",
+			Handler:    _CoordinatorService_CancelJob_Handler,
+		},
+		{
+			MethodName: "PauseJob",
+			Handler:    _CoordinatorService_PauseJob_Handler,
+		},
+		{
+			MethodName: "ResumeJob",
+			Handler:    _CoordinatorService_ResumeJob_Handler,
+		},
+		{
+			MethodName: "SubmitGraph",
+			Handler:    _CoordinatorService_SubmitGraph_Handler,
+		},
+		{
+			MethodName: "Predict",
+			Handler:    _CoordinatorService_Predict_Handler,
+		},
+		{
+			MethodName: "RegisterModel",
+			Handler:    _CoordinatorService_RegisterModel_Handler,
+		},
+		{
+			MethodName: "ListModels",
+			Handler:    _CoordinatorService_ListModels_Handler,
+		},
+		{
+			MethodName: "PromoteModel",
+			Handler:    _CoordinatorService_PromoteModel_Handler,
+		},
+		{
+			MethodName: "RegisterDataset",
+			Handler:    _CoordinatorService_RegisterDataset_Handler,
+		},
+		{
+			MethodName: "ListDatasets",
+			Handler:    _CoordinatorService_ListDatasets_Handler,
+		},
 		{
 			MethodName: "IDunnoStatus",
 			Handler:    _CoordinatorService_IDunnoStatus_Handler,
@@ -653,8 +1165,23 @@ var CoordinatorService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Backup",
 			Handler:    _CoordinatorService_Backup_Handler,
 		},
+		{
+			MethodName: "AppendLog",
+			Handler:    _CoordinatorService_AppendLog_Handler,
+		},
+		{
+			MethodName: "FetchSnapshot",
+			Handler:    _CoordinatorService_FetchSnapshot_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Dispatch",
+			Handler:       _CoordinatorService_Dispatch_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/api.proto",
 }
 
@@ -668,6 +1195,12 @@ type WorkerServiceClient interface {
 	Inference(ctx context.Context, in *InferenceRequest, opts ...grpc.CallOption) (*InferenceResponse, error)
 	// notify worker that inference is finished
 	FinishInference(ctx context.Context, in *FinishInferenceRequest, opts ...grpc.CallOption) (*FinishInferenceResponse, error)
+	// evaluate online inputs with the loaded model, alongside the batch being processed
+	Predict(ctx context.Context, in *PredictRequest, opts ...grpc.CallOption) (*PredictResponse, error)
+	// load a model for online requests, without assigning the worker a job
+	ServeModel(ctx context.Context, in *ServeModelRequest, opts ...grpc.CallOption) (*ServeModelResponse, error)
+	// capabilities of the worker, fetched by coordinators once it joins
+	FetchCapabilities(ctx context.Context, in *FetchCapabilitiesRequest, opts ...grpc.CallOption) (*FetchCapabilitiesResponse, error)
 }
 
 type workerServiceClient struct {
@@ -705,6 +1238,33 @@ func (c *workerServiceClient) FinishInference(ctx context.Context, in *FinishInf
 	return out, nil
 }
 
+func (c *workerServiceClient) Predict(ctx context.Context, in *PredictRequest, opts ...grpc.CallOption) (*PredictResponse, error) {
+	out := new(PredictResponse)
+	err := c.cc.Invoke(ctx, "/api.WorkerService/Predict", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workerServiceClient) ServeModel(ctx context.Context, in *ServeModelRequest, opts ...grpc.CallOption) (*ServeModelResponse, error) {
+	out := new(ServeModelResponse)
+	err := c.cc.Invoke(ctx, "/api.WorkerService/ServeModel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workerServiceClient) FetchCapabilities(ctx context.Context, in *FetchCapabilitiesRequest, opts ...grpc.CallOption) (*FetchCapabilitiesResponse, error) {
+	out := new(FetchCapabilitiesResponse)
+	err := c.cc.Invoke(ctx, "/api.WorkerService/FetchCapabilities", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // WorkerServiceServer is the server API for WorkerService service.
 // All implementations must embed UnimplementedWorkerServiceServer
 // for forward compatibility
@@ -715,6 +1275,12 @@ type WorkerServiceServer interface {
 	Inference(context.Context, *InferenceRequest) (*InferenceResponse, error)
 	// notify worker that inference is finished
 	FinishInference(context.Context, *FinishInferenceRequest) (*FinishInferenceResponse, error)
+	// evaluate online inputs with the loaded model, alongside the batch being processed
+	Predict(context.Context, *PredictRequest) (*PredictResponse, error)
+	// load a model for online requests, without assigning the worker a job
+	ServeModel(context.Context, *ServeModelRequest) (*ServeModelResponse, error)
+	// capabilities of the worker, fetched by coordinators once it joins
+	FetchCapabilities(context.Context, *FetchCapabilitiesRequest) (*FetchCapabilitiesResponse, error)
 	mustEmbedUnimplementedWorkerServiceServer()
 }
 
@@ -731,6 +1297,15 @@ func (UnimplementedWorkerServiceServer) Inference(context.Context, *InferenceReq
 func (UnimplementedWorkerServiceServer) FinishInference(context.Context, *FinishInferenceRequest) (*FinishInferenceResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FinishInference not implemented")
 }
+func (UnimplementedWorkerServiceServer) Predict(context.Context, *PredictRequest) (*PredictResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Predict not implemented")
+}
+func (UnimplementedWorkerServiceServer) ServeModel(context.Context, *ServeModelRequest) (*ServeModelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ServeModel not implemented")
+}
+func (UnimplementedWorkerServiceServer) FetchCapabilities(context.Context, *FetchCapabilitiesRequest) (*FetchCapabilitiesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchCapabilities not implemented")
+}
 func (UnimplementedWorkerServiceServer) mustEmbedUnimplementedWorkerServiceServer() {}
 
 // UnsafeWorkerServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -798,6 +1373,60 @@ func _WorkerService_FinishInference_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WorkerService_Predict_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PredictRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkerServiceServer).Predict(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.WorkerService/Predict",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkerServiceServer).Predict(ctx, req.(*PredictRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WorkerService_ServeModel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServeModelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkerServiceServer).ServeModel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.WorkerService/ServeModel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkerServiceServer).ServeModel(ctx, req.(*ServeModelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WorkerService_FetchCapabilities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchCapabilitiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkerServiceServer).FetchCapabilities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.WorkerService/FetchCapabilities",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkerServiceServer).FetchCapabilities(ctx, req.(*FetchCapabilitiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // WorkerService_ServiceDesc is the grpc.ServiceDesc for WorkerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -817,6 +1446,18 @@ var WorkerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "FinishInference",
 			Handler:    _WorkerService_FinishInference_Handler,
 		},
+		{
+			MethodName: "Predict",
+			Handler:    _WorkerService_Predict_Handler,
+		},
+		{
+			MethodName: "ServeModel",
+			Handler:    _WorkerService_ServeModel_Handler,
+		},
+		{
+			MethodName: "FetchCapabilities",
+			Handler:    _WorkerService_FetchCapabilities_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/api.proto",
@@ -827,6 +1468,8 @@ var WorkerService_ServiceDesc = grpc.ServiceDesc{
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type InferenceServiceClient interface {
 	Greet(ctx context.Context, in *GreetRequest, opts ...grpc.CallOption) (*GreetResponse, error)
+	// answered as long as the runner is serving requests, used by the worker to supervise it
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
 	// pretrain model on specified dataset
 	Train(ctx context.Context, in *TrainRequest, opts ...grpc.CallOption) (*TrainResponse, error)
 	// start loading model and waiting for incoming input
@@ -852,6 +1495,15 @@ func (c *inferenceServiceClient) Greet(ctx context.Context, in *GreetRequest, op
 	return out, nil
 }
 
+func (c *inferenceServiceClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	out := new(HealthResponse)
+	err := c.cc.Invoke(ctx, "/api.InferenceService/Health", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *inferenceServiceClient) Train(ctx context.Context, in *TrainRequest, opts ...grpc.CallOption) (*TrainResponse, error) {
 	out := new(TrainResponse)
 	err := c.cc.Invoke(ctx, "/api.InferenceService/Train", in, out, opts...)
@@ -884,6 +1536,8 @@ func (c *inferenceServiceClient) Evaluate(ctx context.Context, in *EvaluateReque
 // for forward compatibility
 type InferenceServiceServer interface {
 	Greet(context.Context, *GreetRequest) (*GreetResponse, error)
+	// answered as long as the runner is serving requests, used by the worker to supervise it
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
 	// pretrain model on specified dataset
 	Train(context.Context, *TrainRequest) (*TrainResponse, error)
 	// start loading model and waiting for incoming input
@@ -900,6 +1554,9 @@ type UnimplementedInferenceServiceServer struct {
 func (UnimplementedInferenceServiceServer) Greet(context.Context, *GreetRequest) (*GreetResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Greet not implemented")
 }
+func (UnimplementedInferenceServiceServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
+}
 func (UnimplementedInferenceServiceServer) Train(context.Context, *TrainRequest) (*TrainResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Train not implemented")
 }
@@ -940,6 +1597,24 @@ func _InferenceService_Greet_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _InferenceService_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InferenceServiceServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.InferenceService/Health",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InferenceServiceServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _InferenceService_Train_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(TrainRequest)
 	if err := dec(in); err != nil {
@@ -1005,6 +1680,10 @@ var InferenceService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Greet",
 			Handler:    _InferenceService_Greet_Handler,
 		},
+		{
+			MethodName: "Health",
+			Handler:    _InferenceService_Health_Handler,
+		},
 		{
 			MethodName: "Train",
 			Handler:    _InferenceService_Train_Handler,
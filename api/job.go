@@ -2,11 +2,30 @@ package api
 
 import (
 	"math"
+	"sort"
 	"strings"
 	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const QUERY_TIME_LIMIT = 6
+const DEFAULT_JOB_WEIGHT = 1 // weight of jobs served without an explicit weight
+
+const DEFAULT_BATCH_TIMEOUT = 60 * time.Second // batch timeout before enough batches are completed
+const MIN_BATCH_TIMEOUT = 10 * time.Second     // lower bound of batch timeout
+const BATCH_TIMEOUT_SAMPLES = 5                // completed batches required to estimate batch timeout
+const BATCH_TIMEOUT_FACTOR = 3                 // batch timeout = factor x 95th percentile processing time
+const SPECULATION_FACTOR = 1.5                 // batches running longer than factor x median are speculated
+const MAX_BATCH_ATTEMPTS = 2                   // workers a batch can be handed out to at the same time
+
+// weight used to scale the job's share of workers
+func (j *Job) SchedulingWeight() float64 {
+	if j.GetWeight() <= 0 {
+		return DEFAULT_JOB_WEIGHT
+	}
+	return float64(j.GetWeight())
+}
 
 // second per query (global)
 func (j *Job) SecondPerQuery() float64 {
@@ -87,9 +106,41 @@ func (j *Job) GetExpectedTimeLeft(resource int) float64 {
 	return remainQueryCount / j.GetQPS(10)
 }
 
-func (j *Job) FetchBatchInput() *BatchInput {
+// minimum number of workers to finish remaining queries before deadline, 0 if the job has no deadline
+func (j *Job) WorkersForDeadline(maxResource int) int {
+	remainQueryCount := float64(j.TotalQueries - j.CompletedQueries)
+	if j.Deadline == nil || remainQueryCount <= 0 {
+		return 0
+	}
+
+	// deadline has passed, catch up as fast as possible
+	timeLeft := time.Until(j.Deadline.AsTime()).Seconds()
+	if timeLeft <= 0 {
+		return maxResource
+	}
+
+	resource := int(math.Ceil(remainQueryCount * j.SecondPerQuery() / timeLeft))
+	return int(math.Max(1, math.Min(float64(resource), float64(maxResource))))
+}
+
+// number of workers the job must get before resources are shared fairly
+func (j *Job) RequiredWorkers(maxResource int) int {
+	resource := int(math.Max(float64(j.MinWorkers), float64(j.WorkersForDeadline(maxResource))))
+	return int(math.Min(float64(resource), float64(maxResource)))
+}
+
+// whether the job is predicted to finish after its deadline at the current query rate
+func (j *Job) MissesDeadline(resource int) bool {
+	if j.Deadline == nil || j.CompletedQueries >= j.TotalQueries {
+		return false
+	}
+	return j.GetExpectedTimeLeft(resource) > time.Until(j.Deadline.AsTime()).Seconds()
+}
+
+// hand out the first available batch evaluated by given version of the model
+func (j *Job) FetchBatchInput(version int32) *BatchInput {
 	for i := range j.BatchStates {
-		if j.BatchStates[i].Status == BatchStatus_Available {
+		if j.BatchStates[i].Status == BatchStatus_Available && j.BatchStates[i].BatchInput.GetVersion() == version {
 			j.BatchStates[i].Status = BatchStatus_InProgress
 			j.BatchStates[i].QueryTime = CurrentTimestamp()
 			return j.BatchStates[i].BatchInput
@@ -98,6 +149,116 @@ func (j *Job) FetchBatchInput() *BatchInput {
 	return nil
 }
 
+// hand out the longest running batch of given version once more, if it runs longer than threshold and is not speculated yet
+func (j *Job) FetchSpeculativeBatch(version int32, threshold time.Duration) *BatchInput {
+	var straggler *BatchState
+	for _, state := range j.BatchStates {
+		if state.Status != BatchStatus_InProgress || state.BatchInput.GetVersion() != version ||
+			len(state.Leases) >= MAX_BATCH_ATTEMPTS || time.Since(state.QueryTime.AsTime()) < threshold {
+			continue
+		}
+		if straggler == nil || state.QueryTime.AsTime().Before(straggler.QueryTime.AsTime()) {
+			straggler = state
+		}
+	}
+
+	if straggler == nil {
+		return nil
+	}
+	return straggler.BatchInput
+}
+
+// grant a worker the right to submit output of a batch until expiry
+func (j *Job) GrantLease(batchId int32, worker string, expiry time.Time) *BatchLease {
+	lease := &BatchLease{
+		Id:     j.LeaseCount + 1,
+		Worker: worker,
+		Expiry: timestamppb.New(expiry),
+	}
+	j.AddLease(batchId, lease)
+	return lease
+}
+
+// add a granted lease to a batch, lease ids are never reused within the job
+func (j *Job) AddLease(batchId int32, lease *BatchLease) {
+	if lease == nil || int(batchId) >= len(j.BatchStates) {
+		return
+	}
+	j.BatchStates[batchId].Leases = append(j.BatchStates[batchId].Leases, lease)
+	if lease.Id > j.LeaseCount {
+		j.LeaseCount = lease.Id
+	}
+}
+
+// revoke a worker's lease of a batch, the batch is available again once no other worker holds a lease of it
+func (j *Job) ReleaseLease(batchId int32, worker string) {
+	if int(batchId) >= len(j.BatchStates) || j.BatchStates[batchId].Status != BatchStatus_InProgress {
+		return
+	}
+
+	state := j.BatchStates[batchId]
+	leases := make([]*BatchLease, 0)
+	for _, lease := range state.Leases {
+		if lease.Worker != worker {
+			leases = append(leases, lease)
+		}
+	}
+	state.Leases = leases
+
+	if len(leases) == 0 {
+		state.Status = BatchStatus_Available
+	}
+}
+
+// whether a worker holds an unexpired lease of a batch that is not completed yet
+func (j *Job) HoldsLease(batchId int32, leaseId int64, worker string) bool {
+	if int(batchId) >= len(j.BatchStates) || j.BatchStates[batchId].Status != BatchStatus_InProgress {
+		return false
+	}
+	for _, lease := range j.BatchStates[batchId].Leases {
+		if lease.Id == leaseId && lease.Worker == worker {
+			return time.Now().Before(lease.Expiry.AsTime())
+		}
+	}
+	return false
+}
+
+// processing time in seconds of each completed batch, in ascending order
+func (j *Job) BatchProcessingTimes() []float64 {
+	times := make([]float64, 0)
+	for _, state := range j.BatchStates {
+		if state.Status == BatchStatus_Completed && state.QueryTime != nil && state.ReceiveTime != nil {
+			times = append(times, state.ReceiveTime.AsTime().Sub(state.QueryTime.AsTime()).Seconds())
+		}
+	}
+	sort.Float64s(times)
+	return times
+}
+
+// time a worker can hold a batch before it is handed out again
+func (j *Job) BatchTimeout() time.Duration {
+	times := j.BatchProcessingTimes()
+	if len(times) < BATCH_TIMEOUT_SAMPLES {
+		return DEFAULT_BATCH_TIMEOUT
+	}
+
+	p95 := times[int(math.Ceil(0.95*float64(len(times)-1)))]
+	timeout := time.Duration(BATCH_TIMEOUT_FACTOR * p95 * float64(time.Second))
+	if timeout < MIN_BATCH_TIMEOUT {
+		return MIN_BATCH_TIMEOUT
+	}
+	return timeout
+}
+
+// time a batch runs before it is speculatively handed out to another worker
+func (j *Job) SpeculationThreshold() time.Duration {
+	times := j.BatchProcessingTimes()
+	if len(times) == 0 {
+		return DEFAULT_BATCH_TIMEOUT
+	}
+	return time.Duration(SPECULATION_FACTOR * times[len(times)/2] * float64(time.Second))
+}
+
 func (j *Job) GetCompletedBatchCount() int {
 	count := 0
 	for _, state := range j.BatchStates {
@@ -108,14 +269,44 @@ func (j *Job) GetCompletedBatchCount() int {
 	return count
 }
 
+// number of batches of each version waiting for a worker
+func (j *Job) AvailableBatches() map[int32]int {
+	available := make(map[int32]int)
+	for _, state := range j.BatchStates {
+		if state.Status == BatchStatus_Available {
+			available[state.BatchInput.GetVersion()]++
+		}
+	}
+	return available
+}
+
+// whether a batch of any version is waiting for a worker
+func (j *Job) HasAvailableBatch() bool {
+	for _, state := range j.BatchStates {
+		if state.Status == BatchStatus_Available {
+			return true
+		}
+	}
+	return false
+}
+
 func (j *Job) GetResults() ([]*EvalResult, float32) {
+	return j.getResults(func(state *BatchState) bool { return true })
+}
+
+// results of the batches evaluated by given version of the model
+func (j *Job) GetVersionResults(version int32) ([]*EvalResult, float32) {
+	return j.getResults(func(state *BatchState) bool { return state.BatchInput.GetVersion() == version })
+}
+
+func (j *Job) getResults(include func(state *BatchState) bool) ([]*EvalResult, float32) {
 	metricSum := float32(0)
 	evalResults := make([]*EvalResult, 0)
 
 	for _, state := range j.BatchStates {
 		batchOutput := state.BatchOutput
 
-		if batchOutput == nil {
+		if batchOutput == nil || !include(state) {
 			continue
 		}
 
@@ -131,5 +322,9 @@ func (j *Job) GetResults() ([]*EvalResult, float32) {
 		}
 	}
 
+	// job cancelled before any batch is completed
+	if len(evalResults) == 0 {
+		return evalResults, 0
+	}
 	return evalResults, metricSum / float32(len(evalResults))
 }
@@ -0,0 +1,181 @@
+package api_test
+
+import (
+	"mp4/api"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// job with available batches, evaluated by the given versions in turn
+func newJob(batches int, versions ...int32) *api.Job {
+	job := &api.Job{Id: "albert:10:0"}
+	for i := 0; i < batches; i++ {
+		job.BatchStates = append(job.BatchStates, &api.BatchState{
+			Status:     api.BatchStatus_Available,
+			BatchInput: &api.BatchInput{BatchId: int32(i), Version: versions[i%len(versions)]},
+		})
+	}
+	return job
+}
+
+// hand out a batch as if it was queried the given time ago
+func startBatch(job *api.Job, batchId int32, worker string, ago time.Duration) {
+	job.BatchStates[batchId].Status = api.BatchStatus_InProgress
+	job.BatchStates[batchId].QueryTime = timestamppb.New(time.Now().Add(-ago))
+	job.GrantLease(batchId, worker, time.Now().Add(time.Minute))
+}
+
+// complete a batch that took the given time to process
+func completeBatch(job *api.Job, batchId int32, took time.Duration) {
+	now := time.Now()
+	job.BatchStates[batchId].Status = api.BatchStatus_Completed
+	job.BatchStates[batchId].QueryTime = timestamppb.New(now.Add(-took))
+	job.BatchStates[batchId].ReceiveTime = timestamppb.New(now)
+}
+
+func TestGrantLease(t *testing.T) {
+	job := newJob(2, 1)
+
+	first := job.GrantLease(0, "a:1", time.Now().Add(time.Minute))
+	second := job.GrantLease(0, "b:1", time.Now().Add(time.Minute))
+	third := job.GrantLease(1, "a:1", time.Now().Add(time.Minute))
+	assert.Equal(t, []int64{1, 2, 3}, []int64{first.Id, second.Id, third.Id})
+	assert.Equal(t, int64(3), job.LeaseCount)
+	assert.Len(t, job.BatchStates[0].Leases, 2)
+	assert.Equal(t, "b:1", job.BatchStates[0].Leases[1].Worker)
+
+	// leases replicated out of order never lower the count, ids are not reused
+	job.AddLease(1, &api.BatchLease{Id: 2, Worker: "c:1"})
+	assert.Equal(t, int64(3), job.LeaseCount)
+	assert.Equal(t, int64(4), job.GrantLease(1, "d:1", time.Now()).Id)
+
+	// lease of an unknown batch is not recorded
+	job.GrantLease(5, "a:1", time.Now().Add(time.Minute))
+	assert.Equal(t, int64(4), job.LeaseCount)
+	assert.Len(t, job.BatchStates[1].Leases, 3)
+}
+
+func TestHoldsLease(t *testing.T) {
+	job := newJob(3, 1)
+	startBatch(job, 0, "a:1", 0)
+	lease := job.BatchStates[0].Leases[0]
+	job.BatchStates[1].Status = api.BatchStatus_InProgress
+	expired := job.GrantLease(1, "a:1", time.Now().Add(-time.Second))
+	startBatch(job, 2, "a:1", 0)
+	job.BatchStates[2].Status = api.BatchStatus_Completed
+
+	tests := []struct {
+		name     string
+		batchId  int32
+		leaseId  int64
+		worker   string
+		expected bool
+	}{
+		{"current holder", 0, lease.Id, "a:1", true},
+		{"another worker", 0, lease.Id, "b:1", false},
+		{"older lease", 0, lease.Id - 1, "a:1", false},
+		{"expired lease", 1, expired.Id, "a:1", false},
+		{"completed batch", 2, job.BatchStates[2].Leases[0].Id, "a:1", false},
+		{"unknown batch", 3, lease.Id, "a:1", false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, job.HoldsLease(test.batchId, test.leaseId, test.worker), test.name)
+	}
+}
+
+func TestReleaseLease(t *testing.T) {
+	job := newJob(2, 1)
+	startBatch(job, 0, "a:1", 0)
+	startBatch(job, 0, "b:1", 0)
+
+	// batch stays in progress while a speculative attempt holds a lease
+	job.ReleaseLease(0, "a:1")
+	assert.Equal(t, api.BatchStatus_InProgress, job.BatchStates[0].Status)
+	assert.Len(t, job.BatchStates[0].Leases, 1)
+	assert.Equal(t, "b:1", job.BatchStates[0].Leases[0].Worker)
+
+	// releasing a lease the worker does not hold changes nothing
+	job.ReleaseLease(0, "c:1")
+	assert.Len(t, job.BatchStates[0].Leases, 1)
+
+	job.ReleaseLease(0, "b:1")
+	assert.Equal(t, api.BatchStatus_Available, job.BatchStates[0].Status)
+	assert.Empty(t, job.BatchStates[0].Leases)
+
+	// completed batches keep their leases, unknown batches are ignored
+	startBatch(job, 1, "a:1", 0)
+	job.BatchStates[1].Status = api.BatchStatus_Completed
+	job.ReleaseLease(1, "a:1")
+	job.ReleaseLease(2, "a:1")
+	assert.Equal(t, api.BatchStatus_Completed, job.BatchStates[1].Status)
+	assert.Len(t, job.BatchStates[1].Leases, 1)
+}
+
+func TestFetchSpeculativeBatch(t *testing.T) {
+	threshold := 10 * time.Second
+
+	tests := []struct {
+		name     string
+		setup    func(job *api.Job)
+		version  int32
+		expected int32 // batch id, -1 if no batch is speculated
+	}{
+		{"no batch in progress", func(job *api.Job) {}, 1, -1},
+		{"batch below threshold", func(job *api.Job) { startBatch(job, 0, "a:1", time.Second) }, 1, -1},
+		{"straggler", func(job *api.Job) { startBatch(job, 0, "a:1", time.Minute) }, 1, 0},
+		{"longest running straggler", func(job *api.Job) {
+			startBatch(job, 0, "a:1", 20*time.Second)
+			startBatch(job, 2, "b:1", time.Minute)
+		}, 1, 2},
+		{"straggler of another version", func(job *api.Job) { startBatch(job, 1, "a:1", time.Minute) }, 1, -1},
+		{"straggler of the requested version", func(job *api.Job) { startBatch(job, 1, "a:1", time.Minute) }, 2, 1},
+		{"already speculated", func(job *api.Job) {
+			startBatch(job, 0, "a:1", time.Minute)
+			job.GrantLease(0, "b:1", time.Now().Add(time.Minute))
+		}, 1, -1},
+		{"completed batch", func(job *api.Job) { completeBatch(job, 0, time.Minute) }, 1, -1},
+	}
+
+	for _, test := range tests {
+		job := newJob(4, 1, 2)
+		test.setup(job)
+
+		batch := job.FetchSpeculativeBatch(test.version, threshold)
+		if test.expected == -1 {
+			assert.Nil(t, batch, test.name)
+			continue
+		}
+		if assert.NotNil(t, batch, test.name) {
+			assert.Equal(t, test.expected, batch.BatchId, test.name)
+		}
+	}
+}
+
+func TestBatchTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		took     []time.Duration
+		expected time.Duration
+	}{
+		{"no completed batch", nil, api.DEFAULT_BATCH_TIMEOUT},
+		{"too few samples", []time.Duration{time.Second, time.Second, time.Second, time.Second}, api.DEFAULT_BATCH_TIMEOUT},
+		{"fast batches", []time.Duration{time.Second, time.Second, time.Second, time.Second, time.Second}, api.MIN_BATCH_TIMEOUT},
+		{"slow batches", []time.Duration{5 * time.Second, 5 * time.Second, 6 * time.Second, 8 * time.Second, 10 * time.Second}, 30 * time.Second},
+		{"one slow batch", []time.Duration{4 * time.Second, 4 * time.Second, 4 * time.Second, 4 * time.Second, 4 * time.Second, 40 * time.Second}, 120 * time.Second},
+	}
+
+	for _, test := range tests {
+		job := newJob(len(test.took)+1, 1)
+		for i, took := range test.took {
+			completeBatch(job, int32(i), took)
+		}
+		// batches in progress do not count
+		startBatch(job, int32(len(test.took)), "a:1", time.Hour)
+
+		assert.InDelta(t, test.expected.Seconds(), job.BatchTimeout().Seconds(), 0.01, test.name)
+	}
+}
@@ -26,6 +26,27 @@ func IsSameProcess(p1 *Process, p2 *Process) bool {
 	return p1.Ip == p2.Ip && p1.Port == p2.Port && p1.JoinTime.AsTime() == p2.JoinTime.AsTime()
 }
 
+// Whether two processes are incarnations of the same machine, i.e. a process restarted with the same node id
+func IsSameNode(p1 *Process, p2 *Process) bool {
+	if p1 == nil || p2 == nil {
+		return false
+	}
+	if p1.NodeId != "" && p2.NodeId != "" {
+		return p1.NodeId == p2.NodeId
+	}
+	return p1.Address() == p2.Address()
+}
+
+// api.InferenceTask
+// Whether two tasks are the same queued task, tasks of a graph are queued at once and told apart by node
+func IsSameTask(t1 *InferenceTask, t2 *InferenceTask) bool {
+	if t1 == nil || t2 == nil {
+		return false
+	}
+	return t1.Model == t2.Model && t1.User == t2.User && t1.QueueTime.AsTime().Equal(t2.QueueTime.AsTime()) &&
+		t1.GraphId == t2.GraphId && t1.Node == t2.Node
+}
+
 // api.Sequence
 func (s *Sequence) Less(other *Sequence) bool {
 	// compare timestamp first
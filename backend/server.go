@@ -4,13 +4,14 @@ import (
 	"context"
 	"fmt"
 	"mp4/api"
+	"mp4/connpool"
 	"mp4/logger"
 	"mp4/sdfs"
+	"mp4/security"
 	"net/http"
 	"strconv"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 const (
@@ -18,6 +19,9 @@ const (
 	STATUS_JSON_JOBS           = "jj"
 	STATUS_JSON_JOB_ID         = "jij"
 	STATUS_JSON_COMPLETED_JOBS = "jcj"
+	STATUS_JSON_QUEUED_TASKS   = "jq"
+	STATUS_JSON_GRAPHS         = "jg"
+	STATUS_JSON_ONLINE         = "jo"
 )
 
 const DNS_ADDR = "fa22-cs425-2401.cs.illinois.edu:8889"
@@ -38,6 +42,9 @@ func (s *IDunnoStatServer) Serve() {
 	http.HandleFunc("/worker", WorkerHandler)
 	http.HandleFunc("/jobs", JobsHandler)
 	http.HandleFunc("/completed-jobs", CompletedJobsHandler)
+	http.HandleFunc("/queue", QueueHandler)
+	http.HandleFunc("/graphs", GraphsHandler)
+	http.HandleFunc("/online", OnlineHandler)
 
 	http.ListenAndServe(":"+strconv.Itoa(s.Port), nil)
 }
@@ -73,12 +80,35 @@ func CompletedJobsHandler(w http.ResponseWriter, r *http.Request) {
 	RequestsHandler(w, STATUS_JSON_COMPLETED_JOBS, "")
 }
 
+func QueueHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Headers", "Access-Control-Allow-Headers, Origin,Accept, X-Requested-With, Content-Type, Access-Control-Request-Method, Access-Control-Request-Headers")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+
+	RequestsHandler(w, STATUS_JSON_QUEUED_TASKS, "")
+}
+
+func GraphsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Headers", "Access-Control-Allow-Headers, Origin,Accept, X-Requested-With, Content-Type, Access-Control-Request-Method, Access-Control-Request-Headers")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+
+	RequestsHandler(w, STATUS_JSON_GRAPHS, "")
+}
+
+func OnlineHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Headers", "Access-Control-Allow-Headers, Origin,Accept, X-Requested-With, Content-Type, Access-Control-Request-Method, Access-Control-Request-Headers")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+
+	RequestsHandler(w, STATUS_JSON_ONLINE, "")
+}
+
 func LookupLeader() (string, error) {
-	conn, err := grpc.Dial(DNS_ADDR, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := connpool.POOL.Get(DNS_ADDR, grpc.WithTransportCredentials(security.CLIENT_CREDENTIALS), connpool.KEEPALIVE)
 	if err != nil {
 		return "", err
 	}
-	defer conn.Close()
 
 	DNSClient := api.NewDNSServiceClient(conn)
 	res, err := DNSClient.Lookup(context.Background(), &api.LookupLeaderRequest{})
@@ -98,13 +128,12 @@ func RequestsHandler(w http.ResponseWriter, which string, payload string) {
 		return
 	}
 
-	conn, err := grpc.Dial(coordinatorAddr, sdfs.GRPC_OPTIONS...)
+	conn, err := connpool.POOL.Get(coordinatorAddr, sdfs.GRPC_OPTIONS...)
 	if err != nil {
 		logger.StatServerError("Failed to connect to coordinator")
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, "Failed to connect to coordinator")
 	}
-	defer conn.Close()
 
 	client := api.NewCoordinatorServiceClient(conn)
 
@@ -0,0 +1,36 @@
+#!/bin/bash
+
+# generate a CA, a node certificate signed by the CA and a shared cluster key under bin/certs,
+# copy the whole certs folder to every machine in the cluster.
+# Names given as arguments get their own client certificate, i.e. ./gen_certs.sh alice bob,
+# queued tasks are limited per common name of the certificate a client presents
+cd "$(dirname "$0")"
+mkdir -p certs && cd certs
+
+# certificate authority
+openssl req -x509 -newkey rsa:4096 -nodes -days 365 -subj "/CN=IDunno CA" -keyout ca.key -out ca.pem
+
+# node certificate, valid as both server and client certificate
+openssl req -newkey rsa:4096 -nodes -subj "/CN=IDunno Node" -keyout node.key -out node.csr
+cat > node.ext <<EXT
+subjectAltName = DNS:*.cs.illinois.edu, DNS:localhost, IP:127.0.0.1
+extendedKeyUsage = serverAuth, clientAuth
+EXT
+openssl x509 -req -in node.csr -CA ca.pem -CAkey ca.key -CAcreateserial -days 365 -extfile node.ext -out node.pem
+rm node.csr node.ext
+
+# certificates of named users, valid wherever the node certificate is, pass one with --security to the idunno submitting tasks
+for name in "$@"; do
+    openssl req -newkey rsa:4096 -nodes -subj "/CN=$name" -keyout "$name.key" -out "$name.csr"
+    cat > "$name.ext" <<EXT
+subjectAltName = DNS:*.cs.illinois.edu, DNS:localhost, IP:127.0.0.1
+extendedKeyUsage = serverAuth, clientAuth
+EXT
+    openssl x509 -req -in "$name.csr" -CA ca.pem -CAkey ca.key -CAcreateserial -days 365 -extfile "$name.ext" -out "$name.pem"
+    rm "$name.csr" "$name.ext"
+    chmod 600 "$name.key"
+done
+
+# shared key for signing ring messages
+openssl rand 32 > cluster.key
+chmod 600 ca.key node.key cluster.key
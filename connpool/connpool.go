@@ -0,0 +1,106 @@
+package connpool
+
+import (
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/keepalive"
+)
+
+const KEEPALIVE_TIME = 10 * time.Second   // idle pooled connections are pinged this often
+const KEEPALIVE_TIMEOUT = 5 * time.Second // a connection whose ping is not acked in time is closed
+
+// client keepalives of pooled connections between cluster nodes
+var KEEPALIVE = grpc.WithKeepaliveParams(keepalive.ClientParameters{
+	Time:                KEEPALIVE_TIME,
+	Timeout:             KEEPALIVE_TIMEOUT,
+	PermitWithoutStream: true,
+})
+
+// servers must accept client keepalives, otherwise they close connections that ping more often than every 5 minutes
+var SERVER_KEEPALIVE = grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+	MinTime:             KEEPALIVE_TIME / 2,
+	PermitWithoutStream: true,
+})
+
+/* ConnPool
+ * gRPC connections shared by all clients of a process, keyed by address. A connection is dialed on first use,
+ * redialed once it is unhealthy, and closed when its process leaves the ring
+ */
+type ConnPool struct {
+	conns map[string]*grpc.ClientConn
+	sync.Mutex
+}
+
+// connections shared by SDFS, IDunno and the backend of this process
+var POOL = NewConnPool()
+
+func NewConnPool() *ConnPool {
+	return &ConnPool{
+		conns: make(map[string]*grpc.ClientConn),
+	}
+}
+
+/*
+ * Get the pooled connection to an address, dialing it if there is none or the pooled one is unhealthy
+ *
+ * @param address: address of the server, i.e. "host:port"
+ * @param options: dial options, only used when a connection is dialed
+ * @return *grpc.ClientConn: shared connection, never closed by callers
+ * @return error: raise error if the connection cannot be dialed
+ */
+func (pool *ConnPool) Get(address string, options ...grpc.DialOption) (*grpc.ClientConn, error) {
+	pool.Lock()
+	defer pool.Unlock()
+
+	if conn, ok := pool.conns[address]; ok {
+		if Healthy(conn) {
+			return conn, nil
+		}
+		conn.Close()
+		delete(pool.conns, address)
+	}
+
+	conn, err := grpc.Dial(address, options...)
+	if err != nil {
+		return nil, err
+	}
+	pool.conns[address] = conn
+	return conn, nil
+}
+
+// Close the connection to an address, i.e. when its process is deleted from the membership list
+func (pool *ConnPool) Evict(address string) {
+	pool.Lock()
+	defer pool.Unlock()
+
+	if conn, ok := pool.conns[address]; ok {
+		conn.Close()
+		delete(pool.conns, address)
+	}
+}
+
+// Close all pooled connections
+func (pool *ConnPool) Clear() {
+	pool.Lock()
+	defer pool.Unlock()
+
+	for address, conn := range pool.conns {
+		conn.Close()
+		delete(pool.conns, address)
+	}
+}
+
+func (pool *ConnPool) Len() int {
+	pool.Lock()
+	defer pool.Unlock()
+	return len(pool.conns)
+}
+
+// Whether a connection can carry calls. A failing connection is redialed instead of waiting for its reconnect backoff
+func Healthy(conn *grpc.ClientConn) bool {
+	state := conn.GetState()
+	return state != connectivity.Shutdown && state != connectivity.TransientFailure
+}
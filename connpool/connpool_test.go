@@ -0,0 +1,71 @@
+package connpool_test
+
+import (
+	"context"
+	"mp4/connpool"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+var INSECURE = grpc.WithTransportCredentials(insecure.NewCredentials())
+
+func TestGetReusesConnection(t *testing.T) {
+	pool := connpool.NewConnPool()
+	defer pool.Clear()
+
+	a, err := pool.Get("a:1", INSECURE)
+	assert.Nil(t, err)
+	b, err := pool.Get("a:1", INSECURE)
+	assert.Nil(t, err)
+	assert.Same(t, a, b)
+
+	c, err := pool.Get("b:1", INSECURE)
+	assert.Nil(t, err)
+	assert.NotSame(t, a, c)
+	assert.Equal(t, 2, pool.Len())
+}
+
+func TestEvictClosesConnection(t *testing.T) {
+	pool := connpool.NewConnPool()
+	defer pool.Clear()
+
+	a, _ := pool.Get("a:1", INSECURE)
+	pool.Evict("a:1")
+	assert.Equal(t, connectivity.Shutdown, a.GetState())
+	assert.Equal(t, 0, pool.Len())
+
+	// evicting an unknown address is a no-op
+	pool.Evict("b:1")
+
+	b, _ := pool.Get("a:1", INSECURE)
+	assert.NotSame(t, a, b)
+}
+
+func TestGetRedialsUnhealthyConnection(t *testing.T) {
+	pool := connpool.NewConnPool()
+	defer pool.Clear()
+
+	// nothing listens on a closed listener's address, so connecting fails
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	address := lis.Addr().String()
+	lis.Close()
+
+	a, _ := pool.Get(address, INSECURE)
+	a.Connect()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for a.GetState() != connectivity.TransientFailure && a.WaitForStateChange(ctx, a.GetState()) {
+	}
+	assert.False(t, connpool.Healthy(a))
+
+	b, _ := pool.Get(address, INSECURE)
+	assert.NotSame(t, a, b)
+	assert.Equal(t, connectivity.Shutdown, a.GetState())
+}
@@ -5,22 +5,39 @@ import (
 	"fmt"
 	"mp4/api"
 	"mp4/backend"
+	"mp4/connpool"
 	"mp4/logger"
+	"mp4/sdfs"
+	"mp4/security"
 
 	"net"
 	"os"
 	"strings"
 
+	"github.com/alexflint/go-arg"
 	"google.golang.org/grpc"
 )
 
+var DNSArgs struct {
+	Security string `arg:"-s" help:"path to security config" default:"./security.json"`
+}
+
 func main() {
+	arg.MustParse(&DNSArgs)
+
+	// load mTLS credentials, shared by the DNS server and the stat server
+	if err := security.Init(DNSArgs.Security); err != nil {
+		fmt.Println("Failed to initialize security: " + err.Error())
+		return
+	}
+	sdfs.SetTransportCredentials(security.CLIENT_CREDENTIALS)
+
 	lis, err := net.Listen("tcp", ":8889")
 	if err != nil {
 		logger.Error("Failed to listen: " + err.Error())
 	}
 
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(grpc.Creds(security.SERVER_CREDENTIALS), connpool.SERVER_KEEPALIVE)
 	dnsServer := NewDNSServer("dns.txt")
 	defer dnsServer.Clear()
 
@@ -0,0 +1,131 @@
+package fault
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+func parseProb(s string) (float64, error) {
+	prob, err := strconv.ParseFloat(s, 64)
+	if err != nil || prob < 0 || prob > 1 {
+		return 0, errors.New("probability must be between 0 and 1")
+	}
+	return prob, nil
+}
+
+func parseMillis(s string) (time.Duration, error) {
+	ms, err := strconv.Atoi(s)
+	if err != nil || ms < 0 {
+		return 0, errors.New("duration must be a non-negative number of milliseconds")
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+/*
+ * Execute a fault injection command from the terminal, args exclude the leading "fault"
+ *
+ * @param args: command arguments
+ * @return error: raise error if the command is invalid
+ */
+func (inj *Injector) ExecuteCommand(args []string) error {
+	if len(args) == 0 {
+		fmt.Println("format: fault status|drop|duplicate|reorder|latency|grpc-fail|partition|heal|reset ...")
+		return errors.New("invalid arguments")
+	}
+
+	switch args[0] {
+	case "status":
+		fmt.Println(inj.String())
+		return nil
+
+	case "drop", "duplicate", "grpc-fail":
+		if len(args) != 2 {
+			fmt.Printf("format: fault %v probability\n", args[0])
+			return errors.New("invalid arguments")
+		}
+		prob, err := parseProb(args[1])
+		if err != nil {
+			fmt.Println(err)
+			return err
+		}
+
+		switch args[0] {
+		case "drop":
+			inj.SetDropRate(prob)
+		case "duplicate":
+			inj.SetDuplicateRate(prob)
+		case "grpc-fail":
+			inj.SetGRPCFailureRate(prob)
+		}
+		return nil
+
+	case "reorder":
+		if len(args) != 2 && len(args) != 3 {
+			fmt.Println("format: fault reorder probability [delay-ms]")
+			return errors.New("invalid arguments")
+		}
+		prob, err := parseProb(args[1])
+		if err != nil {
+			fmt.Println(err)
+			return err
+		}
+		delay := DEFAULT_REORDER_DELAY
+		if len(args) == 3 {
+			if delay, err = parseMillis(args[2]); err != nil {
+				fmt.Println(err)
+				return err
+			}
+		}
+		inj.SetReorderRate(prob, delay)
+		return nil
+
+	case "latency":
+		if len(args) != 2 && len(args) != 3 {
+			fmt.Println("format: fault latency latency-ms [jitter-ms]")
+			return errors.New("invalid arguments")
+		}
+		latency, err := parseMillis(args[1])
+		if err != nil {
+			fmt.Println(err)
+			return err
+		}
+		jitter := time.Duration(0)
+		if len(args) == 3 {
+			if jitter, err = parseMillis(args[2]); err != nil {
+				fmt.Println(err)
+				return err
+			}
+		}
+		inj.SetLatency(latency, jitter)
+		return nil
+
+	case "partition":
+		if len(args) != 3 {
+			fmt.Println("format: fault partition address address")
+			return errors.New("invalid arguments")
+		}
+		inj.Partition(args[1], args[2])
+		return nil
+
+	case "heal":
+		if len(args) == 1 {
+			inj.HealAll()
+			return nil
+		}
+		if len(args) != 3 {
+			fmt.Println("format: fault heal [address address]")
+			return errors.New("invalid arguments")
+		}
+		inj.Heal(args[1], args[2])
+		return nil
+
+	case "reset":
+		inj.Reset()
+		return nil
+	}
+
+	fmt.Println("Invalid fault command")
+	return errors.New("invalid command")
+}
@@ -0,0 +1,298 @@
+package fault
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const DEFAULT_REORDER_DELAY = 200 * time.Millisecond // smaller than ring READ_TIMEOUT, so a held ping can still be acked
+
+/* Injector
+ * Network fault injection layer shared by ring UDP messages and gRPC calls between nodes
+ * - Drop, Duplicate, Reorder: probability of dropping, sending twice or holding back a packet
+ * - Latency, Jitter: fixed & random extra delay before a packet is sent
+ * - GRPCFailure: probability of failing a gRPC call with codes.Unavailable
+ * - Partitions: pairs of addresses that cannot reach each other, faults are applied on the sending side,
+ *   so a partition must be installed on both endpoints to cut the link in both directions
+ */
+type Injector struct {
+	Self         string // address of current process, used as the source of gRPC calls
+	Drop         float64
+	Duplicate    float64
+	Reorder      float64
+	ReorderDelay time.Duration
+	Latency      time.Duration
+	Jitter       time.Duration
+	GRPCFailure  float64
+	Partitions   map[string]bool
+	rand         *rand.Rand
+	sync.Mutex
+}
+
+// global injector used by ring and gRPC dial options
+var INJECTOR = NewInjector(time.Now().UnixNano())
+
+func NewInjector(seed int64) *Injector {
+	return &Injector{
+		ReorderDelay: DEFAULT_REORDER_DELAY,
+		Partitions:   make(map[string]bool),
+		rand:         rand.New(rand.NewSource(seed)),
+	}
+}
+
+// partition key of an unordered address pair
+func pairKey(a string, b string) string {
+	pair := []string{a, b}
+	sort.Strings(pair)
+	return pair[0] + "|" + pair[1]
+}
+
+func (inj *Injector) SetSelf(addr string) {
+	inj.Lock()
+	defer inj.Unlock()
+	inj.Self = addr
+}
+
+func (inj *Injector) SetDropRate(prob float64) {
+	inj.Lock()
+	defer inj.Unlock()
+	inj.Drop = prob
+}
+
+func (inj *Injector) SetDuplicateRate(prob float64) {
+	inj.Lock()
+	defer inj.Unlock()
+	inj.Duplicate = prob
+}
+
+func (inj *Injector) SetReorderRate(prob float64, delay time.Duration) {
+	inj.Lock()
+	defer inj.Unlock()
+	inj.Reorder = prob
+	inj.ReorderDelay = delay
+}
+
+func (inj *Injector) SetLatency(latency time.Duration, jitter time.Duration) {
+	inj.Lock()
+	defer inj.Unlock()
+	inj.Latency = latency
+	inj.Jitter = jitter
+}
+
+func (inj *Injector) SetGRPCFailureRate(prob float64) {
+	inj.Lock()
+	defer inj.Unlock()
+	inj.GRPCFailure = prob
+}
+
+// Cut the link between two addresses for packets sent through this injector
+func (inj *Injector) Partition(a string, b string) {
+	inj.Lock()
+	defer inj.Unlock()
+	inj.Partitions[pairKey(a, b)] = true
+}
+
+// Restore the link between two addresses
+func (inj *Injector) Heal(a string, b string) {
+	inj.Lock()
+	defer inj.Unlock()
+	delete(inj.Partitions, pairKey(a, b))
+}
+
+func (inj *Injector) HealAll() {
+	inj.Lock()
+	defer inj.Unlock()
+	inj.Partitions = make(map[string]bool)
+}
+
+// Remove all injected faults
+func (inj *Injector) Reset() {
+	inj.Lock()
+	defer inj.Unlock()
+	inj.Drop, inj.Duplicate, inj.Reorder, inj.GRPCFailure = 0, 0, 0, 0
+	inj.Latency, inj.Jitter = 0, 0
+	inj.ReorderDelay = DEFAULT_REORDER_DELAY
+	inj.Partitions = make(map[string]bool)
+}
+
+func (inj *Injector) IsPartitioned(a string, b string) bool {
+	inj.Lock()
+	defer inj.Unlock()
+	return inj.Partitions[pairKey(a, b)]
+}
+
+// roll a dice with given probability, must hold the lock
+func (inj *Injector) roll(prob float64) bool {
+	return prob > 0 && inj.rand.Float64() < prob
+}
+
+// extra delay of a packet, must hold the lock
+func (inj *Injector) delay() time.Duration {
+	delay := inj.Latency
+	if inj.Jitter > 0 {
+		delay += time.Duration(inj.rand.Int63n(int64(inj.Jitter)))
+	}
+	return delay
+}
+
+/*
+ * Send a packet from one address to another through the fault layer
+ *
+ * @param from: address of sender
+ * @param to: address of receiver
+ * @param write: callback function to write the packet
+ * @return int: number of bytes written, 0 if the packet is held back for reordering
+ * @return error: raise error if the packet is dropped or writing fails
+ */
+func (inj *Injector) Send(from string, to string, write func() (int, error)) (int, error) {
+	inj.Lock()
+	partitioned := inj.Partitions[pairKey(from, to)]
+	drop := inj.roll(inj.Drop)
+	duplicate := inj.roll(inj.Duplicate)
+	reorder := inj.roll(inj.Reorder)
+	reorderDelay := inj.ReorderDelay
+	delay := inj.delay()
+	inj.Unlock()
+
+	if partitioned {
+		return 0, fmt.Errorf("network partitioned between %v and %v", from, to)
+	}
+	if drop {
+		return 0, fmt.Errorf("network packet dropped from %v to %v", from, to)
+	}
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+
+	// hold back the packet so that packets sent afterwards overtake it
+	if reorder {
+		go func() {
+			time.Sleep(reorderDelay)
+			write()
+			if duplicate {
+				write()
+			}
+		}()
+		return 0, nil
+	}
+
+	n, err := write()
+	if err == nil && duplicate {
+		write()
+	}
+	return n, err
+}
+
+/*
+ * Apply partitions, failures and latency to a gRPC call before it is sent
+ *
+ * @param method: full name of the called method
+ * @param target: address of the callee
+ * @return error: raise codes.Unavailable if the callee is partitioned away or the call is failed
+ */
+func (inj *Injector) beforeCall(method string, target string) error {
+	inj.Lock()
+	partitioned := inj.Partitions[pairKey(inj.Self, target)]
+	fail := inj.roll(inj.GRPCFailure)
+	delay := inj.delay()
+	inj.Unlock()
+
+	if partitioned {
+		return status.Errorf(codes.Unavailable, "network partitioned between %v and %v", inj.Self, target)
+	}
+	if fail {
+		return status.Errorf(codes.Unavailable, "injected failure on %v to %v", method, target)
+	}
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	return nil
+}
+
+/*
+ * gRPC client interceptor that applies partitions, latency and failures to calls between nodes
+ */
+func (inj *Injector) UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if err := inj.beforeCall(method, cc.Target()); err != nil {
+		return err
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+/*
+ * gRPC client interceptor that applies partitions, latency and failures to streams between nodes.
+ * Failures apply when the stream is opened, partitions & latency to every message of the open stream as well
+ */
+func (inj *Injector) StreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if err := inj.beforeCall(method, cc.Target()); err != nil {
+		return nil, err
+	}
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &faultyStream{ClientStream: stream, inj: inj, target: cc.Target()}, nil
+}
+
+// client stream whose messages go through the fault layer
+type faultyStream struct {
+	grpc.ClientStream
+	inj    *Injector
+	target string
+}
+
+func (fs *faultyStream) SendMsg(m interface{}) error {
+	fs.inj.Lock()
+	partitioned := fs.inj.Partitions[pairKey(fs.inj.Self, fs.target)]
+	delay := fs.inj.delay()
+	fs.inj.Unlock()
+
+	if partitioned {
+		return status.Errorf(codes.Unavailable, "network partitioned between %v and %v", fs.inj.Self, fs.target)
+	}
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	return fs.ClientStream.SendMsg(m)
+}
+
+func (fs *faultyStream) RecvMsg(m interface{}) error {
+	if err := fs.ClientStream.RecvMsg(m); err != nil {
+		return err
+	}
+	// message made it through, but the link was cut meanwhile
+	if fs.inj.IsPartitioned(fs.inj.Self, fs.target) {
+		return status.Errorf(codes.Unavailable, "network partitioned between %v and %v", fs.inj.Self, fs.target)
+	}
+	return nil
+}
+
+func (inj *Injector) String() string {
+	inj.Lock()
+	defer inj.Unlock()
+
+	partitions := make([]string, 0)
+	for pair := range inj.Partitions {
+		partitions = append(partitions, strings.Replace(pair, "|", " <-> ", 1))
+	}
+	sort.Strings(partitions)
+
+	lines := []string{
+		fmt.Sprintf("Drop rate: %v", inj.Drop),
+		fmt.Sprintf("Duplicate rate: %v", inj.Duplicate),
+		fmt.Sprintf("Reorder rate: %v (delay %v)", inj.Reorder, inj.ReorderDelay),
+		fmt.Sprintf("Latency: %v (jitter %v)", inj.Latency, inj.Jitter),
+		fmt.Sprintf("gRPC failure rate: %v", inj.GRPCFailure),
+		fmt.Sprintf("Partitions: %v", partitions),
+	}
+	return strings.Join(lines, "\n")
+}
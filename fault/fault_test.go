@@ -0,0 +1,187 @@
+package fault_test
+
+import (
+	"context"
+	"mp4/fault"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func countingWrite(count *int32) func() (int, error) {
+	return func() (int, error) {
+		atomic.AddInt32(count, 1)
+		return 1, nil
+	}
+}
+
+func TestSendWithoutFaults(t *testing.T) {
+	inj := fault.NewInjector(1)
+	count := int32(0)
+
+	n, err := inj.Send("a:1", "b:1", countingWrite(&count))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, int32(1), count)
+}
+
+func TestDropAndDuplicate(t *testing.T) {
+	inj := fault.NewInjector(1)
+	count := int32(0)
+
+	inj.SetDropRate(1)
+	_, err := inj.Send("a:1", "b:1", countingWrite(&count))
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(0), count)
+
+	inj.SetDropRate(0)
+	inj.SetDuplicateRate(1)
+	_, err = inj.Send("a:1", "b:1", countingWrite(&count))
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), count)
+}
+
+func TestPartition(t *testing.T) {
+	inj := fault.NewInjector(1)
+	count := int32(0)
+
+	inj.Partition("a:1", "b:1")
+	assert.True(t, inj.IsPartitioned("b:1", "a:1"))
+
+	// both directions are cut, other links are untouched
+	_, err := inj.Send("a:1", "b:1", countingWrite(&count))
+	assert.NotNil(t, err)
+	_, err = inj.Send("b:1", "a:1", countingWrite(&count))
+	assert.NotNil(t, err)
+	_, err = inj.Send("a:1", "c:1", countingWrite(&count))
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), count)
+
+	inj.Heal("b:1", "a:1")
+	_, err = inj.Send("a:1", "b:1", countingWrite(&count))
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), count)
+}
+
+func TestReorder(t *testing.T) {
+	inj := fault.NewInjector(1)
+	count := int32(0)
+
+	inj.SetReorderRate(1, 20*time.Millisecond)
+	n, err := inj.Send("a:1", "b:1", countingWrite(&count))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&count))
+
+	// held back packet is eventually sent
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&count) == 1 }, time.Second, 5*time.Millisecond)
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	inj := fault.NewInjector(1)
+	inj.SetSelf("a:1")
+
+	conn, err := grpc.Dial("b:1", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	invoked := 0
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		invoked++
+		return nil
+	}
+
+	err = inj.UnaryClientInterceptor(context.Background(), "/api.SDFSService/Read", nil, nil, conn, invoker)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, invoked)
+
+	inj.Partition("a:1", "b:1")
+	err = inj.UnaryClientInterceptor(context.Background(), "/api.SDFSService/Read", nil, nil, conn, invoker)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	inj.Reset()
+	inj.SetGRPCFailureRate(1)
+	err = inj.UnaryClientInterceptor(context.Background(), "/api.SDFSService/Read", nil, nil, conn, invoker)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 1, invoked)
+}
+
+// client stream that counts the messages it sends & receives
+type countingStream struct {
+	grpc.ClientStream
+	sent     int
+	received int
+}
+
+func (cs *countingStream) SendMsg(m interface{}) error {
+	cs.sent++
+	return nil
+}
+
+func (cs *countingStream) RecvMsg(m interface{}) error {
+	cs.received++
+	return nil
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	inj := fault.NewInjector(1)
+	inj.SetSelf("a:1")
+
+	conn, err := grpc.Dial("b:1", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	opened := 0
+	inner := &countingStream{}
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		opened++
+		return inner, nil
+	}
+	desc := &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}
+
+	stream, err := inj.StreamClientInterceptor(context.Background(), desc, conn, "/api.CoordinatorService/Dispatch", streamer)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, opened)
+	assert.Nil(t, stream.SendMsg(nil))
+	assert.Nil(t, stream.RecvMsg(nil))
+	assert.Equal(t, 1, inner.sent)
+	assert.Equal(t, 1, inner.received)
+
+	// partition installed while the stream is open cuts it in both directions
+	inj.Partition("a:1", "b:1")
+	assert.Equal(t, codes.Unavailable, status.Code(stream.SendMsg(nil)))
+	assert.Equal(t, codes.Unavailable, status.Code(stream.RecvMsg(nil)))
+	assert.Equal(t, 1, inner.sent)
+
+	_, err = inj.StreamClientInterceptor(context.Background(), desc, conn, "/api.CoordinatorService/Dispatch", streamer)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	inj.Reset()
+	inj.SetGRPCFailureRate(1)
+	_, err = inj.StreamClientInterceptor(context.Background(), desc, conn, "/api.CoordinatorService/Dispatch", streamer)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 1, opened)
+}
+
+func TestExecuteCommand(t *testing.T) {
+	inj := fault.NewInjector(1)
+
+	assert.Nil(t, inj.ExecuteCommand([]string{"drop", "0.5"}))
+	assert.Equal(t, 0.5, inj.Drop)
+	assert.Nil(t, inj.ExecuteCommand([]string{"latency", "10", "5"}))
+	assert.Equal(t, 10*time.Millisecond, inj.Latency)
+	assert.Equal(t, 5*time.Millisecond, inj.Jitter)
+	assert.Nil(t, inj.ExecuteCommand([]string{"partition", "a:1", "b:1"}))
+	assert.True(t, inj.IsPartitioned("a:1", "b:1"))
+	assert.NotNil(t, inj.ExecuteCommand([]string{"drop", "2"}))
+
+	assert.Nil(t, inj.ExecuteCommand([]string{"reset"}))
+	assert.Equal(t, 0.0, inj.Drop)
+	assert.False(t, inj.IsPartitioned("a:1", "b:1"))
+}
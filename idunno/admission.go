@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mp4/api"
+	"mp4/logger"
+	"mp4/security"
+	"mp4/utils"
+	"time"
+
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/jedib0t/go-pretty/text"
+)
+
+const MAX_ACTIVE_JOBS = 4            // queued tasks wait until there are less active jobs than this
+const MAX_QUEUED_TASKS_PER_USER = 4  // inference requests rejected once a user has this many queued tasks
+const MAX_QUEUED_TASKS_PER_MODEL = 8 // inference requests rejected once a model has this many queued tasks
+const ADMISSION_RETRY_AFTER = 30     // seconds a rejected client is told to wait before retrying
+const ANONYMOUS_USER = "anonymous"   // user of inference tasks whose client cannot be identified
+
+// User submitting a request, taken from its client certificate so that a client cannot pose as another user to dodge its limit
+func RequestUser(ctx context.Context) string {
+	user, err := security.PeerIdentity(ctx)
+	if err != nil {
+		logger.Error("Cannot identify client, counted as anonymous user: " + err.Error())
+		return ANONYMOUS_USER
+	}
+	return user
+}
+
+/*
+ * Check whether an inference task can be queued, must hold the lock
+ *
+ * @param task: inference task to admit
+ * @return bool: whether the task is admitted
+ * @return string: reason of rejection
+ */
+func (ic *IDunnoCoordinator) Admit(task *api.InferenceTask) (bool, string) {
+	userCount, modelCount := 0, 0
+	for _, queued := range ic.TaskQueue.ToSlice() {
+		if queued.GetUser() == task.GetUser() {
+			userCount++
+		}
+		if queued.GetModel() == task.GetModel() {
+			modelCount++
+		}
+	}
+
+	if userCount >= MAX_QUEUED_TASKS_PER_USER {
+		return false, fmt.Sprintf("user %v already has %v queued tasks", task.GetUser(), userCount)
+	}
+	if modelCount >= MAX_QUEUED_TASKS_PER_MODEL {
+		return false, fmt.Sprintf("model %v already has %v queued tasks", task.GetModel(), modelCount)
+	}
+	return true, utils.EMPTY_STRING
+}
+
+// Whether a queued task can be turned into a new active job, must hold the lock
+func (ic *IDunnoCoordinator) HasJobCapacity() bool {
+	activeCount := 0
+	for _, job := range ic.Scheduler.ActiveJobs {
+		// cancelled jobs are only waiting to be flushed
+		if job.Status != api.JobStatus_Cancelled {
+			activeCount++
+		}
+	}
+	return activeCount < MAX_ACTIVE_JOBS
+}
+
+func (ic *IDunnoCoordinator) PrintQueuedTasks() string {
+	ic.Lock()
+	defer ic.Unlock()
+
+	t := table.NewWriter()
+
+	t.AppendHeader(table.Row{
+		"Model Type",
+		"Batch Size",
+		"User",
+		"Weight",
+		"Deadline",
+		"Queued For",
+	})
+
+	for _, task := range ic.TaskQueue.ToSlice() {
+		deadline := "-"
+		if task.GetDeadline() != nil {
+			deadline = task.GetDeadline().AsTime().Local().Format(DEADLINE_FORMAT)
+		}
+
+		t.AppendRow(table.Row{
+			task.GetModel(),
+			task.GetBatchSize(),
+			task.GetUser(),
+			task.GetWeight(),
+			deadline,
+			fmt.Sprintf("%.2f sec", time.Since(task.GetQueueTime().AsTime()).Seconds()),
+		})
+	}
+
+	t.AppendFooter(table.Row{
+		"Total Queued Tasks",
+		ic.TaskQueue.Len(),
+	})
+
+	t.SetAutoIndex(true)
+	t.SetStyle(table.StyleLight)
+	t.Style().Format.Header = text.FormatTitle
+	t.Style().Format.Footer = text.FormatTitle
+
+	return t.Render()
+}
+
+func (ic *IDunnoCoordinator) PrintQueuedTasksJSON() string {
+	ic.Lock()
+	defer ic.Unlock()
+
+	tasks := make([]map[string]interface{}, 0)
+	for _, task := range ic.TaskQueue.ToSlice() {
+		queued := map[string]interface{}{
+			"modelType": task.GetModel(),
+			"batchSize": task.GetBatchSize(),
+			"user":      task.GetUser(),
+			"weight":    task.GetWeight(),
+			"queuedFor": time.Since(task.GetQueueTime().AsTime()).Seconds(),
+		}
+		// tasks without deadline leave the field out
+		if task.GetDeadline() != nil {
+			queued["deadline"] = task.GetDeadline().AsTime().Unix()
+		}
+		tasks = append(tasks, queued)
+	}
+
+	marshalled, err := json.Marshal(tasks)
+	if err != nil {
+		logger.Error("Failed to marshal queued tasks to JSON: " + err.Error())
+		return utils.EMPTY_STRING
+	}
+
+	return string(marshalled)
+}
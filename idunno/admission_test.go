@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"mp4/api"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// context of a call from a client whose verified certificate has the given common name
+func userContext(name string) context.Context {
+	chain := []*x509.Certificate{{Subject: pkix.Name{CommonName: name}}, {Subject: pkix.Name{CommonName: "IDunno CA"}}}
+	return peer.NewContext(context.Background(), &peer.Peer{
+		Addr:     &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7000},
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{chain}}},
+	})
+}
+
+func Test_Admission_Admit(t *testing.T) {
+	assert := assert.New(t)
+
+	// tasks of the given users on the given model
+	queued := func(model string, users ...string) []*api.InferenceTask {
+		tasks := make([]*api.InferenceTask, 0)
+		for _, user := range users {
+			tasks = append(tasks, &api.InferenceTask{Model: model, User: user})
+		}
+		return tasks
+	}
+	// n tasks of distinct users on the given model
+	distinct := func(model string, n int) []*api.InferenceTask {
+		users := make([]string, 0)
+		for i := 0; i < n; i++ {
+			users = append(users, fmt.Sprintf("user-%v", i))
+		}
+		return queued(model, users...)
+	}
+
+	tests := []struct {
+		name     string
+		queued   []*api.InferenceTask
+		task     *api.InferenceTask
+		admitted bool
+	}{
+		{"empty queue", nil, &api.InferenceTask{Model: "albert", User: "alice"}, true},
+		{"below user limit", queued("albert", "alice", "alice", "alice"), &api.InferenceTask{Model: "albert", User: "alice"}, true},
+		{"user limit", queued("albert", "alice", "alice", "alice", "alice"), &api.InferenceTask{Model: "albert", User: "alice"}, false},
+		{"user limit counts every model", append(queued("albert", "alice", "alice"), queued("resnet50", "alice", "alice")...), &api.InferenceTask{Model: "sentiment", User: "alice"}, false},
+		{"another user", queued("albert", "alice", "alice", "alice", "alice"), &api.InferenceTask{Model: "albert", User: "bob"}, true},
+		{"below model limit", distinct("albert", MAX_QUEUED_TASKS_PER_MODEL-1), &api.InferenceTask{Model: "albert", User: "alice"}, true},
+		{"model limit", distinct("albert", MAX_QUEUED_TASKS_PER_MODEL), &api.InferenceTask{Model: "albert", User: "alice"}, false},
+		{"another model", distinct("albert", MAX_QUEUED_TASKS_PER_MODEL), &api.InferenceTask{Model: "resnet50", User: "alice"}, true},
+	}
+
+	for _, test := range tests {
+		coordinator := NewIDunnoCoordinator(nil, nil)
+		for _, task := range test.queued {
+			coordinator.TaskQueue.Push(task)
+		}
+
+		admitted, reason := coordinator.Admit(test.task)
+		assert.Equal(test.admitted, admitted, test.name)
+		if !test.admitted {
+			assert.NotEmpty(reason, test.name)
+		}
+	}
+}
+
+func Test_Admission_HasJobCapacity(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		name     string
+		statuses []api.JobStatus
+		expected bool
+	}{
+		{"no active job", nil, true},
+		{"below limit", []api.JobStatus{api.JobStatus_Running, api.JobStatus_Running, api.JobStatus_Running}, true},
+		{"limit", []api.JobStatus{api.JobStatus_Running, api.JobStatus_Running, api.JobStatus_Running, api.JobStatus_Running}, false},
+		{"paused jobs count", []api.JobStatus{api.JobStatus_Running, api.JobStatus_Paused, api.JobStatus_Paused, api.JobStatus_Paused}, false},
+		{"cancelled jobs do not count", []api.JobStatus{api.JobStatus_Running, api.JobStatus_Running, api.JobStatus_Running, api.JobStatus_Cancelled}, true},
+	}
+
+	for _, test := range tests {
+		coordinator := NewIDunnoCoordinator(nil, nil)
+		for i, status := range test.statuses {
+			coordinator.Scheduler.AddJob(&api.Job{Id: fmt.Sprintf("job-%v", i), Status: status})
+		}
+		assert.Equal(test.expected, coordinator.HasJobCapacity(), test.name)
+	}
+}
+
+// Limits apply to the user of the client certificate, a client cannot dodge its limit by naming another user
+func Test_Admission_Inference(t *testing.T) {
+	assert := assert.New(t)
+
+	coordinator := newStandaloneCoordinator()
+	addWorkers(coordinator, 1)
+	coordinator.ModelStore.AddModel(coordinator.Models.Get("albert"), "emotion", timestamppb.New(time.Now()))
+	inference := func(ctx context.Context, user string) *api.InferenceResponse {
+		res, err := coordinator.Inference(ctx, &api.InferenceRequest{InferenceTask: &api.InferenceTask{Model: "albert", BatchSize: 10, User: user}})
+		assert.Nil(err)
+		return res
+	}
+
+	for i := 0; i < MAX_QUEUED_TASKS_PER_USER; i++ {
+		assert.Equal(api.ResponseStatus_OK, inference(userContext("alice"), fmt.Sprintf("user-%v", i)).GetStatus())
+	}
+	for _, task := range coordinator.TaskQueue.ToSlice() {
+		assert.Equal("alice", task.GetUser(), "user should be taken from the certificate")
+		assert.NotNil(task.GetQueueTime())
+	}
+
+	res := inference(userContext("alice"), "bob")
+	assert.Equal(api.ResponseStatus_OVER_CAPACITY, res.GetStatus())
+	assert.Equal(int32(ADMISSION_RETRY_AFTER), res.GetRetryAfter())
+	assert.Contains(res.GetMessage(), "alice")
+	assert.Equal(MAX_QUEUED_TASKS_PER_USER, coordinator.TaskQueue.Len(), "rejected task should not be queued")
+
+	// other users are still admitted, clients without a certificate count as the anonymous user
+	assert.Equal(api.ResponseStatus_OK, inference(userContext("bob"), "alice").GetStatus())
+	assert.Equal(api.ResponseStatus_OK, inference(context.Background(), "bob").GetStatus())
+	tasks := coordinator.TaskQueue.ToSlice()
+	assert.Equal("bob", tasks[MAX_QUEUED_TASKS_PER_USER].GetUser())
+	assert.Equal(ANONYMOUS_USER, tasks[MAX_QUEUED_TASKS_PER_USER+1].GetUser())
+}
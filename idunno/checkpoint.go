@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"mp4/api"
+	"mp4/logger"
+	"mp4/sdfs"
+	"mp4/utils"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const CHECKPOINT_FILE = "coordinator.checkpoint"     // SDFS file keeping versioned coordinator checkpoints
+const CHECKPOINT_INTERVAL = 60000 * time.Millisecond // interval of writing a checkpoint if state has changed
+const RESTORE_INTERVAL = 2000 * time.Millisecond     // interval of retrying to load the latest checkpoint
+const MAX_RESTORE_ATTEMPTS = 5                       // give up loading checkpoint after this many attempts
+
+// Whether coordinator has nothing to serve, i.e. it is elected right after the whole cluster restarts. Must hold the lock
+func (ic *IDunnoCoordinator) IsStateEmpty() bool {
+	return len(*ic.ModelStore) == 0 && ic.TaskQueue.Empty() && len(ic.Scheduler.ActiveJobs) == 0 &&
+		len(ic.Scheduler.CompletedJobs) == 0 && ic.Scheduler.PendingJobs.Empty() && len(ic.Graphs) == 0 &&
+		ic.Models.IsDefault() && ic.Datasets.IsDefault()
+}
+
+/*
+ * Write coordinator state into SDFS as a new version of the checkpoint file, skipped if nothing has changed
+ * since the last checkpoint, or the latest checkpoint has not been considered yet
+ */
+func (ic *IDunnoCoordinator) WriteCheckpoint() {
+	ic.Lock()
+	ic.AwaitReplication()
+	if !ic.Restored || ic.IsStateEmpty() || (ic.Log.Epoch == ic.CheckpointEpoch && ic.Log.AppliedIndex == ic.CheckpointIndex) {
+		ic.Unlock()
+		return
+	}
+	snapshot := ic.BuildSnapshot()
+	data, err := proto.Marshal(snapshot)
+	ic.Unlock()
+
+	if err != nil {
+		logger.Error("Failed to marshal checkpoint: " + err.Error())
+		return
+	}
+
+	localFile := utils.CreateTempFilename()
+	if err := ic.SDFSClient.WriteLocalFile(localFile, data); err != nil {
+		logger.Error("Failed to write checkpoint to local file: " + err.Error())
+		return
+	}
+	defer ic.SDFSClient.DeleteLocalFile(localFile)
+
+	if err := ic.SDFSClient.Put(localFile, CHECKPOINT_FILE); err != nil {
+		logger.Error("Failed to put checkpoint to SDFS: " + err.Error())
+		return
+	}
+
+	ic.Lock()
+	ic.CheckpointEpoch, ic.CheckpointIndex = snapshot.GetEpoch(), snapshot.GetLogIndex()
+	ic.Unlock()
+	logger.Info(fmt.Sprintf("Checkpoint of epoch %v at log index %v written", snapshot.GetEpoch(), snapshot.GetLogIndex()))
+}
+
+/*
+ * Load the latest checkpoint from SDFS if coordinator was elected without any state, and merge it into
+ * the state accepted since then. Retried until it is loaded, or it is not found after MAX_RESTORE_ATTEMPTS
+ */
+func (ic *IDunnoCoordinator) RestoreCheckpoint() {
+	ic.Lock()
+	if ic.Restored {
+		ic.Unlock()
+		return
+	}
+	ic.RestoreAttempts++
+	attempts := ic.RestoreAttempts
+	ic.Unlock()
+
+	localFile := utils.CreateTempFilename()
+	err := ic.SDFSClient.Get(localFile, CHECKPOINT_FILE, sdfs.LATEST_VERSION)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get checkpoint from SDFS (attempt %v): %v", attempts, err))
+		ic.GiveUpRestore(attempts)
+		return
+	}
+
+	// checkpoint file is not written to local file if it does not exist in SDFS
+	data, err := ic.SDFSClient.ReadLocalFile(localFile)
+	if err != nil {
+		logger.Info(fmt.Sprintf("No checkpoint found in SDFS (attempt %v)", attempts))
+		ic.GiveUpRestore(attempts)
+		return
+	}
+	defer ic.SDFSClient.DeleteLocalFile(localFile)
+
+	snapshot := &api.CoordinatorBackup{}
+	if err := proto.Unmarshal(data, snapshot); err != nil {
+		logger.Error("Failed to unmarshal checkpoint: " + err.Error())
+		ic.GiveUpRestore(MAX_RESTORE_ATTEMPTS)
+		return
+	}
+
+	ic.Lock()
+	defer ic.Unlock()
+
+	ic.MergeSnapshot(snapshot)
+	ic.Restored = true
+	// standbys receive the restored state as a snapshot on next replication
+	ic.Log.NextIndex = make(map[string]int64)
+	logger.Info(fmt.Sprintf("Restored %v active and %v completed jobs from checkpoint", len(snapshot.GetActiveJobs()), len(snapshot.GetCompletedJobs())))
+}
+
+func (ic *IDunnoCoordinator) GiveUpRestore(attempts int) {
+	if attempts < MAX_RESTORE_ATTEMPTS {
+		return
+	}
+
+	ic.Lock()
+	defer ic.Unlock()
+	logger.Info("Starting coordinator without checkpoint")
+	ic.Restored = true
+}
+
+/*
+ * Merge a checkpoint into coordinator state, entries already known to coordinator are kept as is.
+ * Must hold the lock
+ *
+ * @param snapshot: checkpoint to merge
+ */
+func (ic *IDunnoCoordinator) MergeSnapshot(snapshot *api.CoordinatorBackup) {
+	// versions trained after the restart are kept, and so is the promoted version
+	for k, v := range snapshot.GetModelStore() {
+		if !ic.ModelStore.Contains(utils.ModelType(k)) {
+			(*ic.ModelStore)[utils.ModelType(k)] = v
+			continue
+		}
+		for version, trained := range v.GetVersions() {
+			if !ic.ModelStore.ContainsVersion(utils.ModelType(k), version) {
+				ic.ModelStore.AddModel(trained.GetSpec(), utils.DatasetType(trained.GetDataset()), trained.GetTrainTime())
+			}
+		}
+	}
+
+	// newer versions are kept
+	for _, spec := range snapshot.GetModels() {
+		ic.Models.Register(spec)
+	}
+
+	// schemas registered after the restart are kept
+	for _, spec := range snapshot.GetDatasets() {
+		if curr := ic.Datasets.Get(spec.GetName()); curr == nil || curr.GetRegisterTime() == nil {
+			ic.Datasets.Register(spec)
+		}
+	}
+
+	// tasks queued before the restart go first
+	taskQueue := utils.NewQueue[*api.InferenceTask]()
+	for _, task := range snapshot.GetTaskQueue() {
+		taskQueue.Push(task)
+	}
+	for _, task := range ic.TaskQueue.ToSlice() {
+		taskQueue.Push(task)
+	}
+	ic.TaskQueue = taskQueue
+
+	for _, job := range snapshot.GetCompletedJobs() {
+		if _, ok := ic.Scheduler.CompletedJobs[job.GetId()]; !ok {
+			ic.Scheduler.CompletedJobs[job.GetId()] = job
+		}
+	}
+
+	for _, job := range snapshot.GetActiveJobs() {
+		_, active := ic.Scheduler.ActiveJobs[job.GetId()]
+		_, completed := ic.Scheduler.CompletedJobs[job.GetId()]
+		if !active && !completed {
+			ic.Scheduler.ActiveJobs[job.GetId()] = job
+		}
+	}
+
+	for _, graph := range snapshot.GetGraphs() {
+		if _, ok := ic.Graphs[graph.GetId()]; !ok {
+			ic.Graphs[graph.GetId()] = graph
+		}
+	}
+
+	pending := make(map[string]bool)
+	for _, job := range ic.Scheduler.PendingJobs.ToSlice() {
+		pending[job.GetId()] = true
+	}
+	for _, job := range snapshot.GetPendingJobs() {
+		if _, completed := ic.Scheduler.CompletedJobs[job.GetId()]; !pending[job.GetId()] && !completed {
+			ic.Scheduler.PendingJobs.Push(job)
+		}
+	}
+}
@@ -4,13 +4,18 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"mp4/api"
 	"mp4/ring"
 	"mp4/sdfs"
 	"mp4/utils"
 	"strconv"
 	"strings"
+	"time"
 )
 
+const DEADLINE_FORMAT = "Jan 02 15:04" // format of deadlines in job view
+const DEADLINE_CLOCK_FORMAT = "15:04"  // deadline given as a time of day
+
 type IDunnoClient struct {
 	// ring failure detector
 	Ring *ring.RingServer
@@ -39,8 +44,8 @@ func (ic *IDunnoClient) ExecuteCommand(command string) error {
 		return ic.TrainModel(model, dataset)
 
 	case "serve":
-		if len(args) != 3 {
-			fmt.Println("format: serve model batch_size")
+		if len(args) < 3 || len(args)%2 == 0 {
+			fmt.Println("format: serve model batch_size [--weight weight] [--min-workers count] [--deadline time] [--from job_id] [--filter output] [--version version | --split version:percent,...] [--require key=value,...] [--min-memory mb] [--min-cpus count]")
 			return errors.New("invalid arguments")
 		}
 		model, batchSize := args[1], args[2]
@@ -49,7 +54,229 @@ func (ic *IDunnoClient) ExecuteCommand(command string) error {
 			fmt.Println("batch_size must be an integer")
 			return errors.New("invalid arguments")
 		}
-		return ic.ServeModel(model, size)
+
+		options := ServeOptions{}
+		for i := 3; i < len(args); i += 2 {
+			switch args[i] {
+			case "--weight":
+				options.Weight, err = strconv.ParseFloat(args[i+1], 64)
+				if err != nil || options.Weight <= 0 {
+					fmt.Println("weight must be a positive number")
+					return errors.New("invalid arguments")
+				}
+			case "--min-workers":
+				options.MinWorkers, err = strconv.Atoi(args[i+1])
+				if err != nil || options.MinWorkers < 0 {
+					fmt.Println("min-workers must be a non-negative integer")
+					return errors.New("invalid arguments")
+				}
+			case "--deadline":
+				options.Deadline, err = ParseDeadline(args[i+1], time.Now())
+				if err != nil {
+					fmt.Println("deadline must be a duration (e.g. 90m) or a time of day (e.g. 18:00)")
+					return errors.New("invalid arguments")
+				}
+			case "--from":
+				options.UpstreamJob = args[i+1]
+			case "--filter":
+				options.Filter = args[i+1]
+			case "--version":
+				options.Version, err = strconv.Atoi(args[i+1])
+				if err != nil || options.Version <= 0 {
+					fmt.Println("version must be a positive integer")
+					return errors.New("invalid arguments")
+				}
+			case "--require", "--min-memory", "--min-cpus":
+				if options.Placement == nil {
+					options.Placement = &api.PlacementConstraints{}
+				}
+				err = ParsePlacementOption(options.Placement, args[i], args[i+1])
+				if err != nil {
+					fmt.Println(err.Error())
+					return errors.New("invalid arguments")
+				}
+			case "--split":
+				options.Split, err = ParseSplit(args[i+1])
+				if err != nil {
+					fmt.Println("split must list version:percent pairs, e.g. 1:90,2:10")
+					return errors.New("invalid arguments")
+				}
+			default:
+				fmt.Printf("unknown option %s\n", args[i])
+				return errors.New("invalid arguments")
+			}
+		}
+		if options.Filter != utils.EMPTY_STRING && options.UpstreamJob == utils.EMPTY_STRING {
+			fmt.Println("filter requires --from job_id")
+			return errors.New("invalid arguments")
+		}
+		if options.Version != 0 && options.Split != nil {
+			fmt.Println("either pin a version or split between versions")
+			return errors.New("invalid arguments")
+		}
+		return ic.ServeModel(model, size, options)
+
+	case "promote":
+		if len(args) != 3 {
+			fmt.Println("format: promote model version")
+			return errors.New("invalid arguments")
+		}
+		version, err := strconv.Atoi(args[2])
+		if err != nil || version <= 0 {
+			fmt.Println("version must be a positive integer")
+			return errors.New("invalid arguments")
+		}
+		return ic.PromoteModel(args[1], version)
+
+	case "register-model":
+		if len(args) < 4 || len(args)%2 != 0 {
+			fmt.Println("format: register-model name framework filename|raw [--pattern regex] [--artifact sdfs_file] [--version version]")
+			return errors.New("invalid arguments")
+		}
+
+		spec := &api.ModelSpec{Name: args[1], Framework: args[2]}
+		switch args[3] {
+		case "filename":
+			spec.InputType = api.InputType_FilenameInput
+		case "raw":
+			spec.InputType = api.InputType_RawInput
+		default:
+			fmt.Println("input type must be filename or raw")
+			return errors.New("invalid arguments")
+		}
+
+		for i := 4; i < len(args); i += 2 {
+			switch args[i] {
+			case "--pattern":
+				spec.InputPattern = args[i+1]
+			case "--artifact":
+				spec.Artifact = args[i+1]
+			case "--version":
+				version, err := strconv.Atoi(args[i+1])
+				if err != nil || version <= 0 {
+					fmt.Println("version must be a positive integer")
+					return errors.New("invalid arguments")
+				}
+				spec.Version = int32(version)
+			default:
+				fmt.Printf("unknown option %s\n", args[i])
+				return errors.New("invalid arguments")
+			}
+		}
+		return ic.RegisterModel(spec)
+
+	case "list-models":
+		if len(args) != 1 {
+			fmt.Println("format: list-models")
+			return errors.New("invalid arguments")
+		}
+		return ic.ListModels(utils.EMPTY_STRING)
+
+	case "describe-model":
+		if len(args) != 2 {
+			fmt.Println("format: describe-model name")
+			return errors.New("invalid arguments")
+		}
+		return ic.ListModels(args[1])
+
+	case "register-dataset":
+		if len(args) < 3 || len(args)%2 == 0 {
+			fmt.Println("format: register-dataset sdfs_file files|text|jsonl [--delimiter d] [--columns n] [--label-column n] [--input-field f] [--label-field f] [--labels a,b] [--pattern regex]")
+			return errors.New("invalid arguments")
+		}
+
+		spec := &api.DatasetSpec{Name: args[1]}
+		switch args[2] {
+		case "files":
+			spec.Format = api.DatasetFormat_FileList
+		case "text":
+			spec.Format = api.DatasetFormat_DelimitedText
+		case "jsonl":
+			spec.Format = api.DatasetFormat_JSONLines
+		default:
+			fmt.Println("format must be files, text or jsonl")
+			return errors.New("invalid arguments")
+		}
+
+		for i := 3; i < len(args); i += 2 {
+			var err error
+			var value int
+			switch args[i] {
+			case "--delimiter":
+				spec.Delimiter = args[i+1]
+			case "--columns":
+				value, err = strconv.Atoi(args[i+1])
+				spec.Columns = int32(value)
+			case "--label-column":
+				value, err = strconv.Atoi(args[i+1])
+				spec.LabelColumn = int32(value)
+			case "--input-field":
+				spec.InputField = args[i+1]
+			case "--label-field":
+				spec.LabelField = args[i+1]
+			case "--labels":
+				spec.Labels = strings.Split(args[i+1], ",")
+			case "--pattern":
+				spec.InputPattern = args[i+1]
+			default:
+				fmt.Printf("unknown option %s\n", args[i])
+				return errors.New("invalid arguments")
+			}
+			if err != nil {
+				fmt.Printf("%s must be an integer\n", args[i])
+				return errors.New("invalid arguments")
+			}
+		}
+		return ic.RegisterDataset(spec)
+
+	case "list-datasets":
+		if len(args) != 1 {
+			fmt.Println("format: list-datasets")
+			return errors.New("invalid arguments")
+		}
+		return ic.ListDatasets()
+
+	case "dag":
+		if len(args) != 2 {
+			fmt.Println("format: dag graph.json")
+			return errors.New("invalid arguments")
+		}
+		return ic.SubmitGraph(args[1])
+
+	case "predict":
+		// inputs are separated by "|", since raw inputs may contain spaces
+		if len(args) < 3 {
+			fmt.Println("format: predict model [--slo milliseconds] input | input ...")
+			return errors.New("invalid arguments")
+		}
+		model, rest, sloMillis := args[1], args[2:], 0
+		if rest[0] == "--slo" {
+			if len(rest) < 3 {
+				fmt.Println("format: predict model [--slo milliseconds] input | input ...")
+				return errors.New("invalid arguments")
+			}
+			slo, err := strconv.Atoi(rest[1])
+			if err != nil || slo <= 0 {
+				fmt.Println("slo must be a positive number of milliseconds")
+				return errors.New("invalid arguments")
+			}
+			sloMillis, rest = slo, rest[2:]
+		}
+
+		inputs := make([]string, 0)
+		for _, input := range strings.Split(strings.Join(rest, " "), "|") {
+			if input = strings.TrimSpace(input); input != utils.EMPTY_STRING {
+				inputs = append(inputs, input)
+			}
+		}
+		return ic.Predict(model, inputs, sloMillis)
+
+	case JOB_CANCEL, JOB_PAUSE, JOB_RESUME:
+		if len(args) != 2 {
+			fmt.Printf("format: %s job_id\n", args[0])
+			return errors.New("invalid arguments")
+		}
+		return ic.ControlJob(args[0], args[1])
 
 	case "idunno-worker", "iw", "w":
 		if len(args) != 1 {
@@ -72,6 +299,27 @@ func (ic *IDunnoClient) ExecuteCommand(command string) error {
 		}
 		return ic.GetRealTimeStatus(STATUS_JOB_ID, args[1])
 
+	case "idunno-graphs", "g":
+		if len(args) != 1 {
+			fmt.Println("format: idunno-graphs")
+			return errors.New("invalid arguments")
+		}
+		return ic.GetRealTimeStatus(STATUS_GRAPHS, "")
+
+	case "idunno-online", "o":
+		if len(args) != 1 {
+			fmt.Println("format: idunno-online")
+			return errors.New("invalid arguments")
+		}
+		return ic.GetRealTimeStatus(STATUS_ONLINE, "")
+
+	case "idunno-queue", "q":
+		if len(args) != 1 {
+			fmt.Println("format: idunno-queue")
+			return errors.New("invalid arguments")
+		}
+		return ic.GetRealTimeStatus(STATUS_QUEUED_TASKS, "")
+
 	case "idunno-completed-jobs", "cj":
 		if len(args) != 1 {
 			fmt.Println("format: idunno-completed-jobs")
@@ -102,3 +350,31 @@ func (ic *IDunnoClient) ExecuteCommand(command string) error {
 		return errors.New("invalid command")
 	}
 }
+
+/*
+ * Parse a job deadline given either as a duration from now, or as a time of day
+ * (the next occurrence of it)
+ *
+ * @param value: deadline argument, e.g. "90m" or "18:00"
+ * @param now: current time
+ * @return time.Time: absolute deadline
+ */
+func ParseDeadline(value string, now time.Time) (time.Time, error) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		if duration <= 0 {
+			return time.Time{}, fmt.Errorf("deadline %v is not in the future", value)
+		}
+		return now.Add(duration), nil
+	}
+
+	clock, err := time.ParseInLocation(DEADLINE_CLOCK_FORMAT, value, now.Location())
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	deadline := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+	if !deadline.After(now) {
+		deadline = deadline.AddDate(0, 0, 1)
+	}
+	return deadline, nil
+}
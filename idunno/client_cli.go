@@ -2,33 +2,68 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"mp4/api"
+	"mp4/connpool"
 	"mp4/sdfs"
 	"mp4/utils"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
-	"google.golang.org/grpc"
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/jedib0t/go-pretty/text"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type IDunnoClientCLI interface {
 	TrainModel(modelType string, dataset string) error
-	ServeModel(modelType string, batchSize int) error
+	ServeModel(modelType string, batchSize int, options ServeOptions) error
+	ControlJob(action string, jobId string) error
+	SubmitGraph(filename string) error
+	Predict(modelType string, inputs []string, sloMillis int) error
+	RegisterModel(spec *api.ModelSpec) error
+	ListModels(name string) error
+	PromoteModel(modelType string, version int) error
+	RegisterDataset(spec *api.DatasetSpec) error
+	ListDatasets() error
 }
 
-func (ic *IDunnoClient) TrainModel(modelType string, dataset string) error {
-	if !IsValidModelType(modelType) {
-		fmt.Printf("Invalid model type: %s\n", modelType)
-		fmt.Printf("Supported model types are: %v\n", utils.SupportedModelTypes)
-		return errors.New("invalid model type")
-	}
+// Optional scheduling and dataset options of serve command
+type ServeOptions struct {
+	Weight      float64                   // scheduling weight, 0 means default weight
+	MinWorkers  int                       // number of workers guaranteed to the job
+	Deadline    time.Time                 // time the job should be done by, zero if none
+	UpstreamJob string                    // completed job whose results are used as dataset, empty if none
+	Filter      string                    // keep upstream results whose output contains the filter
+	Version     int                       // trained version of the model to serve, promoted version if 0
+	Split       []*api.VersionShare       // split inputs between trained versions instead, nil if none
+	Placement   *api.PlacementConstraints // constraints on the workers of the job, nil if none
+}
 
-	if !IsValidDatasetType(dataset) {
-		fmt.Printf("Invalid dataset type: %s\n", dataset)
-		fmt.Printf("Supported dataset types are: %v\n", utils.SupportedDatasetTypes)
-		return errors.New("invalid dataset type")
-	}
+// Job graph described in a local JSON file
+type GraphSpec struct {
+	Nodes []struct {
+		Name       string  `json:"name"`
+		Model      string  `json:"model"`
+		BatchSize  int     `json:"batchSize"`
+		Upstream   string  `json:"upstream"`
+		Filter     string  `json:"filter"`
+		Weight     float64 `json:"weight"`
+		MinWorkers int     `json:"minWorkers"`
+		Version    int     `json:"version"`
+		Placement  struct {
+			MinMemoryMb int64             `json:"minMemoryMb"`
+			MinCpus     int32             `json:"minCpus"`
+			Labels      map[string]string `json:"labels"`
+		} `json:"placement"`
+	} `json:"nodes"`
+}
 
+func (ic *IDunnoClient) TrainModel(modelType string, dataset string) error {
 	err := ic.SDFSClient.List(dataset)
 	if err != nil {
 		fmt.Printf("Error retrieving dataset %s: %s\n", dataset, err.Error())
@@ -45,16 +80,15 @@ func (ic *IDunnoClient) TrainModel(modelType string, dataset string) error {
 	}
 
 	// dial to coordinator
-	conn, err := grpc.Dial(coordinatorAddr, sdfs.GRPC_OPTIONS...)
+	conn, err := connpool.POOL.Get(coordinatorAddr, sdfs.GRPC_OPTIONS...)
 	if err != nil {
 		fmt.Printf("Failed to dial coordinator: " + coordinatorAddr)
 		return err
 	}
-	defer conn.Close()
 
 	// send Train gRPC to coordinator
 	client := api.NewCoordinatorServiceClient(conn)
-	_, err = client.Train(context.Background(), &api.TrainRequest{
+	res, err := client.Train(context.Background(), &api.TrainRequest{
 		TrainTask: &api.TrainTask{
 			Model:   modelType,
 			Dataset: dataset,
@@ -65,17 +99,11 @@ func (ic *IDunnoClient) TrainModel(modelType string, dataset string) error {
 		return err
 	}
 
-	fmt.Printf("Successfully sent train request to coordinator\n")
+	fmt.Printf("Successfully trained version %v of model %s\n", res.GetVersion(), modelType)
 	return nil
 }
 
-func (ic *IDunnoClient) ServeModel(modelType string, batchSize int) error {
-	if !IsValidModelType(modelType) {
-		fmt.Printf("Invalid model type: %s\n", modelType)
-		fmt.Printf("Supported model types are: %v\n", utils.SupportedModelTypes)
-		return errors.New("invalid model type")
-	}
-
+func (ic *IDunnoClient) ServeModel(modelType string, batchSize int, options ServeOptions) error {
 	fmt.Printf("Serving model %s with batch size %v...\n", modelType, batchSize)
 
 	// creating gRPC Coordinator client
@@ -86,30 +114,534 @@ func (ic *IDunnoClient) ServeModel(modelType string, batchSize int) error {
 	}
 
 	// dial to coordinator
-	conn, err := grpc.Dial(coordinatorAddr, sdfs.GRPC_OPTIONS...)
+	conn, err := connpool.POOL.Get(coordinatorAddr, sdfs.GRPC_OPTIONS...)
 	if err != nil {
 		fmt.Printf("Failed to dial coordinator: " + coordinatorAddr)
 		return err
 	}
-	defer conn.Close()
+
+	task := &api.InferenceTask{
+		Model:       modelType,
+		BatchSize:   int32(batchSize),
+		Weight:      float32(options.Weight),
+		MinWorkers:  int32(options.MinWorkers),
+		UpstreamJob: options.UpstreamJob,
+		Filter:      options.Filter,
+		Version:     int32(options.Version),
+		Split:       options.Split,
+		Placement:   options.Placement,
+	}
+	if !options.Deadline.IsZero() {
+		task.Deadline = timestamppb.New(options.Deadline)
+	}
 
 	// send Train gRPC to coordinator
 	client := api.NewCoordinatorServiceClient(conn)
-	_, err = client.Inference(context.Background(), &api.InferenceRequest{
-		InferenceTask: &api.InferenceTask{
-			Model:     modelType,
-			BatchSize: int32(batchSize),
-		},
+	res, err := client.Inference(context.Background(), &api.InferenceRequest{
+		InferenceTask: task,
 	})
 	if err != nil {
 		fmt.Printf("Error sending train request to coordinator: %s\n", err.Error())
 		return err
 	}
 
+	if res.GetStatus() == api.ResponseStatus_OVER_CAPACITY {
+		fmt.Printf("IDunno is over capacity: %s, retry after %v seconds\n", res.GetMessage(), res.GetRetryAfter())
+		return errors.New("over capacity")
+	}
+
 	fmt.Printf("Successfully uploaded serving job to Idunno\n")
 	return nil
 }
 
+/*
+ * Cancel, pause or resume an active job on coordinator
+ *
+ * @param action: one of JOB_CANCEL, JOB_PAUSE, JOB_RESUME
+ * @param jobId: id of the job to control
+ */
+func (ic *IDunnoClient) ControlJob(action string, jobId string) error {
+	// creating gRPC Coordinator client
+	coordinatorAddr, err := ic.Ring.LookupLeader()
+	if err != nil {
+		fmt.Printf("Error retrieving coordinator: %s\n", err.Error())
+		return err
+	}
+
+	// dial to coordinator
+	conn, err := connpool.POOL.Get(coordinatorAddr, sdfs.GRPC_OPTIONS...)
+	if err != nil {
+		fmt.Printf("Failed to dial coordinator: " + coordinatorAddr)
+		return err
+	}
+
+	client := api.NewCoordinatorServiceClient(conn)
+	req := &api.JobControlRequest{JobId: jobId}
+
+	switch action {
+	case JOB_CANCEL:
+		_, err = client.CancelJob(context.Background(), req)
+	case JOB_PAUSE:
+		_, err = client.PauseJob(context.Background(), req)
+	case JOB_RESUME:
+		_, err = client.ResumeJob(context.Background(), req)
+	default:
+		return fmt.Errorf("invalid job action %v", action)
+	}
+	if err != nil {
+		fmt.Printf("Error sending %s request to coordinator: %s\n", action, err.Error())
+		return err
+	}
+
+	fmt.Printf("Successfully sent %s request of job %s to coordinator\n", action, jobId)
+	return nil
+}
+
+/*
+ * Submit a graph of jobs described in a local JSON file, downstream jobs start when their upstream job completes
+ *
+ * @param filename: local JSON file of the job graph
+ */
+func (ic *IDunnoClient) SubmitGraph(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Printf("Error reading job graph %s: %s\n", filename, err.Error())
+		return err
+	}
+
+	spec := &GraphSpec{}
+	if err := json.Unmarshal(data, spec); err != nil {
+		fmt.Printf("Error parsing job graph %s: %s\n", filename, err.Error())
+		return err
+	}
+
+	graph := &api.JobGraph{Nodes: make([]*api.GraphNode, 0)}
+	for _, node := range spec.Nodes {
+		graph.Nodes = append(graph.Nodes, &api.GraphNode{
+			Name: node.Name,
+			Task: &api.InferenceTask{
+				Model:      node.Model,
+				BatchSize:  int32(node.BatchSize),
+				Weight:     float32(node.Weight),
+				MinWorkers: int32(node.MinWorkers),
+				Version:    int32(node.Version),
+				Placement: &api.PlacementConstraints{
+					MinMemoryMb: node.Placement.MinMemoryMb,
+					MinCpus:     node.Placement.MinCpus,
+					Labels:      node.Placement.Labels,
+				},
+			},
+			Upstream: node.Upstream,
+			Filter:   node.Filter,
+		})
+	}
+
+	// creating gRPC Coordinator client
+	coordinatorAddr, err := ic.Ring.LookupLeader()
+	if err != nil {
+		fmt.Printf("Error retrieving coordinator: %s\n", err.Error())
+		return err
+	}
+
+	// dial to coordinator
+	conn, err := connpool.POOL.Get(coordinatorAddr, sdfs.GRPC_OPTIONS...)
+	if err != nil {
+		fmt.Printf("Failed to dial coordinator: " + coordinatorAddr)
+		return err
+	}
+
+	client := api.NewCoordinatorServiceClient(conn)
+	res, err := client.SubmitGraph(context.Background(), &api.SubmitGraphRequest{Graph: graph})
+	if err != nil {
+		fmt.Printf("Error sending job graph to coordinator: %s\n", err.Error())
+		return err
+	}
+	if res.GetStatus() != api.ResponseStatus_OK {
+		fmt.Printf("Job graph is rejected (%s): %s\n", res.GetStatus(), res.GetMessage())
+		return errors.New("job graph rejected")
+	}
+
+	fmt.Printf("Successfully submitted job graph %s\n", res.GetGraphId())
+	return nil
+}
+
+/*
+ * Evaluate a few inputs synchronously on a worker serving the model
+ *
+ * @param modelType: model to evaluate the inputs with
+ * @param inputs: raw inputs, local image files are sent as image bytes
+ * @param sloMillis: latency objective in milliseconds, default objective if 0
+ */
+func (ic *IDunnoClient) Predict(modelType string, inputs []string, sloMillis int) error {
+	req := &api.PredictRequest{
+		Model:     modelType,
+		Inputs:    make([]string, 0),
+		Images:    make([][]byte, 0),
+		SloMillis: int32(sloMillis),
+	}
+	for _, input := range inputs {
+		if info, err := os.Stat(input); err == nil && !info.IsDir() {
+			image, err := os.ReadFile(input)
+			if err != nil {
+				fmt.Printf("Error reading image %s: %s\n", input, err.Error())
+				return err
+			}
+			req.Images = append(req.Images, image)
+			continue
+		}
+		req.Inputs = append(req.Inputs, input)
+	}
+
+	// creating gRPC Coordinator client
+	coordinatorAddr, err := ic.Ring.LookupLeader()
+	if err != nil {
+		fmt.Printf("Error retrieving coordinator: %s\n", err.Error())
+		return err
+	}
+
+	// dial to coordinator
+	conn, err := connpool.POOL.Get(coordinatorAddr, sdfs.GRPC_OPTIONS...)
+	if err != nil {
+		fmt.Printf("Failed to dial coordinator: " + coordinatorAddr)
+		return err
+	}
+
+	client := api.NewCoordinatorServiceClient(conn)
+	res, err := client.Predict(context.Background(), req)
+	if err != nil {
+		fmt.Printf("Error sending predict request to coordinator: %s\n", err.Error())
+		return err
+	}
+
+	if res.GetStatus() == api.ResponseStatus_OVER_CAPACITY {
+		fmt.Printf("IDunno is over capacity: %s, retry after %v seconds\n", res.GetMessage(), res.GetRetryAfter())
+		return errors.New("over capacity")
+	}
+	if res.GetStatus() != api.ResponseStatus_OK {
+		fmt.Printf("Predict request failed (%s): %s\n", res.GetStatus(), res.GetMessage())
+		return errors.New("predict request failed")
+	}
+
+	for _, result := range res.GetResults() {
+		fmt.Printf("%s %s\n", result.GetInput(), result.GetOutput())
+	}
+	fmt.Printf("Served by %s in %v ms (SLO met: %v)\n", res.GetWorker(), res.GetLatencyMillis(), res.GetSloMet())
+	return nil
+}
+
+/*
+ * Add a model to the registry of coordinator, so that it can be trained & served
+ *
+ * @param spec: model to register, version 0 means the next version
+ */
+func (ic *IDunnoClient) RegisterModel(spec *api.ModelSpec) error {
+	if spec.GetArtifact() != utils.EMPTY_STRING {
+		err := ic.SDFSClient.List(spec.GetArtifact())
+		if err != nil {
+			fmt.Printf("Error retrieving model artifact %s: %s\n", spec.GetArtifact(), err.Error())
+			return err
+		}
+	}
+
+	// creating gRPC Coordinator client
+	coordinatorAddr, err := ic.Ring.LookupLeader()
+	if err != nil {
+		fmt.Printf("Error retrieving coordinator: %s\n", err.Error())
+		return err
+	}
+
+	// dial to coordinator
+	conn, err := connpool.POOL.Get(coordinatorAddr, sdfs.GRPC_OPTIONS...)
+	if err != nil {
+		fmt.Printf("Failed to dial coordinator: " + coordinatorAddr)
+		return err
+	}
+
+	client := api.NewCoordinatorServiceClient(conn)
+	res, err := client.RegisterModel(context.Background(), &api.RegisterModelRequest{Spec: spec})
+	if err != nil {
+		fmt.Printf("Error sending register request to coordinator: %s\n", err.Error())
+		return err
+	}
+	if res.GetStatus() != api.ResponseStatus_OK {
+		fmt.Printf("Model is rejected (%s): %s\n", res.GetStatus(), res.GetMessage())
+		return errors.New("model rejected")
+	}
+
+	fmt.Printf("Successfully registered model %s version %v\n", res.GetSpec().GetName(), res.GetSpec().GetVersion())
+	return nil
+}
+
+/*
+ * Print registered models as a table, or the details of one model
+ *
+ * @param name: model to describe, all models are listed if empty
+ */
+func (ic *IDunnoClient) ListModels(name string) error {
+	// creating gRPC Coordinator client
+	coordinatorAddr, err := ic.Ring.LookupLeader()
+	if err != nil {
+		fmt.Printf("Error retrieving coordinator: %s\n", err.Error())
+		return err
+	}
+
+	// dial to coordinator
+	conn, err := connpool.POOL.Get(coordinatorAddr, sdfs.GRPC_OPTIONS...)
+	if err != nil {
+		fmt.Printf("Failed to dial coordinator: " + coordinatorAddr)
+		return err
+	}
+
+	client := api.NewCoordinatorServiceClient(conn)
+	res, err := client.ListModels(context.Background(), &api.ListModelsRequest{Name: name})
+	if err != nil {
+		fmt.Printf("Error listing models: %s\n", err.Error())
+		return err
+	}
+
+	if name != utils.EMPTY_STRING {
+		spec := res.GetModels()[0]
+		fmt.Printf("Name:          %s\n", spec.GetName())
+		fmt.Printf("Version:       %v\n", spec.GetVersion())
+		fmt.Printf("Framework:     %s\n", spec.GetFramework())
+		fmt.Printf("Input Type:    %s\n", spec.GetInputType())
+		fmt.Printf("Input Pattern: %s\n", spec.GetInputPattern())
+		fmt.Printf("Artifact:      %s\n", FormatArtifact(spec))
+		if spec.GetRegisterTime() != nil {
+			fmt.Printf("Registered:    %s\n", spec.GetRegisterTime().AsTime().Local().Format("2006-01-02 15:04:05"))
+		}
+
+		trained := res.GetTrained()[spec.GetName()]
+		if len(trained.GetVersions()) == 0 {
+			fmt.Printf("Trained:       %s\n", FormatTrainedVersions(trained))
+			return nil
+		}
+
+		t := table.NewWriter()
+		t.AppendHeader(table.Row{
+			"Version",
+			"Dataset",
+			"Artifact",
+			"Trained",
+			"Promoted",
+		})
+		for version, model := range trained.GetVersions() {
+			t.AppendRow(table.Row{
+				version,
+				model.GetDataset(),
+				FormatArtifact(model.GetSpec()),
+				model.GetTrainTime().AsTime().Local().Format("2006-01-02 15:04:05"),
+				version == trained.GetPromoted(),
+			})
+		}
+		t.SetStyle(table.StyleLight)
+		t.Style().Format.Header = text.FormatTitle
+		t.SortBy([]table.SortBy{{Name: "Version", Mode: table.Asc}})
+
+		fmt.Printf("\n%v\n", t.Render())
+		return nil
+	}
+
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{
+		"Model",
+		"Version",
+		"Framework",
+		"Input Type",
+		"Artifact",
+		"Trained Versions",
+	})
+	for _, spec := range res.GetModels() {
+		t.AppendRow(table.Row{
+			spec.GetName(),
+			spec.GetVersion(),
+			spec.GetFramework(),
+			spec.GetInputType(),
+			FormatArtifact(spec),
+			FormatTrainedVersions(res.GetTrained()[spec.GetName()]),
+		})
+	}
+	t.SetStyle(table.StyleLight)
+	t.Style().Format.Header = text.FormatTitle
+	t.SortBy([]table.SortBy{{Name: "Model", Mode: table.Asc}})
+
+	fmt.Printf("\n%v\n", t.Render())
+	return nil
+}
+
+/*
+ * Add the schema of a dataset stored in SDFS to the registry of coordinator
+ *
+ * @param spec: schema of the dataset, named after its SDFS file
+ */
+func (ic *IDunnoClient) RegisterDataset(spec *api.DatasetSpec) error {
+	err := ic.SDFSClient.List(spec.GetName())
+	if err != nil {
+		fmt.Printf("Error retrieving dataset %s: %s\n", spec.GetName(), err.Error())
+		return err
+	}
+
+	// creating gRPC Coordinator client
+	coordinatorAddr, err := ic.Ring.LookupLeader()
+	if err != nil {
+		fmt.Printf("Error retrieving coordinator: %s\n", err.Error())
+		return err
+	}
+
+	// dial to coordinator
+	conn, err := connpool.POOL.Get(coordinatorAddr, sdfs.GRPC_OPTIONS...)
+	if err != nil {
+		fmt.Printf("Failed to dial coordinator: " + coordinatorAddr)
+		return err
+	}
+
+	client := api.NewCoordinatorServiceClient(conn)
+	res, err := client.RegisterDataset(context.Background(), &api.RegisterDatasetRequest{Spec: spec})
+	if err != nil {
+		fmt.Printf("Error sending register request to coordinator: %s\n", err.Error())
+		return err
+	}
+	if res.GetStatus() != api.ResponseStatus_OK {
+		fmt.Printf("Dataset is rejected (%s): %s\n", res.GetStatus(), res.GetMessage())
+		return errors.New("dataset rejected")
+	}
+
+	fmt.Printf("Successfully registered dataset %s\n", spec.GetName())
+	return nil
+}
+
+func (ic *IDunnoClient) ListDatasets() error {
+	// creating gRPC Coordinator client
+	coordinatorAddr, err := ic.Ring.LookupLeader()
+	if err != nil {
+		fmt.Printf("Error retrieving coordinator: %s\n", err.Error())
+		return err
+	}
+
+	// dial to coordinator
+	conn, err := connpool.POOL.Get(coordinatorAddr, sdfs.GRPC_OPTIONS...)
+	if err != nil {
+		fmt.Printf("Failed to dial coordinator: " + coordinatorAddr)
+		return err
+	}
+
+	client := api.NewCoordinatorServiceClient(conn)
+	res, err := client.ListDatasets(context.Background(), &api.ListDatasetsRequest{})
+	if err != nil {
+		fmt.Printf("Error listing datasets: %s\n", err.Error())
+		return err
+	}
+
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{
+		"Dataset",
+		"Format",
+		"Schema",
+		"Labels",
+		"Input Pattern",
+	})
+	for _, spec := range res.GetDatasets() {
+		t.AppendRow(table.Row{
+			spec.GetName(),
+			spec.GetFormat(),
+			FormatDatasetSchema(spec),
+			strings.Join(spec.GetLabels(), ","),
+			spec.GetInputPattern(),
+		})
+	}
+	t.SetStyle(table.StyleLight)
+	t.Style().Format.Header = text.FormatTitle
+	t.SortBy([]table.SortBy{{Name: "Dataset", Mode: table.Asc}})
+
+	fmt.Printf("\n%v\n", t.Render())
+	return nil
+}
+
+// Format options of a dataset schema, i.e. "delimiter ';', 2 columns, label column 2"
+func FormatDatasetSchema(spec *api.DatasetSpec) string {
+	switch spec.GetFormat() {
+	case api.DatasetFormat_DelimitedText:
+		schema := fmt.Sprintf("delimiter %q", spec.GetDelimiter())
+		if spec.GetColumns() > 0 {
+			schema += fmt.Sprintf(", %v columns", spec.GetColumns())
+		}
+		if spec.GetLabelColumn() > 0 {
+			schema += fmt.Sprintf(", label column %v", spec.GetLabelColumn())
+		}
+		return schema
+	case api.DatasetFormat_JSONLines:
+		if spec.GetLabelField() == utils.EMPTY_STRING {
+			return fmt.Sprintf("input field %q", spec.GetInputField())
+		}
+		return fmt.Sprintf("input field %q, label field %q", spec.GetInputField(), spec.GetLabelField())
+	}
+	return utils.EMPTY_STRING
+}
+
+func FormatArtifact(spec *api.ModelSpec) string {
+	if spec.GetArtifact() == utils.EMPTY_STRING {
+		return "(built-in)"
+	}
+	return spec.GetArtifact()
+}
+
+// Trained versions of a model and their dataset, i.e. "v1 imagenet (promoted), v2 imagenet"
+func FormatTrainedVersions(trained *api.ModelVersions) string {
+	if len(trained.GetVersions()) == 0 {
+		return "(not trained)"
+	}
+
+	versions := make([]int32, 0)
+	for version := range trained.GetVersions() {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+
+	formatted := make([]string, 0)
+	for _, version := range versions {
+		entry := fmt.Sprintf("v%v %v", version, trained.GetVersions()[version].GetDataset())
+		if version == trained.GetPromoted() {
+			entry += " (promoted)"
+		}
+		formatted = append(formatted, entry)
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// Make a trained version of a model the one served by default
+func (ic *IDunnoClient) PromoteModel(modelType string, version int) error {
+	// creating gRPC Coordinator client
+	coordinatorAddr, err := ic.Ring.LookupLeader()
+	if err != nil {
+		fmt.Printf("Error retrieving coordinator: %s\n", err.Error())
+		return err
+	}
+
+	// dial to coordinator
+	conn, err := connpool.POOL.Get(coordinatorAddr, sdfs.GRPC_OPTIONS...)
+	if err != nil {
+		fmt.Printf("Failed to dial coordinator: " + coordinatorAddr)
+		return err
+	}
+
+	client := api.NewCoordinatorServiceClient(conn)
+	res, err := client.PromoteModel(context.Background(), &api.PromoteModelRequest{
+		Model:   modelType,
+		Version: int32(version),
+	})
+	if err != nil {
+		fmt.Printf("Error sending promote request to coordinator: %s\n", err.Error())
+		return err
+	}
+	if res.GetStatus() != api.ResponseStatus_OK {
+		fmt.Printf("Promotion is rejected (%s): %s\n", res.GetStatus(), res.GetMessage())
+		return errors.New("promotion rejected")
+	}
+
+	fmt.Printf("Version %v of model %s is now served by default\n", version, modelType)
+	return nil
+}
+
 func (ic *IDunnoClient) GetRealTimeStatus(which string, payload string) error {
 	// creating gRPC Coordinator client
 	coordinatorAddr, err := ic.Ring.LookupLeader()
@@ -119,12 +651,11 @@ func (ic *IDunnoClient) GetRealTimeStatus(which string, payload string) error {
 	}
 
 	// dial to coordinator
-	conn, err := grpc.Dial(coordinatorAddr, sdfs.GRPC_OPTIONS...)
+	conn, err := connpool.POOL.Get(coordinatorAddr, sdfs.GRPC_OPTIONS...)
 	if err != nil {
 		fmt.Printf("Failed to dial coordinator: " + coordinatorAddr)
 		return err
 	}
-	defer conn.Close()
 
 	client := api.NewCoordinatorServiceClient(conn)
 
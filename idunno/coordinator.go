@@ -3,10 +3,10 @@ package main
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"math"
 	"mp4/api"
+	"mp4/connpool"
 	"mp4/logger"
 	"mp4/ralloc"
 	"mp4/ring"
@@ -19,15 +19,15 @@ import (
 	"github.com/jedib0t/go-pretty/table"
 	"github.com/jedib0t/go-pretty/text"
 	"github.com/montanaflynn/stats"
-	"google.golang.org/grpc"
 )
 
 const PROCESS_QUEUE_INTERVAL = 1000 * time.Millisecond
 const RESCHEDULE_INTERVAL = 2000 * time.Millisecond
 const FLUSH_JOB_IONTERVAL = 2000 * time.Millisecond
 const REFRESH_INTERVAL = 2000 * time.Millisecond
-const BACKUP_INTERVAL = 3000 * time.Millisecond
 const MEASURE_QPS_INTERVAL = 1000 * time.Millisecond
+const REGISTER_INTERVAL = 5000 * time.Millisecond // interval of fetching capabilities of workers not registered yet
+const REGISTER_TIMEOUT = 2000 * time.Millisecond
 
 type WhichStatus int // command shortcuts client sends to coordinator
 
@@ -41,17 +41,40 @@ const (
 	STATUS_JSON_JOBS           = "jj"
 	STATUS_JSON_JOB_ID         = "jij"
 	STATUS_JSON_COMPLETED_JOBS = "jcj"
+	STATUS_QUEUED_TASKS        = "q"
+	STATUS_JSON_QUEUED_TASKS   = "jq"
+	STATUS_GRAPHS              = "g"
+	STATUS_JSON_GRAPHS         = "jg"
+	STATUS_ONLINE              = "o"
+	STATUS_JSON_ONLINE         = "jo"
+)
+
+const (
+	JOB_CANCEL = "cancel"
+	JOB_PAUSE  = "pause"
+	JOB_RESUME = "resume"
 )
 
 type IDunnoCoordinator struct {
 	TaskQueue       *utils.Queue[*api.InferenceTask]
 	ModelStore      *ModelStore
+	Models          *ModelRegistry   // registered models
+	Datasets        *DatasetRegistry // registered dataset schemas
 	Ring            *ring.RingServer
 	ResourceManager *ResourceManager
 	Scheduler       *IDunnoScheduler
 	SDFSClient      *sdfs.SDFSClient
-	IsCoordinator   bool // flag to indicate if this coordinator is serving requests
-	IsScheduling    bool // flag to indicate if this coordinator is scheduling jobs
+	Graphs          map[string]*api.JobGraph // submitted job graphs
+	Online          map[string]*OnlineStats  // model -> online traffic, not replicated
+	Log             *CoordinatorLog          // replicated log of state mutations
+	Dispatcher      *Dispatcher              // dispatch streams of workers, not replicated
+	Replicated      *sync.Cond               // signaled whenever an entry is done replicating, on the coordinator lock
+	IsCoordinator   bool                     // flag to indicate if this coordinator is serving requests
+	IsScheduling    bool                     // flag to indicate if this coordinator is scheduling jobs
+	Restored        bool                     // flag to indicate if the latest checkpoint has been considered
+	RestoreAttempts int                      // number of attempts to load the latest checkpoint
+	CheckpointEpoch int64                    // epoch of the last written checkpoint
+	CheckpointIndex int64                    // log index of the last written checkpoint
 	sync.Mutex
 	api.CoordinatorServiceServer
 }
@@ -60,34 +83,59 @@ func NewIDunnoCoordinator(ring *ring.RingServer, sdfsClient *sdfs.SDFSClient) *I
 	rm := NewResourceManager()
 	scheduler := NewIDunnoScheduler(rm)
 
-	return &IDunnoCoordinator{
+	ic := &IDunnoCoordinator{
 		TaskQueue:       utils.NewQueue[*api.InferenceTask](),
 		ModelStore:      NewModelStore(),
+		Models:          NewModelRegistry(),
+		Datasets:        NewDatasetRegistry(),
 		Ring:            ring,
 		ResourceManager: rm,
 		Scheduler:       scheduler,
 		SDFSClient:      sdfsClient,
+		Graphs:          make(map[string]*api.JobGraph),
+		Online:          make(map[string]*OnlineStats),
+		Log:             NewCoordinatorLog(),
+		Dispatcher:      NewDispatcher(),
 		IsCoordinator:   false,
 		IsScheduling:    false,
 	}
+	ic.Replicated = sync.NewCond(&ic.Mutex)
+	return ic
 }
 
 // Tasks that runs periodically
 func (ic *IDunnoCoordinator) Corn() {
 	go func() {
 		for {
-			time.Sleep(BACKUP_INTERVAL)
-			if !ic.IsCoordinator {
+			time.Sleep(COMPACT_INTERVAL)
+			ic.CompactLog()
+		}
+	}()
+
+	go func() {
+		for {
+			time.Sleep(RESTORE_INTERVAL)
+			if !ic.CanSchedule() {
 				continue
 			}
-			ic.BackupCoordinatorData()
+			ic.RestoreCheckpoint()
+		}
+	}()
+
+	go func() {
+		for {
+			time.Sleep(CHECKPOINT_INTERVAL)
+			if !ic.CanSchedule() {
+				continue
+			}
+			ic.WriteCheckpoint()
 		}
 	}()
 
 	go func() {
 		for {
 			time.Sleep(PROCESS_QUEUE_INTERVAL)
-			if !ic.IsCoordinator {
+			if !ic.CanSchedule() {
 				continue
 			}
 			ic.ProcessQueuedJob()
@@ -97,7 +145,7 @@ func (ic *IDunnoCoordinator) Corn() {
 	go func() {
 		for {
 			time.Sleep(RESCHEDULE_INTERVAL)
-			if !ic.IsCoordinator {
+			if !ic.CanSchedule() {
 				continue
 			}
 			ic.RescheduleJobs()
@@ -107,7 +155,7 @@ func (ic *IDunnoCoordinator) Corn() {
 	go func() {
 		for {
 			time.Sleep(FLUSH_JOB_IONTERVAL)
-			if !ic.IsCoordinator {
+			if !ic.CanSchedule() {
 				continue
 			}
 			ic.FlushPendingJobs()
@@ -117,7 +165,7 @@ func (ic *IDunnoCoordinator) Corn() {
 	go func() {
 		for {
 			time.Sleep(REFRESH_INTERVAL)
-			if !ic.IsCoordinator {
+			if !ic.CanSchedule() {
 				continue
 			}
 			ic.RefreshBatchStatus()
@@ -127,17 +175,25 @@ func (ic *IDunnoCoordinator) Corn() {
 	go func() {
 		for {
 			time.Sleep(REFRESH_INTERVAL)
-			if !ic.IsCoordinator {
+			if !ic.CanSchedule() {
 				continue
 			}
 			ic.RefreshWorkerStatus()
 		}
 	}()
 
+	// standbys register workers too, so that a promoted standby places jobs right away
+	go func() {
+		for {
+			time.Sleep(REGISTER_INTERVAL)
+			ic.RegisterWorkers()
+		}
+	}()
+
 	go func() {
 		for {
 			time.Sleep(MEASURE_QPS_INTERVAL)
-			if !ic.IsCoordinator {
+			if !ic.CanSchedule() {
 				continue
 			}
 			ic.MesureStats()
@@ -145,6 +201,19 @@ func (ic *IDunnoCoordinator) Corn() {
 	}()
 }
 
+// Coordinator only runs its periodic tasks if it is serving requests
+// and its partition has quorum, so that a minority partition never schedules jobs
+func (ic *IDunnoCoordinator) CanSchedule() bool {
+	return ic.Serving() && ic.Ring.HasQuorum()
+}
+
+// Whether this coordinator is serving requests, the flag is written by request handlers & ring callbacks holding the lock
+func (ic *IDunnoCoordinator) Serving() bool {
+	ic.Lock()
+	defer ic.Unlock()
+	return ic.IsCoordinator
+}
+
 func (ic *IDunnoCoordinator) MesureStats() {
 	ic.Lock()
 	defer ic.Unlock()
@@ -155,52 +224,33 @@ func (ic *IDunnoCoordinator) MesureStats() {
 	}
 }
 
-// Coordinator backup its data to its successor
+// Coordinator pushes a snapshot of its state to all standbys, i.e. before it leaves the ring
 func (ic *IDunnoCoordinator) BackupCoordinatorData() {
 	ic.Lock()
-	defer ic.Unlock()
-
-	client, close, err := ic.CreateCoordinatorClient()
-	if err != nil {
-		return
-	}
-	defer close()
-
-	modelStore := make(map[string]string)
-	activeJobs := make([]*api.Job, 0)
-	completedJobs := make([]*api.Job, 0)
-	pendingJobs := make([]*api.Job, 0)
-
-	for k, v := range *ic.ModelStore {
-		modelStore[string(k)] = string(v)
-	}
-
-	for _, job := range ic.Scheduler.ActiveJobs {
-		activeJobs = append(activeJobs, job)
-	}
+	ic.AwaitReplication()
+	snapshot := ic.BuildSnapshot()
+	standbys := ic.Standbys()
+	ic.Unlock()
 
-	for _, job := range ic.Scheduler.CompletedJobs {
-		completedJobs = append(completedJobs, job)
-	}
+	wg := sync.WaitGroup{}
 
-	for _, job := range ic.Scheduler.PendingJobs.ToSlice() {
-		pendingJobs = append(pendingJobs, job)
-	}
+	logger.Info("Sending backup data ...")
+	for _, standby := range standbys {
+		wg.Add(1)
+		go func(p *api.Process) {
+			defer wg.Done()
 
-	backup := &api.CoordinatorBackup{
-		ModelStore:    modelStore,
-		ActiveJobs:    activeJobs,
-		CompletedJobs: completedJobs,
-		PendingJobs:   pendingJobs,
-	}
+			client, err := ic.CreatePeerCoordinatorClient(p)
+			if err != nil {
+				return
+			}
 
-	logger.Info("Sending backup data ...")
-	_, err = client.Backup(context.Background(), &api.BackupRequest{
-		Backup: backup,
-	})
-	if err != nil {
-		logger.Error("Failed to backup coordinator data: " + err.Error())
+			if err := ic.SendSnapshot(client, snapshot); err != nil {
+				logger.Error("Failed to backup coordinator data to " + p.Address() + ": " + err.Error())
+			}
+		}(standby)
 	}
+	wg.Wait()
 	logger.Info("Backup data sent")
 }
 
@@ -211,6 +261,7 @@ func (ic *IDunnoCoordinator) RefreshBatchStatus() {
 	defer ic.Unlock()
 
 	ic.Scheduler.RefreshBatchStatus()
+	ic.Dispatcher.WakeAll()
 }
 
 // For all workers that are not running on a particular
@@ -227,13 +278,14 @@ func (ic *IDunnoCoordinator) RefreshWorkerStatus() {
 func (ic *IDunnoCoordinator) FlushPendingJobs() {
 	ic.Lock()
 	defer ic.Unlock()
+	ic.AwaitReplication()
 
 	if ic.Scheduler.PendingJobs.Empty() {
 		return
 	}
 
+	// job stays active and keeps its workers until JobFinished is committed, so a failed flush is retried
 	job := ic.Scheduler.PendingJobs.Top()
-	delete(ic.Scheduler.ActiveJobs, job.Id)
 
 	workers := ic.ResourceManager.GetWorkersById(job.Id)
 	wg := sync.WaitGroup{}
@@ -242,15 +294,13 @@ func (ic *IDunnoCoordinator) FlushPendingJobs() {
 	for _, worker := range workers {
 		wg.Add(1)
 		go func(worker *Worker) {
-			defer worker.Reset()
 			defer wg.Done()
 
-			client, close, err := ic.CreateWorkerClient(worker.Process)
+			client, err := ic.CreateWorkerClient(worker.Process)
 			if err != nil {
 				logger.Error("Trying to finish inference on worker " + worker.Process.Address() + " failed: " + err.Error())
 				return
 			}
-			defer close()
 
 			_, err = client.FinishInference(context.Background(), &api.FinishInferenceRequest{})
 			if err != nil {
@@ -263,14 +313,7 @@ func (ic *IDunnoCoordinator) FlushPendingJobs() {
 
 	logger.Info("Job " + job.Id + " completed, processing results...")
 
-	results, metric := job.GetResults()
-	lines := make([]string, 0)
-	for _, result := range results {
-		lines = append(lines, fmt.Sprintf("%s %s", result.GetInput(), result.GetOutput()))
-	}
-	lines = append(lines, fmt.Sprintf("\n%f", metric))
-
-	err := ic.SDFSClient.WriteLocalFile(job.Id, []byte(strings.Join(lines, "\n")))
+	err := ic.SDFSClient.WriteLocalFile(job.Id, []byte(FormatJobResults(job)))
 	if err != nil {
 		logger.Error("Failed to write results to SDFS: " + err.Error())
 		return
@@ -283,23 +326,42 @@ func (ic *IDunnoCoordinator) FlushPendingJobs() {
 		return
 	}
 
-	ic.Scheduler.PendingJobs.Pop()
-	ic.Scheduler.CompletedJobs[job.Id] = job
-	job.FinishTime = api.CurrentTimestamp()
+	err = ic.Commit(&api.LogEntry{
+		Type:  api.LogEntryType_JobFinished,
+		JobId: job.Id,
+	})
+	if err != nil {
+		logger.Error("Failed to replicate finished job " + job.Id + ": " + err.Error())
+	}
 
 	logger.Info("Job " + job.Id + " completed!")
 	logger.Info("Pending job len: " + fmt.Sprint(ic.Scheduler.PendingJobs.Len()))
 	logger.Info("Completed job len: " + fmt.Sprint(len(ic.Scheduler.CompletedJobs)))
 }
 
+// Results file of a job, one "input output" line per evaluated input followed by the metric.
+// A cancelled job only has the results of its completed batches
+func FormatJobResults(job *api.Job) string {
+	results, metric := job.GetResults()
+	lines := make([]string, 0)
+	for _, result := range results {
+		lines = append(lines, fmt.Sprintf("%s %s", result.GetInput(), result.GetOutput()))
+	}
+	lines = append(lines, fmt.Sprintf("\n%f", metric))
+	return strings.Join(lines, "\n")
+}
+
 // Core function of the coordinator that  reschedule
 // job to different workers in a fair-time fashion
 func (ic *IDunnoCoordinator) RescheduleJobs() {
 	ic.SetSchedulingStatus(true)
 
 	ic.Lock()
-	schedule := ic.Scheduler.RefreshSchedule()
+	reserved, load := ic.RefreshOnlineWorkers()
+	schedule := ic.Scheduler.RefreshSchedule(reserved)
+	versions := ic.AssignVersions(schedule)
 	ic.Unlock()
+	ic.LoadOnlineWorkers(load)
 	if len(schedule) == 0 {
 		return
 	}
@@ -310,19 +372,27 @@ func (ic *IDunnoCoordinator) RescheduleJobs() {
 
 			go func(jobId string, worker *Worker) {
 				// create worker client
-				client, close, err := ic.CreateWorkerClient(worker.Process)
+				client, err := ic.CreateWorkerClient(worker.Process)
 				if err != nil {
 					return
 				}
-				defer close()
 
 				// construct request struct
+				ic.Lock()
+				job := ic.Scheduler.ActiveJobs[jobId]
+				ic.Unlock()
+				if job == nil {
+					return
+				}
+				version := versions[worker.Process.Address()]
 				req := &api.InferenceRequest{
 					InferenceTask: &api.InferenceTask{
-						Model:     string(ic.Scheduler.ActiveJobs[jobId].ModelType),
-						BatchSize: int32(ic.Scheduler.ActiveJobs[jobId].BatchSize),
+						Model:     string(job.ModelType),
+						BatchSize: int32(job.BatchSize),
+						Version:   version,
 					},
 					JobId: jobId,
+					Spec:  ic.VersionSpec(job.ModelType, version),
 				}
 
 				// send start inference request
@@ -340,8 +410,11 @@ func (ic *IDunnoCoordinator) RescheduleJobs() {
 				// update worker info
 				ic.Lock()
 				worker.JobId = jobId
+				worker.Model = req.GetInferenceTask().GetModel()
+				worker.Version = version
 				worker.LastQueryTime = api.CurrentTimestamp()
 				ic.Unlock()
+				ic.Dispatcher.Wake(worker.Process.Address())
 				logger.Schedule(jobId, worker.Process.Address())
 			}(jobId, worker)
 		}
@@ -354,25 +427,52 @@ func (ic *IDunnoCoordinator) RescheduleJobs() {
 
 // Process queued inference request in FIFO order
 func (ic *IDunnoCoordinator) ProcessQueuedJob() {
-	if ic.TaskQueue.Empty() {
+	// task is only removed from the queue once its job is created, so it survives a coordinator failure,
+	// and no job is created before the checkpoint is restored, as restoring may reorder the queue
+	ic.Lock()
+	if !ic.Restored || ic.TaskQueue.Empty() || !ic.HasJobCapacity() {
+		ic.Unlock()
 		return
 	}
-
-	ic.Lock()
 	task := ic.TaskQueue.Top()
-	ic.TaskQueue.Pop()
 	ic.Unlock()
 
 	model, batchSize := utils.ModelType(task.GetModel()), int(task.GetBatchSize())
 
-	// fetch dataset folder (containing list of sdfs filenames)
-	dataset := ic.ModelStore.GetDataset(model)
+	// versions of the model evaluating the inputs, a version may have been trained since the task is queued
+	ic.Lock()
+	shares, err := ic.ResolveVersions(task)
+	var dataset utils.DatasetType
+	if err == nil {
+		dataset = ic.TaskDataset(task.GetModel(), shares)
+	}
+	ic.Unlock()
+	if err != nil {
+		logger.Error("Failed to resolve versions of queued task: " + err.Error())
+		ic.DropQueuedTask(task)
+		return
+	}
+
+	// fetch dataset folder (containing list of sdfs filenames), or results of upstream job
+	datasetFile := string(dataset)
+	if task.GetUpstreamJob() != utils.EMPTY_STRING {
+		ic.Lock()
+		upstream, ok := ic.Scheduler.CompletedJobs[task.GetUpstreamJob()]
+		ic.Unlock()
+		if !ok {
+			logger.Error("Upstream job " + task.GetUpstreamJob() + " is not completed")
+			ic.DropQueuedTask(task)
+			return
+		}
+		dataset, datasetFile = utils.DatasetType(upstream.Dataset), upstream.Id
+	}
 	localFile := utils.CreateTempFilename()
 
 	// if dataset is not found, meaning it is deleted before inference starts, simply return
-	err := ic.SDFSClient.Get(localFile, string(dataset), sdfs.LATEST_VERSION)
+	err = ic.SDFSClient.Get(localFile, datasetFile, sdfs.LATEST_VERSION)
 	if err != nil {
 		logger.Error("Failed to get dataset from SDFS: " + err.Error())
+		ic.DropQueuedTask(task)
 		return
 	}
 
@@ -380,28 +480,55 @@ func (ic *IDunnoCoordinator) ProcessQueuedJob() {
 	data, err := ic.SDFSClient.ReadLocalFile(localFile)
 	if err != nil {
 		logger.Error("Failed to read local file: " + err.Error())
+		ic.DropQueuedTask(task)
 		return
 	}
 	defer ic.SDFSClient.DeleteLocalFile(localFile)
 
-	// split by "\n" to get list of sdfs filenames (or raw inputs)
-	inputs := strings.Split(string(data), "\n")
-	logger.Info(fmt.Sprintf("Dataset %v has %v inputs", dataset, len(inputs)))
+	// parse rows into sdfs filenames (or raw inputs) with the dataset schema, upstream results are parsed already
+	ic.Lock()
+	modelSpec := ic.ModelStore.GetVersion(model, shares[0].GetVersion()).GetSpec()
+	datasetSpec := ic.Datasets.Get(string(dataset))
+	ic.Unlock()
+	if datasetSpec == nil {
+		logger.Error(fmt.Sprintf("Dataset %v is not registered", dataset))
+		ic.DropQueuedTask(task)
+		return
+	}
 
-	// split inputs into a set of batches
+	var inputs []string
+	invalidRows, invalidRowSamples := int32(0), make([]*api.InvalidRow, 0)
+	if task.GetUpstreamJob() != utils.EMPTY_STRING {
+		inputs = FilterJobResults(modelSpec, string(data), task.GetFilter())
+	} else {
+		inputs, invalidRows, invalidRowSamples = ParseDataset(datasetSpec, modelSpec, string(data))
+	}
+	if len(inputs) == 0 {
+		logger.Error(fmt.Sprintf("No valid input in %v for model %v", datasetFile, model))
+		ic.DropQueuedTask(task)
+		return
+	}
+	logger.Info(fmt.Sprintf("Dataset %v has %v inputs, %v invalid rows", datasetFile, len(inputs), invalidRows))
+
+	// split inputs between versions, then into a set of batches for each version
 	batchStates := make([]*api.BatchState, 0)
-	for i := 0; i < len(inputs); i += batchSize {
-		end := int(math.Min(float64(i+batchSize), float64(len(inputs))))
-		batchStates = append(batchStates, &api.BatchState{
-			Status: api.BatchStatus_Available,
-			BatchInput: &api.BatchInput{
-				BatchId: int32(len(batchStates)),
-				Inputs:  inputs[i:end],
-			},
-			BatchOutput: nil,
-			QueryTime:   nil,
-			ReceiveTime: nil,
-		})
+	split := SplitInputs(shares, inputs)
+	for _, share := range shares {
+		versionInputs := split[share.GetVersion()]
+		for i := 0; i < len(versionInputs); i += batchSize {
+			end := int(math.Min(float64(i+batchSize), float64(len(versionInputs))))
+			batchStates = append(batchStates, &api.BatchState{
+				Status: api.BatchStatus_Available,
+				BatchInput: &api.BatchInput{
+					BatchId: int32(len(batchStates)),
+					Inputs:  versionInputs[i:end],
+					Version: share.GetVersion(),
+				},
+				BatchOutput: nil,
+				QueryTime:   nil,
+				ReceiveTime: nil,
+			})
+		}
 	}
 
 	// create job info struct
@@ -413,20 +540,57 @@ func (ic *IDunnoCoordinator) ProcessQueuedJob() {
 		Dataset:           string(dataset),
 		StartTime:         api.CurrentTimestamp(),
 		FinishTime:        nil,
-		TotalQueries:      int32(math.Ceil(float64(len(inputs)) / float64(batchSize))),
+		TotalQueries:      int32(len(batchStates)),
 		CompletedQueries:  0,
 		BatchStates:       batchStates,
 		QueryRates:        make([]float32, 0),
 		QueryProcessTimes: make([]float32, 0),
+		Weight:            task.GetWeight(),
+		MinWorkers:        task.GetMinWorkers(),
+		Deadline:          task.GetDeadline(),
+		UpstreamJob:       task.GetUpstreamJob(),
+		Filter:            task.GetFilter(),
+		GraphId:           task.GetGraphId(),
+		Node:              task.GetNode(),
+		InvalidRows:       invalidRows,
+		InvalidRowSamples: invalidRowSamples,
+		Versions:          shares,
 	}
 
 	ic.Lock()
-	ic.Scheduler.AddJob(job)
+	ic.AwaitReplication()
+	job.Placement = ic.JobPlacement(task, shares)
+	err = ic.Commit(&api.LogEntry{
+		Type:          api.LogEntryType_JobCreated,
+		Job:           job,
+		InferenceTask: task,
+	})
 	ic.Unlock()
+	if err != nil {
+		logger.Error("Failed to replicate job " + job.Id + ": " + err.Error())
+	}
 
 	logger.NewJob(job)
 }
 
+// Remove a queued task that cannot be served
+func (ic *IDunnoCoordinator) DropQueuedTask(task *api.InferenceTask) {
+	ic.Lock()
+	defer ic.Unlock()
+	ic.AwaitReplication()
+
+	if err := ic.Commit(&api.LogEntry{Type: api.LogEntryType_TaskDropped, InferenceTask: task}); err != nil {
+		logger.Error("Failed to replicate dropped task: " + err.Error())
+	}
+}
+
+// Remove the queued task, returns false if it is not queued. Must hold the lock
+func (ic *IDunnoCoordinator) RemoveQueuedTask(task *api.InferenceTask) (*api.InferenceTask, bool) {
+	return ic.TaskQueue.Remove(func(queued *api.InferenceTask) bool {
+		return api.IsSameTask(queued, task)
+	})
+}
+
 // Callback function for worker that is called when a machine
 // becomes the coordinator
 func (ic *IDunnoCoordinator) OnBecomeCoordinator() {
@@ -437,7 +601,13 @@ func (ic *IDunnoCoordinator) OnBecomeCoordinator() {
 		return
 	}
 
-	defer logger.Info("I am the new coordinator! My address is " + ic.Ring.Address())
+	// recover acknowledged work of previous coordinator before serving, and fall back
+	// to the checkpoint in SDFS if no one has any state, i.e. the whole cluster restarted
+	ic.CatchUp()
+	ic.Restored = !ic.IsStateEmpty()
+	ic.RestoreAttempts = 0
+
+	defer logger.Info(fmt.Sprintf("I am the new coordinator of epoch %v! My address is %v", ic.Log.Epoch, ic.Ring.Address()))
 	ic.IsCoordinator = true
 }
 
@@ -450,38 +620,89 @@ func (ic *IDunnoCoordinator) OnMemberUpdate(action ring.MemAction, process *api.
 	switch action {
 	case ring.MEMBER_INSERT:
 		ic.Scheduler.OnWorkerJoined(process)
+		go ic.RegisterWorkers()
 	case ring.MEMBER_DELETE:
 		ic.Scheduler.OnWorkerFailed(process)
+	case ring.MEMBER_REJOINED:
+		ic.Scheduler.OnWorkerRejoined(process)
+		go ic.RegisterWorkers()
 	case ring.MEMBER_LEAVED:
-		ic.Scheduler.OnWorkerLeaved(process)
+		if api.IsSameProcess(process, ic.Ring.Process) {
+			// current process left the ring, cluster state lives on in the next coordinator
+			ic.IsCoordinator = false
+			ic.Scheduler.Reset()
+			ic.Log.Reset(ic.Log.Epoch, 0)
+		} else {
+			ic.Scheduler.OnWorkerLeaved(process)
+		}
+	case ring.MEMBER_MERGED:
+		// state of the minority partition is stale, coordinator of the majority partition owns the jobs
+		ic.IsCoordinator = false
+		ic.Scheduler.OnPartitionMerged()
+		ic.Log.Reset(ic.Log.Epoch, 0)
 	}
 }
 
-func (ic *IDunnoCoordinator) CreateCoordinatorClient() (api.CoordinatorServiceClient, func(), error) {
-	successors := ic.Ring.Successors()
+// Fetch capabilities of workers that joined since the last registration, workers are only scheduled once registered
+func (ic *IDunnoCoordinator) RegisterWorkers() {
+	ic.Lock()
+	processes := make([]*api.Process, 0)
+	for _, worker := range ic.ResourceManager.GetUnregisteredWorkers() {
+		processes = append(processes, worker.Process)
+	}
+	ic.Unlock()
+
+	for _, process := range processes {
+		capabilities, err := ic.FetchWorkerCapabilities(process)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to register worker %v: %v", process.Address(), err))
+			continue
+		}
 
-	if len(successors) == 0 {
-		return nil, nil, errors.New("not enough successors")
+		ic.Lock()
+		if ic.ResourceManager.RegisterCapabilities(process, capabilities) {
+			logger.Info(fmt.Sprintf("Registered worker %v: %v", process.Address(), FormatCapabilities(capabilities)))
+		}
+		ic.Unlock()
 	}
+}
 
-	next := successors[0]
-	conn, err := grpc.Dial(next.Address(), sdfs.GRPC_OPTIONS...)
+/*
+ * Fetch the capabilities of a worker
+ *
+ * @param process: worker process
+ * @return *api.WorkerCapabilities: resources, labels & models loaded by the worker's runner
+ * @return error: raise error if the worker is unreachable or its labels exceed the caps
+ */
+func (ic *IDunnoCoordinator) FetchWorkerCapabilities(process *api.Process) (*api.WorkerCapabilities, error) {
+	client, err := ic.CreateWorkerClient(process)
 	if err != nil {
-		logger.Error("Failed to connect to successor: " + err.Error())
-		return nil, nil, err
+		return nil, err
 	}
 
-	return api.NewCoordinatorServiceClient(conn), func() { conn.Close() }, nil
+	ctx, cancel := context.WithTimeout(context.Background(), REGISTER_TIMEOUT)
+	defer cancel()
+	res, err := client.FetchCapabilities(ctx, &api.FetchCapabilitiesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if res.GetCapabilities() == nil {
+		return nil, fmt.Errorf("worker sent no capabilities")
+	}
+	if err := ValidateLabels(res.GetCapabilities().GetLabels()); err != nil {
+		return nil, err
+	}
+	return res.GetCapabilities(), nil
 }
 
-func (ic *IDunnoCoordinator) CreateWorkerClient(sendToProcess *api.Process) (api.WorkerServiceClient, func(), error) {
-	conn, err := grpc.Dial(sendToProcess.Address(), sdfs.GRPC_OPTIONS...)
+func (ic *IDunnoCoordinator) CreateWorkerClient(sendToProcess *api.Process) (api.WorkerServiceClient, error) {
+	conn, err := connpool.POOL.Get(sendToProcess.Address(), sdfs.GRPC_OPTIONS...)
 	if err != nil {
 		logger.Error("Failed to connect to worker service: " + err.Error())
-		return nil, nil, err
+		return nil, err
 	}
 
-	return api.NewWorkerServiceClient(conn), func() { conn.Close() }, nil
+	return api.NewWorkerServiceClient(conn), nil
 }
 
 func (ic *IDunnoCoordinator) PrintWorkers() string {
@@ -495,6 +716,12 @@ func (ic *IDunnoCoordinator) PrintWorkers() string {
 		"Join Time",
 		"Running Job",
 		"Idle",
+		"Model",
+		"Version",
+		"Online",
+		"Healthy",
+		"Capabilities",
+		"Resident",
 		"Last Query Time",
 	})
 	for _, worker := range *ic.ResourceManager {
@@ -503,6 +730,12 @@ func (ic *IDunnoCoordinator) PrintWorkers() string {
 			worker.Process.JoinTime.AsTime().Format("2006-01-02 15:04:05"),
 			worker.JobId,
 			worker.Idle(),
+			worker.Model,
+			worker.Version,
+			worker.Online,
+			!worker.Unhealthy,
+			FormatCapabilities(worker.Capabilities),
+			FormatResident(worker.Resident),
 			worker.LastQueryTime.AsTime().Format("2006-01-02 15:04:05"),
 		})
 	}
@@ -530,6 +763,12 @@ func (ic *IDunnoCoordinator) PrintWorkersJSON() string {
 			"address":       worker.Process.Address(),
 			"joinTime":      worker.Process.JoinTime.AsTime().Format("2006-01-02 15:04:05"),
 			"runningJob":    worker.JobId,
+			"model":         worker.Model,
+			"version":       worker.Version,
+			"online":        worker.Online,
+			"healthy":       !worker.Unhealthy,
+			"capabilities":  worker.Capabilities,
+			"resident":      worker.Resident,
 			"lastQueryTime": worker.LastQueryTime.AsTime().Format("2006-01-02 15:04:05"),
 		})
 	}
@@ -551,8 +790,12 @@ func (ic *IDunnoCoordinator) PrintJobs() string {
 
 	t.AppendHeader(table.Row{
 		"Job ID",
+		"Status",
 		"Model Type",
+		"Versions",
 		"Batch Size",
+		"Weight",
+		"Min VMs",
 		"Total Queries",
 		"Completed Queries",
 		"Total Query Time",
@@ -560,6 +803,8 @@ func (ic *IDunnoCoordinator) PrintJobs() string {
 		"Progress",
 		"Query/Sec",
 		"Time Left",
+		"Deadline",
+		"Placement",
 	})
 
 	qps := make([]float64, 0)
@@ -578,11 +823,15 @@ func (ic *IDunnoCoordinator) PrintJobs() string {
 			queries = job.GetQPS(utils.LAST_SECONDS)
 		}
 
-		qps = append(qps, queries)
+		qps = append(qps, queries/job.SchedulingWeight())
 		t.AppendRow(table.Row{
 			job.Id,
+			job.Status.String(),
 			job.ModelType,
+			FormatVersions(job),
 			job.BatchSize,
+			job.SchedulingWeight(),
+			job.MinWorkers,
 			job.TotalQueries,
 			job.CompletedQueries,
 			fmt.Sprintf("%.2f sec", job.TotalQueryTime().Seconds()),
@@ -590,6 +839,8 @@ func (ic *IDunnoCoordinator) PrintJobs() string {
 			fmt.Sprintf("%.2f%%", float64(job.CompletedQueries)/float64(job.TotalQueries)*100),
 			fmt.Sprintf("%.2f", queries),
 			fmt.Sprintf("%.2f sec", timeLeft),
+			FormatDeadline(job, workerCount),
+			FormatPlacement(job.Placement),
 		})
 	}
 
@@ -599,7 +850,7 @@ func (ic *IDunnoCoordinator) PrintJobs() string {
 	})
 
 	t.AppendFooter(table.Row{
-		"Relative QPS Difference",
+		"Relative Weighted QPS Difference",
 		fmt.Sprintf("%.2f%%", ralloc.GetRelQPSDiff(qps)*100),
 	})
 
@@ -612,6 +863,19 @@ func (ic *IDunnoCoordinator) PrintJobs() string {
 	return t.Render()
 }
 
+// Deadline of a job for job view, flagged if it is predicted to be missed
+func FormatDeadline(job *api.Job, workerCount int) string {
+	if job.GetDeadline() == nil {
+		return "-"
+	}
+
+	deadline := job.GetDeadline().AsTime().Local().Format(DEADLINE_FORMAT)
+	if job.MissesDeadline(workerCount) {
+		return deadline + " (MISS)"
+	}
+	return deadline
+}
+
 func (ic *IDunnoCoordinator) PrintJobsJSON() string {
 	ic.Lock()
 	defer ic.Unlock()
@@ -631,11 +895,15 @@ func (ic *IDunnoCoordinator) PrintJobsJSON() string {
 		} else {
 			queries = job.GetQPS(utils.LAST_SECONDS)
 		}
-		qps = append(qps, queries)
-		jobs = append(jobs, map[string]interface{}{
+		qps = append(qps, queries/job.SchedulingWeight())
+		row := map[string]interface{}{
 			"id":               job.Id,
+			"status":           job.Status.String(),
 			"modelType":        job.ModelType,
+			"versions":         job.Versions,
 			"batchSize":        job.BatchSize,
+			"weight":           job.SchedulingWeight(),
+			"minWorkers":       job.MinWorkers,
 			"totalQueries":     job.TotalQueries,
 			"completedQueries": job.CompletedQueries,
 			"totalQueryTime":   job.TotalQueryTime().Seconds(),
@@ -643,7 +911,14 @@ func (ic *IDunnoCoordinator) PrintJobsJSON() string {
 			"progress":         float64(job.CompletedQueries) / float64(job.TotalQueries) * 100,
 			"qps":              queries,
 			"timeLeft":         timeLeft,
-		})
+			"missDeadline":     job.MissesDeadline(workerCount),
+			"placement":        job.Placement,
+		}
+		// jobs without deadline leave the field out
+		if job.GetDeadline() != nil {
+			row["deadline"] = job.GetDeadline().AsTime().Unix()
+		}
+		jobs = append(jobs, row)
 	}
 
 	var response = map[string]interface{}{
@@ -668,6 +943,7 @@ func (ic *IDunnoCoordinator) PrintCompletedJobs() string {
 
 	t.AppendHeader(table.Row{
 		"Job ID",
+		"Status",
 		"Model Type",
 		"Batch Size",
 		"Total Queries",
@@ -678,6 +954,7 @@ func (ic *IDunnoCoordinator) PrintCompletedJobs() string {
 	for _, job := range ic.Scheduler.CompletedJobs {
 		t.AppendRow(table.Row{
 			job.Id,
+			job.Status.String(),
 			job.ModelType,
 			job.BatchSize,
 			job.TotalQueries,
@@ -703,6 +980,7 @@ func (ic *IDunnoCoordinator) PrintCompletedJobsJSON() string {
 	for _, job := range ic.Scheduler.CompletedJobs {
 		responses = append(responses, map[string]interface{}{
 			"id":             job.Id,
+			"status":         job.Status.String(),
 			"modelType":      job.ModelType,
 			"batchSize":      job.BatchSize,
 			"totalQueries":   job.TotalQueries,
@@ -757,8 +1035,63 @@ func (ic *IDunnoCoordinator) PrintJob(jobId string) string {
 	t.SetStyle(table.StyleLight)
 	t.Style().Format.Header = text.FormatTitle
 	t.Style().Format.Footer = text.FormatTitle
+	output := t.Render()
+
+	// metric of each version side by side, when inputs are split between versions
+	if len(job.Versions) > 1 {
+		versions := table.NewWriter()
+		versions.AppendHeader(table.Row{
+			"Version",
+			"Share",
+			"Completed Queries",
+			"Total Queries",
+			"Results",
+			"Metric",
+		})
+		completed, total := VersionProgress(job)
+		for _, share := range job.Versions {
+			versionResults, versionMetric := job.GetVersionResults(share.GetVersion())
+			versions.AppendRow(table.Row{
+				fmt.Sprintf("v%v", share.GetVersion()),
+				fmt.Sprintf("%v%%", share.GetPercent()),
+				completed[share.GetVersion()],
+				total[share.GetVersion()],
+				len(versionResults),
+				fmt.Sprintf("%.2f%%", versionMetric*100),
+			})
+		}
+		versions.SetStyle(table.StyleLight)
+		versions.Style().Format.Header = text.FormatTitle
+		output += "\n" + versions.Render()
+	}
 
-	return t.Render()
+	if job.InvalidRows == 0 {
+		return output
+	}
+
+	// rows of the dataset left out of the job
+	invalid := table.NewWriter()
+	invalid.AppendHeader(table.Row{
+		"Line",
+		"Row",
+		"Reason",
+	})
+	for _, row := range job.InvalidRowSamples {
+		invalid.AppendRow(table.Row{
+			row.GetLine(),
+			fmt.Sprintf("%.100s", row.GetRow()),
+			row.GetReason(),
+		})
+	}
+	invalid.AppendFooter(table.Row{
+		"Invalid Rows",
+		job.InvalidRows,
+	})
+	invalid.SetStyle(table.StyleLight)
+	invalid.Style().Format.Header = text.FormatTitle
+	invalid.Style().Format.Footer = text.FormatTitle
+
+	return output + "\n" + invalid.Render()
 }
 
 func (ic *IDunnoCoordinator) PrintJobJSON(jobId string) string {
@@ -782,12 +1115,29 @@ func (ic *IDunnoCoordinator) PrintJobJSON(jobId string) string {
 			"batchOutput": result.Output,
 		})
 	}
+	versions := make([]map[string]interface{}, 0)
+	completed, total := VersionProgress(job)
+	for _, share := range job.Versions {
+		_, versionMetric := job.GetVersionResults(share.GetVersion())
+		versions = append(versions, map[string]interface{}{
+			"version":          share.GetVersion(),
+			"percent":          share.GetPercent(),
+			"completedQueries": completed[share.GetVersion()],
+			"totalQueries":     total[share.GetVersion()],
+			"metric":           versionMetric,
+		})
+	}
+
 	var response = map[string]interface{}{
 		"metric":            metric,
+		"versions":          versions,
 		"batches":           batches,
 		"id":                job.Id,
 		"queryRates":        job.QueryRates,
 		"queryProcessTimes": job.QueryProcessTimes,
+		"duplicateOutputs":  job.DuplicateOutputs,
+		"invalidRows":       job.InvalidRows,
+		"invalidRowSamples": job.InvalidRowSamples,
 	}
 
 	marshalled, err := json.Marshal(response)
@@ -889,6 +1239,11 @@ func (ic *IDunnoCoordinator) PrintJobStatistics(jobId string) string {
 		fmt.Sprintf("%.2f", timeP99),
 	})
 
+	t.AppendFooter(table.Row{
+		"Duplicate Outputs",
+		job.DuplicateOutputs,
+	})
+
 	t.SetAutoIndex(true)
 	t.SetStyle(table.StyleLight)
 	t.Style().Format.Header = text.FormatTitle
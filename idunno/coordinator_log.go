@@ -0,0 +1,686 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"mp4/api"
+	"mp4/connpool"
+	"mp4/logger"
+	"mp4/sdfs"
+	"mp4/utils"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const STANDBY_COUNT = 2                           // number of successors that replicate coordinator log
+const REPLICATE_TIMEOUT = 2000 * time.Millisecond // timeout of replicating to a single standby
+const COMPACT_INTERVAL = 10000 * time.Millisecond // interval of dropping log entries already replicated
+
+/* CoordinatorLog
+ * Log of coordinator state mutations, replicated from the coordinator to its standbys
+ * - Epoch: incremented whenever a standby is promoted, entries and snapshots from an older epoch are rejected
+ * - BaseIndex: index of the last entry that is compacted, i.e. only reflected in coordinator state
+ * - AppliedIndex: index of the last entry reflected in coordinator state, snapshots are taken at this index
+ * - Entries: entries after BaseIndex
+ * - NextIndex: index of the next entry to send to each standby, only used by the coordinator
+ * - Replicating: an entry is being sent to standbys, entries are replicated one at a time
+ */
+type CoordinatorLog struct {
+	Epoch        int64
+	BaseIndex    int64
+	AppliedIndex int64
+	Entries      []*api.LogEntry
+	NextIndex    map[string]int64
+	Replicating  bool
+}
+
+func NewCoordinatorLog() *CoordinatorLog {
+	return &CoordinatorLog{
+		Epoch:        0,
+		BaseIndex:    0,
+		AppliedIndex: 0,
+		Entries:      make([]*api.LogEntry, 0),
+		NextIndex:    make(map[string]int64),
+	}
+}
+
+func (cl *CoordinatorLog) LastIndex() int64 {
+	return cl.BaseIndex + int64(len(cl.Entries))
+}
+
+func (cl *CoordinatorLog) Append(entry *api.LogEntry) {
+	cl.Entries = append(cl.Entries, entry)
+}
+
+// Entries starting from given index, false if some of them are already compacted
+func (cl *CoordinatorLog) EntriesFrom(index int64) ([]*api.LogEntry, bool) {
+	if index <= cl.BaseIndex {
+		return nil, false
+	}
+	if index > cl.LastIndex() {
+		return make([]*api.LogEntry, 0), true
+	}
+	// entries are sent without the lock, later appends must not write into them
+	return append(make([]*api.LogEntry, 0), cl.Entries[index-cl.BaseIndex-1:]...), true
+}
+
+// Drop entries up to given index, entries not yet applied are kept
+func (cl *CoordinatorLog) Compact(index int64) {
+	if index > cl.AppliedIndex {
+		index = cl.AppliedIndex
+	}
+	if index <= cl.BaseIndex {
+		return
+	}
+	if index > cl.LastIndex() {
+		index = cl.LastIndex()
+	}
+	cl.Entries = cl.Entries[index-cl.BaseIndex:]
+	cl.BaseIndex = index
+}
+
+// Drop entries after given index, i.e. an entry that failed to be replicated
+func (cl *CoordinatorLog) Truncate(index int64) {
+	if index < cl.BaseIndex {
+		index = cl.BaseIndex
+	}
+	if index >= cl.LastIndex() {
+		return
+	}
+	cl.Entries = cl.Entries[:index-cl.BaseIndex]
+	if cl.AppliedIndex > index {
+		cl.AppliedIndex = index
+	}
+}
+
+// Start over from a snapshot taken at given index
+func (cl *CoordinatorLog) Reset(epoch int64, index int64) {
+	cl.Epoch = epoch
+	cl.BaseIndex = index
+	cl.AppliedIndex = index
+	cl.Entries = make([]*api.LogEntry, 0)
+	cl.NextIndex = make(map[string]int64)
+}
+
+/*
+ * Entries a standby takes from an append request of the coordinator
+ *
+ * @param epoch: epoch of the coordinator
+ * @param prevIndex: index of the entry right before the sent entries
+ * @param entries: entries to append, in index order
+ * @return []*api.LogEntry: entries after the last index of the log, to be applied & appended in order
+ * @return api.ResponseStatus: OK if the entries are taken, ERROR if the coordinator is from an older epoch,
+ *     NOT_FOUND if the log is missing entries before prevIndex, CONFLICT if the log holds an entry
+ *     that differs from the coordinator's at the same index, so that the standby needs a snapshot
+ */
+func (cl *CoordinatorLog) Accept(epoch int64, prevIndex int64, entries []*api.LogEntry) ([]*api.LogEntry, api.ResponseStatus) {
+	if epoch < cl.Epoch {
+		return nil, api.ResponseStatus_ERROR
+	}
+	if prevIndex > cl.LastIndex() {
+		return nil, api.ResponseStatus_NOT_FOUND
+	}
+
+	accepted := make([]*api.LogEntry, 0)
+	for _, entry := range entries {
+		if entry.GetIndex() > cl.LastIndex() {
+			accepted = append(accepted, entry)
+			continue
+		}
+		// entries already compacted are reflected in state, they cannot be compared
+		if entry.GetIndex() <= cl.BaseIndex {
+			continue
+		}
+		if held := cl.Entries[entry.GetIndex()-cl.BaseIndex-1]; !proto.Equal(held, entry) {
+			return nil, api.ResponseStatus_CONFLICT
+		}
+	}
+	return accepted, api.ResponseStatus_OK
+}
+
+/*
+ * Apply a log entry on coordinator state, must hold the lock
+ *
+ * @param entry: log entry to apply
+ */
+func (ic *IDunnoCoordinator) ApplyLogEntry(entry *api.LogEntry) {
+	switch entry.GetType() {
+	case api.LogEntryType_ModelAdded:
+		// retrained version is registered along, registered versions are kept as is
+		ic.Models.Register(entry.GetModelSpec())
+		ic.ModelStore.AddModel(entry.GetModelSpec(), utils.DatasetType(entry.GetTrainTask().GetDataset()), entry.GetTime())
+
+	case api.LogEntryType_ModelPromoted:
+		ic.ModelStore.Promote(utils.ModelType(entry.GetModelSpec().GetName()), entry.GetModelSpec().GetVersion())
+
+	case api.LogEntryType_TaskQueued:
+		ic.TaskQueue.Push(entry.GetInferenceTask())
+
+	case api.LogEntryType_ModelRegistered:
+		ic.Models.Register(entry.GetModelSpec())
+
+	case api.LogEntryType_DatasetRegistered:
+		ic.Datasets.Register(entry.GetDatasetSpec())
+
+	case api.LogEntryType_TaskDropped:
+		// the task named by the entry is removed, the queue may have been reordered by a restored checkpoint
+		if task, ok := ic.RemoveQueuedTask(entry.GetInferenceTask()); ok {
+			ic.OnGraphTaskDropped(task)
+		}
+
+	case api.LogEntryType_JobCreated:
+		ic.RemoveQueuedTask(entry.GetInferenceTask())
+		// keep the logged job untouched, since it may be sent to a lagging standby later
+		ic.Scheduler.AddJob(proto.Clone(entry.GetJob()).(*api.Job))
+		ic.OnGraphJobCreated(entry.GetJob())
+
+	case api.LogEntryType_WorkerStateChanged:
+		if worker := ic.ResourceManager.GetWorker(entry.GetWorker().Address()); worker != nil {
+			ic.Scheduler.OnWorkerHealth(worker, entry.GetRunnerUnhealthy())
+		}
+
+	case api.LogEntryType_WorkerReleased:
+		if worker := ic.ResourceManager.GetWorker(entry.GetWorker().Address()); worker != nil && worker.JobId == entry.GetJobId() {
+			worker.Reset()
+		}
+
+	case api.LogEntryType_BatchAssigned:
+		job := ic.Scheduler.GetJob(entry.GetJobId())
+		if job == nil || int(entry.GetBatchId()) >= len(job.BatchStates) {
+			return
+		}
+		batchState := job.BatchStates[entry.GetBatchId()]
+		if batchState.Status == api.BatchStatus_Completed {
+			return
+		}
+		// speculative copy of a running batch keeps its original query time
+		if batchState.Status != api.BatchStatus_InProgress {
+			batchState.Status = api.BatchStatus_InProgress
+			batchState.QueryTime = entry.GetTime()
+		}
+		job.AddLease(entry.GetBatchId(), entry.GetLease())
+
+		if worker := ic.ResourceManager.GetWorker(entry.GetWorker().Address()); worker != nil {
+			worker.JobId = entry.GetJobId()
+			worker.AddBatch(batchState.BatchInput)
+			worker.LastQueryTime = entry.GetTime()
+		}
+
+	case api.LogEntryType_BatchCompleted:
+		ic.Scheduler.OnReceiveBatchOutput(entry.GetJobId(), entry.GetWorker(), entry.GetBatchOutput())
+
+	case api.LogEntryType_BatchRejected:
+		ic.Scheduler.OnBatchRejected(entry.GetJobId(), entry.GetWorker(), entry.GetBatchId())
+
+	case api.LogEntryType_BatchFailed:
+		ic.Scheduler.OnBatchFailed(entry.GetJobId(), entry.GetWorker(), entry.GetBatchId())
+
+	case api.LogEntryType_GraphSubmitted:
+		ic.OnGraphSubmitted(entry.GetGraph(), entry.GetTime())
+
+	case api.LogEntryType_JobFinished:
+		ic.Scheduler.OnJobFinished(entry.GetJobId(), entry.GetTime())
+		if job, ok := ic.Scheduler.CompletedJobs[entry.GetJobId()]; ok {
+			ic.OnGraphJobFinished(job, entry.GetTime())
+		}
+
+	case api.LogEntryType_JobPaused:
+		if job := ic.Scheduler.GetJob(entry.GetJobId()); job != nil && job.Status == api.JobStatus_Running {
+			job.Status = api.JobStatus_Paused
+		}
+
+	case api.LogEntryType_JobResumed:
+		if job := ic.Scheduler.GetJob(entry.GetJobId()); job != nil && job.Status == api.JobStatus_Paused {
+			job.Status = api.JobStatus_Running
+		}
+
+	case api.LogEntryType_JobCancelled:
+		ic.Scheduler.OnJobCancelled(entry.GetJobId())
+	}
+}
+
+/*
+ * Replicate a mutation to standbys, then apply it on coordinator state once a majority of coordinator
+ * and standbys hold it, must hold the lock. The state is left untouched if the entry is not replicated,
+ * so that a retry of the failed request does not apply the mutation twice
+ *
+ * @param entry: log entry to commit
+ * @return error: raise error if the entry is not replicated to a majority of coordinator and standbys
+ */
+func (ic *IDunnoCoordinator) Commit(entry *api.LogEntry) error {
+	entry.Time = api.CurrentTimestamp()
+	return ic.replicate(entry, false)
+}
+
+/*
+ * Replicate a mutation that is already applied to coordinator state, must hold the lock. Callers wait for
+ * AwaitReplication before applying it, so that no snapshot is taken between the mutation and its entry
+ *
+ * @param entry: log entry to replicate
+ * @return error: raise error if the entry is not replicated to a majority of coordinator and standbys
+ */
+func (ic *IDunnoCoordinator) Replicate(entry *api.LogEntry) error {
+	if entry.Time == nil {
+		entry.Time = api.CurrentTimestamp()
+	}
+	return ic.replicate(entry, true)
+}
+
+// Wait until no entry is being replicated, must hold the lock. The lock is released while waiting
+func (ic *IDunnoCoordinator) AwaitReplication() {
+	for ic.Log.Replicating {
+		ic.Replicated.Wait()
+	}
+}
+
+/*
+ * Append an entry into the log and send it to standbys, must hold the lock. The lock is released while
+ * standbys are contacted, so that queries & views are served meanwhile. The entry is committed once
+ * a majority of coordinator and standbys have it, so a promoted standby can always recover it from one
+ * of the survivors. An entry that is not committed is dropped, and standbys that may hold it are sent
+ * a snapshot on next replication
+ *
+ * @param entry: log entry to replicate
+ * @param applied: whether the entry is already applied to coordinator state
+ * @return error: raise error if the entry is not replicated to a majority of coordinator and standbys
+ */
+func (ic *IDunnoCoordinator) replicate(entry *api.LogEntry, applied bool) error {
+	ic.AwaitReplication()
+	ic.Log.Replicating = true
+	defer func() {
+		ic.Log.Replicating = false
+		ic.Replicated.Broadcast()
+	}()
+
+	entry.Index = ic.Log.LastIndex() + 1
+	entry.Epoch = ic.Log.Epoch
+	ic.Log.Append(entry)
+	if applied {
+		ic.Log.AppliedIndex = entry.Index
+	}
+
+	standbys := ic.Standbys()
+	required := (len(standbys) + 1) / 2
+	nextIndexes := make(map[string]int64)
+	for _, standby := range standbys {
+		// first contact in this epoch overwrites whatever state the standby has
+		nextIndexes[standby.Address()] = ic.Log.NextIndex[standby.Address()]
+	}
+
+	type ack struct {
+		addr      string
+		lastIndex int64
+		err       error
+	}
+	ackChan := make(chan ack, len(standbys))
+
+	ic.Unlock()
+	for _, standby := range standbys {
+		go func(p *api.Process, nextIndex int64) {
+			lastIndex, err := ic.SendLog(p, nextIndex)
+			ackChan <- ack{p.Address(), lastIndex, err}
+		}(standby, nextIndexes[standby.Address()])
+	}
+	acks := make([]ack, 0)
+	for range standbys {
+		acks = append(acks, <-ackChan)
+	}
+	ic.Lock()
+
+	// a newer coordinator took over meanwhile, its state replaced this one
+	if ic.Log.Epoch != entry.Epoch || ic.Log.LastIndex() < entry.Index {
+		return fmt.Errorf("coordinator of epoch %v stepped down while replicating log entry %v", entry.Epoch, entry.Index)
+	}
+
+	received := 0
+	for _, res := range acks {
+		if res.err != nil {
+			logger.Error(fmt.Sprintf("Failed to replicate log entry %v to standby %v: %v", entry.Index, res.addr, res.err))
+			delete(ic.Log.NextIndex, res.addr)
+			continue
+		}
+		ic.Log.NextIndex[res.addr] = res.lastIndex + 1
+		received++
+	}
+
+	if received < required {
+		ic.Log.Truncate(entry.Index - 1)
+		ic.Log.NextIndex = make(map[string]int64)
+		return fmt.Errorf("log entry %v is replicated to %v standbys, %v required", entry.Index, received, required)
+	}
+
+	if !applied {
+		ic.ApplyLogEntry(entry)
+		ic.Log.AppliedIndex = entry.Index
+	}
+	return nil
+}
+
+/*
+ * Bring a standby up to date with coordinator log, a snapshot is installed first if the standby has never
+ * been contacted in this epoch, it is missing entries that are already compacted, or it holds entries
+ * the coordinator dropped. Called without the lock, which is only taken to read the log
+ *
+ * @param p: standby process
+ * @param nextIndex: index of the next entry the standby needs, 0 if it needs a snapshot
+ * @return int64: index of the last entry in standby log
+ * @return error: raise error if the standby is unreachable or rejects the entries
+ */
+func (ic *IDunnoCoordinator) SendLog(p *api.Process, nextIndex int64) (int64, error) {
+	client, err := ic.CreatePeerCoordinatorClient(p)
+	if err != nil {
+		return 0, err
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		ic.Lock()
+		epoch := ic.Log.Epoch
+		entries, ok := ic.Log.EntriesFrom(nextIndex)
+		var snapshot *api.CoordinatorBackup
+		if !ok {
+			// snapshot is taken at the applied index, entries after it are sent along
+			snapshot = ic.BuildSnapshot()
+			nextIndex = snapshot.GetLogIndex() + 1
+			entries, _ = ic.Log.EntriesFrom(nextIndex)
+		}
+		ic.Unlock()
+
+		if snapshot != nil {
+			if err := ic.SendSnapshot(client, snapshot); err != nil {
+				return 0, err
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), REPLICATE_TIMEOUT)
+		res, err := client.AppendLog(ctx, &api.AppendLogRequest{
+			Epoch:     epoch,
+			PrevIndex: nextIndex - 1,
+			Entries:   entries,
+		})
+		cancel()
+		if err != nil {
+			return 0, err
+		}
+
+		switch res.GetStatus() {
+		case api.ResponseStatus_OK:
+			return res.GetLastIndex(), nil
+		case api.ResponseStatus_NOT_FOUND:
+			// standby is missing entries before nextIndex, resend from where it stops
+			nextIndex = res.GetLastIndex() + 1
+		case api.ResponseStatus_CONFLICT:
+			// standby applied an entry the coordinator dropped, overwrite its state
+			nextIndex = 0
+		default:
+			return 0, fmt.Errorf("standby rejected entries of epoch %v, its epoch is %v", epoch, res.GetEpoch())
+		}
+	}
+
+	return 0, fmt.Errorf("standby %v cannot catch up with coordinator log", p.Address())
+}
+
+func (ic *IDunnoCoordinator) SendSnapshot(client api.CoordinatorServiceClient, snapshot *api.CoordinatorBackup) error {
+	ctx, cancel := context.WithTimeout(context.Background(), REPLICATE_TIMEOUT)
+	defer cancel()
+
+	res, err := client.Backup(ctx, &api.BackupRequest{Backup: snapshot})
+	if err != nil {
+		return err
+	}
+	if res.GetStatus() != api.ResponseStatus_OK {
+		return fmt.Errorf("standby rejected snapshot of epoch %v", snapshot.GetEpoch())
+	}
+	return nil
+}
+
+/*
+ * Snapshot of coordinator state, must hold the lock
+ *
+ * @return *api.CoordinatorBackup: snapshot that reflects all entries up to the applied log index
+ */
+func (ic *IDunnoCoordinator) BuildSnapshot() *api.CoordinatorBackup {
+	modelStore := make(map[string]*api.ModelVersions)
+	activeJobs := make([]*api.Job, 0)
+	completedJobs := make([]*api.Job, 0)
+	pendingJobs := make([]*api.Job, 0)
+	taskQueue := make([]*api.InferenceTask, 0)
+	graphs := make([]*api.JobGraph, 0)
+	models := make([]*api.ModelSpec, 0)
+	datasets := make([]*api.DatasetSpec, 0)
+	unhealthyWorkers := make([]string, 0)
+
+	for k, v := range *ic.ModelStore {
+		modelStore[string(k)] = v
+	}
+
+	for _, spec := range *ic.Models {
+		models = append(models, spec)
+	}
+
+	for _, spec := range *ic.Datasets {
+		datasets = append(datasets, spec)
+	}
+
+	for _, job := range ic.Scheduler.ActiveJobs {
+		activeJobs = append(activeJobs, job)
+	}
+
+	for _, job := range ic.Scheduler.CompletedJobs {
+		completedJobs = append(completedJobs, job)
+	}
+
+	pendingJobs = append(pendingJobs, ic.Scheduler.PendingJobs.ToSlice()...)
+	taskQueue = append(taskQueue, ic.TaskQueue.ToSlice()...)
+
+	for _, graph := range ic.Graphs {
+		graphs = append(graphs, graph)
+	}
+
+	for address, worker := range *ic.ResourceManager {
+		if worker.Unhealthy {
+			unhealthyWorkers = append(unhealthyWorkers, address)
+		}
+	}
+
+	return &api.CoordinatorBackup{
+		ModelStore:       modelStore,
+		ActiveJobs:       activeJobs,
+		CompletedJobs:    completedJobs,
+		PendingJobs:      pendingJobs,
+		TaskQueue:        taskQueue,
+		Graphs:           graphs,
+		Models:           models,
+		Datasets:         datasets,
+		LogIndex:         ic.Log.AppliedIndex,
+		Epoch:            ic.Log.Epoch,
+		UnhealthyWorkers: unhealthyWorkers,
+	}
+}
+
+/*
+ * Replace coordinator state with a snapshot, must hold the lock
+ *
+ * @param snapshot: snapshot to install
+ */
+func (ic *IDunnoCoordinator) InstallSnapshot(snapshot *api.CoordinatorBackup) {
+	ic.ModelStore = NewModelStore()
+	ic.TaskQueue = utils.NewQueue[*api.InferenceTask]()
+	ic.Scheduler.ActiveJobs = make(map[string]*api.Job)
+	ic.Scheduler.CompletedJobs = make(map[string]*api.Job)
+	ic.Scheduler.PendingJobs = utils.NewQueue[*api.Job]()
+	ic.Graphs = make(map[string]*api.JobGraph)
+	ic.Models = NewModelRegistry()
+	ic.Datasets = NewDatasetRegistry()
+
+	for k, v := range snapshot.GetModelStore() {
+		(*ic.ModelStore)[utils.ModelType(k)] = v
+	}
+
+	for _, spec := range snapshot.GetModels() {
+		ic.Models.Register(spec)
+	}
+
+	for _, spec := range snapshot.GetDatasets() {
+		ic.Datasets.Register(spec)
+	}
+
+	for _, task := range snapshot.GetTaskQueue() {
+		ic.TaskQueue.Push(task)
+	}
+
+	for _, job := range snapshot.GetActiveJobs() {
+		ic.Scheduler.ActiveJobs[job.GetId()] = job
+	}
+
+	for _, job := range snapshot.GetCompletedJobs() {
+		ic.Scheduler.CompletedJobs[job.GetId()] = job
+	}
+
+	for _, job := range snapshot.GetPendingJobs() {
+		ic.Scheduler.PendingJobs.Push(job)
+	}
+
+	for _, graph := range snapshot.GetGraphs() {
+		ic.Graphs[graph.GetId()] = graph
+	}
+
+	// workers themselves come from the membership list, only their health is replicated
+	unhealthy := make(map[string]bool)
+	for _, address := range snapshot.GetUnhealthyWorkers() {
+		unhealthy[address] = true
+	}
+	for address, worker := range *ic.ResourceManager {
+		worker.Unhealthy = unhealthy[address]
+	}
+
+	ic.Log.Reset(snapshot.GetEpoch(), snapshot.GetLogIndex())
+}
+
+/*
+ * Catch up with the most recent state among other members before serving as coordinator, must hold the lock.
+ * Any entry acknowledged by previous coordinator is held by a majority of its standbys, so the member
+ * with the highest (epoch, log index) has all of them
+ */
+func (ic *IDunnoCoordinator) CatchUp() {
+	members := ic.Ring.GetMembershipList()
+	snapshotChan := make(chan *api.CoordinatorBackup, len(members))
+
+	count := 0
+	for _, member := range members {
+		if member.Status != api.Status_Alive || member.Address() == ic.Ring.Address() {
+			continue
+		}
+		count++
+
+		go func(p *api.Process) {
+			client, err := ic.CreatePeerCoordinatorClient(p)
+			if err != nil {
+				snapshotChan <- nil
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), REPLICATE_TIMEOUT)
+			defer cancel()
+			res, err := client.FetchSnapshot(ctx, &api.FetchSnapshotRequest{})
+			if err != nil {
+				logger.Error(fmt.Sprintf("Failed to fetch snapshot from %v: %v", p.Address(), err))
+				snapshotChan <- nil
+				return
+			}
+			snapshotChan <- res.GetSnapshot()
+		}(member)
+	}
+
+	maxEpoch := ic.Log.Epoch
+	var latest *api.CoordinatorBackup
+	for i := 0; i < count; i++ {
+		snapshot := <-snapshotChan
+		if snapshot == nil {
+			continue
+		}
+		if snapshot.GetEpoch() > maxEpoch {
+			maxEpoch = snapshot.GetEpoch()
+		}
+		if IsNewerSnapshot(snapshot, latest) {
+			latest = snapshot
+		}
+	}
+
+	if latest != nil && IsNewerSnapshot(latest, ic.BuildSnapshot()) {
+		logger.Info(fmt.Sprintf("Catching up with coordinator state of epoch %v at log index %v", latest.GetEpoch(), latest.GetLogIndex()))
+		ic.InstallSnapshot(latest)
+	}
+
+	// fence off previous coordinator
+	ic.Log.Reset(maxEpoch+1, ic.Log.AppliedIndex)
+}
+
+func IsNewerSnapshot(s1 *api.CoordinatorBackup, s2 *api.CoordinatorBackup) bool {
+	if s2 == nil {
+		return true
+	}
+	if s1.GetEpoch() != s2.GetEpoch() {
+		return s1.GetEpoch() > s2.GetEpoch()
+	}
+	return s1.GetLogIndex() > s2.GetLogIndex()
+}
+
+// Adopt epoch of the current coordinator, and stop serving if current process used to be the coordinator. Must hold the lock
+func (ic *IDunnoCoordinator) StepDown(epoch int64) {
+	if epoch <= ic.Log.Epoch {
+		return
+	}
+
+	ic.Log.Epoch = epoch
+	if ic.IsCoordinator {
+		logger.Info(fmt.Sprintf("Coordinator of epoch %v takes over, stepping down", epoch))
+		ic.IsCoordinator = false
+	}
+}
+
+// Drop log entries that are no longer needed, coordinator keeps entries some standby has not received yet
+func (ic *IDunnoCoordinator) CompactLog() {
+	ic.Lock()
+	defer ic.Unlock()
+	ic.AwaitReplication()
+
+	index := ic.Log.AppliedIndex
+	if ic.IsCoordinator {
+		for _, standby := range ic.Standbys() {
+			nextIndex, ok := ic.Log.NextIndex[standby.Address()]
+			if !ok {
+				// standby will receive a snapshot anyway
+				continue
+			}
+			if nextIndex-1 < index {
+				index = nextIndex - 1
+			}
+		}
+	}
+	ic.Log.Compact(index)
+}
+
+// Successors that replicate coordinator log, they are also the next processes to be elected as coordinator
+func (ic *IDunnoCoordinator) Standbys() []*api.Process {
+	standbys := make([]*api.Process, 0)
+	for _, successor := range ic.Ring.Successors() {
+		if len(standbys) >= STANDBY_COUNT {
+			break
+		}
+		if successor.Status == api.Status_Alive {
+			standbys = append(standbys, successor)
+		}
+	}
+	return standbys
+}
+
+func (ic *IDunnoCoordinator) CreatePeerCoordinatorClient(p *api.Process) (api.CoordinatorServiceClient, error) {
+	conn, err := connpool.POOL.Get(p.Address(), sdfs.GRPC_OPTIONS...)
+	if err != nil {
+		logger.Error("Failed to connect to coordinator service: " + err.Error())
+		return nil, err
+	}
+
+	return api.NewCoordinatorServiceClient(conn), nil
+}
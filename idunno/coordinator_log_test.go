@@ -0,0 +1,439 @@
+package main
+
+import (
+	"mp4/api"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// entries queuing a task each, one per index in [from, to]
+func queuedEntries(epoch int64, from int64, to int64) []*api.LogEntry {
+	entries := make([]*api.LogEntry, 0)
+	for index := from; index <= to; index++ {
+		entries = append(entries, &api.LogEntry{
+			Type:          api.LogEntryType_TaskQueued,
+			Index:         index,
+			Epoch:         epoch,
+			InferenceTask: &api.InferenceTask{Model: "albert", BatchSize: int32(index)},
+		})
+	}
+	return entries
+}
+
+func newLog(epoch int64, entries []*api.LogEntry) *CoordinatorLog {
+	log := NewCoordinatorLog()
+	log.Epoch = epoch
+	for _, entry := range entries {
+		log.Append(entry)
+	}
+	log.AppliedIndex = log.LastIndex()
+	return log
+}
+
+func Test_CoordinatorLog_Accept(t *testing.T) {
+	assert := assert.New(t)
+
+	held := queuedEntries(2, 1, 3)
+	conflicting := queuedEntries(2, 3, 3)
+	conflicting[0].InferenceTask.BatchSize = 100
+
+	tests := []struct {
+		name      string
+		epoch     int64
+		prevIndex int64
+		entries   []*api.LogEntry
+		status    api.ResponseStatus
+		accepted  []int64
+	}{
+		{"next entries", 2, 3, queuedEntries(2, 4, 5), api.ResponseStatus_OK, []int64{4, 5}},
+		{"newer epoch", 3, 3, queuedEntries(3, 4, 4), api.ResponseStatus_OK, []int64{4}},
+		{"older epoch", 1, 3, queuedEntries(1, 4, 4), api.ResponseStatus_ERROR, nil},
+		{"gap", 2, 5, queuedEntries(2, 6, 6), api.ResponseStatus_NOT_FOUND, nil},
+		{"held entries are skipped", 2, 1, queuedEntries(2, 2, 4), api.ResponseStatus_OK, []int64{4}},
+		{"resent entries", 2, 0, queuedEntries(2, 1, 3), api.ResponseStatus_OK, []int64{}},
+		{"conflicting entry", 3, 2, conflicting, api.ResponseStatus_CONFLICT, nil},
+		{"heartbeat", 2, 3, nil, api.ResponseStatus_OK, []int64{}},
+	}
+
+	for _, test := range tests {
+		log := newLog(2, held)
+		accepted, status := log.Accept(test.epoch, test.prevIndex, test.entries)
+		assert.Equal(test.status, status, test.name)
+		if test.accepted == nil {
+			assert.Nil(accepted, test.name)
+			continue
+		}
+		indexes := make([]int64, 0)
+		for _, entry := range accepted {
+			indexes = append(indexes, entry.GetIndex())
+		}
+		assert.Equal(test.accepted, indexes, test.name)
+		assert.Equal(int64(3), log.LastIndex(), "accepting should not append: "+test.name)
+	}
+}
+
+func Test_CoordinatorLog_AcceptCompacted(t *testing.T) {
+	assert := assert.New(t)
+
+	log := newLog(1, queuedEntries(1, 1, 4))
+	log.Compact(2)
+
+	// compacted entries cannot be compared, they are already reflected in state
+	accepted, status := log.Accept(1, 0, queuedEntries(1, 1, 5))
+	assert.Equal(api.ResponseStatus_OK, status)
+	assert.Len(accepted, 1)
+	assert.Equal(int64(5), accepted[0].GetIndex())
+}
+
+func Test_CoordinatorLog_Compact(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		name    string
+		applied int64
+		index   int64
+		base    int64
+		from    int64
+		found   bool
+	}{
+		{"compact prefix", 5, 3, 3, 4, true},
+		{"compact all", 5, 5, 5, 6, true},
+		{"compact past last index", 5, 10, 5, 6, true},
+		{"compacted entries are gone", 5, 3, 3, 3, false},
+		{"unapplied entries are kept", 2, 4, 2, 3, true},
+		{"nothing to compact", 5, 0, 0, 1, true},
+	}
+
+	for _, test := range tests {
+		log := newLog(1, queuedEntries(1, 1, 5))
+		log.AppliedIndex = test.applied
+		log.Compact(test.index)
+
+		assert.Equal(test.base, log.BaseIndex, test.name)
+		assert.Equal(int64(5), log.LastIndex(), test.name)
+
+		entries, found := log.EntriesFrom(test.from)
+		assert.Equal(test.found, found, test.name)
+		if found {
+			assert.Len(entries, int(5-test.from+1), test.name)
+			if len(entries) > 0 {
+				assert.Equal(test.from, entries[0].GetIndex(), test.name)
+			}
+		}
+	}
+}
+
+func Test_CoordinatorLog_EntriesFromIsCopied(t *testing.T) {
+	assert := assert.New(t)
+
+	log := newLog(1, queuedEntries(1, 1, 2))
+	entries, found := log.EntriesFrom(1)
+	assert.True(found)
+
+	// entries are sent without the lock, a truncation followed by an append must not show through
+	log.Truncate(1)
+	log.Append(queuedEntries(1, 2, 2)[0])
+	log.Entries[1] = &api.LogEntry{Index: 2}
+	assert.Equal(api.LogEntryType_TaskQueued, entries[1].GetType())
+}
+
+func Test_CoordinatorLog_Truncate(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		name    string
+		base    int64
+		index   int64
+		last    int64
+		applied int64
+	}{
+		{"drop failed entry", 0, 4, 4, 4},
+		{"drop several entries", 0, 2, 2, 2},
+		{"past last index", 0, 7, 5, 5},
+		{"before base index", 3, 1, 3, 3},
+	}
+
+	for _, test := range tests {
+		log := newLog(1, queuedEntries(1, 1, 5))
+		log.Compact(test.base)
+		log.Truncate(test.index)
+
+		assert.Equal(test.last, log.LastIndex(), test.name)
+		assert.Equal(test.applied, log.AppliedIndex, test.name)
+	}
+}
+
+func Test_CoordinatorLog_Reset(t *testing.T) {
+	assert := assert.New(t)
+
+	log := newLog(1, queuedEntries(1, 1, 3))
+	log.NextIndex["standby:1"] = 2
+	log.Reset(4, 10)
+
+	assert.Equal(int64(4), log.Epoch)
+	assert.Equal(int64(10), log.BaseIndex)
+	assert.Equal(int64(10), log.AppliedIndex)
+	assert.Equal(int64(10), log.LastIndex())
+	assert.Empty(log.Entries)
+	assert.Empty(log.NextIndex)
+
+	_, found := log.EntriesFrom(10)
+	assert.False(found, "entries before the snapshot are gone")
+	entries, found := log.EntriesFrom(11)
+	assert.True(found)
+	assert.Empty(entries)
+}
+
+// Standby installs a snapshot, then replays the entries sent after it
+func Test_CoordinatorLog_InstallSnapshotThenReplay(t *testing.T) {
+	assert := assert.New(t)
+
+	coordinator := NewIDunnoCoordinator(nil, nil)
+	coordinator.Log.Epoch = 2
+	for _, entry := range queuedEntries(2, 1, 3) {
+		coordinator.ApplyLogEntry(entry)
+		coordinator.Log.Append(entry)
+		coordinator.Log.AppliedIndex = entry.GetIndex()
+	}
+	snapshot := coordinator.BuildSnapshot()
+	assert.Equal(int64(3), snapshot.GetLogIndex())
+	assert.Equal(int64(2), snapshot.GetEpoch())
+
+	// standby holds stale state of an older epoch
+	standby := NewIDunnoCoordinator(nil, nil)
+	standby.Log.Epoch = 1
+	standby.TaskQueue.Push(&api.InferenceTask{Model: "resnet50"})
+	standby.Log.Append(queuedEntries(1, 1, 1)[0])
+
+	standby.InstallSnapshot(snapshot)
+	assert.Equal(3, standby.TaskQueue.Len())
+	assert.Equal("albert", standby.TaskQueue.Top().GetModel())
+	assert.Equal(int64(3), standby.Log.LastIndex())
+	assert.Equal(int64(3), standby.Log.AppliedIndex)
+
+	// entries the coordinator appended after the snapshot
+	next := append(queuedEntries(2, 3, 5), &api.LogEntry{
+		Type:        api.LogEntryType_DatasetRegistered,
+		Index:       6,
+		Epoch:       2,
+		DatasetSpec: &api.DatasetSpec{Name: "reviews", Format: api.DatasetFormat_DelimitedText},
+	})
+	accepted, status := standby.Log.Accept(2, 2, next)
+	assert.Equal(api.ResponseStatus_OK, status)
+	for _, entry := range accepted {
+		standby.ApplyLogEntry(entry)
+		standby.Log.Append(entry)
+		standby.Log.AppliedIndex = entry.GetIndex()
+	}
+
+	assert.Equal(5, standby.TaskQueue.Len(), "resent entry 3 should not be applied twice")
+	assert.True(standby.Datasets.Contains("reviews"))
+	assert.Equal(int64(6), standby.Log.LastIndex())
+	assert.Equal(int64(6), standby.BuildSnapshot().GetLogIndex())
+
+	// snapshot of the standby is newer than the one it installed
+	assert.True(IsNewerSnapshot(standby.BuildSnapshot(), snapshot))
+	assert.False(IsNewerSnapshot(snapshot, standby.BuildSnapshot()))
+}
+
+func Test_CoordinatorLog_IsNewerSnapshot(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		name     string
+		s1       *api.CoordinatorBackup
+		s2       *api.CoordinatorBackup
+		expected bool
+	}{
+		{"no snapshot yet", &api.CoordinatorBackup{}, nil, true},
+		{"newer epoch", &api.CoordinatorBackup{Epoch: 2, LogIndex: 1}, &api.CoordinatorBackup{Epoch: 1, LogIndex: 9}, true},
+		{"older epoch", &api.CoordinatorBackup{Epoch: 1, LogIndex: 9}, &api.CoordinatorBackup{Epoch: 2, LogIndex: 1}, false},
+		{"higher index", &api.CoordinatorBackup{Epoch: 2, LogIndex: 5}, &api.CoordinatorBackup{Epoch: 2, LogIndex: 4}, true},
+		{"same index", &api.CoordinatorBackup{Epoch: 2, LogIndex: 5}, &api.CoordinatorBackup{Epoch: 2, LogIndex: 5}, false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(test.expected, IsNewerSnapshot(test.s1, test.s2), test.name)
+	}
+}
+
+// Checkpoint written before a restart is merged into the state built since
+func Test_CoordinatorLog_MergeSnapshot(t *testing.T) {
+	assert := assert.New(t)
+
+	trainTime := timestamppb.New(time.Now())
+	checkpoint := NewIDunnoCoordinator(nil, nil)
+	checkpoint.ModelStore.AddModel(&api.ModelSpec{Name: "albert", Version: 1}, "emotion", trainTime)
+	checkpoint.ModelStore.AddModel(&api.ModelSpec{Name: "albert", Version: 2}, "emotion", trainTime)
+	checkpoint.Datasets.Register(&api.DatasetSpec{Name: "reviews", Format: api.DatasetFormat_DelimitedText, RegisterTime: trainTime})
+	checkpoint.TaskQueue.Push(&api.InferenceTask{Model: "albert", BatchSize: 1})
+	checkpoint.Scheduler.ActiveJobs["active"] = &api.Job{Id: "active"}
+	checkpoint.Scheduler.ActiveJobs["finished"] = &api.Job{Id: "finished"}
+	checkpoint.Scheduler.CompletedJobs["done"] = &api.Job{Id: "done", TotalQueries: 1}
+
+	restarted := NewIDunnoCoordinator(nil, nil)
+	restarted.ModelStore.AddModel(&api.ModelSpec{Name: "albert", Version: 3}, "emotion", trainTime)
+	restarted.Datasets.Register(&api.DatasetSpec{Name: "reviews", Format: api.DatasetFormat_FileList, RegisterTime: trainTime})
+	restarted.TaskQueue.Push(&api.InferenceTask{Model: "albert", BatchSize: 2})
+	restarted.Scheduler.CompletedJobs["finished"] = &api.Job{Id: "finished"}
+	restarted.Scheduler.CompletedJobs["done"] = &api.Job{Id: "done", TotalQueries: 2}
+
+	restarted.MergeSnapshot(checkpoint.BuildSnapshot())
+
+	for _, version := range []int32{1, 2, 3} {
+		assert.True(restarted.ModelStore.ContainsVersion("albert", version), "version %v should be kept", version)
+	}
+	assert.Equal(api.DatasetFormat_FileList, restarted.Datasets.Get("reviews").GetFormat(), "schema registered after the restart should be kept")
+
+	tasks := restarted.TaskQueue.ToSlice()
+	assert.Len(tasks, 2)
+	assert.Equal(int32(1), tasks[0].GetBatchSize(), "tasks queued before the restart should go first")
+	assert.Equal(int32(2), tasks[1].GetBatchSize())
+
+	assert.Contains(restarted.Scheduler.ActiveJobs, "active")
+	assert.NotContains(restarted.Scheduler.ActiveJobs, "finished", "job completed after the restart should not be active again")
+	assert.Equal(int32(2), restarted.Scheduler.CompletedJobs["done"].GetTotalQueries(), "known jobs should be kept as is")
+}
+
+// Health of workers is replicated, a promoted standby does not schedule a worker whose runner is unhealthy
+func Test_CoordinatorLog_WorkerHealth(t *testing.T) {
+	assert := assert.New(t)
+
+	healthy, unhealthy := &api.Process{Ip: "10.0.0.1", Port: 8000}, &api.Process{Ip: "10.0.0.2", Port: 8000}
+	coordinator := NewIDunnoCoordinator(nil, nil)
+	coordinator.ResourceManager.AddWorker(healthy)
+	coordinator.ResourceManager.AddWorker(unhealthy)
+	coordinator.ResourceManager.GetWorker(unhealthy.Address()).JobId = "job"
+
+	coordinator.ApplyLogEntry(&api.LogEntry{Type: api.LogEntryType_WorkerStateChanged, Worker: unhealthy, RunnerUnhealthy: true})
+	assert.True(coordinator.ResourceManager.GetWorker(unhealthy.Address()).Unhealthy)
+	assert.Empty(coordinator.ResourceManager.GetWorker(unhealthy.Address()).JobId, "unhealthy worker should be released from its job")
+
+	snapshot := coordinator.BuildSnapshot()
+	assert.Equal([]string{unhealthy.Address()}, snapshot.GetUnhealthyWorkers())
+
+	standby := NewIDunnoCoordinator(nil, nil)
+	standby.ResourceManager.AddWorker(healthy)
+	standby.ResourceManager.AddWorker(unhealthy)
+	standby.ResourceManager.GetWorker(healthy.Address()).Unhealthy = true
+	standby.InstallSnapshot(snapshot)
+	assert.False(standby.ResourceManager.GetWorker(healthy.Address()).Unhealthy)
+	assert.True(standby.ResourceManager.GetWorker(unhealthy.Address()).Unhealthy)
+
+	standby.ApplyLogEntry(&api.LogEntry{Type: api.LogEntryType_WorkerStateChanged, Worker: unhealthy, RunnerUnhealthy: false})
+	assert.False(standby.ResourceManager.GetWorker(unhealthy.Address()).Unhealthy)
+}
+
+// Retrained version is registered & added to the model store by a single entry
+func Test_CoordinatorLog_ModelAdded(t *testing.T) {
+	assert := assert.New(t)
+
+	coordinator := NewIDunnoCoordinator(nil, nil)
+	spec := coordinator.Models.Get("albert")
+	task := &api.TrainTask{Model: "albert", Dataset: "emotion"}
+
+	coordinator.ApplyLogEntry(&api.LogEntry{Type: api.LogEntryType_ModelAdded, TrainTask: task, ModelSpec: spec})
+	retrained := &api.ModelSpec{Name: "albert", Version: spec.GetVersion() + 1, Framework: spec.GetFramework()}
+	coordinator.ApplyLogEntry(&api.LogEntry{Type: api.LogEntryType_ModelAdded, TrainTask: task, ModelSpec: retrained})
+
+	assert.Equal(retrained.GetVersion(), coordinator.Models.Get("albert").GetVersion())
+	assert.True(coordinator.ModelStore.ContainsVersion("albert", spec.GetVersion()))
+	assert.True(coordinator.ModelStore.ContainsVersion("albert", retrained.GetVersion()))
+	assert.Equal(spec.GetVersion(), coordinator.ModelStore.Promoted("albert"), "first trained version should stay promoted")
+
+	// older version trained again does not replace the registered one
+	coordinator.ApplyLogEntry(&api.LogEntry{Type: api.LogEntryType_ModelAdded, TrainTask: task, ModelSpec: spec})
+	assert.Equal(retrained.GetVersion(), coordinator.Models.Get("albert").GetVersion())
+}
+
+// Failed batch is handed out again right away, unless a speculative copy of it still runs
+func Test_CoordinatorLog_BatchFailed(t *testing.T) {
+	assert := assert.New(t)
+
+	first, second := &api.Process{Ip: "10.0.0.1", Port: 8000}, &api.Process{Ip: "10.0.0.2", Port: 8000}
+	coordinator := NewIDunnoCoordinator(nil, nil)
+	coordinator.ResourceManager.AddWorker(first)
+	coordinator.ResourceManager.AddWorker(second)
+	coordinator.Scheduler.AddJob(&api.Job{Id: "job", BatchStates: []*api.BatchState{
+		{Status: api.BatchStatus_Available, BatchInput: &api.BatchInput{BatchId: 0}},
+	}})
+	job := coordinator.Scheduler.GetJob("job")
+
+	expiry := timestamppb.New(time.Now().Add(time.Minute))
+	for i, worker := range []*api.Process{first, second} {
+		coordinator.ApplyLogEntry(&api.LogEntry{Type: api.LogEntryType_BatchAssigned, JobId: "job", Worker: worker, BatchId: 0,
+			Lease: &api.BatchLease{Id: int64(i + 1), Worker: worker.Address(), Expiry: expiry}})
+	}
+
+	coordinator.ApplyLogEntry(&api.LogEntry{Type: api.LogEntryType_BatchFailed, JobId: "job", Worker: first, BatchId: 0})
+	assert.Empty(coordinator.ResourceManager.GetWorker(first.Address()).BatchInputs)
+	assert.Equal(api.BatchStatus_InProgress, job.BatchStates[0].Status, "speculative copy should keep running")
+	assert.True(job.HoldsLease(0, 2, second.Address()))
+
+	coordinator.ApplyLogEntry(&api.LogEntry{Type: api.LogEntryType_BatchFailed, JobId: "job", Worker: second, BatchId: 0})
+	assert.Empty(coordinator.ResourceManager.GetWorker(second.Address()).BatchInputs)
+	assert.Equal(api.BatchStatus_Available, job.BatchStates[0].Status)
+	assert.Equal(int32(0), job.DuplicateOutputs, "failed batches are not duplicates")
+}
+
+// Job created while a checkpoint is merged removes its own task, not the task at the top of the reordered queue
+func Test_CoordinatorLog_JobCreatedRemovesItsTask(t *testing.T) {
+	assert := assert.New(t)
+
+	queueTime := timestamppb.New(time.Now())
+	checkpoint := NewIDunnoCoordinator(nil, nil)
+	checkpoint.TaskQueue.Push(&api.InferenceTask{Model: "albert", User: "alice", QueueTime: timestamppb.New(queueTime.AsTime().Add(-time.Hour))})
+
+	coordinator := NewIDunnoCoordinator(nil, nil)
+	created := &api.InferenceTask{Model: "albert", User: "alice", QueueTime: queueTime}
+	dropped := &api.InferenceTask{Model: "albert", User: "alice", QueueTime: queueTime, GraphId: "graph", Node: "b"}
+	coordinator.TaskQueue.Push(created)
+	coordinator.TaskQueue.Push(dropped)
+	coordinator.MergeSnapshot(checkpoint.BuildSnapshot())
+
+	coordinator.ApplyLogEntry(&api.LogEntry{Type: api.LogEntryType_JobCreated, Job: &api.Job{Id: "job"}, InferenceTask: created})
+	coordinator.ApplyLogEntry(&api.LogEntry{Type: api.LogEntryType_TaskDropped, InferenceTask: dropped})
+	tasks := coordinator.TaskQueue.ToSlice()
+	if assert.Len(tasks, 1) {
+		assert.True(tasks[0].GetQueueTime().AsTime().Before(queueTime.AsTime()), "restored task should still be queued")
+	}
+	assert.NotNil(coordinator.Scheduler.GetJob("job"))
+
+	// entry naming a task that is not queued removes nothing
+	coordinator.ApplyLogEntry(&api.LogEntry{Type: api.LogEntryType_TaskDropped, InferenceTask: created})
+	assert.Equal(1, coordinator.TaskQueue.Len())
+}
+
+// Completed job keeps its workers until JobFinished is applied, but is not given workers while it waits
+func Test_CoordinatorLog_JobFinished(t *testing.T) {
+	assert := assert.New(t)
+
+	coordinator := NewIDunnoCoordinator(nil, nil)
+	process := &api.Process{Ip: "10.0.0.1", Port: 8000}
+	coordinator.ResourceManager.AddWorker(process)
+	coordinator.ResourceManager.RegisterCapabilities(process, &api.WorkerCapabilities{Runner: RUNNER_PYTHON})
+	coordinator.Scheduler.AddJob(&api.Job{Id: "job", TotalQueries: 1, BatchStates: []*api.BatchState{
+		{Status: api.BatchStatus_Available, BatchInput: &api.BatchInput{BatchId: 0}},
+	}})
+	worker := coordinator.ResourceManager.GetWorker(process.Address())
+	worker.JobId = "job"
+
+	coordinator.Scheduler.OnReceiveBatchOutput("job", process, &api.BatchOutput{BatchId: 0})
+	assert.True(coordinator.Scheduler.IsPending("job"))
+	assert.Contains(coordinator.Scheduler.ActiveJobs, "job")
+	assert.Equal("job", worker.JobId)
+
+	coordinator.ApplyLogEntry(&api.LogEntry{Type: api.LogEntryType_JobFinished, JobId: "job", Time: api.CurrentTimestamp()})
+	assert.NotContains(coordinator.Scheduler.ActiveJobs, "job")
+	assert.False(coordinator.Scheduler.IsPending("job"))
+	assert.Equal(api.JobStatus_Finished, coordinator.Scheduler.CompletedJobs["job"].GetStatus())
+	assert.Empty(worker.JobId, "worker should be released with the job")
+
+	// a completed job waiting to be flushed gets no worker
+	coordinator.Scheduler.AddJob(&api.Job{Id: "pending", TotalQueries: 1})
+	coordinator.Scheduler.PendingJobs.Push(coordinator.Scheduler.GetJob("pending"))
+	assert.Empty(coordinator.Scheduler.RefreshSchedule(0)["pending"])
+}
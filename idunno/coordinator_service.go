@@ -6,10 +6,18 @@ import (
 	"mp4/api"
 	"mp4/logger"
 	"mp4/utils"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/protobuf/proto"
 )
 
 func (ic *IDunnoCoordinator) Train(ctx context.Context, req *api.TrainRequest) (*api.TrainResponse, error) {
 	logger.Info(fmt.Sprintf("Received Train request - Model: %v, Dataset: %v", req.GetTrainTask().GetModel(), req.GetTrainTask().GetDataset()))
+	if !ic.Ring.HasQuorum() {
+		logger.Error("Cannot train without quorum")
+		return nil, fmt.Errorf("cannot train without quorum")
+	}
 	ic.OnBecomeCoordinator()
 
 	ic.Lock()
@@ -18,7 +26,31 @@ func (ic *IDunnoCoordinator) Train(ctx context.Context, req *api.TrainRequest) (
 		ic.Unlock()
 		return nil, fmt.Errorf("cannot train while serving inference requests")
 	}
+	spec, datasetSpec := ic.Models.Get(req.GetTrainTask().GetModel()), ic.Datasets.Get(req.GetTrainTask().GetDataset())
+	ic.Unlock()
+	if spec == nil {
+		logger.Error(fmt.Sprintf("Model %v is not registered", req.GetTrainTask().GetModel()))
+		return nil, fmt.Errorf("model %v is not registered", req.GetTrainTask().GetModel())
+	}
+	if datasetSpec == nil {
+		logger.Error(fmt.Sprintf("Dataset %v is not registered", req.GetTrainTask().GetDataset()))
+		return nil, fmt.Errorf("dataset %v is not registered", req.GetTrainTask().GetDataset())
+	}
+	if !IsCompatible(spec, datasetSpec) {
+		logger.Error(fmt.Sprintf("Model %v takes %v, but dataset %v is %v", spec.GetName(), spec.GetInputType(), datasetSpec.GetName(), datasetSpec.GetFormat()))
+		return nil, fmt.Errorf("model %v takes %v, but dataset %v is %v", spec.GetName(), spec.GetInputType(), datasetSpec.GetName(), datasetSpec.GetFormat())
+	}
+
+	// a trained version keeps serving, retraining it trains the next version of the same spec
+	ic.Lock()
+	retrained := ic.ModelStore.ContainsVersion(utils.ModelType(spec.GetName()), spec.GetVersion())
+	if retrained {
+		spec = proto.Clone(spec).(*api.ModelSpec)
+		spec.Version = ic.Models.NextVersion(spec.GetName())
+		spec.RegisterTime = api.CurrentTimestamp()
+	}
 	ic.Unlock()
+	req.Spec = spec
 
 	// send train request to all worker machines
 	logger.Info("Sending train request to all worker machines...")
@@ -28,12 +60,11 @@ func (ic *IDunnoCoordinator) Train(ctx context.Context, req *api.TrainRequest) (
 
 	for _, process := range memList {
 		go func(process *api.Process) {
-			client, close, err := ic.CreateWorkerClient(process)
+			client, err := ic.CreateWorkerClient(process)
 			if err != nil {
 				quitChan <- err
 				return
 			}
-			defer close()
 
 			res, err := client.Train(context.Background(), req)
 			if err != nil {
@@ -62,33 +93,76 @@ func (ic *IDunnoCoordinator) Train(ctx context.Context, req *api.TrainRequest) (
 		}
 	}
 
-	// add model to model store
+	// add model to model store, a retrained version is added to the registry by the same entry
 	ic.Lock()
 	defer ic.Unlock()
-	ic.ModelStore.AddModel(utils.ModelType(req.GetTrainTask().GetModel()), utils.DatasetType(req.GetTrainTask().GetDataset()))
-
-	// TODO: forward message to backup coordinator
-	// ...
+	ic.AwaitReplication()
+	err := ic.Commit(&api.LogEntry{
+		Type:      api.LogEntryType_ModelAdded,
+		TrainTask: req.GetTrainTask(),
+		ModelSpec: spec,
+	})
+	if err != nil {
+		logger.Error("Failed to replicate trained model: " + err.Error())
+		return &api.TrainResponse{Status: api.ResponseStatus_ERROR}, err
+	}
 
-	return &api.TrainResponse{}, nil
+	return &api.TrainResponse{Status: api.ResponseStatus_OK, Version: spec.GetVersion()}, nil
 }
 
 func (ic *IDunnoCoordinator) Inference(ctx context.Context, req *api.InferenceRequest) (*api.InferenceResponse, error) {
 	logger.Info(fmt.Sprintf("Received Inference request - Model: %v, Batch Size: %v", req.GetInferenceTask().GetModel(), req.GetInferenceTask().GetBatchSize()))
+	if !ic.Ring.HasQuorum() {
+		logger.Error("Cannot serve inference request without quorum")
+		return &api.InferenceResponse{Status: api.ResponseStatus_NO_QUORUM}, fmt.Errorf("cannot serve inference request without quorum")
+	}
 	ic.OnBecomeCoordinator()
 
-	if !ic.ModelStore.Contains(utils.ModelType(req.GetInferenceTask().GetModel())) {
-		logger.Error(fmt.Sprintf("Model %v has not been trained", req.GetInferenceTask().GetModel()))
-		return nil, fmt.Errorf("model %v has not been trained", req.GetInferenceTask().GetModel())
-	}
+	task := req.GetInferenceTask()
+	task.User = RequestUser(ctx)
+	task.QueueTime = api.CurrentTimestamp()
 
 	// add inference task to task queue
 	ic.Lock()
 	defer ic.Unlock()
-	ic.TaskQueue.Push(req.GetInferenceTask())
+	ic.AwaitReplication()
+	shares, err := ic.ResolveVersions(task)
+	if err != nil {
+		logger.Error("Invalid versions of inference task: " + err.Error())
+		return nil, err
+	}
+	if task.GetUpstreamJob() != utils.EMPTY_STRING {
+		upstream, ok := ic.Scheduler.CompletedJobs[task.GetUpstreamJob()]
+		if !ok {
+			logger.Error(fmt.Sprintf("Upstream job %v is not completed", task.GetUpstreamJob()))
+			return nil, fmt.Errorf("upstream job %v is not completed", task.GetUpstreamJob())
+		}
+		if dataset := ic.TaskDataset(task.GetModel(), shares); string(dataset) != upstream.Dataset {
+			logger.Error(fmt.Sprintf("Model %v is trained on %v, but upstream job %v produces %v", task.GetModel(), dataset, upstream.Id, upstream.Dataset))
+			return nil, fmt.Errorf("model %v is trained on %v, but upstream job %v produces %v", task.GetModel(), dataset, upstream.Id, upstream.Dataset)
+		}
+	}
+	if placement := ic.JobPlacement(task, shares); !ic.ResourceManager.CanPlace(placement) {
+		logger.Error(fmt.Sprintf("No worker runs framework %v with placement constraints %v", placement.GetFramework(), FormatPlacement(placement)))
+		return nil, fmt.Errorf("no worker runs framework %v with placement constraints %v", placement.GetFramework(), FormatPlacement(placement))
+	}
+	if ok, reason := ic.Admit(task); !ok {
+		logger.Error("Rejected inference task: " + reason)
+		return &api.InferenceResponse{
+			Status:     api.ResponseStatus_OVER_CAPACITY,
+			RetryAfter: ADMISSION_RETRY_AFTER,
+			Message:    reason,
+		}, nil
+	}
 
-	// TODO: forward message to backup coordinator
-	// ...
+	err = ic.Commit(&api.LogEntry{
+		Type:          api.LogEntryType_TaskQueued,
+		InferenceTask: task,
+	})
+	if err != nil {
+		logger.Error("Failed to replicate inference task: " + err.Error())
+		return &api.InferenceResponse{Status: api.ResponseStatus_ERROR}, err
+	}
 
 	return &api.InferenceResponse{
 		Status: api.ResponseStatus_OK,
@@ -97,10 +171,12 @@ func (ic *IDunnoCoordinator) Inference(ctx context.Context, req *api.InferenceRe
 
 func (ic *IDunnoCoordinator) QueryData(ctx context.Context, req *api.QueryDataRequest) (*api.QueryDataResponse, error) {
 	logger.Query(fmt.Sprintf("Received QueryData request - Job ID: %v", req.GetJobId()))
+	if !ic.Ring.HasQuorum() {
+		logger.Error("Cannot query data without quorum")
+		return nil, fmt.Errorf("cannot query data without quorum")
+	}
 	ic.OnBecomeCoordinator()
 
-	// TODO: forward message to backup coordinator
-	// ...
 	ic.Lock()
 	defer ic.Unlock()
 
@@ -111,19 +187,87 @@ func (ic *IDunnoCoordinator) QueryData(ctx context.Context, req *api.QueryDataRe
 		logger.Error(fmt.Sprintf("Trying to query data, but worker %v not found", req.GetWorker().Address()))
 		return nil, fmt.Errorf("trying to query data, but worker %v not found", req.GetWorker().Address())
 	}
+	// job is finished or gone, the worker is released from it
 	if job == nil {
-		logger.Error(fmt.Sprintf("Trying to query data, but job %v not found", req.GetJobId()))
-		return nil, fmt.Errorf("trying to query data, but job %v not found", req.GetJobId())
+		logger.Info(fmt.Sprintf("Worker %v queried job %v, but job is not found", req.GetWorker().Address(), req.GetJobId()))
+		return &api.QueryDataResponse{JobId: req.GetJobId(), Released: true}, nil
 	}
 
 	if req.GetBatchOutput() != nil {
-		ic.Scheduler.OnReceiveBatchOutput(req.GetJobId(), req.GetWorker(), req.GetBatchOutput())
+		entry := &api.LogEntry{
+			Type:        api.LogEntryType_BatchCompleted,
+			JobId:       req.GetJobId(),
+			Worker:      req.GetWorker(),
+			BatchOutput: req.GetBatchOutput(),
+		}
+		holdsLease := job.HoldsLease(req.GetBatchOutput().GetBatchId(), req.GetLeaseId(), req.GetWorker().Address())
+
+		// failed batch is handed out again right away, rather than holding the worker's slot until its lease expires
+		if req.GetFailed() {
+			logger.Info(fmt.Sprintf("Worker %v failed to evaluate batch %v of job %v", req.GetWorker().Address(), req.GetBatchOutput().GetBatchId(), req.GetJobId()))
+			entry = &api.LogEntry{
+				Type:    api.LogEntryType_BatchFailed,
+				JobId:   req.GetJobId(),
+				Worker:  req.GetWorker(),
+				BatchId: req.GetBatchOutput().GetBatchId(),
+			}
+		} else if !holdsLease {
+			// output of a batch completed by another worker, or of an expired lease
+			logger.Info(fmt.Sprintf("Rejected output of batch %v of job %v from worker %v without a current lease", req.GetBatchOutput().GetBatchId(), req.GetJobId(), req.GetWorker().Address()))
+			entry = &api.LogEntry{
+				Type:    api.LogEntryType_BatchRejected,
+				JobId:   req.GetJobId(),
+				Worker:  req.GetWorker(),
+				BatchId: req.GetBatchOutput().GetBatchId(),
+			}
+		}
+
+		// lease of a failed batch is already gone, i.e. expired or completed by another worker
+		if holdsLease || !req.GetFailed() {
+			err := ic.Commit(entry)
+			if err != nil {
+				logger.Error("Failed to replicate batch output: " + err.Error())
+				return nil, err
+			}
+		}
+	}
+	// health is recorded once the output is processed, so that an output leased while the runner was healthy is kept
+	if err := ic.OnWorkerState(worker, req); err != nil {
+		logger.Error("Failed to replicate worker state: " + err.Error())
+		return nil, err
 	}
 
-	// job id mismatch
+	// batches are leased before their assignment is replicated, no other entry may be in flight meanwhile.
+	// The lock is released while replicating, the job may be gone since
+	ic.AwaitReplication()
+	if worker = ic.Scheduler.ResourceManager.GetWorker(req.GetWorker().Address()); worker == nil {
+		logger.Error(fmt.Sprintf("Trying to query data, but worker %v not found", req.GetWorker().Address()))
+		return nil, fmt.Errorf("trying to query data, but worker %v not found", req.GetWorker().Address())
+	}
+	if job = ic.Scheduler.GetJob(req.GetJobId()); job == nil {
+		logger.Info(fmt.Sprintf("Worker %v queried job %v, but job is not found", req.GetWorker().Address(), req.GetJobId()))
+		return &api.QueryDataResponse{JobId: req.GetJobId(), Released: true}, nil
+	}
+
+	// leaving worker submits its last output, release it without handing out a new batch
+	if req.GetDraining() {
+		logger.Info(fmt.Sprintf("Worker %v is draining from job %v", req.GetWorker().Address(), req.GetJobId()))
+		ic.Scheduler.OnWorkerDraining(worker)
+		return &api.QueryDataResponse{}, nil
+	}
+	// pipelined worker submits outputs apart from leasing batches, i.e. of a job it is no longer assigned to
+	if req.GetSubmitOnly() {
+		return &api.QueryDataResponse{}, nil
+	}
+	// worker whose runner is unhealthy takes no new batch
+	if worker.Unhealthy {
+		return &api.QueryDataResponse{}, nil
+	}
+
+	// worker was released from the job, i.e. its runner turned unhealthy
 	if worker.JobId != req.GetJobId() {
-		logger.Error(fmt.Sprintf("Trying to query data with job id %v, but worker %v is not assigned to job %v", req.GetJobId(), req.GetWorker().Address(), worker.JobId))
-		return nil, fmt.Errorf("trying to query data with job id %v, but worker %v is not assigned to job %v", req.GetJobId(), req.GetWorker().Address(), worker.JobId)
+		logger.Info(fmt.Sprintf("Worker %v queried job %v, but it is released from it, assigned to job %q", req.GetWorker().Address(), req.GetJobId(), worker.JobId))
+		return &api.QueryDataResponse{JobId: req.GetJobId(), Released: true}, nil
 	}
 	// idle worker
 	if worker.Idle() {
@@ -131,69 +275,506 @@ func (ic *IDunnoCoordinator) QueryData(ctx context.Context, req *api.QueryDataRe
 		return nil, fmt.Errorf("trying to query data from idle worker %v", req.GetWorker().Address())
 	}
 
-	// validate SDFS input dataset
-	batchInput := job.FetchBatchInput()
+	// paused or cancelled job, no new batch is handed out
+	if job.Status != api.JobStatus_Running {
+		worker.BatchInputs = nil
+		return &api.QueryDataResponse{}, nil
+	}
+	// pipelined worker holds up to its pipeline depth of batches
+	depth := int(req.GetPipelineDepth())
+	if depth < 1 {
+		depth = 1
+	}
+	if len(worker.BatchInputs) >= depth {
+		return &api.QueryDataResponse{}, nil
+	}
 
+	// validate SDFS input dataset, once every batch is handed out, stragglers are handed out again.
+	// Workers only evaluate batches of the version they loaded
+	batchInput := job.FetchBatchInput(worker.Version)
+	if batchInput == nil {
+		batchInput = job.FetchSpeculativeBatch(worker.Version, job.SpeculationThreshold())
+		// never hand a straggler to the worker already holding it
+		if batchInput != nil && worker.HoldsBatch(batchInput.GetBatchId()) {
+			batchInput = nil
+		}
+		if batchInput != nil {
+			logger.Info(fmt.Sprintf("Speculatively handing batch %v of job %v to worker %v", batchInput.GetBatchId(), job.Id, req.GetWorker().Address()))
+		}
+	}
+
+	// batches of other versions are left, release the worker so that it is rescheduled with another version
+	if batchInput == nil && job.HasAvailableBatch() && len(worker.BatchInputs) == 0 {
+		logger.Info(fmt.Sprintf("Releasing worker %v from job %v, version %v has no batch left", req.GetWorker().Address(), job.Id, worker.Version))
+		err := ic.Commit(&api.LogEntry{
+			Type:   api.LogEntryType_WorkerReleased,
+			JobId:  job.Id,
+			Worker: req.GetWorker(),
+		})
+		if err != nil {
+			logger.Error("Failed to replicate worker release: " + err.Error())
+			return nil, err
+		}
+		return &api.QueryDataResponse{JobId: job.Id, Released: true}, nil
+	}
+
+	// inputs are validated against the dataset schema when the job is created
+	var lease *api.BatchLease
 	if batchInput != nil {
-		inputs := batchInput.GetInputs()
-		batchInput.Inputs = ValidateDatasetInputs(utils.DatasetType(job.Dataset), inputs)
+		// batch waits behind the batches the worker already holds before it is evaluated
+		timeout := job.BatchTimeout() * time.Duration(len(worker.BatchInputs)+1)
+		lease = job.GrantLease(batchInput.GetBatchId(), req.GetWorker().Address(), time.Now().Add(timeout))
+
+		err := ic.Replicate(&api.LogEntry{
+			Type:    api.LogEntryType_BatchAssigned,
+			JobId:   job.Id,
+			Worker:  req.GetWorker(),
+			BatchId: batchInput.GetBatchId(),
+			Lease:   lease,
+		})
+		if err != nil {
+			logger.Error("Failed to replicate batch assignment: " + err.Error())
+			// the batch is handed out again to a worker that asks once the entry is replicated
+			job.ReleaseLease(batchInput.GetBatchId(), req.GetWorker().Address())
+			return nil, err
+		}
 	}
 
-	worker.CurrBatchInput = batchInput
+	worker.AddBatch(batchInput)
 	return &api.QueryDataResponse{
-		BatchInput: worker.CurrBatchInput,
-		IsFilename: ContainFilenames(utils.DatasetType(job.Dataset)),
+		BatchInput: batchInput,
+		IsFilename: ic.Datasets.Get(job.Dataset).GetFormat() == api.DatasetFormat_FileList,
+		Lease:      lease,
 	}, nil
 }
 
-func (ic *IDunnoCoordinator) Backup(ctx context.Context, req *api.BackupRequest) (*api.BackupResponse, error) {
-	logger.Info("Received Backup request")
+func (ic *IDunnoCoordinator) SubmitGraph(ctx context.Context, req *api.SubmitGraphRequest) (*api.SubmitGraphResponse, error) {
+	logger.Info(fmt.Sprintf("Received SubmitGraph request - Nodes: %v", len(req.GetGraph().GetNodes())))
+	if !ic.Ring.HasQuorum() {
+		logger.Error("Cannot submit job graph without quorum")
+		return &api.SubmitGraphResponse{Status: api.ResponseStatus_NO_QUORUM}, fmt.Errorf("cannot submit job graph without quorum")
+	}
+	ic.OnBecomeCoordinator()
+	user := RequestUser(ctx)
 
 	ic.Lock()
 	defer ic.Unlock()
+	ic.AwaitReplication()
 
-	if req.GetBackup() == nil {
-		logger.Info("Backup request is nil")
+	graph := req.GetGraph()
+	if err := ic.ValidateGraph(graph); err != nil {
+		logger.Error("Invalid job graph: " + err.Error())
+		return &api.SubmitGraphResponse{Status: api.ResponseStatus_ERROR, Message: err.Error()}, nil
+	}
+
+	graph.Id = utils.CreateId(GRAPH_PREFIX)
+	for i := 1; ic.Graphs[graph.Id] != nil; i++ {
+		graph.Id = fmt.Sprintf("%v:%v", utils.CreateId(GRAPH_PREFIX), i)
+	}
+	graph.SubmitTime = api.CurrentTimestamp()
+	for _, node := range graph.GetNodes() {
+		node.Status = api.GraphNodeStatus_NodeWaiting
+		node.JobId = utils.EMPTY_STRING
+		node.Task.User = user
+
+		// root nodes are queued right away
+		if node.GetUpstream() == utils.EMPTY_STRING {
+			if ok, reason := ic.Admit(node.GetTask()); !ok {
+				logger.Error("Rejected job graph: " + reason)
+				return &api.SubmitGraphResponse{Status: api.ResponseStatus_OVER_CAPACITY, Message: reason}, nil
+			}
+		}
+	}
+
+	err := ic.Commit(&api.LogEntry{
+		Type:  api.LogEntryType_GraphSubmitted,
+		Graph: graph,
+	})
+	if err != nil {
+		logger.Error("Failed to replicate job graph: " + err.Error())
+		return &api.SubmitGraphResponse{Status: api.ResponseStatus_ERROR}, err
+	}
+
+	return &api.SubmitGraphResponse{Status: api.ResponseStatus_OK, GraphId: graph.GetId()}, nil
+}
+
+/*
+ * Evaluate a few inputs synchronously on a worker already serving the model, next to batch jobs
+ *
+ * @param req: model, raw inputs (or SDFS filenames of images) and image bytes to evaluate
+ * @return *api.PredictResponse: results with the latency of the request, OVER_CAPACITY if no worker serves the model yet
+ */
+func (ic *IDunnoCoordinator) Predict(ctx context.Context, req *api.PredictRequest) (*api.PredictResponse, error) {
+	startTime := time.Now()
+	logger.Info(fmt.Sprintf("Received Predict request - Model: %v, Inputs: %v, Images: %v", req.GetModel(), len(req.GetInputs()), len(req.GetImages())))
+	if !ic.Ring.HasQuorum() {
+		logger.Error("Cannot serve predict request without quorum")
+		return &api.PredictResponse{Status: api.ResponseStatus_NO_QUORUM}, fmt.Errorf("cannot serve predict request without quorum")
+	}
+	ic.OnBecomeCoordinator()
+
+	// requests are served by the promoted version unless they pick one
+	ic.Lock()
+	version := req.GetVersion()
+	if version == 0 {
+		version = ic.ModelStore.Promoted(utils.ModelType(req.GetModel()))
+	}
+	spec := ic.ModelStore.GetVersion(utils.ModelType(req.GetModel()), version).GetSpec()
+	ic.Unlock()
+	if spec == nil {
+		logger.Error(fmt.Sprintf("Version %v of model %v has not been trained", version, req.GetModel()))
+		return nil, fmt.Errorf("version %v of model %v has not been trained", version, req.GetModel())
+	}
+
+	// validate inputs, images are only taken by models evaluating files
+	inputCount := len(req.GetInputs()) + len(req.GetImages())
+	if inputCount == 0 || inputCount > MAX_PREDICT_INPUTS {
+		logger.Error(fmt.Sprintf("Predict request has %v inputs", inputCount))
+		return nil, fmt.Errorf("predict request must have 1 to %v inputs, got %v", MAX_PREDICT_INPUTS, inputCount)
+	}
+	if len(req.GetImages()) > 0 && !TakesFilenames(spec) {
+		logger.Error(fmt.Sprintf("Model %v does not take images", req.GetModel()))
+		return nil, fmt.Errorf("model %v takes %v and does not take images", req.GetModel(), spec.GetInputType())
+	}
+	inputs := ValidateModelInputs(spec, req.GetInputs())
+	if len(inputs) != len(req.GetInputs()) {
+		logger.Error(fmt.Sprintf("Predict request has inputs invalid for model %v", req.GetModel()))
+		return nil, fmt.Errorf("%v inputs do not match input pattern %q of model %v", len(req.GetInputs())-len(inputs), spec.GetInputPattern(), req.GetModel())
+	}
+
+	slo := DEFAULT_PREDICT_SLO
+	if req.GetSloMillis() > 0 {
+		slo = time.Duration(req.GetSloMillis()) * time.Millisecond
+	}
+
+	// route to a worker serving the model, models without one get workers reserved on next reschedule
+	ic.Lock()
+	online := ic.RecordOnlineRequest(req.GetModel())
+	worker := ic.PickPredictWorker(req.GetModel(), version)
+	if worker == nil {
+		online.Rejected++
+		ic.Unlock()
+		logger.Error(fmt.Sprintf("No worker is serving model %v", req.GetModel()))
+		return &api.PredictResponse{
+			Status:     api.ResponseStatus_OVER_CAPACITY,
+			RetryAfter: PREDICT_RETRY_AFTER,
+			Message:    fmt.Sprintf("no worker is serving model %v yet", req.GetModel()),
+		}, nil
+	}
+	worker.Predicting++
+	ic.Unlock()
+
+	defer func() {
+		ic.Lock()
+		worker.Predicting--
+		ic.Unlock()
+	}()
+
+	// images are fetched by the worker from SDFS, same as batch inputs
+	if len(req.GetImages()) > 0 {
+		filenames, err := ic.PutPredictImages(req.GetImages())
+		defer ic.DeletePredictImages(filenames)
+		if err != nil {
+			logger.Error("Failed to put predict images to SDFS: " + err.Error())
+			return &api.PredictResponse{Status: api.ResponseStatus_ERROR}, err
+		}
+		inputs = append(inputs, filenames...)
+	}
+
+	client, err := ic.CreateWorkerClient(worker.Process)
+	if err != nil {
+		return &api.PredictResponse{Status: api.ResponseStatus_ERROR}, err
+	}
+
+	// latency objective is advisory, a slower request is still served and counted as a violation.
+	// The worker gets the client's deadline, capped at PREDICT_TIMEOUT
+	workerCtx, cancel := context.WithTimeout(ctx, PREDICT_TIMEOUT)
+	defer cancel()
+
+	res, err := client.Predict(workerCtx, &api.PredictRequest{
+		Model:      req.GetModel(),
+		Inputs:     inputs,
+		IsFilename: TakesFilenames(spec),
+		Version:    version,
+	})
+	latency := time.Since(startTime)
+
+	ic.Lock()
+	defer ic.Unlock()
+	if err != nil || res.GetStatus() != api.ResponseStatus_OK {
+		online.Failures++
+		logger.Error(fmt.Sprintf("Worker %v failed to serve predict request of model %v", worker.Process.Address(), req.GetModel()))
+		if err != nil {
+			return &api.PredictResponse{Status: api.ResponseStatus_ERROR}, err
+		}
+		return &api.PredictResponse{Status: res.GetStatus(), Message: res.GetMessage()}, nil
 	}
+	online.RecordLatency(latency, slo)
 
-	if req.GetBackup().GetModelStore() == nil {
-		req.GetBackup().ModelStore = make(map[string]string)
+	// inputs of images are local paths of worker
+	results := res.GetResults()
+	for _, result := range results {
+		result.Input = filepath.Base(result.GetInput())
 	}
 
-	if req.GetBackup().GetActiveJobs() == nil {
-		req.GetBackup().ActiveJobs = make([]*api.Job, 0)
+	return &api.PredictResponse{
+		Status:        api.ResponseStatus_OK,
+		Results:       results,
+		LatencyMillis: latency.Milliseconds(),
+		SloMet:        latency <= slo,
+		Worker:        worker.Process.Address(),
+	}, nil
+}
+
+func (ic *IDunnoCoordinator) RegisterModel(ctx context.Context, req *api.RegisterModelRequest) (*api.RegisterModelResponse, error) {
+	logger.Info(fmt.Sprintf("Received RegisterModel request - Model: %v, Framework: %v", req.GetSpec().GetName(), req.GetSpec().GetFramework()))
+	if !ic.Ring.HasQuorum() {
+		logger.Error("Cannot register model without quorum")
+		return &api.RegisterModelResponse{Status: api.ResponseStatus_NO_QUORUM}, fmt.Errorf("cannot register model without quorum")
 	}
+	ic.OnBecomeCoordinator()
+
+	ic.Lock()
+	defer ic.Unlock()
+	ic.AwaitReplication()
 
-	if req.GetBackup().GetCompletedJobs() == nil {
-		req.GetBackup().CompletedJobs = make([]*api.Job, 0)
+	spec := req.GetSpec()
+	if err := ic.Models.Validate(spec); err != nil {
+		logger.Error("Rejected model: " + err.Error())
+		return &api.RegisterModelResponse{Status: api.ResponseStatus_ERROR, Message: err.Error()}, nil
+	}
+	if spec.GetVersion() == 0 {
+		spec.Version = ic.Models.NextVersion(spec.GetName())
+	}
+	spec.RegisterTime = api.CurrentTimestamp()
+
+	err := ic.Commit(&api.LogEntry{
+		Type:      api.LogEntryType_ModelRegistered,
+		ModelSpec: spec,
+	})
+	if err != nil {
+		logger.Error("Failed to replicate registered model: " + err.Error())
+		return &api.RegisterModelResponse{Status: api.ResponseStatus_ERROR}, err
 	}
 
-	if req.GetBackup().GetPendingJobs() == nil {
-		req.GetBackup().PendingJobs = make([]*api.Job, 0)
+	return &api.RegisterModelResponse{Status: api.ResponseStatus_OK, Spec: spec}, nil
+}
+
+func (ic *IDunnoCoordinator) ListModels(ctx context.Context, req *api.ListModelsRequest) (*api.ListModelsResponse, error) {
+	ic.Lock()
+	defer ic.Unlock()
+
+	res := &api.ListModelsResponse{
+		Models:  make([]*api.ModelSpec, 0),
+		Trained: make(map[string]*api.ModelVersions),
+	}
+	for name, spec := range *ic.Models {
+		if req.GetName() != utils.EMPTY_STRING && req.GetName() != name {
+			continue
+		}
+		res.Models = append(res.Models, spec)
+		if ic.ModelStore.Contains(utils.ModelType(name)) {
+			res.Trained[name] = proto.Clone((*ic.ModelStore)[utils.ModelType(name)]).(*api.ModelVersions)
+		}
 	}
 
-	ic.ModelStore = NewModelStore()
-	ic.Scheduler.ActiveJobs = make(map[string]*api.Job)
-	ic.Scheduler.CompletedJobs = make(map[string]*api.Job)
-	ic.Scheduler.PendingJobs = utils.NewQueue[*api.Job]()
+	if req.GetName() != utils.EMPTY_STRING && len(res.Models) == 0 {
+		logger.Error(fmt.Sprintf("Model %v is not registered", req.GetName()))
+		return nil, fmt.Errorf("model %v is not registered", req.GetName())
+	}
+	return res, nil
+}
+
+func (ic *IDunnoCoordinator) PromoteModel(ctx context.Context, req *api.PromoteModelRequest) (*api.PromoteModelResponse, error) {
+	logger.Info(fmt.Sprintf("Received PromoteModel request - Model: %v, Version: %v", req.GetModel(), req.GetVersion()))
+	if !ic.Ring.HasQuorum() {
+		logger.Error("Cannot promote model without quorum")
+		return &api.PromoteModelResponse{Status: api.ResponseStatus_NO_QUORUM}, fmt.Errorf("cannot promote model without quorum")
+	}
+	ic.OnBecomeCoordinator()
 
-	for k, v := range req.GetBackup().GetModelStore() {
-		ic.ModelStore.AddModel(utils.ModelType(k), utils.DatasetType(v))
+	ic.Lock()
+	defer ic.Unlock()
+	ic.AwaitReplication()
+
+	trained := ic.ModelStore.GetVersion(utils.ModelType(req.GetModel()), req.GetVersion())
+	if trained == nil {
+		logger.Error(fmt.Sprintf("Version %v of model %v has not been trained", req.GetVersion(), req.GetModel()))
+		return &api.PromoteModelResponse{
+			Status:  api.ResponseStatus_NOT_FOUND,
+			Message: fmt.Sprintf("version %v of model %v has not been trained", req.GetVersion(), req.GetModel()),
+		}, nil
 	}
 
-	for _, job := range req.GetBackup().GetActiveJobs() {
-		ic.Scheduler.ActiveJobs[job.GetId()] = job
+	err := ic.Commit(&api.LogEntry{
+		Type:      api.LogEntryType_ModelPromoted,
+		ModelSpec: trained.GetSpec(),
+	})
+	if err != nil {
+		logger.Error("Failed to replicate promoted model: " + err.Error())
+		return &api.PromoteModelResponse{Status: api.ResponseStatus_ERROR}, err
 	}
 
-	for _, job := range req.GetBackup().GetCompletedJobs() {
-		ic.Scheduler.CompletedJobs[job.GetId()] = job
+	return &api.PromoteModelResponse{Status: api.ResponseStatus_OK}, nil
+}
+
+func (ic *IDunnoCoordinator) RegisterDataset(ctx context.Context, req *api.RegisterDatasetRequest) (*api.RegisterDatasetResponse, error) {
+	logger.Info(fmt.Sprintf("Received RegisterDataset request - Dataset: %v, Format: %v", req.GetSpec().GetName(), req.GetSpec().GetFormat()))
+	if !ic.Ring.HasQuorum() {
+		logger.Error("Cannot register dataset without quorum")
+		return &api.RegisterDatasetResponse{Status: api.ResponseStatus_NO_QUORUM}, fmt.Errorf("cannot register dataset without quorum")
 	}
+	ic.OnBecomeCoordinator()
+
+	ic.Lock()
+	defer ic.Unlock()
+	ic.AwaitReplication()
 
-	for _, job := range req.GetBackup().GetPendingJobs() {
-		ic.Scheduler.PendingJobs.Push(job)
+	spec := req.GetSpec()
+	if err := ValidateDatasetSpec(spec); err != nil {
+		logger.Error("Rejected dataset: " + err.Error())
+		return &api.RegisterDatasetResponse{Status: api.ResponseStatus_ERROR, Message: err.Error()}, nil
 	}
+	spec.RegisterTime = api.CurrentTimestamp()
+
+	err := ic.Commit(&api.LogEntry{
+		Type:        api.LogEntryType_DatasetRegistered,
+		DatasetSpec: spec,
+	})
+	if err != nil {
+		logger.Error("Failed to replicate registered dataset: " + err.Error())
+		return &api.RegisterDatasetResponse{Status: api.ResponseStatus_ERROR}, err
+	}
+
+	return &api.RegisterDatasetResponse{Status: api.ResponseStatus_OK}, nil
+}
+
+func (ic *IDunnoCoordinator) ListDatasets(ctx context.Context, req *api.ListDatasetsRequest) (*api.ListDatasetsResponse, error) {
+	ic.Lock()
+	defer ic.Unlock()
+
+	res := &api.ListDatasetsResponse{Datasets: make([]*api.DatasetSpec, 0)}
+	for _, spec := range *ic.Datasets {
+		res.Datasets = append(res.Datasets, spec)
+	}
+	return res, nil
+}
+
+func (ic *IDunnoCoordinator) CancelJob(ctx context.Context, req *api.JobControlRequest) (*api.JobControlResponse, error) {
+	logger.Info(fmt.Sprintf("Received CancelJob request - Job ID: %v", req.GetJobId()))
+	return ic.ControlJob(req.GetJobId(), api.LogEntryType_JobCancelled, api.JobStatus_Running, api.JobStatus_Paused)
+}
+
+func (ic *IDunnoCoordinator) PauseJob(ctx context.Context, req *api.JobControlRequest) (*api.JobControlResponse, error) {
+	logger.Info(fmt.Sprintf("Received PauseJob request - Job ID: %v", req.GetJobId()))
+	return ic.ControlJob(req.GetJobId(), api.LogEntryType_JobPaused, api.JobStatus_Running)
+}
+
+func (ic *IDunnoCoordinator) ResumeJob(ctx context.Context, req *api.JobControlRequest) (*api.JobControlResponse, error) {
+	logger.Info(fmt.Sprintf("Received ResumeJob request - Job ID: %v", req.GetJobId()))
+	return ic.ControlJob(req.GetJobId(), api.LogEntryType_JobResumed, api.JobStatus_Paused)
+}
+
+/*
+ * Change the status of an active job through the replicated log
+ *
+ * @param jobId: id of the job to control
+ * @param entryType: log entry applying the change
+ * @param allowed: statuses the job can be changed from
+ * @return *api.JobControlResponse: NOT_FOUND if job is not active, ERROR if the change is not allowed
+ */
+func (ic *IDunnoCoordinator) ControlJob(jobId string, entryType api.LogEntryType, allowed ...api.JobStatus) (*api.JobControlResponse, error) {
+	if !ic.Ring.HasQuorum() {
+		logger.Error("Cannot control job without quorum")
+		return &api.JobControlResponse{Status: api.ResponseStatus_NO_QUORUM}, fmt.Errorf("cannot control job without quorum")
+	}
+	ic.OnBecomeCoordinator()
+
+	ic.Lock()
+	defer ic.Unlock()
+	ic.AwaitReplication()
+
+	job, ok := ic.Scheduler.ActiveJobs[jobId]
+	if !ok {
+		logger.Error(fmt.Sprintf("Job %v is not active", jobId))
+		return &api.JobControlResponse{Status: api.ResponseStatus_NOT_FOUND}, fmt.Errorf("job %v is not active", jobId)
+	}
+
+	valid := false
+	for _, status := range allowed {
+		valid = valid || job.Status == status
+	}
+	if !valid {
+		logger.Error(fmt.Sprintf("Cannot apply %v on job %v, job is %v", entryType, jobId, job.Status))
+		return &api.JobControlResponse{Status: api.ResponseStatus_ERROR}, fmt.Errorf("cannot apply %v on job %v, job is %v", entryType, jobId, job.Status)
+	}
+
+	err := ic.Commit(&api.LogEntry{
+		Type:  entryType,
+		JobId: jobId,
+	})
+	if err != nil {
+		logger.Error("Failed to replicate job control: " + err.Error())
+		return &api.JobControlResponse{Status: api.ResponseStatus_ERROR}, err
+	}
+
+	return &api.JobControlResponse{Status: api.ResponseStatus_OK}, nil
+}
+
+func (ic *IDunnoCoordinator) Backup(ctx context.Context, req *api.BackupRequest) (*api.BackupResponse, error) {
+	logger.Info("Received Backup request")
+
+	ic.Lock()
+	defer ic.Unlock()
+
+	if req.GetBackup() == nil {
+		logger.Error("Backup request is nil")
+		return &api.BackupResponse{Status: api.ResponseStatus_ERROR}, nil
+	}
+
+	// snapshot from a coordinator that has been replaced
+	if req.GetBackup().GetEpoch() < ic.Log.Epoch {
+		logger.Error(fmt.Sprintf("Rejected snapshot of epoch %v, current epoch is %v", req.GetBackup().GetEpoch(), ic.Log.Epoch))
+		return &api.BackupResponse{Status: api.ResponseStatus_ERROR}, nil
+	}
+	ic.StepDown(req.GetBackup().GetEpoch())
+
+	ic.InstallSnapshot(req.GetBackup())
+	return &api.BackupResponse{Status: api.ResponseStatus_OK}, nil
+}
+
+func (ic *IDunnoCoordinator) AppendLog(ctx context.Context, req *api.AppendLogRequest) (*api.AppendLogResponse, error) {
+	ic.Lock()
+	defer ic.Unlock()
+
+	entries, status := ic.Log.Accept(req.GetEpoch(), req.GetPrevIndex(), req.GetEntries())
+	// entries from a coordinator that has been replaced
+	if status == api.ResponseStatus_ERROR {
+		logger.Error(fmt.Sprintf("Rejected log entries of epoch %v, current epoch is %v", req.GetEpoch(), ic.Log.Epoch))
+		return &api.AppendLogResponse{Status: status, LastIndex: ic.Log.LastIndex(), Epoch: ic.Log.Epoch}, nil
+	}
+	ic.StepDown(req.GetEpoch())
+
+	// missing entries before these ones, or holding entries the coordinator dropped
+	if status != api.ResponseStatus_OK {
+		return &api.AppendLogResponse{Status: status, LastIndex: ic.Log.LastIndex(), Epoch: ic.Log.Epoch}, nil
+	}
+
+	for _, entry := range entries {
+		ic.ApplyLogEntry(entry)
+		ic.Log.Append(entry)
+		ic.Log.AppliedIndex = entry.GetIndex()
+	}
+
+	return &api.AppendLogResponse{Status: api.ResponseStatus_OK, LastIndex: ic.Log.LastIndex(), Epoch: ic.Log.Epoch}, nil
+}
+
+func (ic *IDunnoCoordinator) FetchSnapshot(ctx context.Context, req *api.FetchSnapshotRequest) (*api.FetchSnapshotResponse, error) {
+	ic.Lock()
+	defer ic.Unlock()
+	ic.AwaitReplication()
 
-	return &api.BackupResponse{}, nil
+	return &api.FetchSnapshotResponse{Snapshot: ic.BuildSnapshot()}, nil
 }
 
 func (ic *IDunnoCoordinator) IDunnoStatus(ctx context.Context, req *api.IDunnoStatusRequest) (*api.IDunnoStatusResponse, error) {
@@ -216,6 +797,18 @@ func (ic *IDunnoCoordinator) IDunnoStatus(ctx context.Context, req *api.IDunnoSt
 		return &api.IDunnoStatusResponse{Message: ic.PrintCompletedJobs()}, nil
 	case STATUS_JSON_COMPLETED_JOBS:
 		return &api.IDunnoStatusResponse{Message: ic.PrintCompletedJobsJSON()}, nil
+	case STATUS_QUEUED_TASKS:
+		return &api.IDunnoStatusResponse{Message: ic.PrintQueuedTasks()}, nil
+	case STATUS_JSON_QUEUED_TASKS:
+		return &api.IDunnoStatusResponse{Message: ic.PrintQueuedTasksJSON()}, nil
+	case STATUS_GRAPHS:
+		return &api.IDunnoStatusResponse{Message: ic.PrintGraphs()}, nil
+	case STATUS_JSON_GRAPHS:
+		return &api.IDunnoStatusResponse{Message: ic.PrintGraphsJSON()}, nil
+	case STATUS_ONLINE:
+		return &api.IDunnoStatusResponse{Message: ic.PrintOnline()}, nil
+	case STATUS_JSON_ONLINE:
+		return &api.IDunnoStatusResponse{Message: ic.PrintOnlineJSON()}, nil
 	}
 
 	return nil, fmt.Errorf("invalid status request")
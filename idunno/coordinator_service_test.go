@@ -0,0 +1,129 @@
+package main
+
+import (
+	"mp4/api"
+	"mp4/ring"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// coordinator that is the only member of its ring, entries are committed without standbys
+func newStandaloneCoordinator() *IDunnoCoordinator {
+	server := ring.NewRingServer(nil, "127.0.0.1", 3000)
+	server.MembershipList = ring.MembershipList{server.Process}
+	coordinator := NewIDunnoCoordinator(server, nil)
+	coordinator.OnBecomeCoordinator()
+	return coordinator
+}
+
+// register schedulable workers listening on consecutive ports
+func addWorkers(coordinator *IDunnoCoordinator, n int) []*api.Process {
+	processes := make([]*api.Process, 0)
+	for i := 0; i < n; i++ {
+		process := &api.Process{Ip: "127.0.0.1", Port: int32(8000 + i)}
+		coordinator.ResourceManager.AddWorker(process)
+		coordinator.ResourceManager.RegisterCapabilities(process, &api.WorkerCapabilities{Runner: RUNNER_PYTHON})
+		processes = append(processes, process)
+	}
+	return processes
+}
+
+// job of the given number of batches, the first completed ones holding an output each
+func newBatchJob(id string, batches int, completed int) *api.Job {
+	job := &api.Job{Id: id, TotalQueries: int32(batches)}
+	for i := 0; i < batches; i++ {
+		state := &api.BatchState{Status: api.BatchStatus_Available, BatchInput: &api.BatchInput{BatchId: int32(i)}}
+		if i < completed {
+			state.Status = api.BatchStatus_Completed
+			state.BatchOutput = &api.BatchOutput{BatchId: int32(i), Metric: 1, Results: []*api.EvalResult{{Input: id, Output: "joy"}}}
+		}
+		job.BatchStates = append(job.BatchStates, state)
+	}
+	job.CompletedQueries = int32(completed)
+	return job
+}
+
+func Test_CoordinatorService_ControlJob(t *testing.T) {
+	assert := assert.New(t)
+
+	coordinator := newStandaloneCoordinator()
+	coordinator.Scheduler.AddJob(newBatchJob("job", 2, 0))
+	cancel := func() api.ResponseStatus {
+		res, _ := coordinator.ControlJob("job", api.LogEntryType_JobCancelled, api.JobStatus_Running, api.JobStatus_Paused)
+		return res.GetStatus()
+	}
+
+	tests := []struct {
+		name      string
+		entryType api.LogEntryType
+		allowed   []api.JobStatus
+		status    api.ResponseStatus
+		expected  api.JobStatus
+	}{
+		{"resume a running job", api.LogEntryType_JobResumed, []api.JobStatus{api.JobStatus_Paused}, api.ResponseStatus_ERROR, api.JobStatus_Running},
+		{"pause", api.LogEntryType_JobPaused, []api.JobStatus{api.JobStatus_Running}, api.ResponseStatus_OK, api.JobStatus_Paused},
+		{"pause twice", api.LogEntryType_JobPaused, []api.JobStatus{api.JobStatus_Running}, api.ResponseStatus_ERROR, api.JobStatus_Paused},
+		{"resume", api.LogEntryType_JobResumed, []api.JobStatus{api.JobStatus_Paused}, api.ResponseStatus_OK, api.JobStatus_Running},
+	}
+
+	for _, test := range tests {
+		res, _ := coordinator.ControlJob("job", test.entryType, test.allowed...)
+		assert.Equal(test.status, res.GetStatus(), test.name)
+		assert.Equal(test.expected, coordinator.Scheduler.GetJob("job").GetStatus(), test.name)
+	}
+
+	// job is cancelled once, and queued once to be flushed
+	assert.Equal(api.ResponseStatus_OK, cancel())
+	assert.Equal(api.ResponseStatus_ERROR, cancel(), "job should not be cancelled twice")
+	assert.Equal(1, coordinator.Scheduler.PendingJobs.Len())
+
+	res, _ := coordinator.ControlJob("unknown", api.LogEntryType_JobCancelled, api.JobStatus_Running)
+	assert.Equal(api.ResponseStatus_NOT_FOUND, res.GetStatus())
+}
+
+func Test_CoordinatorService_PausedJobGetsNoWorker(t *testing.T) {
+	assert := assert.New(t)
+
+	coordinator := newStandaloneCoordinator()
+	addWorkers(coordinator, 4)
+	coordinator.Scheduler.AddJob(newBatchJob("paused", 10, 0))
+	coordinator.Scheduler.AddJob(newBatchJob("running", 10, 0))
+	for _, worker := range *coordinator.ResourceManager {
+		worker.JobId = "paused"
+	}
+
+	res, _ := coordinator.ControlJob("paused", api.LogEntryType_JobPaused, api.JobStatus_Running)
+	assert.Equal(api.ResponseStatus_OK, res.GetStatus())
+
+	schedule := coordinator.Scheduler.RefreshSchedule(0)
+	assert.Empty(schedule["paused"])
+	assert.Len(schedule["running"], 4, "workers of the paused job should be given to the running one")
+	assert.Zero(coordinator.ResourceManager.GetWorkerCountById("paused"))
+}
+
+// Cancelled job is flushed with the results of its completed batches, and stays cancelled once finished
+func Test_CoordinatorService_CancelledJobIsFlushed(t *testing.T) {
+	assert := assert.New(t)
+
+	coordinator := newStandaloneCoordinator()
+	addWorkers(coordinator, 2)
+	coordinator.Scheduler.AddJob(newBatchJob("job", 4, 2))
+
+	res, _ := coordinator.ControlJob("job", api.LogEntryType_JobCancelled, api.JobStatus_Running, api.JobStatus_Paused)
+	assert.Equal(api.ResponseStatus_OK, res.GetStatus())
+	assert.Empty(coordinator.Scheduler.RefreshSchedule(0)["job"])
+	pending := coordinator.Scheduler.PendingJobs.Top()
+	assert.Equal("job", pending.GetId())
+	assert.Equal("job joy\njob joy\n\n1.000000", FormatJobResults(pending), "only completed batches should be flushed")
+
+	coordinator.Lock()
+	assert.Nil(coordinator.Commit(&api.LogEntry{Type: api.LogEntryType_JobFinished, JobId: "job"}))
+	coordinator.Unlock()
+	assert.True(coordinator.Scheduler.PendingJobs.Empty())
+	assert.NotContains(coordinator.Scheduler.ActiveJobs, "job")
+	job := coordinator.Scheduler.CompletedJobs["job"]
+	assert.Equal(api.JobStatus_Cancelled, job.GetStatus())
+	assert.NotNil(job.GetFinishTime())
+	assert.Equal(int32(2), job.GetCompletedQueries())
+}
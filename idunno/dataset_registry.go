@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"mp4/api"
+	"mp4/utils"
+	"regexp"
+	"strings"
+)
+
+const ROW_LABEL_DELIMITER = ";"    // rows handed to runners are "input;label", or only the input if unlabeled
+const MAX_INVALID_ROW_SAMPLES = 10 // invalid rows kept per job to show why they are left out
+
+// dataset name -> schema
+type DatasetRegistry map[string]*api.DatasetSpec
+
+// Registry holding the datasets shipped with IDunno
+func NewDatasetRegistry() *DatasetRegistry {
+	return &DatasetRegistry{
+		string(utils.Imagenet): {
+			Name:         string(utils.Imagenet),
+			Format:       api.DatasetFormat_FileList,
+			InputPattern: `\.JPEG$`,
+		},
+		string(utils.Emotion): {
+			Name:        string(utils.Emotion),
+			Format:      api.DatasetFormat_DelimitedText,
+			Delimiter:   ";",
+			Columns:     2,
+			LabelColumn: 2,
+		},
+	}
+}
+
+func (dr DatasetRegistry) Get(name string) *api.DatasetSpec {
+	return dr[name]
+}
+
+func (dr DatasetRegistry) Contains(name string) bool {
+	_, ok := dr[name]
+	return ok
+}
+
+func (dr DatasetRegistry) Register(spec *api.DatasetSpec) {
+	dr[spec.GetName()] = spec
+}
+
+// Whether the registry only holds built-in datasets
+func (dr DatasetRegistry) IsDefault() bool {
+	builtins := *NewDatasetRegistry()
+	if len(dr) != len(builtins) {
+		return false
+	}
+	for name, spec := range dr {
+		if _, ok := builtins[name]; !ok || spec.GetRegisterTime() != nil {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+ * Check that a dataset schema is complete for its format
+ *
+ * @param spec: schema to register
+ * @return error: raise error if the name, pattern or format options are invalid
+ */
+func ValidateDatasetSpec(spec *api.DatasetSpec) error {
+	if spec.GetName() == utils.EMPTY_STRING || strings.Contains(spec.GetName(), " ") {
+		return fmt.Errorf("invalid dataset name %q", spec.GetName())
+	}
+	if _, err := regexp.Compile(spec.GetInputPattern()); err != nil {
+		return fmt.Errorf("invalid input pattern %q: %v", spec.GetInputPattern(), err)
+	}
+
+	switch spec.GetFormat() {
+	case api.DatasetFormat_FileList:
+		if spec.GetLabelColumn() != 0 || spec.GetLabelField() != utils.EMPTY_STRING || len(spec.GetLabels()) > 0 {
+			return fmt.Errorf("dataset of files has no label")
+		}
+	case api.DatasetFormat_DelimitedText:
+		if spec.GetDelimiter() == utils.EMPTY_STRING {
+			return fmt.Errorf("delimited text requires a delimiter")
+		}
+		if spec.GetColumns() < 0 || spec.GetLabelColumn() < 0 {
+			return fmt.Errorf("columns must not be negative")
+		}
+		if spec.GetColumns() > 0 && spec.GetLabelColumn() > spec.GetColumns() {
+			return fmt.Errorf("label column %v is out of %v columns", spec.GetLabelColumn(), spec.GetColumns())
+		}
+	case api.DatasetFormat_JSONLines:
+		if spec.GetInputField() == utils.EMPTY_STRING {
+			return fmt.Errorf("json lines require an input field")
+		}
+	default:
+		return fmt.Errorf("unknown dataset format %v", spec.GetFormat())
+	}
+	return nil
+}
+
+// Whether the model can be trained on the dataset, models taking files need a dataset of files
+func IsCompatible(model *api.ModelSpec, dataset *api.DatasetSpec) bool {
+	return TakesFilenames(model) == (dataset.GetFormat() == api.DatasetFormat_FileList)
+}
+
+/*
+ * Parse a dataset row into the input handed to runners
+ *
+ * @param spec: schema of the dataset
+ * @param row: line of the dataset file
+ * @param pattern: compiled input pattern of the dataset
+ * @return string: SDFS filename, or "input;label" for labeled text
+ * @return error: reason the row does not match the schema
+ */
+func ParseDatasetRow(spec *api.DatasetSpec, row string, pattern *regexp.Regexp) (string, error) {
+	input, label, labeled := strings.TrimSpace(row), utils.EMPTY_STRING, false
+
+	switch spec.GetFormat() {
+	case api.DatasetFormat_DelimitedText:
+		columns := strings.Split(row, spec.GetDelimiter())
+		if spec.GetColumns() > 0 && len(columns) != int(spec.GetColumns()) {
+			return utils.EMPTY_STRING, fmt.Errorf("expected %v columns, got %v", spec.GetColumns(), len(columns))
+		}
+		if labelColumn := int(spec.GetLabelColumn()); labelColumn > 0 {
+			if labelColumn > len(columns) {
+				return utils.EMPTY_STRING, fmt.Errorf("missing label column %v", labelColumn)
+			}
+			label, labeled = columns[labelColumn-1], true
+			columns = append(columns[:labelColumn-1:labelColumn-1], columns[labelColumn:]...)
+		}
+		input = strings.Join(columns, spec.GetDelimiter())
+
+	case api.DatasetFormat_JSONLines:
+		fields := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(row), &fields); err != nil {
+			return utils.EMPTY_STRING, fmt.Errorf("invalid json: %v", err)
+		}
+		value, ok := fields[spec.GetInputField()].(string)
+		if !ok {
+			return utils.EMPTY_STRING, fmt.Errorf("missing string field %q", spec.GetInputField())
+		}
+		input = value
+		if spec.GetLabelField() != utils.EMPTY_STRING {
+			value, ok := fields[spec.GetLabelField()]
+			if !ok {
+				return utils.EMPTY_STRING, fmt.Errorf("missing field %q", spec.GetLabelField())
+			}
+			label, labeled = fmt.Sprint(value), true
+		}
+	}
+
+	if !pattern.MatchString(input) {
+		return utils.EMPTY_STRING, fmt.Errorf("input does not match %q", spec.GetInputPattern())
+	}
+	if labeled && !IsKnownLabel(spec, label) {
+		return utils.EMPTY_STRING, fmt.Errorf("unknown label %q", label)
+	}
+
+	if labeled {
+		return input + ROW_LABEL_DELIMITER + label, nil
+	}
+	return input, nil
+}
+
+// Whether a label is one of the dataset's labels, any label is known if none is declared
+func IsKnownLabel(spec *api.DatasetSpec, label string) bool {
+	if len(spec.GetLabels()) == 0 {
+		return true
+	}
+	for _, known := range spec.GetLabels() {
+		if known == label {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * Parse a dataset file into inputs of a model, rows that do not match the dataset schema
+ * or the model's input pattern are left out and reported
+ *
+ * @param spec: schema of the dataset
+ * @param model: spec of the model evaluating the inputs
+ * @param data: content of the dataset file
+ * @return []string: inputs handed to runners
+ * @return int32: number of invalid rows
+ * @return []*api.InvalidRow: first MAX_INVALID_ROW_SAMPLES invalid rows
+ */
+func ParseDataset(spec *api.DatasetSpec, model *api.ModelSpec, data string) ([]string, int32, []*api.InvalidRow) {
+	inputs, invalidCount, samples := make([]string, 0), int32(0), make([]*api.InvalidRow, 0)
+	report := func(line int, row string, reason string) {
+		invalidCount++
+		if len(samples) < MAX_INVALID_ROW_SAMPLES {
+			samples = append(samples, &api.InvalidRow{Line: int32(line), Row: row, Reason: reason})
+		}
+	}
+
+	// both patterns are validated when registered
+	pattern, _ := regexp.Compile(spec.GetInputPattern())
+	modelPattern, _ := regexp.Compile(model.GetInputPattern())
+
+	for i, row := range strings.Split(data, "\n") {
+		// blank lines, i.e. a trailing newline, are not rows
+		if strings.TrimSpace(row) == utils.EMPTY_STRING {
+			continue
+		}
+
+		input, err := ParseDatasetRow(spec, row, pattern)
+		if err != nil {
+			report(i+1, row, err.Error())
+			continue
+		}
+		if !modelPattern.MatchString(input) {
+			report(i+1, row, fmt.Sprintf("input does not match %q of model %v", model.GetInputPattern(), model.GetName()))
+			continue
+		}
+		inputs = append(inputs, input)
+	}
+
+	return inputs, invalidCount, samples
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"mp4/api"
+	"mp4/utils"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DatasetRegistry_ParseDatasetRow(t *testing.T) {
+	assert := assert.New(t)
+
+	emotion := NewDatasetRegistry().Get(string(utils.Emotion))
+	reviews := &api.DatasetSpec{Name: "reviews", Format: api.DatasetFormat_JSONLines, InputField: "text", LabelField: "stars", Labels: []string{"1", "5"}}
+	unlabeled := &api.DatasetSpec{Name: "tweets", Format: api.DatasetFormat_DelimitedText, Delimiter: ",", InputPattern: `^\S`}
+	images := &api.DatasetSpec{Name: "images", Format: api.DatasetFormat_FileList, InputPattern: `\.JPEG$`}
+
+	tests := []struct {
+		name     string
+		spec     *api.DatasetSpec
+		row      string
+		expected string
+		valid    bool
+	}{
+		{"file", images, " a.JPEG ", "a.JPEG", true},
+		{"file not matching the pattern", images, "a.png", "", false},
+		{"labeled text", emotion, "i feel great;joy", "i feel great;joy", true},
+		{"label column first", &api.DatasetSpec{Format: api.DatasetFormat_DelimitedText, Delimiter: ",", Columns: 3, LabelColumn: 1}, "joy,a,b", "a,b;joy", true},
+		{"too few columns", emotion, "i feel great", "", false},
+		{"too many columns", emotion, "i;feel;great", "", false},
+		{"missing label column", &api.DatasetSpec{Format: api.DatasetFormat_DelimitedText, Delimiter: ",", LabelColumn: 3}, "a,b", "", false},
+		{"any number of columns", unlabeled, "a,b,c", "a,b,c", true},
+		{"input not matching the pattern", unlabeled, " a", "", false},
+		{"json", reviews, `{"text": "great", "stars": 5}`, "great;5", true},
+		{"invalid json", reviews, `{"text": "great"`, "", false},
+		{"missing input field", reviews, `{"stars": 5}`, "", false},
+		{"input field not a string", reviews, `{"text": 5, "stars": 5}`, "", false},
+		{"missing label field", reviews, `{"text": "great"}`, "", false},
+		{"unknown label", reviews, `{"text": "great", "stars": 3}`, "", false},
+	}
+
+	for _, test := range tests {
+		pattern := regexp.MustCompile(test.spec.GetInputPattern())
+		input, err := ParseDatasetRow(test.spec, test.row, pattern)
+		if !test.valid {
+			assert.NotNil(err, test.name)
+			continue
+		}
+		assert.Nil(err, test.name)
+		assert.Equal(test.expected, input, test.name)
+	}
+}
+
+func Test_DatasetRegistry_ParseDataset(t *testing.T) {
+	assert := assert.New(t)
+
+	datasets, models := NewDatasetRegistry(), NewModelRegistry()
+	emotion, imagenet := datasets.Get(string(utils.Emotion)), datasets.Get(string(utils.Imagenet))
+	albert, resnet := models.Get("albert"), models.Get("resnet50")
+
+	tests := []struct {
+		name         string
+		spec         *api.DatasetSpec
+		model        *api.ModelSpec
+		data         string
+		expected     []string
+		invalidLines []int32
+	}{
+		{"valid rows", emotion, albert, "i feel great;joy\ni am down;sadness\n", []string{"i feel great;joy", "i am down;sadness"}, []int32{}},
+		{"malformed rows", emotion, albert, "i feel great;joy\nno label\n\na;b;c\ni am down;sadness", []string{"i feel great;joy", "i am down;sadness"}, []int32{2, 4}},
+		{"empty dataset", emotion, albert, "", []string{}, []int32{}},
+		{"blank lines only", imagenet, resnet, "\n  \n\n", []string{}, []int32{}},
+		{"model taking files on text", emotion, resnet, "i feel great;joy\ni am down;sadness", []string{}, []int32{1, 2}},
+		{"model taking text on files", imagenet, albert, "a.JPEG\nb.JPEG", []string{}, []int32{1, 2}},
+		{"files matching the model", imagenet, resnet, "a.JPEG\nb.png\nc.JPEG", []string{"a.JPEG", "c.JPEG"}, []int32{2}},
+	}
+
+	for _, test := range tests {
+		inputs, invalidCount, samples := ParseDataset(test.spec, test.model, test.data)
+		assert.Equal(test.expected, inputs, test.name)
+		assert.Equal(int32(len(test.invalidLines)), invalidCount, test.name)
+		lines := make([]int32, 0)
+		for _, sample := range samples {
+			assert.NotEmpty(sample.GetReason(), test.name)
+			lines = append(lines, sample.GetLine())
+		}
+		assert.Equal(test.invalidLines, lines, test.name)
+	}
+
+	// mismatched rows are reported with the model's input pattern
+	_, _, samples := ParseDataset(imagenet, albert, "a.JPEG")
+	assert.Equal("a.JPEG", samples[0].GetRow())
+	assert.Contains(samples[0].GetReason(), albert.GetName())
+}
+
+func Test_DatasetRegistry_InvalidRowSamples(t *testing.T) {
+	assert := assert.New(t)
+
+	datasets, models := NewDatasetRegistry(), NewModelRegistry()
+	rows := make([]string, 0)
+	for i := 0; i < 3*MAX_INVALID_ROW_SAMPLES; i++ {
+		rows = append(rows, fmt.Sprintf("image-%v.png", i))
+	}
+	rows = append(rows, "valid.JPEG")
+
+	// every invalid row is counted, only the first ones are kept as samples
+	inputs, invalidCount, samples := ParseDataset(datasets.Get(string(utils.Imagenet)), models.Get("resnet50"), strings.Join(rows, "\n"))
+	assert.Equal([]string{"valid.JPEG"}, inputs)
+	assert.Equal(int32(3*MAX_INVALID_ROW_SAMPLES), invalidCount)
+	assert.Len(samples, MAX_INVALID_ROW_SAMPLES)
+	for i, sample := range samples {
+		assert.Equal(int32(i+1), sample.GetLine())
+		assert.Equal(rows[i], sample.GetRow())
+	}
+}
+
+func Test_DatasetRegistry_IsCompatible(t *testing.T) {
+	assert := assert.New(t)
+
+	datasets, models := NewDatasetRegistry(), NewModelRegistry()
+	assert.True(IsCompatible(models.Get("resnet50"), datasets.Get(string(utils.Imagenet))))
+	assert.True(IsCompatible(models.Get("albert"), datasets.Get(string(utils.Emotion))))
+	assert.False(IsCompatible(models.Get("resnet50"), datasets.Get(string(utils.Emotion))))
+	assert.False(IsCompatible(models.Get("albert"), datasets.Get(string(utils.Imagenet))))
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mp4/api"
+	"mp4/logger"
+	"mp4/utils"
+	"sync"
+	"time"
+)
+
+const DISPATCH_INTERVAL = 500 * time.Millisecond // streams also look for batches periodically, i.e. once expired leases are revoked
+
+// worker address -> wake-up channel of its dispatch stream
+type Dispatcher struct {
+	streams map[string]chan struct{}
+	sync.Mutex
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		streams: make(map[string]chan struct{}),
+	}
+}
+
+func (d *Dispatcher) Register(address string) chan struct{} {
+	d.Lock()
+	defer d.Unlock()
+
+	wake := make(chan struct{}, 1)
+	d.streams[address] = wake
+	return wake
+}
+
+// Remove the stream of a worker, unless the worker already opened a new one
+func (d *Dispatcher) Unregister(address string, wake chan struct{}) {
+	d.Lock()
+	defer d.Unlock()
+
+	if d.streams[address] == wake {
+		delete(d.streams, address)
+	}
+}
+
+// Push batches to a worker right away, i.e. when it is assigned a job
+func (d *Dispatcher) Wake(address string) {
+	d.Lock()
+	defer d.Unlock()
+
+	if wake, ok := d.streams[address]; ok {
+		notify(wake)
+	}
+}
+
+// Push batches to every worker right away, i.e. when batches are available again
+func (d *Dispatcher) WakeAll() {
+	d.Lock()
+	defer d.Unlock()
+
+	for _, wake := range d.streams {
+		notify(wake)
+	}
+}
+
+// wake up a waiting goroutine without blocking, a pending wake-up is enough
+func notify(wake chan struct{}) {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+/*
+ * Hold the dispatch stream of a worker. Batches of the worker's job are pushed as soon as its pipeline has room,
+ * and outputs streamed back are submitted as with QueryData. The stream ends once this process stops coordinating,
+ * so that the worker reconnects to the new coordinator
+ *
+ * @param stream: stream opened by the worker, its first message identifies the worker
+ * @return error: reason the stream ended, nil if the worker closed it
+ */
+func (ic *IDunnoCoordinator) Dispatch(stream api.CoordinatorService_DispatchServer) error {
+	hello, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	address := hello.GetWorker().Address()
+	logger.Info(fmt.Sprintf("Worker %v opened dispatch stream", address))
+
+	wake := ic.Dispatcher.Register(address)
+	defer ic.Dispatcher.Unregister(address, wake)
+
+	// latest state of the worker, i.e. pipeline depth & resident models
+	state, stateLock := hello, sync.Mutex{}
+	sendLock := sync.Mutex{}
+	send := func(res *api.QueryDataResponse) error {
+		sendLock.Lock()
+		defer sendLock.Unlock()
+		return stream.Send(res)
+	}
+
+	received := make(chan error, 1)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				received <- err
+				return
+			}
+
+			// outputs are submitted, state messages only update what the worker takes
+			if req.GetBatchOutput() != nil {
+				req.SubmitOnly = true
+				if _, err := ic.QueryData(stream.Context(), req); err != nil {
+					logger.Error(fmt.Sprintf("Failed to submit output streamed by worker %v: %v", address, err))
+				}
+				notify(wake)
+				continue
+			}
+
+			stateLock.Lock()
+			state = req
+			stateLock.Unlock()
+			ic.RecordState(req)
+
+			if req.GetDraining() {
+				if _, err := ic.QueryData(stream.Context(), req); err != nil {
+					logger.Error(fmt.Sprintf("Failed to drain worker %v: %v", address, err))
+					continue
+				}
+				if err := send(&api.QueryDataResponse{JobId: req.GetJobId(), Drained: true}); err != nil {
+					received <- err
+					return
+				}
+			}
+			notify(wake)
+		}
+	}()
+
+	ticker := time.NewTicker(DISPATCH_INTERVAL)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-received:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-wake:
+		case <-ticker.C:
+		}
+
+		if !ic.CanSchedule() {
+			logger.Info(fmt.Sprintf("Closing dispatch stream of worker %v, no longer coordinating", address))
+			return fmt.Errorf("%v is not coordinating", ic.Ring.Address())
+		}
+
+		stateLock.Lock()
+		current := state
+		stateLock.Unlock()
+		if err := ic.PushBatches(stream.Context(), current, send); err != nil {
+			return err
+		}
+	}
+}
+
+/*
+ * Push batches of the worker's job until its pipeline is full or no batch is left
+ *
+ * @param state: latest state message of the worker
+ * @param send: send a batch on the worker's stream
+ * @return error: raise error if the stream is broken
+ */
+func (ic *IDunnoCoordinator) PushBatches(ctx context.Context, state *api.QueryDataRequest, send func(*api.QueryDataResponse) error) error {
+	// draining worker takes no new batch
+	if state.GetSubmitOnly() || state.GetDraining() {
+		return nil
+	}
+
+	// worker still runs a job it was released from, i.e. its runner turned unhealthy
+	ic.Lock()
+	worker := ic.ResourceManager.GetWorker(state.GetWorker().Address())
+	released := worker != nil && state.GetJobId() != utils.EMPTY_STRING && worker.JobId != state.GetJobId()
+	ic.Unlock()
+	if released {
+		if err := send(&api.QueryDataResponse{JobId: state.GetJobId(), Released: true}); err != nil {
+			return err
+		}
+	}
+
+	for {
+		ic.Lock()
+		jobId := utils.EMPTY_STRING
+		if worker := ic.ResourceManager.GetWorker(state.GetWorker().Address()); worker != nil && worker.Schedulable() {
+			jobId = worker.JobId
+		}
+		ic.Unlock()
+		if jobId == utils.EMPTY_STRING {
+			return nil
+		}
+
+		res, err := ic.QueryData(ctx, &api.QueryDataRequest{
+			JobId:           jobId,
+			Worker:          state.GetWorker(),
+			Resident:        state.GetResident(),
+			PipelineDepth:   state.GetPipelineDepth(),
+			RunnerUnhealthy: state.GetRunnerUnhealthy(),
+		})
+		// stream is kept, batches are pushed again on the next wake-up
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to push a batch of job %v to worker %v: %v", jobId, state.GetWorker().Address(), err))
+			return nil
+		}
+		// job is gone or the worker is released meanwhile
+		if res.GetReleased() {
+			return send(res)
+		}
+		if res.GetBatchInput() == nil {
+			return nil
+		}
+
+		res.JobId = jobId
+		if err := send(res); err != nil {
+			logger.Error(fmt.Sprintf("Failed to push batch %v of job %v to worker %v: %v", res.GetBatchInput().GetBatchId(), jobId, state.GetWorker().Address(), err))
+			return err
+		}
+	}
+}
+
+// Record the state streamed by a worker, so that idle workers report their runner's health as well
+func (ic *IDunnoCoordinator) RecordState(state *api.QueryDataRequest) {
+	ic.Lock()
+	defer ic.Unlock()
+	if worker := ic.ResourceManager.GetWorker(state.GetWorker().Address()); worker != nil {
+		if err := ic.OnWorkerState(worker, state); err != nil {
+			logger.Error(fmt.Sprintf("Failed to record state of worker %v: %v", state.GetWorker().Address(), err))
+		}
+	}
+}
+
+/*
+ * Record the state a worker reports with its queries, must hold the lock. Resident models are only a placement hint,
+ * a change of the runner's health is replicated since it releases the worker from its job
+ *
+ * @param worker: worker reporting its state
+ * @param state: query or state message of the worker
+ * @return error: raise error if the change of health is not replicated
+ */
+func (ic *IDunnoCoordinator) OnWorkerState(worker *Worker, state *api.QueryDataRequest) error {
+	worker.Resident = state.GetResident()
+	if worker.Unhealthy == state.GetRunnerUnhealthy() {
+		return nil
+	}
+
+	return ic.Commit(&api.LogEntry{
+		Type:            api.LogEntryType_WorkerStateChanged,
+		Worker:          state.GetWorker(),
+		RunnerUnhealthy: state.GetRunnerUnhealthy(),
+	})
+}
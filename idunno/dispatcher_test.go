@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// whether a wake-up is pending on the channel, consuming it
+func woken(wake chan struct{}) bool {
+	select {
+	case <-wake:
+		return true
+	default:
+		return false
+	}
+}
+
+func Test_Dispatcher_Notify(t *testing.T) {
+	assert := assert.New(t)
+
+	wake := make(chan struct{}, 1)
+	assert.False(woken(wake))
+
+	// a pending wake-up is enough, notifying again never blocks
+	notify(wake)
+	notify(wake)
+	assert.True(woken(wake))
+	assert.False(woken(wake), "wake-ups should coalesce into one")
+}
+
+func Test_Dispatcher_Wake(t *testing.T) {
+	assert := assert.New(t)
+
+	dispatcher := NewDispatcher()
+	a, b := dispatcher.Register("a:1"), dispatcher.Register("b:1")
+
+	dispatcher.Wake("a:1")
+	assert.True(woken(a))
+	assert.False(woken(b), "only the woken worker should push batches")
+
+	// worker without a stream is ignored
+	dispatcher.Wake("c:1")
+	assert.False(woken(a))
+	assert.False(woken(b))
+
+	dispatcher.WakeAll()
+	assert.True(woken(a))
+	assert.True(woken(b))
+}
+
+func Test_Dispatcher_Unregister(t *testing.T) {
+	assert := assert.New(t)
+
+	dispatcher := NewDispatcher()
+	old := dispatcher.Register("a:1")
+	current := dispatcher.Register("a:1")
+
+	// stream replaced by a reconnect ends after the new one is registered, the new one is kept
+	dispatcher.Unregister("a:1", old)
+	dispatcher.Wake("a:1")
+	assert.False(woken(old))
+	assert.True(woken(current))
+
+	dispatcher.Unregister("a:1", current)
+	dispatcher.WakeAll()
+	assert.False(woken(current))
+	assert.Empty(dispatcher.streams)
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"mp4/api"
+	"mp4/logger"
+	"mp4/utils"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/jedib0t/go-pretty/text"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const GRAPH_PREFIX = "graph" // prefix of job graph ids
+
+/*
+ * Check that a job graph can be served, must hold the lock
+ *
+ * @param graph: job graph to validate
+ * @return error: raise error if a node is invalid, an upstream node does not exist, or nodes form a cycle
+ */
+func (ic *IDunnoCoordinator) ValidateGraph(graph *api.JobGraph) error {
+	if len(graph.GetNodes()) == 0 {
+		return fmt.Errorf("job graph has no node")
+	}
+
+	nodes := make(map[string]*api.GraphNode)
+	datasets := make(map[string]utils.DatasetType) // node -> dataset of the versions it serves
+	for _, node := range graph.GetNodes() {
+		if node.GetName() == utils.EMPTY_STRING || strings.Contains(node.GetName(), " ") {
+			return fmt.Errorf("invalid node name %q", node.GetName())
+		}
+		if _, ok := nodes[node.GetName()]; ok {
+			return fmt.Errorf("duplicate node %v", node.GetName())
+		}
+		if node.GetTask().GetBatchSize() <= 0 {
+			return fmt.Errorf("node %v has invalid batch size %v", node.GetName(), node.GetTask().GetBatchSize())
+		}
+		shares, err := ic.ResolveVersions(node.GetTask())
+		if err != nil {
+			return fmt.Errorf("node %v: %v", node.GetName(), err)
+		}
+		if placement := ic.JobPlacement(node.GetTask(), shares); !ic.ResourceManager.CanPlace(placement) {
+			return fmt.Errorf("no worker runs framework %v with placement constraints %v of node %v", placement.GetFramework(), FormatPlacement(placement), node.GetName())
+		}
+		nodes[node.GetName()] = node
+		datasets[node.GetName()] = ic.TaskDataset(node.GetTask().GetModel(), shares)
+	}
+
+	for _, node := range graph.GetNodes() {
+		if node.GetUpstream() == utils.EMPTY_STRING {
+			continue
+		}
+
+		upstream, ok := nodes[node.GetUpstream()]
+		if !ok {
+			return fmt.Errorf("upstream node %v of node %v does not exist", node.GetUpstream(), node.GetName())
+		}
+
+		// upstream results are inputs of the upstream model's dataset
+		dataset, upstreamDataset := datasets[node.GetName()], datasets[upstream.GetName()]
+		if dataset != upstreamDataset {
+			return fmt.Errorf("model %v of node %v is trained on %v, but upstream node %v produces %v", node.GetTask().GetModel(), node.GetName(), dataset, upstream.GetName(), upstreamDataset)
+		}
+
+		// walk up from the node, a cycle visits a node twice
+		visited := map[string]bool{node.GetName(): true}
+		for curr := upstream; curr.GetUpstream() != utils.EMPTY_STRING; curr = nodes[curr.GetUpstream()] {
+			if visited[curr.GetName()] {
+				return fmt.Errorf("node %v is part of a cycle", node.GetName())
+			}
+			visited[curr.GetName()] = true
+		}
+	}
+
+	return nil
+}
+
+/*
+ * Store a submitted job graph and queue its root nodes, must hold the lock
+ *
+ * @param graph: job graph to store
+ * @param queueTime: time the graph is accepted
+ */
+func (ic *IDunnoCoordinator) OnGraphSubmitted(graph *api.JobGraph, queueTime *timestamppb.Timestamp) {
+	// keep the logged graph untouched, since it may be sent to a lagging standby later
+	graph = proto.Clone(graph).(*api.JobGraph)
+	ic.Graphs[graph.GetId()] = graph
+
+	for _, node := range graph.GetNodes() {
+		if node.GetUpstream() == utils.EMPTY_STRING {
+			ic.QueueGraphNode(graph, node, utils.EMPTY_STRING, queueTime)
+		}
+	}
+}
+
+// Queue the task of a graph node, using results of upstream job as dataset if given. Must hold the lock
+func (ic *IDunnoCoordinator) QueueGraphNode(graph *api.JobGraph, node *api.GraphNode, upstreamJob string, queueTime *timestamppb.Timestamp) {
+	task := proto.Clone(node.GetTask()).(*api.InferenceTask)
+	task.UpstreamJob = upstreamJob
+	task.Filter = node.GetFilter()
+	task.GraphId = graph.GetId()
+	task.Node = node.GetName()
+	task.QueueTime = queueTime
+
+	node.Status = api.GraphNodeStatus_NodeQueued
+	ic.TaskQueue.Push(task)
+}
+
+// Find the node of a job graph, nil if it does not exist. Must hold the lock
+func (ic *IDunnoCoordinator) GetGraphNode(graphId string, name string) (*api.JobGraph, *api.GraphNode) {
+	graph, ok := ic.Graphs[graphId]
+	if !ok {
+		return nil, nil
+	}
+
+	for _, node := range graph.GetNodes() {
+		if node.GetName() == name {
+			return graph, node
+		}
+	}
+	return graph, nil
+}
+
+// Record the job created for a graph node, must hold the lock
+func (ic *IDunnoCoordinator) OnGraphJobCreated(job *api.Job) {
+	if _, node := ic.GetGraphNode(job.GetGraphId(), job.GetNode()); node != nil {
+		node.JobId = job.GetId()
+		node.Status = api.GraphNodeStatus_NodeRunning
+	}
+}
+
+// Fail a graph node whose task cannot be served, must hold the lock
+func (ic *IDunnoCoordinator) OnGraphTaskDropped(task *api.InferenceTask) {
+	graph, node := ic.GetGraphNode(task.GetGraphId(), task.GetNode())
+	if node == nil {
+		return
+	}
+
+	node.Status = api.GraphNodeStatus_NodeFailed
+	ic.SkipDownstream(graph, node.GetName())
+}
+
+/*
+ * Queue downstream nodes of a finished graph job with its results as their dataset, must hold the lock
+ *
+ * @param job: job whose results are written to SDFS
+ * @param queueTime: time the job is finished
+ */
+func (ic *IDunnoCoordinator) OnGraphJobFinished(job *api.Job, queueTime *timestamppb.Timestamp) {
+	graph, node := ic.GetGraphNode(job.GetGraphId(), job.GetNode())
+	if node == nil {
+		return
+	}
+
+	// partial results of a cancelled job are not fed to downstream jobs
+	if job.GetStatus() == api.JobStatus_Cancelled {
+		node.Status = api.GraphNodeStatus_NodeFailed
+		ic.SkipDownstream(graph, node.GetName())
+		return
+	}
+
+	node.Status = api.GraphNodeStatus_NodeDone
+	for _, downstream := range graph.GetNodes() {
+		if downstream.GetUpstream() == node.GetName() {
+			ic.QueueGraphNode(graph, downstream, job.GetId(), queueTime)
+		}
+	}
+}
+
+// Skip all nodes depending on a node that did not finish, must hold the lock
+func (ic *IDunnoCoordinator) SkipDownstream(graph *api.JobGraph, name string) {
+	for _, node := range graph.GetNodes() {
+		if node.GetUpstream() == name && node.GetStatus() == api.GraphNodeStatus_NodeWaiting {
+			node.Status = api.GraphNodeStatus_NodeSkipped
+			ic.SkipDownstream(graph, node.GetName())
+		}
+	}
+}
+
+// Overall status of a job graph
+func GraphStatus(graph *api.JobGraph) string {
+	done := true
+	for _, node := range graph.GetNodes() {
+		switch node.GetStatus() {
+		case api.GraphNodeStatus_NodeWaiting, api.GraphNodeStatus_NodeQueued, api.GraphNodeStatus_NodeRunning:
+			return "Running"
+		case api.GraphNodeStatus_NodeFailed, api.GraphNodeStatus_NodeSkipped:
+			done = false
+		}
+	}
+
+	if done {
+		return "Done"
+	}
+	return "Failed"
+}
+
+/*
+ * Parse a job result file into inputs whose output contains the filter
+ *
+ * @param spec: spec of the model evaluating the kept inputs
+ * @param data: content of the result file, "input output" lines followed by the metric
+ * @param filter: keep results whose output contains the filter, all results if empty
+ * @return []string: inputs of the kept results
+ */
+func FilterJobResults(spec *api.ModelSpec, data string, filter string) []string {
+	inputs := make([]string, 0)
+
+	for _, line := range strings.Split(data, "\n") {
+		// filenames have no space, while raw inputs may have
+		sep := strings.LastIndex(line, " ")
+		if TakesFilenames(spec) {
+			sep = strings.Index(line, " ")
+		}
+		if sep == -1 {
+			continue
+		}
+
+		input, output := line[:sep], line[sep+1:]
+		if strings.Contains(output, filter) {
+			inputs = append(inputs, input)
+		}
+	}
+
+	return ValidateModelInputs(spec, inputs)
+}
+
+func (ic *IDunnoCoordinator) PrintGraphs() string {
+	ic.Lock()
+	defer ic.Unlock()
+
+	t := table.NewWriter()
+
+	t.AppendHeader(table.Row{
+		"Graph ID",
+		"Graph Status",
+		"Node",
+		"Model Type",
+		"Upstream",
+		"Filter",
+		"Node Status",
+		"Job ID",
+	})
+
+	for _, graph := range ic.Graphs {
+		status := GraphStatus(graph)
+		for _, node := range graph.GetNodes() {
+			t.AppendRow(table.Row{
+				graph.GetId(),
+				status,
+				node.GetName(),
+				node.GetTask().GetModel(),
+				node.GetUpstream(),
+				node.GetFilter(),
+				strings.TrimPrefix(node.GetStatus().String(), "Node"),
+				node.GetJobId(),
+			})
+		}
+	}
+
+	t.AppendFooter(table.Row{
+		"Total Graphs",
+		len(ic.Graphs),
+	})
+
+	t.SetAutoIndex(true)
+	t.SetStyle(table.StyleLight)
+	t.Style().Format.Header = text.FormatTitle
+	t.Style().Format.Footer = text.FormatTitle
+	t.SortBy([]table.SortBy{{Name: "Graph ID", Mode: table.Asc}})
+
+	return t.Render()
+}
+
+func (ic *IDunnoCoordinator) PrintGraphsJSON() string {
+	ic.Lock()
+	defer ic.Unlock()
+
+	graphs := make([]map[string]interface{}, 0)
+	for _, graph := range ic.Graphs {
+		nodes := make([]map[string]interface{}, 0)
+		for _, node := range graph.GetNodes() {
+			nodes = append(nodes, map[string]interface{}{
+				"name":      node.GetName(),
+				"modelType": node.GetTask().GetModel(),
+				"upstream":  node.GetUpstream(),
+				"filter":    node.GetFilter(),
+				"status":    strings.TrimPrefix(node.GetStatus().String(), "Node"),
+				"jobId":     node.GetJobId(),
+			})
+		}
+
+		graphs = append(graphs, map[string]interface{}{
+			"id":     graph.GetId(),
+			"status": GraphStatus(graph),
+			"nodes":  nodes,
+		})
+	}
+
+	marshalled, err := json.Marshal(graphs)
+	if err != nil {
+		logger.Error("Failed to marshal job graphs to JSON: " + err.Error())
+		return utils.EMPTY_STRING
+	}
+
+	return string(marshalled)
+}
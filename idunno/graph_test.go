@@ -0,0 +1,159 @@
+package main
+
+import (
+	"mp4/api"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// graph node evaluating a model in batches of 10
+func graphNode(name string, model string, upstream string) *api.GraphNode {
+	return &api.GraphNode{Name: name, Task: &api.InferenceTask{Model: model, BatchSize: 10}, Upstream: upstream}
+}
+
+func Test_Graph_ValidateGraph(t *testing.T) {
+	assert := assert.New(t)
+
+	coordinator := NewIDunnoCoordinator(nil, nil)
+	trainTime := timestamppb.New(time.Now())
+	coordinator.ModelStore.AddModel(&api.ModelSpec{Name: "albert", Version: 1, Framework: "transformers"}, "emotion", trainTime)
+	coordinator.ModelStore.AddModel(&api.ModelSpec{Name: "sentiment", Version: 1, Framework: FRAMEWORK_BOW}, "emotion", trainTime)
+	coordinator.ModelStore.AddModel(&api.ModelSpec{Name: "resnet50", Version: 1, Framework: "pytorch"}, "imagenet", trainTime)
+	process := &api.Process{Ip: "127.0.0.1", Port: 3000}
+	coordinator.ResourceManager.AddWorker(process)
+	coordinator.ResourceManager.RegisterCapabilities(process, &api.WorkerCapabilities{Runner: RUNNER_NATIVE})
+
+	tests := []struct {
+		name  string
+		nodes []*api.GraphNode
+		valid bool
+	}{
+		{"single node", []*api.GraphNode{graphNode("a", "sentiment", "")}, true},
+		{"chain", []*api.GraphNode{graphNode("a", "sentiment", ""), graphNode("b", "sentiment", "a"), graphNode("c", "sentiment", "b")}, true},
+		{"fan out", []*api.GraphNode{graphNode("a", "sentiment", ""), graphNode("b", "sentiment", "a"), graphNode("c", "sentiment", "a")}, true},
+		{"upstream listed after its node", []*api.GraphNode{graphNode("b", "sentiment", "a"), graphNode("a", "sentiment", "")}, true},
+		{"no node", []*api.GraphNode{}, false},
+		{"empty name", []*api.GraphNode{graphNode("", "sentiment", "")}, false},
+		{"name with a space", []*api.GraphNode{graphNode("a b", "sentiment", "")}, false},
+		{"duplicate node", []*api.GraphNode{graphNode("a", "sentiment", ""), graphNode("a", "sentiment", "")}, false},
+		{"invalid batch size", []*api.GraphNode{{Name: "a", Task: &api.InferenceTask{Model: "sentiment"}}}, false},
+		{"untrained model", []*api.GraphNode{graphNode("a", "bert", "")}, false},
+		{"no worker runs the framework", []*api.GraphNode{graphNode("a", "albert", "")}, false},
+		{"missing upstream", []*api.GraphNode{graphNode("a", "sentiment", ""), graphNode("b", "sentiment", "c")}, false},
+		{"self loop", []*api.GraphNode{graphNode("a", "sentiment", "a")}, false},
+		{"cycle", []*api.GraphNode{graphNode("a", "sentiment", "b"), graphNode("b", "sentiment", "a")}, false},
+		{"cycle below a root", []*api.GraphNode{graphNode("a", "sentiment", ""), graphNode("b", "sentiment", "d"), graphNode("c", "sentiment", "b"), graphNode("d", "sentiment", "c")}, false},
+	}
+
+	for _, test := range tests {
+		err := coordinator.ValidateGraph(&api.JobGraph{Id: "graph", Nodes: test.nodes})
+		if test.valid {
+			assert.Nil(err, test.name)
+		} else {
+			assert.NotNil(err, test.name)
+		}
+	}
+
+	// python runners serve every framework, downstream models must be trained on the upstream dataset
+	coordinator.ResourceManager.RegisterCapabilities(process, &api.WorkerCapabilities{Runner: RUNNER_PYTHON})
+	assert.Nil(coordinator.ValidateGraph(&api.JobGraph{Nodes: []*api.GraphNode{graphNode("a", "sentiment", ""), graphNode("b", "albert", "a")}}))
+	assert.NotNil(coordinator.ValidateGraph(&api.JobGraph{Nodes: []*api.GraphNode{graphNode("a", "resnet50", ""), graphNode("b", "albert", "a")}}))
+}
+
+func Test_Graph_FilterJobResults(t *testing.T) {
+	assert := assert.New(t)
+
+	registry := NewModelRegistry()
+	resnet, albert := registry.Get("resnet50"), registry.Get("albert")
+	images := "a.JPEG goldfish\nb.JPEG tabby cat\nc.JPEG tiger cat\nAccuracy: 0.5"
+	sentences := "I am happy;You are sad joy\nI am sad;Go away sadness\nmalformed\n\nAccuracy: 0.5"
+
+	tests := []struct {
+		name     string
+		spec     *api.ModelSpec
+		data     string
+		filter   string
+		expected []string
+	}{
+		{"every result", resnet, images, "", []string{"a.JPEG", "b.JPEG", "c.JPEG"}},
+		{"filenames end at the first space", resnet, images, "cat", []string{"b.JPEG", "c.JPEG"}},
+		{"output matching part of the filter", resnet, images, "tabby", []string{"b.JPEG"}},
+		{"no match", resnet, images, "dog", []string{}},
+		{"raw inputs end at the last space", albert, sentences, "sad", []string{"I am sad;Go away"}},
+		{"lines without output skipped", albert, sentences, "", []string{"I am happy;You are sad", "I am sad;Go away"}},
+		{"empty result file", resnet, "", "", []string{}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(test.expected, FilterJobResults(test.spec, test.data, test.filter), test.name)
+	}
+}
+
+func Test_Graph_SkipDownstream(t *testing.T) {
+	assert := assert.New(t)
+
+	coordinator := NewIDunnoCoordinator(nil, nil)
+
+	tests := []struct {
+		name     string
+		failed   string
+		statuses map[string]api.GraphNodeStatus
+		expected map[string]api.GraphNodeStatus
+	}{
+		{
+			"skip every descendant",
+			"a",
+			map[string]api.GraphNodeStatus{"a": api.GraphNodeStatus_NodeFailed},
+			map[string]api.GraphNodeStatus{
+				"a": api.GraphNodeStatus_NodeFailed, "b": api.GraphNodeStatus_NodeSkipped, "c": api.GraphNodeStatus_NodeSkipped,
+				"d": api.GraphNodeStatus_NodeSkipped, "e": api.GraphNodeStatus_NodeWaiting,
+			},
+		},
+		{
+			"siblings & ancestors untouched",
+			"b",
+			map[string]api.GraphNodeStatus{"a": api.GraphNodeStatus_NodeDone, "b": api.GraphNodeStatus_NodeFailed, "c": api.GraphNodeStatus_NodeQueued},
+			map[string]api.GraphNodeStatus{
+				"a": api.GraphNodeStatus_NodeDone, "b": api.GraphNodeStatus_NodeFailed, "c": api.GraphNodeStatus_NodeQueued,
+				"d": api.GraphNodeStatus_NodeSkipped, "e": api.GraphNodeStatus_NodeWaiting,
+			},
+		},
+		{
+			"started nodes keep their status",
+			"a",
+			map[string]api.GraphNodeStatus{"a": api.GraphNodeStatus_NodeFailed, "b": api.GraphNodeStatus_NodeRunning},
+			map[string]api.GraphNodeStatus{
+				"a": api.GraphNodeStatus_NodeFailed, "b": api.GraphNodeStatus_NodeRunning, "c": api.GraphNodeStatus_NodeSkipped,
+				"d": api.GraphNodeStatus_NodeWaiting, "e": api.GraphNodeStatus_NodeWaiting,
+			},
+		},
+		{
+			"leaf node",
+			"d",
+			map[string]api.GraphNodeStatus{"d": api.GraphNodeStatus_NodeFailed},
+			map[string]api.GraphNodeStatus{
+				"a": api.GraphNodeStatus_NodeWaiting, "b": api.GraphNodeStatus_NodeWaiting, "c": api.GraphNodeStatus_NodeWaiting,
+				"d": api.GraphNodeStatus_NodeFailed, "e": api.GraphNodeStatus_NodeWaiting,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		// a -> b -> d, a -> c, e is another root
+		graph := &api.JobGraph{Id: "graph", Nodes: []*api.GraphNode{
+			graphNode("a", "albert", ""), graphNode("b", "albert", "a"), graphNode("c", "albert", "a"),
+			graphNode("d", "albert", "b"), graphNode("e", "albert", ""),
+		}}
+		for _, node := range graph.GetNodes() {
+			node.Status = test.statuses[node.GetName()]
+		}
+
+		coordinator.SkipDownstream(graph, test.failed)
+		for _, node := range graph.GetNodes() {
+			assert.Equal(test.expected[node.GetName()], node.GetStatus(), "%v: node %v", test.name, node.GetName())
+		}
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"context"
 	"fmt"
 	"mp4/api"
+	"mp4/connpool"
+	"mp4/fault"
 	"mp4/logger"
 	"mp4/ring"
 	"mp4/sdfs"
+	"mp4/security"
 	"net"
 	"time"
 
@@ -20,7 +23,14 @@ import (
 )
 
 var ServerArgs struct {
-	Port int `arg:"-p" help:"port number" default:"5000"`
+	Port        int    `arg:"-p" help:"port number" default:"5000"`
+	Security    string `arg:"-s" help:"path to security config" default:"./security.json"`
+	ClusterSize int    `arg:"--cluster-size" help:"expected number of machines, a partition needs more than half of them to make progress (0 disables quorum)" default:"0"`
+	MemoryMb    int64  `arg:"--memory" help:"memory in MB advertised to the scheduler (0 detects it)" default:"0"`
+	Labels      string `arg:"--labels" help:"custom labels advertised to the scheduler, i.e. gpu=true,zone=a"`
+	ModelMemory int64  `arg:"--model-memory" help:"memory in MB the runner keeps models resident in (0 uses half of the advertised memory)" default:"0"`
+	Pipeline    int    `arg:"--pipeline-depth" help:"batches a worker holds at once, prefetched while earlier ones evaluate" default:"2"`
+	Runner      string `arg:"--runner" help:"model runner, python serves every framework, native serves bag-of-words models without python" default:"python"`
 }
 var IgnoredLogTypes = []string{logger.PING, logger.UPDATE}
 
@@ -30,6 +40,14 @@ func main() {
 
 	logger.Init(strconv.Itoa(port), IgnoredLogTypes)
 
+	// load mTLS credentials & cluster key
+	if err := security.Init(ServerArgs.Security); err != nil {
+		logger.Error(err.Error())
+		fmt.Println("Failed to initialize security: " + err.Error())
+		return
+	}
+	sdfs.SetTransportCredentials(security.CLIENT_CREDENTIALS)
+
 	host, _ := os.Hostname()
 
 	// create a UDP connection for failure detector
@@ -41,36 +59,69 @@ func main() {
 		logger.Error(err.Error())
 		return
 	}
-	defer conn.Close()
 	logger.Info("Process is listening on " + host + ":" + strconv.Itoa(port))
 
 	// initialize SDFS server
 	sdfsServer := sdfs.NewSDFSServer()
 	// initialize a ring failure detector with an additional SDFS related callback
 	ringServer := ring.NewRingServer(conn, host, int32(port))
+	ringServer.SetClusterSize(ServerArgs.ClusterSize)
+	fault.INJECTOR.SetSelf(ringServer.Address())
 	sdfsServer.Ring = ringServer
-	sdfsServer.ClearSDFSFiles()
+	sdfsServer.RestoreFileTable()
 	InitDataFolder(ringServer.Address())
 
+	// reuse the identity of this machine across restarts, so peers recognize a rejoin
+	nodeId, err := ring.LoadNodeId("../data/" + ringServer.Address())
+	if err != nil {
+		fmt.Println("Failed to load node id: " + err.Error())
+		return
+	}
+	ringServer.SetNodeId(nodeId)
+
 	// gRPC client initialization
 	sdfsClient := sdfs.NewSDFSClient(sdfsServer)
 	idunnoClient := NewIDunnoClient(ringServer, sdfsClient)
 	sdfsClient.EnableLogs(false)
 
+	// resources & labels advertised to the scheduler when this machine joins
+	capabilities, err := DetectCapabilities(ServerArgs.MemoryMb, ServerArgs.Labels)
+	if err != nil {
+		fmt.Println("Failed to detect capabilities: " + err.Error())
+		return
+	}
+
 	// initialize Idunno worker
-	worker := NewIDunnoWorker(host, port, ringServer, sdfsClient)
+	modelMemory := ServerArgs.ModelMemory
+	if modelMemory == 0 {
+		modelMemory = capabilities.GetMemoryMb() / 2
+	}
+	worker, err := NewIDunnoWorker(host, port, ringServer, sdfsClient, ServerArgs.Runner, modelMemory)
+	if err != nil {
+		fmt.Println("Failed to start model runner: " + err.Error())
+		return
+	}
+	capabilities.Runner = ServerArgs.Runner
+	worker.Capabilities = capabilities
+	worker.Pipeline = NewPipeline(ServerArgs.Pipeline)
 	// initialize Idunno coordinator
 	coordinator := NewIDunnoCoordinator(ringServer, sdfsClient)
 
 	ringServer.SetMemberUpdateCallback(func(process *api.Process, action ring.MemAction) {
+		// connections to a deleted or replaced process are never reused, its next incarnation is dialed afresh
+		if action == ring.MEMBER_DELETE || action == ring.MEMBER_REJOINED {
+			connpool.POOL.Evict(process.Address())
+		}
 		sdfsServer.OnMemberUpdate(action, process)
 		coordinator.OnMemberUpdate(action, process)
 	})
 
 	// initialize gRPC server
 	grpcServer := grpc.NewServer(
+		grpc.Creds(security.SERVER_CREDENTIALS),
 		grpc.MaxRecvMsgSize(sdfs.MAX_BUFFER_SIZE),
 		grpc.MaxSendMsgSize(sdfs.MAX_BUFFER_SIZE),
+		connpool.SERVER_KEEPALIVE,
 	)
 	api.RegisterSDFSServiceServer(grpcServer, sdfsServer)
 	api.RegisterCoordinatorServiceServer(grpcServer, coordinator)
@@ -82,7 +133,7 @@ func main() {
 	go coordinator.Corn()
 	go worker.Cron()
 	go sdfsServer.Ring.Listen()
-	go InitTerminal(sdfsServer, worker, sdfsClient, idunnoClient, grpcServer)
+	go InitTerminal(sdfsServer, worker, coordinator, sdfsClient, idunnoClient, grpcServer)
 
 	// serve grpc
 	lis, err := net.Listen("tcp", ":"+strconv.Itoa(port))
@@ -102,7 +153,7 @@ func main() {
  * - Parse input
  * - Call appropriate function
  */
-func InitTerminal(ss *sdfs.SDFSServer, worker *IDunnoWorker, sc *sdfs.SDFSClient, ic *IDunnoClient, g *grpc.Server) {
+func InitTerminal(ss *sdfs.SDFSServer, worker *IDunnoWorker, coordinator *IDunnoCoordinator, sc *sdfs.SDFSClient, ic *IDunnoClient, g *grpc.Server) {
 	// read input from stdin
 	reader := bufio.NewReader(os.Stdin)
 	host, _ := os.Hostname()
@@ -126,9 +177,18 @@ func InitTerminal(ss *sdfs.SDFSServer, worker *IDunnoWorker, sc *sdfs.SDFSClient
 		case "list_self", "l":
 			ss.Ring.ListSelf()
 		case "join":
+			worker.SetDraining(false)
 			ss.Ring.Join()
 		case "leave":
-			ss.Ring.Leave()
+			if err := GracefulLeave(ss, worker, coordinator); err != nil {
+				fmt.Println("Failed to leave, still in the ring: " + err.Error())
+			} else {
+				fmt.Println("Left the ring")
+			}
+		case "fault":
+			fault.INJECTOR.ExecuteCommand(args[1:])
+		case "quorum":
+			fmt.Printf("Has quorum: %v (expected cluster size: %v)\n", ss.Ring.HasQuorum(), ss.Ring.ClusterSize)
 		case "clear-log":
 			logger.Init(strconv.Itoa(int(ss.Ring.Port)), IgnoredLogTypes)
 		case "stat":
@@ -139,26 +199,16 @@ func InitTerminal(ss *sdfs.SDFSServer, worker *IDunnoWorker, sc *sdfs.SDFSClient
 			sc.ClearFiles()
 		case "stop":
 			g.Stop()
+			worker.Runner.Stop()
 			return
 		}
 
 		// debug command
 		switch args[0] {
-		case "debug:greet":
-			conn, err := grpc.Dial(worker.ModelRunner.Address(), sdfs.GRPC_OPTIONS...)
-			if err != nil {
-				logger.Error("Failed to dial worker")
-				continue
-			}
-			// Fetch sequence from leader
-			client := api.NewInferenceServiceClient(conn)
-			res, err := client.Greet(context.Background(), &api.GreetRequest{Name: args[1]})
-			if err != nil {
-				logger.Error("Failed to greet worker")
-				continue
-			}
-			fmt.Println(res.Message)
-			conn.Close()
+		case "debug:runner":
+			ctx, cancel := context.WithTimeout(context.Background(), RUNNER_HEALTH_TIMEOUT)
+			fmt.Printf("Runner %v health check: %v, last known healthy: %v\n", worker.Runner.Name(), worker.Runner.Check(ctx), worker.Runner.Healthy())
+			cancel()
 		case "debug:idunno":
 			sc.ExecuteCommand("putdir imagenet imagenet")
 			sc.ExecuteCommand("put emotion.txt emotion.txt")
@@ -185,6 +235,33 @@ func InitTerminal(ss *sdfs.SDFSServer, worker *IDunnoWorker, sc *sdfs.SDFSClient
 	}
 }
 
+/*
+ * Leave the ring without losing work or data
+ * - stop worker from taking new batches and wait for in-flight batch to be submitted
+ * - backup coordinator state to successor if current process is the coordinator
+ * - hand off stored SDFS files to their replicas among the other members, stay in the ring & serve again if it fails
+ * - announce leave to all members once every file has its replicas without current process
+ */
+func GracefulLeave(ss *sdfs.SDFSServer, worker *IDunnoWorker, coordinator *IDunnoCoordinator) error {
+	if err := worker.Drain(); err != nil {
+		logger.Error(err.Error())
+	}
+
+	if coordinator.Serving() {
+		coordinator.BackupCoordinatorData()
+	}
+
+	if err := ss.Handoff(ss.Ring.Members()); err != nil {
+		logger.Error("Failed to hand off SDFS files: " + err.Error())
+		worker.SetDraining(false)
+		return err
+	}
+	ss.Ring.Leave()
+	ss.ClearFileTable()
+
+	return nil
+}
+
 func InitDataFolder(addr string) {
 	// Create data folder if not exist
 	if _, err := os.Stat("../data"); os.IsNotExist(err) {
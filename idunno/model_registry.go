@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"mp4/api"
+	"mp4/logger"
+	"mp4/utils"
+	"regexp"
+	"strings"
+)
+
+const BUILTIN_MODEL_VERSION = 1 // version of models shipped with the inference runner
+
+// model name -> latest registered spec
+type ModelRegistry map[string]*api.ModelSpec
+
+// Registry holding the models shipped with the inference runner
+func NewModelRegistry() *ModelRegistry {
+	return &ModelRegistry{
+		string(utils.ResNet50): {
+			Name:         string(utils.ResNet50),
+			Version:      BUILTIN_MODEL_VERSION,
+			Framework:    "pytorch",
+			InputType:    api.InputType_FilenameInput,
+			InputPattern: `\.JPEG$`,
+		},
+		string(utils.Albert): {
+			Name:         string(utils.Albert),
+			Version:      BUILTIN_MODEL_VERSION,
+			Framework:    "transformers",
+			InputType:    api.InputType_RawInput,
+			InputPattern: `^[^;]*;[^;]*$`,
+		},
+	}
+}
+
+func (mr ModelRegistry) Get(name string) *api.ModelSpec {
+	return mr[name]
+}
+
+func (mr ModelRegistry) Contains(name string) bool {
+	_, ok := mr[name]
+	return ok
+}
+
+// Add a registered spec, an older version than the registered one is ignored
+func (mr ModelRegistry) Register(spec *api.ModelSpec) {
+	if curr, ok := mr[spec.GetName()]; ok && curr.GetVersion() > spec.GetVersion() {
+		return
+	}
+	mr[spec.GetName()] = spec
+}
+
+/*
+ * Check that a model spec can be registered, must hold the lock
+ *
+ * @param spec: spec to register, version 0 means the next version
+ * @return error: raise error if the name, pattern or version is invalid
+ */
+func (mr ModelRegistry) Validate(spec *api.ModelSpec) error {
+	// model names are part of job ids, "model:batch_size:time"
+	if spec.GetName() == utils.EMPTY_STRING || strings.ContainsAny(spec.GetName(), " :/") {
+		return fmt.Errorf("invalid model name %q", spec.GetName())
+	}
+	if _, err := regexp.Compile(spec.GetInputPattern()); err != nil {
+		return fmt.Errorf("invalid input pattern %q: %v", spec.GetInputPattern(), err)
+	}
+	if NATIVE_FRAMEWORKS[spec.GetFramework()] && spec.GetArtifact() == utils.EMPTY_STRING {
+		return fmt.Errorf("model %v of framework %v needs a labeled training set as its artifact", spec.GetName(), spec.GetFramework())
+	}
+	if curr, ok := mr[spec.GetName()]; ok && spec.GetVersion() != 0 && spec.GetVersion() <= curr.GetVersion() {
+		return fmt.Errorf("model %v already has version %v", spec.GetName(), curr.GetVersion())
+	}
+	return nil
+}
+
+// Whether the registry only holds built-in models
+func (mr ModelRegistry) IsDefault() bool {
+	builtins := *NewModelRegistry()
+	if len(mr) != len(builtins) {
+		return false
+	}
+	for name, spec := range mr {
+		if builtin, ok := builtins[name]; !ok || spec.GetVersion() != builtin.GetVersion() {
+			return false
+		}
+	}
+	return true
+}
+
+// Version assigned to a spec registered without one
+func (mr ModelRegistry) NextVersion(name string) int32 {
+	if curr, ok := mr[name]; ok {
+		return curr.GetVersion() + 1
+	}
+	return 1
+}
+
+// Whether inputs of the model are SDFS filenames
+func TakesFilenames(spec *api.ModelSpec) bool {
+	return spec.GetInputType() == api.InputType_FilenameInput
+}
+
+/*
+ * Keep inputs matching the input pattern of a model
+ *
+ * @param spec: spec of the model evaluating the inputs
+ * @param inputs: inputs to validate
+ * @return []string: valid inputs, nil if inputs is nil
+ */
+func ValidateModelInputs(spec *api.ModelSpec, inputs []string) []string {
+	if inputs == nil {
+		return nil
+	}
+
+	pattern, err := regexp.Compile(spec.GetInputPattern())
+	if err != nil {
+		logger.Error(fmt.Sprintf("Invalid input pattern of model %v: %v", spec.GetName(), err))
+		return make([]string, 0)
+	}
+
+	validatedInputs := make([]string, 0)
+	for _, input := range inputs {
+		if input == utils.EMPTY_STRING || !pattern.MatchString(input) {
+			continue
+		}
+		validatedInputs = append(validatedInputs, input)
+	}
+
+	return validatedInputs
+}
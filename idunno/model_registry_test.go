@@ -0,0 +1,85 @@
+package main
+
+import (
+	"mp4/api"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ModelRegistry_Validate(t *testing.T) {
+	assert := assert.New(t)
+
+	registry := NewModelRegistry()
+	registry.Register(&api.ModelSpec{Name: "sentiment", Version: 2, Framework: FRAMEWORK_BOW, Artifact: "sentiment.txt"})
+
+	tests := []struct {
+		name  string
+		spec  *api.ModelSpec
+		valid bool
+	}{
+		{"new model", &api.ModelSpec{Name: "bert", InputPattern: `^.+$`}, true},
+		{"next version", &api.ModelSpec{Name: "sentiment", Version: 3, Framework: FRAMEWORK_BOW, Artifact: "sentiment.txt"}, true},
+		{"version left to the registry", &api.ModelSpec{Name: "sentiment", Framework: FRAMEWORK_BOW, Artifact: "sentiment.txt"}, true},
+		{"new version of a built-in model", &api.ModelSpec{Name: "albert", Version: BUILTIN_MODEL_VERSION + 1}, true},
+		{"empty name", &api.ModelSpec{Name: ""}, false},
+		{"name with a colon", &api.ModelSpec{Name: "bert:large"}, false},
+		{"name with a slash", &api.ModelSpec{Name: "bert/large"}, false},
+		{"name with a space", &api.ModelSpec{Name: "bert large"}, false},
+		{"invalid pattern", &api.ModelSpec{Name: "bert", InputPattern: `(`}, false},
+		{"native model without artifact", &api.ModelSpec{Name: "spam", Framework: FRAMEWORK_BOW}, false},
+		{"registered version", &api.ModelSpec{Name: "sentiment", Version: 2, Framework: FRAMEWORK_BOW, Artifact: "sentiment.txt"}, false},
+		{"older version", &api.ModelSpec{Name: "sentiment", Version: 1, Framework: FRAMEWORK_BOW, Artifact: "sentiment.txt"}, false},
+		{"built-in version", &api.ModelSpec{Name: "resnet50", Version: BUILTIN_MODEL_VERSION}, false},
+	}
+
+	for _, test := range tests {
+		err := registry.Validate(test.spec)
+		if test.valid {
+			assert.Nil(err, test.name)
+		} else {
+			assert.NotNil(err, test.name)
+		}
+	}
+}
+
+func Test_ModelRegistry_Register(t *testing.T) {
+	assert := assert.New(t)
+
+	registry := NewModelRegistry()
+	assert.True(registry.IsDefault())
+	assert.Equal(int32(1), registry.NextVersion("sentiment"))
+
+	registry.Register(&api.ModelSpec{Name: "sentiment", Version: 2})
+	registry.Register(&api.ModelSpec{Name: "sentiment", Version: 1})
+	assert.Equal(int32(2), registry.Get("sentiment").GetVersion(), "older versions should be ignored")
+	assert.Equal(int32(3), registry.NextVersion("sentiment"))
+	assert.False(registry.IsDefault())
+}
+
+func Test_ModelRegistry_ValidateModelInputs(t *testing.T) {
+	assert := assert.New(t)
+
+	registry := NewModelRegistry()
+	resnet, albert := registry.Get("resnet50"), registry.Get("albert")
+
+	tests := []struct {
+		name     string
+		spec     *api.ModelSpec
+		inputs   []string
+		expected []string
+	}{
+		{"images", resnet, []string{"a.JPEG", "b.JPEG"}, []string{"a.JPEG", "b.JPEG"}},
+		{"non-image inputs dropped", resnet, []string{"a.JPEG", "a.png", "a.JPEG.txt", ""}, []string{"a.JPEG"}},
+		{"sentence pairs", albert, []string{"first;second", ";", "one;two;three", "no separator"}, []string{"first;second", ";"}},
+		{"no valid input", albert, []string{"", "a"}, []string{}},
+		{"no pattern keeps non-empty inputs", &api.ModelSpec{Name: "bert"}, []string{"a", "", "b"}, []string{"a", "b"}},
+		{"invalid pattern drops every input", &api.ModelSpec{Name: "bert", InputPattern: `(`}, []string{"a", "b"}, []string{}},
+		{"empty inputs", resnet, []string{}, []string{}},
+		{"nil inputs", resnet, nil, nil},
+	}
+
+	for _, test := range tests {
+		assert.Equal(test.expected, ValidateModelInputs(test.spec, test.inputs), test.name)
+	}
+}
@@ -1,18 +1,35 @@
 package main
 
 import (
+	"mp4/api"
 	"mp4/utils"
-	"strings"
+	"sort"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-type ModelStore map[utils.ModelType]utils.DatasetType
+// model -> trained versions
+type ModelStore map[utils.ModelType]*api.ModelVersions
 
 func NewModelStore() *ModelStore {
 	return &ModelStore{}
 }
 
-func (ms ModelStore) AddModel(modelType utils.ModelType, dataset utils.DatasetType) {
-	ms[modelType] = utils.DatasetType(dataset)
+// Add a trained version of a model, the first trained version is promoted
+func (ms ModelStore) AddModel(spec *api.ModelSpec, dataset utils.DatasetType, trainTime *timestamppb.Timestamp) {
+	modelType := utils.ModelType(spec.GetName())
+	if _, ok := ms[modelType]; !ok {
+		ms[modelType] = &api.ModelVersions{
+			Versions: make(map[int32]*api.TrainedModel),
+			Promoted: spec.GetVersion(),
+		}
+	}
+
+	ms[modelType].Versions[spec.GetVersion()] = &api.TrainedModel{
+		Spec:      spec,
+		Dataset:   string(dataset),
+		TrainTime: trainTime,
+	}
 }
 
 func (ms ModelStore) Contains(modelType utils.ModelType) bool {
@@ -20,61 +37,40 @@ func (ms ModelStore) Contains(modelType utils.ModelType) bool {
 	return ok
 }
 
-func (ms ModelStore) GetDataset(modelType utils.ModelType) utils.DatasetType {
-	return ms[modelType]
+func (ms ModelStore) ContainsVersion(modelType utils.ModelType, version int32) bool {
+	return ms.GetVersion(modelType, version) != nil
 }
 
-func IsValidModelType(modelType string) bool {
-	switch utils.ModelType(modelType) {
-	case utils.ResNet50, utils.Albert:
-		return true
-	default:
-		return false
+// Trained version of a model, nil if it is not trained
+func (ms ModelStore) GetVersion(modelType utils.ModelType, version int32) *api.TrainedModel {
+	if versions, ok := ms[modelType]; ok {
+		return versions.GetVersions()[version]
 	}
+	return nil
 }
 
-func IsValidDatasetType(datasetType string) bool {
-	switch utils.DatasetType(datasetType) {
-	case utils.Imagenet, utils.Emotion:
-		return true
-	default:
-		return false
-	}
+// Version of a model served by default, 0 if it is not trained
+func (ms ModelStore) Promoted(modelType utils.ModelType) int32 {
+	return ms[modelType].GetPromoted()
 }
 
-func ContainFilenames(dataset utils.DatasetType) bool {
-	switch dataset {
-	case utils.Imagenet:
-		return true
-	case utils.Emotion:
-		return false
+func (ms ModelStore) Promote(modelType utils.ModelType, version int32) {
+	if ms.ContainsVersion(modelType, version) {
+		ms[modelType].Promoted = version
 	}
-	return false
 }
 
-func ValidateDatasetInput(dataset utils.DatasetType, input string) bool {
-	switch dataset {
-	case utils.Imagenet:
-		return strings.HasSuffix(input, ".JPEG")
-	case utils.Emotion:
-		lst := strings.Split(input, ";")
-		return len(lst) == 2
-	}
-	return false
+// Dataset the promoted version of a model is trained on
+func (ms ModelStore) GetDataset(modelType utils.ModelType) utils.DatasetType {
+	return utils.DatasetType(ms.GetVersion(modelType, ms.Promoted(modelType)).GetDataset())
 }
 
-func ValidateDatasetInputs(dataset utils.DatasetType, inputs []string) []string {
-	if inputs == nil {
-		return nil
-	}
-
-	validatedInputs := make([]string, 0)
-	for _, input := range inputs {
-		if !ValidateDatasetInput(dataset, input) {
-			continue
-		}
-		validatedInputs = append(validatedInputs, input)
+// Trained versions of a model in ascending order
+func (ms ModelStore) ListVersions(modelType utils.ModelType) []int32 {
+	versions := make([]int32, 0)
+	for version := range ms[modelType].GetVersions() {
+		versions = append(versions, version)
 	}
-
-	return validatedInputs
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions
 }
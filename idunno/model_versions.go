@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"mp4/api"
+	"mp4/utils"
+	"sort"
+	"strings"
+)
+
+/*
+ * Versions of the model evaluating a task and their share of its inputs, must hold the lock
+ *
+ * @param task: inference task pinning a version, splitting inputs between versions, or neither
+ * @return []*api.VersionShare: trained versions with their percent of inputs, largest share first
+ * @return error: raise error if a version is not trained or the split does not sum to 100
+ */
+func (ic *IDunnoCoordinator) ResolveVersions(task *api.InferenceTask) ([]*api.VersionShare, error) {
+	model := utils.ModelType(task.GetModel())
+	if !ic.ModelStore.Contains(model) {
+		return nil, fmt.Errorf("model %v has not been trained", model)
+	}
+
+	if len(task.GetSplit()) == 0 {
+		version := task.GetVersion()
+		if version == 0 {
+			version = ic.ModelStore.Promoted(model)
+		}
+		if !ic.ModelStore.ContainsVersion(model, version) {
+			return nil, fmt.Errorf("version %v of model %v has not been trained", version, model)
+		}
+		return []*api.VersionShare{{Version: version, Percent: 100}}, nil
+	}
+
+	if task.GetVersion() != 0 {
+		return nil, fmt.Errorf("a task either pins a version or splits inputs between versions")
+	}
+
+	shares, seen, total := make([]*api.VersionShare, 0), make(map[int32]bool), int32(0)
+	for _, share := range task.GetSplit() {
+		if !ic.ModelStore.ContainsVersion(model, share.GetVersion()) {
+			return nil, fmt.Errorf("version %v of model %v has not been trained", share.GetVersion(), model)
+		}
+		if seen[share.GetVersion()] {
+			return nil, fmt.Errorf("version %v is split more than once", share.GetVersion())
+		}
+		if share.GetPercent() <= 0 {
+			return nil, fmt.Errorf("version %v has invalid share %v%%", share.GetVersion(), share.GetPercent())
+		}
+		seen[share.GetVersion()] = true
+		total += share.GetPercent()
+		shares = append(shares, &api.VersionShare{Version: share.GetVersion(), Percent: share.GetPercent()})
+	}
+	if total != 100 {
+		return nil, fmt.Errorf("shares of versions sum to %v%%, not 100%%", total)
+	}
+
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].GetPercent() != shares[j].GetPercent() {
+			return shares[i].GetPercent() > shares[j].GetPercent()
+		}
+		return shares[i].GetVersion() < shares[j].GetVersion()
+	})
+	return shares, nil
+}
+
+// Dataset a task is evaluated on, the one the version with the largest share is trained on. Must hold the lock
+func (ic *IDunnoCoordinator) TaskDataset(model string, shares []*api.VersionShare) utils.DatasetType {
+	return utils.DatasetType(ic.ModelStore.GetVersion(utils.ModelType(model), shares[0].GetVersion()).GetDataset())
+}
+
+// Spec of a trained version of a model, nil if it is not trained
+func (ic *IDunnoCoordinator) VersionSpec(model string, version int32) *api.ModelSpec {
+	ic.Lock()
+	defer ic.Unlock()
+	return ic.ModelStore.GetVersion(utils.ModelType(model), version).GetSpec()
+}
+
+/*
+ * Split inputs between versions, each input goes to the version furthest behind its share,
+ * so that every prefix of the inputs is split as close to the shares as possible
+ *
+ * @param shares: versions with their percent of inputs
+ * @param inputs: inputs of the job
+ * @return map[int32][]string: version -> inputs it evaluates
+ */
+func SplitInputs(shares []*api.VersionShare, inputs []string) map[int32][]string {
+	split := make(map[int32][]string)
+	for i, input := range inputs {
+		var behind *api.VersionShare
+		deficit := 0.0
+		for _, share := range shares {
+			d := float64(share.GetPercent())*float64(i+1)/100 - float64(len(split[share.GetVersion()]))
+			if behind == nil || d > deficit {
+				behind, deficit = share, d
+			}
+		}
+		split[behind.GetVersion()] = append(split[behind.GetVersion()], input)
+	}
+	return split
+}
+
+/*
+ * Pick the version each newly scheduled worker loads, must hold the lock. A worker goes to the version
+ * with the most available batches per worker, so that versions of a job finish around the same time
+ *
+ * @param schedule: job id -> workers newly allocated to the job
+ * @return map[string]int32: worker address -> version of the model to load
+ */
+func (ic *IDunnoCoordinator) AssignVersions(schedule map[string][]*Worker) map[string]int32 {
+	assigned := make(map[string]int32)
+	for jobId, workers := range schedule {
+		job := ic.Scheduler.GetJob(jobId)
+		if job == nil || len(job.GetVersions()) == 0 {
+			continue
+		}
+
+		available := job.AvailableBatches()
+		counts := make(map[int32]int)
+		for _, worker := range ic.ResourceManager.GetWorkersById(jobId) {
+			counts[worker.Version]++
+		}
+
+		for _, worker := range workers {
+			version := job.GetVersions()[0].GetVersion()
+			load := -1.0
+			for _, share := range job.GetVersions() {
+				l := float64(available[share.GetVersion()]) / float64(counts[share.GetVersion()]+1)
+				if l > load {
+					version, load = share.GetVersion(), l
+				}
+			}
+			counts[version]++
+			assigned[worker.Process.Address()] = version
+		}
+	}
+	return assigned
+}
+
+// Versions of a job for job views, i.e. "v1 90% / v2 10%"
+func FormatVersions(job *api.Job) string {
+	if len(job.GetVersions()) == 1 {
+		return fmt.Sprintf("v%v", job.GetVersions()[0].GetVersion())
+	}
+
+	versions := make([]string, 0)
+	for _, share := range job.GetVersions() {
+		versions = append(versions, fmt.Sprintf("v%v %v%%", share.GetVersion(), share.GetPercent()))
+	}
+	return strings.Join(versions, " / ")
+}
+
+// Completed and total batches of each version of a job
+func VersionProgress(job *api.Job) (map[int32]int, map[int32]int) {
+	completed, total := make(map[int32]int), make(map[int32]int)
+	for _, state := range job.GetBatchStates() {
+		total[state.GetBatchInput().GetVersion()]++
+		if state.GetStatus() == api.BatchStatus_Completed {
+			completed[state.GetBatchInput().GetVersion()]++
+		}
+	}
+	return completed, total
+}
+
+// Parse a split of inputs between versions, i.e. "1:90,2:10"
+func ParseSplit(split string) ([]*api.VersionShare, error) {
+	shares := make([]*api.VersionShare, 0)
+	for _, part := range strings.Split(split, ",") {
+		var version, percent int32
+		if _, err := fmt.Sscanf(part, "%d:%d", &version, &percent); err != nil {
+			return nil, fmt.Errorf("invalid share %q, expected version:percent", part)
+		}
+		shares = append(shares, &api.VersionShare{Version: version, Percent: percent})
+	}
+	return shares, nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"mp4/api"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func Test_ModelVersions_ParseSplit(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		split    string
+		expected []*api.VersionShare
+		valid    bool
+	}{
+		{"1:90,2:10", []*api.VersionShare{{Version: 1, Percent: 90}, {Version: 2, Percent: 10}}, true},
+		{"3:100", []*api.VersionShare{{Version: 3, Percent: 100}}, true},
+		{"1:50,2:30,3:20", []*api.VersionShare{{Version: 1, Percent: 50}, {Version: 2, Percent: 30}, {Version: 3, Percent: 20}}, true},
+		{"", nil, false},
+		{"1", nil, false},
+		{"1:90,", nil, false},
+		{"a:90", nil, false},
+		{"1;90", nil, false},
+	}
+
+	for _, test := range tests {
+		shares, err := ParseSplit(test.split)
+		if !test.valid {
+			assert.NotNil(err, test.split)
+			continue
+		}
+		assert.Nil(err, test.split)
+		assert.Equal(len(test.expected), len(shares), test.split)
+		for i := range test.expected {
+			assert.Equal(test.expected[i].GetVersion(), shares[i].GetVersion(), test.split)
+			assert.Equal(test.expected[i].GetPercent(), shares[i].GetPercent(), test.split)
+		}
+	}
+}
+
+func Test_ModelVersions_SplitInputs(t *testing.T) {
+	assert := assert.New(t)
+
+	inputs := make([]string, 100)
+	for i := range inputs {
+		inputs[i] = fmt.Sprintf("input-%v", i)
+	}
+
+	tests := []struct {
+		name     string
+		shares   []*api.VersionShare
+		inputs   []string
+		expected map[int32]int
+	}{
+		{"single version", []*api.VersionShare{{Version: 1, Percent: 100}}, inputs, map[int32]int{1: 100}},
+		{"canary", []*api.VersionShare{{Version: 1, Percent: 90}, {Version: 2, Percent: 10}}, inputs, map[int32]int{1: 90, 2: 10}},
+		{"three versions", []*api.VersionShare{{Version: 1, Percent: 50}, {Version: 2, Percent: 30}, {Version: 3, Percent: 20}}, inputs, map[int32]int{1: 50, 2: 30, 3: 20}},
+		{"uneven inputs", []*api.VersionShare{{Version: 1, Percent: 50}, {Version: 2, Percent: 50}}, inputs[:7], map[int32]int{1: 4, 2: 3}},
+		{"no input", []*api.VersionShare{{Version: 1, Percent: 100}}, nil, map[int32]int{}},
+	}
+
+	for _, test := range tests {
+		split := SplitInputs(test.shares, test.inputs)
+		counts := make(map[int32]int)
+		total := 0
+		for version, assigned := range split {
+			counts[version] = len(assigned)
+			total += len(assigned)
+		}
+		assert.Equal(test.expected, counts, test.name)
+		assert.Equal(len(test.inputs), total, "every input should go to exactly one version: "+test.name)
+	}
+
+	// every prefix is split as close to the shares as possible, a 10% version gets one of every 10 inputs
+	split := SplitInputs([]*api.VersionShare{{Version: 1, Percent: 90}, {Version: 2, Percent: 10}}, inputs[:20])
+	assert.Len(split[2], 2)
+	assert.NotEqual(split[2][0], inputs[19], "canary inputs should be spread, not packed at the end")
+	// inputs keep their order within a version
+	for version, assigned := range split {
+		for i := 1; i < len(assigned); i++ {
+			assert.Less(indexOf(inputs, assigned[i-1]), indexOf(inputs, assigned[i]), "version %v", version)
+		}
+	}
+}
+
+func indexOf(inputs []string, input string) int {
+	for i, in := range inputs {
+		if in == input {
+			return i
+		}
+	}
+	return -1
+}
+
+func Test_ModelVersions_ResolveVersions(t *testing.T) {
+	assert := assert.New(t)
+
+	coordinator := NewIDunnoCoordinator(nil, nil)
+	trainTime := timestamppb.New(time.Now())
+	for _, version := range []int32{1, 2, 3} {
+		coordinator.ModelStore.AddModel(&api.ModelSpec{Name: "albert", Version: version}, "emotion", trainTime)
+	}
+	coordinator.ModelStore.Promote("albert", 2)
+
+	tests := []struct {
+		name     string
+		task     *api.InferenceTask
+		expected []*api.VersionShare
+		valid    bool
+	}{
+		{"promoted version", &api.InferenceTask{Model: "albert"}, []*api.VersionShare{{Version: 2, Percent: 100}}, true},
+		{"pinned version", &api.InferenceTask{Model: "albert", Version: 3}, []*api.VersionShare{{Version: 3, Percent: 100}}, true},
+		{"split, largest share first", &api.InferenceTask{Model: "albert", Split: []*api.VersionShare{{Version: 3, Percent: 10}, {Version: 1, Percent: 90}}},
+			[]*api.VersionShare{{Version: 1, Percent: 90}, {Version: 3, Percent: 10}}, true},
+		{"even split, lowest version first", &api.InferenceTask{Model: "albert", Split: []*api.VersionShare{{Version: 2, Percent: 50}, {Version: 1, Percent: 50}}},
+			[]*api.VersionShare{{Version: 1, Percent: 50}, {Version: 2, Percent: 50}}, true},
+		{"untrained model", &api.InferenceTask{Model: "resnet50"}, nil, false},
+		{"untrained version", &api.InferenceTask{Model: "albert", Version: 4}, nil, false},
+		{"untrained version in split", &api.InferenceTask{Model: "albert", Split: []*api.VersionShare{{Version: 1, Percent: 50}, {Version: 4, Percent: 50}}}, nil, false},
+		{"pinned & split", &api.InferenceTask{Model: "albert", Version: 1, Split: []*api.VersionShare{{Version: 1, Percent: 100}}}, nil, false},
+		{"version split twice", &api.InferenceTask{Model: "albert", Split: []*api.VersionShare{{Version: 1, Percent: 50}, {Version: 1, Percent: 50}}}, nil, false},
+		{"empty share", &api.InferenceTask{Model: "albert", Split: []*api.VersionShare{{Version: 1, Percent: 100}, {Version: 2, Percent: 0}}}, nil, false},
+		{"shares below 100", &api.InferenceTask{Model: "albert", Split: []*api.VersionShare{{Version: 1, Percent: 50}, {Version: 2, Percent: 40}}}, nil, false},
+		{"shares above 100", &api.InferenceTask{Model: "albert", Split: []*api.VersionShare{{Version: 1, Percent: 90}, {Version: 2, Percent: 20}}}, nil, false},
+	}
+
+	for _, test := range tests {
+		shares, err := coordinator.ResolveVersions(test.task)
+		if !test.valid {
+			assert.NotNil(err, test.name)
+			continue
+		}
+		assert.Nil(err, test.name)
+		assert.Equal(len(test.expected), len(shares), test.name)
+		for i := range test.expected {
+			assert.Equal(test.expected[i].GetVersion(), shares[i].GetVersion(), test.name)
+			assert.Equal(test.expected[i].GetPercent(), shares[i].GetPercent(), test.name)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mp4/api"
+	"mp4/logger"
+	"mp4/utils"
+	"sort"
+	"time"
+
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/jedib0t/go-pretty/text"
+	"github.com/montanaflynn/stats"
+)
+
+const DEFAULT_PREDICT_SLO = 2000 * time.Millisecond // latency objective of predict requests without one
+const PREDICT_TIMEOUT = 30 * time.Second            // predict requests are failed after this, regardless of their objective
+const MAX_PREDICT_INPUTS = 32                       // inputs of a predict request, larger requests should be batch jobs
+const PREDICT_RETRY_AFTER = 2                       // seconds a client waits for a worker to load the model
+const PREDICT_IMAGE_PREFIX = "predict-"             // prefix of temporary SDFS files of predict images
+const ONLINE_RESERVED_FRACTION = 0.2                // share of workers batch jobs leave to online traffic
+const ONLINE_TRAFFIC_WINDOW = 60 * time.Second      // models without predict requests for this long release their workers
+const ONLINE_LATENCY_SAMPLES = 100                  // latencies kept per model to compute percentiles
+
+// Online traffic of a model, kept in memory of coordinator only
+type OnlineStats struct {
+	Requests      int       // predict requests received, including rejected ones
+	Rejected      int       // requests rejected as no worker serves the model
+	Failures      int       // requests failed on the worker
+	SLOViolations int       // served requests slower than their latency objective
+	Latencies     []float64 // latency in milliseconds of the most recent served requests
+	LastRequest   time.Time // time of the last predict request
+}
+
+// Record a predict request of a model, must hold the lock
+func (ic *IDunnoCoordinator) RecordOnlineRequest(model string) *OnlineStats {
+	online, ok := ic.Online[model]
+	if !ok {
+		online = &OnlineStats{Latencies: make([]float64, 0)}
+		ic.Online[model] = online
+	}
+
+	online.Requests++
+	online.LastRequest = time.Now()
+	return online
+}
+
+// Record the latency of a served predict request, must hold the lock
+func (online *OnlineStats) RecordLatency(latency time.Duration, slo time.Duration) {
+	online.Latencies = append(online.Latencies, float64(latency.Milliseconds()))
+	if len(online.Latencies) > ONLINE_LATENCY_SAMPLES {
+		online.Latencies = online.Latencies[len(online.Latencies)-ONLINE_LATENCY_SAMPLES:]
+	}
+	if latency > slo {
+		online.SLOViolations++
+	}
+}
+
+// Latency percentile in milliseconds of recent requests, 0 if none is served yet
+func (online *OnlineStats) LatencyPercentile(percent float64) float64 {
+	if len(online.Latencies) == 0 {
+		return 0
+	}
+	latency, err := stats.Percentile(online.Latencies, percent)
+	if err != nil {
+		return 0
+	}
+	return latency
+}
+
+// Models with recent predict requests, most requested first. Must hold the lock
+func (ic *IDunnoCoordinator) OnlineModels() []string {
+	models := make([]string, 0)
+	for model, online := range ic.Online {
+		if time.Since(online.LastRequest) <= ONLINE_TRAFFIC_WINDOW {
+			models = append(models, model)
+		}
+	}
+
+	sort.Slice(models, func(i, j int) bool {
+		if ic.Online[models[i]].Requests != ic.Online[models[j]].Requests {
+			return ic.Online[models[i]].Requests > ic.Online[models[j]].Requests
+		}
+		return models[i] < models[j]
+	})
+	return models
+}
+
+/*
+ * Number of workers reserved to online traffic, batch jobs always keep at least one worker
+ *
+ * @param numWorkers: number of schedulable workers
+ * @param numModels: number of models with recent predict requests
+ * @return int: number of workers batch jobs must leave to online traffic
+ */
+func OnlineReservation(numWorkers int, numModels int) int {
+	if numModels == 0 || numWorkers <= 1 {
+		return 0
+	}
+	reserved := int(math.Ceil(ONLINE_RESERVED_FRACTION * float64(numWorkers)))
+	return int(math.Min(float64(reserved), float64(numWorkers-1)))
+}
+
+/*
+ * Reserve idle workers to models with recent predict requests, and release reserved workers
+ * of models without recent traffic or with a newly promoted version, must hold the lock
+ *
+ * @return int: number of workers reserved to online traffic
+ * @return map[string][]*Worker: model -> reserved workers that have to load the model
+ */
+func (ic *IDunnoCoordinator) RefreshOnlineWorkers() (int, map[string][]*Worker) {
+	models := ic.OnlineModels()
+	reserved := OnlineReservation(ic.ResourceManager.SchedulableLen(), len(models))
+
+	// spread reserved workers over models, most requested models first
+	target := make(map[string]int)
+	for i := 0; i < reserved; i++ {
+		target[models[i%len(models)]]++
+	}
+
+	// release reserved workers beyond target
+	for _, worker := range *ic.ResourceManager {
+		if !worker.Online {
+			continue
+		}
+		promoted := ic.ModelStore.Promoted(utils.ModelType(worker.Model))
+		if worker.Schedulable() && target[worker.Model] > 0 && worker.Serves(worker.Model, promoted) {
+			target[worker.Model]--
+			continue
+		}
+		worker.Online = false
+	}
+
+	// reserve idle workers, preferring the ones that already loaded the model
+	load := make(map[string][]*Worker)
+	idleWorkers := ic.ResourceManager.GetIdleWorkers()
+	for _, model := range models {
+		promoted := ic.ModelStore.Promoted(utils.ModelType(model))
+		for ; target[model] > 0 && len(idleWorkers) > 0; target[model]-- {
+			idx := 0
+			for i, worker := range idleWorkers {
+				if worker.Serves(model, promoted) {
+					idx = i
+					break
+				}
+			}
+
+			worker := idleWorkers[idx]
+			idleWorkers = append(idleWorkers[:idx], idleWorkers[idx+1:]...)
+			worker.Online = true
+			if !worker.Serves(model, promoted) {
+				load[model] = append(load[model], worker)
+			}
+		}
+	}
+
+	return reserved, load
+}
+
+// Load promoted version of models on reserved workers, so that they serve predict requests
+func (ic *IDunnoCoordinator) LoadOnlineWorkers(load map[string][]*Worker) {
+	for model, workers := range load {
+		for _, worker := range workers {
+			logger.Info(fmt.Sprintf("Reserving worker %v to online requests of model %v", worker.Process.Address(), model))
+
+			go func(model string, worker *Worker) {
+				client, err := ic.CreateWorkerClient(worker.Process)
+				if err != nil {
+					return
+				}
+
+				ic.Lock()
+				version := ic.ModelStore.Promoted(utils.ModelType(model))
+				ic.Unlock()
+
+				// model is served apart from jobs, batches the worker still holds are kept
+				res, err := client.ServeModel(context.Background(), &api.ServeModelRequest{
+					Model: model,
+					Spec:  ic.VersionSpec(model, version),
+				})
+				if err != nil || res.GetStatus() != api.ResponseStatus_OK {
+					logger.Error(fmt.Sprintf("Failed to load model %v on worker %v", model, worker.Process.Address()))
+					return
+				}
+
+				ic.Lock()
+				worker.Model = model
+				worker.Version = version
+				ic.Unlock()
+			}(model, worker)
+		}
+	}
+}
+
+/*
+ * Pick a worker serving the model for a predict request, must hold the lock. Reserved workers are
+ * preferred over other workers that loaded the model, then the ones with less requests in flight
+ *
+ * @param model: model of the predict request
+ * @param version: version of the model serving the request
+ * @return *Worker: worker to forward the request to, nil if no worker serves the version
+ */
+func (ic *IDunnoCoordinator) PickPredictWorker(model string, version int32) *Worker {
+	var picked *Worker
+	for _, worker := range *ic.ResourceManager {
+		if !worker.Schedulable() || !worker.Serves(model, version) {
+			continue
+		}
+		if picked == nil || (worker.Online && !picked.Online) ||
+			(worker.Online == picked.Online && worker.Predicting < picked.Predicting) {
+			picked = worker
+		}
+	}
+	return picked
+}
+
+// Store predict images as temporary SDFS files, return their SDFS filenames
+func (ic *IDunnoCoordinator) PutPredictImages(images [][]byte) ([]string, error) {
+	filenames := make([]string, 0)
+	for i, image := range images {
+		filename := fmt.Sprintf("%v%v-%v.JPEG", PREDICT_IMAGE_PREFIX, time.Now().UnixNano(), i)
+		if err := ic.SDFSClient.WriteLocalFile(filename, image); err != nil {
+			return filenames, err
+		}
+
+		err := ic.SDFSClient.Put(filename, filename)
+		ic.SDFSClient.DeleteLocalFile(filename)
+		if err != nil {
+			return filenames, err
+		}
+		filenames = append(filenames, filename)
+	}
+	return filenames, nil
+}
+
+// Delete temporary SDFS files of predict images
+func (ic *IDunnoCoordinator) DeletePredictImages(filenames []string) {
+	for _, filename := range filenames {
+		if err := ic.SDFSClient.Delete(filename); err != nil {
+			logger.Error("Failed to delete predict image " + filename + ": " + err.Error())
+		}
+	}
+}
+
+func (ic *IDunnoCoordinator) PrintOnline() string {
+	ic.Lock()
+	defer ic.Unlock()
+
+	t := table.NewWriter()
+
+	t.AppendHeader(table.Row{
+		"Model Type",
+		"Requests",
+		"Rejected",
+		"Failures",
+		"SLO Violations",
+		"P50 Latency",
+		"P95 Latency",
+		"Reserved Workers",
+		"Last Request",
+	})
+
+	reservedCount := 0
+	for model, online := range ic.Online {
+		reserved := 0
+		for _, worker := range *ic.ResourceManager {
+			if worker.Online && worker.Model == model {
+				reserved++
+			}
+		}
+		reservedCount += reserved
+
+		t.AppendRow(table.Row{
+			model,
+			online.Requests,
+			online.Rejected,
+			online.Failures,
+			online.SLOViolations,
+			fmt.Sprintf("%.0f ms", online.LatencyPercentile(50)),
+			fmt.Sprintf("%.0f ms", online.LatencyPercentile(95)),
+			reserved,
+			fmt.Sprintf("%.2f sec ago", time.Since(online.LastRequest).Seconds()),
+		})
+	}
+
+	t.AppendFooter(table.Row{
+		"Total Reserved Workers",
+		reservedCount,
+	})
+
+	t.SetAutoIndex(true)
+	t.SetStyle(table.StyleLight)
+	t.Style().Format.Header = text.FormatTitle
+	t.Style().Format.Footer = text.FormatTitle
+	t.SortBy([]table.SortBy{{Name: "Model Type", Mode: table.Asc}})
+
+	return t.Render()
+}
+
+func (ic *IDunnoCoordinator) PrintOnlineJSON() string {
+	ic.Lock()
+	defer ic.Unlock()
+
+	models := make([]map[string]interface{}, 0)
+	for model, online := range ic.Online {
+		reserved := 0
+		for _, worker := range *ic.ResourceManager {
+			if worker.Online && worker.Model == model {
+				reserved++
+			}
+		}
+
+		models = append(models, map[string]interface{}{
+			"modelType":       model,
+			"requests":        online.Requests,
+			"rejected":        online.Rejected,
+			"failures":        online.Failures,
+			"sloViolations":   online.SLOViolations,
+			"p50Latency":      online.LatencyPercentile(50),
+			"p95Latency":      online.LatencyPercentile(95),
+			"reservedWorkers": reserved,
+			"lastRequest":     online.LastRequest.Unix(),
+		})
+	}
+
+	marshalled, err := json.Marshal(models)
+	if err != nil {
+		logger.Error("Failed to marshal online traffic to JSON: " + err.Error())
+		return utils.EMPTY_STRING
+	}
+
+	return string(marshalled)
+}
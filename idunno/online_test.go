@@ -0,0 +1,116 @@
+package main
+
+import (
+	"mp4/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func Test_Online_OnlineReservation(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		name       string
+		numWorkers int
+		numModels  int
+		expected   int
+	}{
+		{"no online traffic", 10, 0, 0},
+		{"single worker is left to batch jobs", 1, 1, 0},
+		{"batch jobs keep a worker", 2, 1, 1},
+		{"rounded up", 3, 1, 1},
+		{"fraction of workers", 10, 1, 2},
+		{"fraction of workers rounded up", 11, 3, 3},
+	}
+
+	for _, test := range tests {
+		assert.Equal(test.expected, OnlineReservation(test.numWorkers, test.numModels), test.name)
+	}
+}
+
+// Reserved workers follow online traffic, workers that already loaded a model are reserved to it first
+func Test_Online_RefreshOnlineWorkers(t *testing.T) {
+	assert := assert.New(t)
+
+	coordinator := newStandaloneCoordinator()
+	processes := addWorkers(coordinator, 10)
+	coordinator.ModelStore.AddModel(coordinator.Models.Get("albert"), "emotion", timestamppb.New(time.Now()))
+	promoted := coordinator.ModelStore.Promoted(utils.ModelType("albert"))
+	loaded := coordinator.ResourceManager.GetWorker(processes[5].Address())
+	loaded.Model, loaded.Version = "albert", promoted
+	busy := coordinator.ResourceManager.GetWorker(processes[6].Address())
+	busy.Model, busy.Version, busy.JobId = "albert", promoted, "job"
+	online := func() []*Worker {
+		workers := make([]*Worker, 0)
+		for _, worker := range *coordinator.ResourceManager {
+			if worker.Online {
+				workers = append(workers, worker)
+			}
+		}
+		return workers
+	}
+
+	reserved, load := coordinator.RefreshOnlineWorkers()
+	assert.Zero(reserved, "no online traffic")
+	assert.Empty(load)
+
+	coordinator.Online["albert"] = &OnlineStats{Requests: 10, LastRequest: time.Now()}
+	reserved, load = coordinator.RefreshOnlineWorkers()
+	assert.Equal(2, reserved)
+	assert.Len(online(), 2)
+	assert.True(loaded.Online, "worker that loaded the model should be reserved first")
+	assert.False(busy.Online, "worker of a batch job should not be reserved")
+	if assert.Len(load["albert"], 1) {
+		assert.NotSame(loaded, load["albert"][0], "reserved worker serving the model needs no reload")
+	}
+
+	// reservation is spread over models, workers that did not load their model yet are released first
+	coordinator.Online["resnet50"] = &OnlineStats{Requests: 5, LastRequest: time.Now()}
+	reserved, load = coordinator.RefreshOnlineWorkers()
+	assert.Equal(2, reserved)
+	assert.Len(online(), 2)
+	assert.True(loaded.Online)
+	assert.Len(load["resnet50"], 1)
+	assert.Empty(load["albert"])
+
+	// workers are released once traffic stops
+	for _, stats := range coordinator.Online {
+		stats.LastRequest = time.Now().Add(-2 * ONLINE_TRAFFIC_WINDOW)
+	}
+	reserved, load = coordinator.RefreshOnlineWorkers()
+	assert.Zero(reserved)
+	assert.Empty(load)
+	assert.Empty(online())
+}
+
+func Test_Online_PickPredictWorker(t *testing.T) {
+	assert := assert.New(t)
+
+	coordinator := newStandaloneCoordinator()
+	processes := addWorkers(coordinator, 4)
+	workers := make([]*Worker, 0)
+	for _, process := range processes {
+		worker := coordinator.ResourceManager.GetWorker(process.Address())
+		worker.Model, worker.Version = "albert", 1
+		workers = append(workers, worker)
+	}
+	workers[0].Predicting = 3
+	workers[1].Predicting = 1
+	workers[2].Predicting = 2
+	workers[3].Draining = true
+
+	assert.Nil(coordinator.PickPredictWorker("resnet50", 1), "no worker serves the model")
+	assert.Nil(coordinator.PickPredictWorker("albert", 2), "no worker serves the version")
+	assert.Same(workers[1], coordinator.PickPredictWorker("albert", 1), "least busy worker should be picked")
+
+	workers[0].Online = true
+	assert.Same(workers[0], coordinator.PickPredictWorker("albert", 1), "reserved worker should be preferred")
+
+	workers[3].Draining, workers[3].Online = false, true
+	assert.Same(workers[3], coordinator.PickPredictWorker("albert", 1), "least busy reserved worker should be picked")
+	workers[3].Draining = true
+	assert.Same(workers[0], coordinator.PickPredictWorker("albert", 1), "draining worker should not be picked")
+}
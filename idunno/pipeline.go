@@ -0,0 +1,53 @@
+package main
+
+import "mp4/api"
+
+// Batch leased from the coordinator, travelling through a worker's pipeline
+type LeasedBatch struct {
+	JobId      string
+	Generation int64 // job assignment of the worker the batch is leased under
+	BatchInput *api.BatchInput
+	Lease      *api.BatchLease
+	IsFilename bool
+	Inputs     []string         // model inputs, local paths of prefetched SDFS files if inputs are filenames
+	Output     *api.BatchOutput // nil until evaluated, or if evaluation failed
+	Failed     bool             // runner failed to evaluate the batch, its lease is released rather than left to expire
+}
+
+/*
+ * Batches a worker holds at once: while one batch is evaluated, the next ones are leased with their
+ * inputs prefetched, and outputs of evaluated batches are submitted without stalling the runner
+ */
+type Pipeline struct {
+	Depth     int
+	slots     chan struct{} // a batch takes a slot from leasing until its output is submitted
+	Leased    chan *LeasedBatch
+	Evaluated chan *LeasedBatch
+}
+
+func NewPipeline(depth int) *Pipeline {
+	if depth < 1 {
+		depth = 1
+	}
+
+	return &Pipeline{
+		Depth:     depth,
+		slots:     make(chan struct{}, depth),
+		Leased:    make(chan *LeasedBatch, depth),
+		Evaluated: make(chan *LeasedBatch, depth),
+	}
+}
+
+// Wait for a free slot to lease a batch into
+func (p *Pipeline) Acquire() {
+	p.slots <- struct{}{}
+}
+
+func (p *Pipeline) Release() {
+	<-p.slots
+}
+
+// Number of slots taken, including one being leased into
+func (p *Pipeline) InFlight() int {
+	return len(p.slots)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Pipeline_InFlight(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(1, NewPipeline(0).Depth, "pipeline holds at least one batch")
+
+	pipeline := NewPipeline(2)
+	assert.Zero(pipeline.InFlight())
+	pipeline.Acquire()
+	pipeline.Acquire()
+	assert.Equal(2, pipeline.InFlight())
+
+	// full pipeline leases the next batch only once a batch is submitted
+	acquired := make(chan struct{})
+	go func() {
+		pipeline.Acquire()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("batch should not be leased beyond the pipeline depth")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	pipeline.Release()
+	<-acquired
+	assert.Equal(2, pipeline.InFlight())
+	pipeline.Release()
+	pipeline.Release()
+	assert.Zero(pipeline.InFlight())
+}
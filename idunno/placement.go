@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"mp4/api"
+	"mp4/utils"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const MEMINFO_FILE = "/proc/meminfo" // source of the memory advertised by workers
+const MAX_LABELS = 16                // labels a worker may register
+const MAX_LABEL_LENGTH = 64          // length of a label key or value
+
+/*
+ * Capabilities of this machine advertised when it joins the ring
+ *
+ * @param memoryMb: memory to advertise, detected from the machine if 0
+ * @param labels: custom labels, i.e. "gpu=true,zone=a"
+ * @return *api.WorkerCapabilities: resources & labels of the machine, without loaded models
+ * @return error: raise error if labels are malformed
+ */
+func DetectCapabilities(memoryMb int64, labels string) (*api.WorkerCapabilities, error) {
+	parsed, err := ParseLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+	if memoryMb == 0 {
+		memoryMb = DetectMemory()
+	}
+
+	return &api.WorkerCapabilities{
+		MemoryMb: memoryMb,
+		Cpus:     int32(runtime.NumCPU()),
+		Models:   make([]string, 0),
+		Labels:   parsed,
+	}, nil
+}
+
+// Total memory of the machine in MB, 0 if it cannot be detected
+func DetectMemory() int64 {
+	file, err := os.Open(MEMINFO_FILE)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	// i.e. "MemTotal:       16318480 kB"
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemTotal:" {
+			continue
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return 0
+		}
+		return kb / 1024
+	}
+	return 0
+}
+
+// Parse labels, i.e. "gpu=true,zone=a", into a map
+func ParseLabels(labels string) (map[string]string, error) {
+	parsed := make(map[string]string)
+	if labels == utils.EMPTY_STRING {
+		return parsed, nil
+	}
+
+	for _, label := range strings.Split(labels, ",") {
+		kv := strings.SplitN(label, "=", 2)
+		if len(kv) != 2 || kv[0] == utils.EMPTY_STRING {
+			return nil, fmt.Errorf("invalid label %q, expected key=value", label)
+		}
+		parsed[kv[0]] = kv[1]
+	}
+	if err := ValidateLabels(parsed); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+// Labels are capped in number & length, they are held by every coordinator for every worker
+func ValidateLabels(labels map[string]string) error {
+	if len(labels) > MAX_LABELS {
+		return fmt.Errorf("%v labels, at most %v are allowed", len(labels), MAX_LABELS)
+	}
+	for key, value := range labels {
+		if len(key) > MAX_LABEL_LENGTH || len(value) > MAX_LABEL_LENGTH {
+			return fmt.Errorf("label %q is longer than %v characters", key+"="+value, MAX_LABEL_LENGTH)
+		}
+	}
+	return nil
+}
+
+// Labels in key order, i.e. "gpu=true,zone=a"
+func FormatLabels(labels map[string]string) string {
+	formatted := make([]string, 0)
+	for key, value := range labels {
+		formatted = append(formatted, key+"="+value)
+	}
+	sort.Strings(formatted)
+	return strings.Join(formatted, ",")
+}
+
+// Capabilities of a worker for worker views, i.e. "4 CPU, 7982 MB, native runner, gpu=true"
+func FormatCapabilities(capabilities *api.WorkerCapabilities) string {
+	if capabilities == nil {
+		return "-"
+	}
+
+	formatted := fmt.Sprintf("%v CPU, %v MB", capabilities.GetCpus(), capabilities.GetMemoryMb())
+	if capabilities.GetRunner() != utils.EMPTY_STRING {
+		formatted += fmt.Sprintf(", %v runner", capabilities.GetRunner())
+	}
+	if len(capabilities.GetLabels()) > 0 {
+		formatted += ", " + FormatLabels(capabilities.GetLabels())
+	}
+	return formatted
+}
+
+// Whether a worker satisfies the placement constraints of a job, any worker satisfies unset constraints
+func Satisfies(capabilities *api.WorkerCapabilities, placement *api.PlacementConstraints) bool {
+	if placement == nil {
+		return true
+	}
+	if capabilities.GetMemoryMb() < placement.GetMinMemoryMb() || capabilities.GetCpus() < placement.GetMinCpus() {
+		return false
+	}
+	if !RunnerServes(capabilities.GetRunner(), placement.GetFramework()) {
+		return false
+	}
+	for key, value := range placement.GetLabels() {
+		if label, ok := capabilities.GetLabels()[key]; !ok || label != value {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+ * Placement constraints of a job, workers must run the framework of its model on top of the task's constraints.
+ * Must hold the lock
+ *
+ * @param task: inference task, with or without constraints
+ * @param shares: trained versions the job serves
+ * @return *api.PlacementConstraints: constraints of the task with the framework a version needs a python runner for,
+ *     or the framework of the largest version if every version is native
+ */
+func (ic *IDunnoCoordinator) JobPlacement(task *api.InferenceTask, shares []*api.VersionShare) *api.PlacementConstraints {
+	placement := &api.PlacementConstraints{}
+	if task.GetPlacement() != nil {
+		placement = proto.Clone(task.GetPlacement()).(*api.PlacementConstraints)
+	}
+
+	for i, share := range shares {
+		framework := ic.ModelStore.GetVersion(utils.ModelType(task.GetModel()), share.GetVersion()).GetSpec().GetFramework()
+		if i == 0 || !NATIVE_FRAMEWORKS[framework] {
+			placement.Framework = framework
+		}
+		if !NATIVE_FRAMEWORKS[framework] {
+			break
+		}
+	}
+	return placement
+}
+
+// Whether a job has constraints on its workers
+func IsConstrained(placement *api.PlacementConstraints) bool {
+	return placement.GetMinMemoryMb() > 0 || placement.GetMinCpus() > 0 || len(placement.GetLabels()) > 0
+}
+
+// Whether any schedulable worker satisfies the placement constraints
+func (rm ResourceManager) CanPlace(placement *api.PlacementConstraints) bool {
+	for _, worker := range rm {
+		if worker.Schedulable() && Satisfies(worker.Capabilities, placement) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * How well a worker suits a job, workers whose runner keeps a version the job serves resident save the runner
+ * from loading a model, and workers keeping another version of the model save fetching its dependencies
+ *
+ * @param worker: idle worker satisfying the job's constraints
+ * @param job: job to place
+ * @return int: 2 if a version of the job is resident, 1 if another version of its model is resident, 0 otherwise
+ */
+func PlacementScore(worker *Worker, job *api.Job) int {
+	if !worker.HasModel(job.GetModelType()) {
+		return 0
+	}
+	for _, share := range job.GetVersions() {
+		if worker.IsResident(job.GetModelType(), share.GetVersion()) {
+			return 2
+		}
+	}
+	return 1
+}
+
+/*
+ * Pick the idle worker to place a job on
+ *
+ * @param job: job to place
+ * @param workers: idle workers
+ * @return int: index of the satisfying worker with the highest placement score, -1 if none satisfies the job
+ */
+func PickWorker(job *api.Job, workers []*Worker) int {
+	picked, score := -1, -1
+	for i, worker := range workers {
+		if !Satisfies(worker.Capabilities, job.GetPlacement()) {
+			continue
+		}
+		if s := PlacementScore(worker, job); s > score {
+			picked, score = i, s
+		}
+	}
+	return picked
+}
+
+// Order jobs so that constrained jobs with the fewest satisfying workers are placed first
+func SortByPlacement(jobs []*api.Job, workers []*Worker) []*api.Job {
+	satisfying := make(map[string]int)
+	for _, job := range jobs {
+		for _, worker := range workers {
+			if Satisfies(worker.Capabilities, job.GetPlacement()) {
+				satisfying[job.GetId()]++
+			}
+		}
+	}
+
+	sorted := append(make([]*api.Job, 0), jobs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ci, cj := IsConstrained(sorted[i].GetPlacement()), IsConstrained(sorted[j].GetPlacement())
+		if ci != cj {
+			return ci
+		}
+		return ci && satisfying[sorted[i].GetId()] < satisfying[sorted[j].GetId()]
+	})
+	return sorted
+}
+
+// Set a placement constraint from a serve option, i.e. --require gpu=true
+func ParsePlacementOption(placement *api.PlacementConstraints, option string, value string) error {
+	switch option {
+	case "--require":
+		labels, err := ParseLabels(value)
+		if err != nil {
+			return err
+		}
+		placement.Labels = labels
+	case "--min-memory":
+		memoryMb, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || memoryMb < 0 {
+			return fmt.Errorf("min-memory must be a non-negative number of MB")
+		}
+		placement.MinMemoryMb = memoryMb
+	case "--min-cpus":
+		cpus, err := strconv.Atoi(value)
+		if err != nil || cpus < 0 {
+			return fmt.Errorf("min-cpus must be a non-negative integer")
+		}
+		placement.MinCpus = int32(cpus)
+	default:
+		return fmt.Errorf("unknown placement option %s", option)
+	}
+	return nil
+}
+
+// Models resident on a worker for worker views, most recently used first, i.e. "albert v2 (412 MB), resnet50 v1 (98 MB)"
+func FormatResident(resident []*api.ResidentModel) string {
+	if len(resident) == 0 {
+		return "-"
+	}
+
+	formatted := make([]string, 0)
+	for i := len(resident) - 1; i >= 0; i-- {
+		formatted = append(formatted, fmt.Sprintf("%v v%v (%v MB)", resident[i].GetModel(), resident[i].GetVersion(), resident[i].GetMemoryMb()))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// Placement constraints of a job for job views, i.e. "2 CPU, 4096 MB, gpu=true"
+func FormatPlacement(placement *api.PlacementConstraints) string {
+	if !IsConstrained(placement) {
+		return "-"
+	}
+
+	constraints := make([]string, 0)
+	if placement.GetMinCpus() > 0 {
+		constraints = append(constraints, fmt.Sprintf("%v CPU", placement.GetMinCpus()))
+	}
+	if placement.GetMinMemoryMb() > 0 {
+		constraints = append(constraints, fmt.Sprintf("%v MB", placement.GetMinMemoryMb()))
+	}
+	if len(placement.GetLabels()) > 0 {
+		constraints = append(constraints, FormatLabels(placement.GetLabels()))
+	}
+	return strings.Join(constraints, ", ")
+}
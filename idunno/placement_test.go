@@ -0,0 +1,99 @@
+package main
+
+import (
+	"mp4/api"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// idle worker with the given capabilities, serving a version of a model if any
+func newPlacementWorker(capabilities *api.WorkerCapabilities, model string, version int32) *Worker {
+	return &Worker{Process: &api.Process{Ip: "127.0.0.1", Port: 8000}, Capabilities: capabilities, Model: model, Version: version}
+}
+
+func Test_Placement_PickWorker(t *testing.T) {
+	assert := assert.New(t)
+
+	python := &api.WorkerCapabilities{Runner: RUNNER_PYTHON, MemoryMb: 4096, Cpus: 4}
+	native := &api.WorkerCapabilities{Runner: RUNNER_NATIVE, MemoryMb: 4096, Cpus: 4}
+	gpu := &api.WorkerCapabilities{Runner: RUNNER_PYTHON, MemoryMb: 4096, Cpus: 4, Labels: map[string]string{"gpu": "true"}}
+	noGpu := &api.WorkerCapabilities{Runner: RUNNER_PYTHON, MemoryMb: 4096, Cpus: 4, Labels: map[string]string{"gpu": "false"}}
+	job := func(placement *api.PlacementConstraints) *api.Job {
+		return &api.Job{Id: "job", ModelType: "albert", Versions: []*api.VersionShare{{Version: 2, Percent: 100}}, Placement: placement}
+	}
+	resident := newPlacementWorker(python, "resnet50", 1)
+	resident.Resident = []*api.ResidentModel{{Model: "albert", Version: 2}}
+
+	tests := []struct {
+		name     string
+		job      *api.Job
+		workers  []*Worker
+		expected int
+	}{
+		{"no constraint", job(nil), []*Worker{newPlacementWorker(python, "", 0), newPlacementWorker(native, "", 0)}, 0},
+		{"not enough memory", job(&api.PlacementConstraints{MinMemoryMb: 8192}), []*Worker{newPlacementWorker(python, "", 0)}, -1},
+		{"not enough cpus", job(&api.PlacementConstraints{MinCpus: 8}), []*Worker{newPlacementWorker(python, "", 0)}, -1},
+		{"missing label", job(&api.PlacementConstraints{Labels: map[string]string{"gpu": "true"}}), []*Worker{newPlacementWorker(python, "", 0), newPlacementWorker(noGpu, "", 0), newPlacementWorker(gpu, "", 0)}, 2},
+		{"native runner cannot serve the framework", job(&api.PlacementConstraints{Framework: "transformers"}), []*Worker{newPlacementWorker(native, "", 0), newPlacementWorker(python, "", 0)}, 1},
+		{"native runner serves a native framework", job(&api.PlacementConstraints{Framework: FRAMEWORK_BOW}), []*Worker{newPlacementWorker(native, "", 0)}, 0},
+		{"loaded model does not lift a constraint", job(&api.PlacementConstraints{MinMemoryMb: 8192}), []*Worker{newPlacementWorker(python, "albert", 2), newPlacementWorker(&api.WorkerCapabilities{MemoryMb: 8192}, "", 0)}, 1},
+		{"worker serving the version is preferred", job(nil), []*Worker{newPlacementWorker(python, "", 0), newPlacementWorker(python, "albert", 1), newPlacementWorker(python, "albert", 2)}, 2},
+		{"resident version is preferred", job(nil), []*Worker{newPlacementWorker(python, "albert", 1), resident}, 1},
+		{"another version of the model is preferred", job(nil), []*Worker{newPlacementWorker(python, "resnet50", 1), newPlacementWorker(python, "albert", 1)}, 1},
+	}
+
+	for _, test := range tests {
+		assert.Equal(test.expected, PickWorker(test.job, test.workers), test.name)
+	}
+}
+
+func Test_Placement_SortByPlacement(t *testing.T) {
+	assert := assert.New(t)
+
+	workers := []*Worker{
+		newPlacementWorker(&api.WorkerCapabilities{MemoryMb: 2048}, "", 0),
+		newPlacementWorker(&api.WorkerCapabilities{MemoryMb: 8192}, "", 0),
+		newPlacementWorker(&api.WorkerCapabilities{MemoryMb: 8192, Labels: map[string]string{"gpu": "true"}}, "", 0),
+	}
+	jobs := []*api.Job{
+		{Id: "any"},
+		{Id: "memory", Placement: &api.PlacementConstraints{MinMemoryMb: 4096}},
+		{Id: "framework", Placement: &api.PlacementConstraints{Framework: "transformers"}},
+		{Id: "gpu", Placement: &api.PlacementConstraints{Labels: map[string]string{"gpu": "true"}}},
+	}
+
+	ids := make([]string, 0)
+	for _, job := range SortByPlacement(jobs, workers) {
+		ids = append(ids, job.GetId())
+	}
+	assert.Equal([]string{"gpu", "memory", "any", "framework"}, ids, "constrained jobs with fewest satisfying workers should be placed first")
+}
+
+// Constrained jobs only get satisfying workers, and jobs get back the workers that loaded their model
+func Test_Placement_RefreshSchedule(t *testing.T) {
+	assert := assert.New(t)
+
+	coordinator := newStandaloneCoordinator()
+	processes := addWorkers(coordinator, 4)
+	gpu := coordinator.ResourceManager.GetWorker(processes[0].Address())
+	gpu.Capabilities.Labels = map[string]string{"gpu": "true"}
+	loaded := coordinator.ResourceManager.GetWorker(processes[3].Address())
+	loaded.Model, loaded.Version = "albert", 1
+
+	constrained := newBatchJob("constrained", 10, 0)
+	constrained.ModelType, constrained.Placement = "resnet50", &api.PlacementConstraints{Labels: map[string]string{"gpu": "true"}}
+	coordinator.Scheduler.AddJob(constrained)
+	job := newBatchJob("job", 10, 0)
+	job.ModelType, job.Versions = "albert", []*api.VersionShare{{Version: 1, Percent: 100}}
+	coordinator.Scheduler.AddJob(job)
+
+	schedule := coordinator.Scheduler.RefreshSchedule(0)
+	if assert.Len(schedule["constrained"], 1) {
+		assert.Same(gpu, schedule["constrained"][0], "only the labelled worker satisfies the job")
+	}
+	if assert.Len(schedule["job"], 2) {
+		assert.Same(loaded, schedule["job"][0], "worker that loaded the model should be picked first")
+	}
+	assert.NotContains(schedule["job"], gpu)
+}
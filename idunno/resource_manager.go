@@ -10,22 +10,96 @@ import (
 )
 
 type Worker struct {
-	JobId          string
-	CurrBatchInput *api.BatchInput
-	LastQueryTime  *timestamppb.Timestamp
-	Process        *api.Process
+	JobId         string
+	BatchInputs   []*api.BatchInput // batches leased by the worker, in the order they were handed out
+	LastQueryTime *timestamppb.Timestamp
+	Process       *api.Process
+	Draining      bool                    // worker is leaving, never schedule it again
+	Model         string                  // model loaded by the worker, kept after its job finishes
+	Version       int32                   // version of the loaded model
+	Online        bool                    // worker is reserved to predict requests of its model, never given a batch job
+	Predicting    int                     // predict requests in flight on the worker
+	Capabilities  *api.WorkerCapabilities // resources & labels registered once the worker joined, nil until then
+	Resident      []*api.ResidentModel    // models kept resident by the worker's runner, reported with each query
+	Unhealthy     bool                    // worker's runner fails health checks, never scheduled until it recovers
 }
 
 func (w *Worker) Reset() {
 	w.JobId = utils.EMPTY_STRING
-	w.CurrBatchInput = nil
+	w.BatchInputs = nil
 	w.LastQueryTime = api.CurrentTimestamp()
 }
 
+// Whether the worker holds a batch of its job
+func (w *Worker) HoldsBatch(batchId int32) bool {
+	for _, batchInput := range w.BatchInputs {
+		if batchInput.GetBatchId() == batchId {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Worker) AddBatch(batchInput *api.BatchInput) {
+	if batchInput != nil && !w.HoldsBatch(batchInput.GetBatchId()) {
+		w.BatchInputs = append(w.BatchInputs, batchInput)
+	}
+}
+
+func (w *Worker) RemoveBatch(batchId int32) {
+	batchInputs := make([]*api.BatchInput, 0)
+	for _, batchInput := range w.BatchInputs {
+		if batchInput.GetBatchId() != batchId {
+			batchInputs = append(batchInputs, batchInput)
+		}
+	}
+	w.BatchInputs = batchInputs
+}
+
+// Make the batches the worker holds available again, i.e. they never finish on this worker
+func (w *Worker) ReleaseBatches(job *api.Job) {
+	if job != nil {
+		for _, batchInput := range w.BatchInputs {
+			job.ReleaseLease(batchInput.GetBatchId(), w.Process.Address())
+		}
+	}
+	w.BatchInputs = nil
+}
+
+// Whether the worker loaded given version of a model
+func (w *Worker) Serves(model string, version int32) bool {
+	return w.Model == model && w.Version == version
+}
+
+// Whether the worker's runner keeps given version of a model resident, so serving it needs no reload
+func (w *Worker) IsResident(model string, version int32) bool {
+	for _, resident := range w.Resident {
+		if resident.GetModel() == model && resident.GetVersion() == version {
+			return true
+		}
+	}
+	return w.Serves(model, version)
+}
+
+// Whether the worker's runner keeps any version of a model resident
+func (w *Worker) HasModel(model string) bool {
+	for _, resident := range w.Resident {
+		if resident.GetModel() == model {
+			return true
+		}
+	}
+	return w.Model == model
+}
+
 func (w *Worker) Idle() bool {
 	return w.JobId == utils.EMPTY_STRING
 }
 
+// Whether the worker can be given work, i.e. it is not leaving, its runner is healthy and its capabilities are registered
+func (w *Worker) Schedulable() bool {
+	return !w.Draining && !w.Unhealthy && w.Capabilities != nil
+}
+
 type WorkerList []*Worker
 
 // implement sort interface
@@ -53,12 +127,45 @@ func (rm *ResourceManager) GetWorker(workerAddr string) *Worker {
 }
 
 func (rm ResourceManager) AddWorker(process *api.Process) {
-	rm[process.Address()] = &Worker{
-		JobId:          utils.EMPTY_STRING,
-		CurrBatchInput: nil,
-		LastQueryTime:  api.CurrentTimestamp(),
-		Process:        process,
+	worker := &Worker{
+		JobId:         utils.EMPTY_STRING,
+		BatchInputs:   nil,
+		LastQueryTime: api.CurrentTimestamp(),
+		Process:       process,
+	}
+	rm[process.Address()] = worker
+}
+
+/*
+ * Register capabilities fetched from a worker
+ *
+ * @param process: incarnation of the worker the capabilities were fetched from
+ * @param capabilities: resources, labels & models loaded by the worker's runner
+ * @return bool: false if the worker is gone or has rejoined since
+ */
+func (rm ResourceManager) RegisterCapabilities(process *api.Process, capabilities *api.WorkerCapabilities) bool {
+	worker, ok := rm[process.Address()]
+	if !ok || worker.Process != process {
+		return false
+	}
+	worker.Capabilities = capabilities
+
+	// version of a model loaded before joining is unknown, it only counts as the same model
+	if models := capabilities.GetModels(); len(models) > 0 && worker.Model == utils.EMPTY_STRING {
+		worker.Model = models[0]
 	}
+	return true
+}
+
+// Workers whose capabilities are not registered yet
+func (rm ResourceManager) GetUnregisteredWorkers() []*Worker {
+	workers := make([]*Worker, 0)
+	for _, worker := range rm {
+		if worker.Capabilities == nil {
+			workers = append(workers, worker)
+		}
+	}
+	return workers
 }
 
 func (rm ResourceManager) RemoveWorker(process *api.Process) *Worker {
@@ -77,7 +184,7 @@ func (rm ResourceManager) RemoveWorker(process *api.Process) *Worker {
 func (rm ResourceManager) GetIdleWorkers() []*Worker {
 	idleWorkers := make([]*Worker, 0)
 	for _, worker := range rm {
-		if worker.Idle() {
+		if worker.Idle() && worker.Schedulable() && !worker.Online {
 			idleWorkers = append(idleWorkers, worker)
 		}
 	}
@@ -103,3 +210,14 @@ func (rm ResourceManager) GetWorkerCountById(jobId string) int {
 func (rm ResourceManager) Len() int {
 	return len(rm)
 }
+
+// number of workers that can be scheduled, excluding draining workers & workers with an unhealthy runner
+func (rm ResourceManager) SchedulableLen() int {
+	count := 0
+	for _, worker := range rm {
+		if worker.Schedulable() {
+			count++
+		}
+	}
+	return count
+}
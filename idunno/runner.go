@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"mp4/api"
+	"mp4/logger"
+	"sync"
+)
+
+const RUNNER_PYTHON = "python" // models are served by the python inference service, native models in process
+const RUNNER_NATIVE = "native" // only native models are served, in process, no python needed
+const FRAMEWORK_BOW = "bow"    // bag-of-words text classifier trained on its artifact
+
+// frameworks served in process by every worker
+var NATIVE_FRAMEWORKS = map[string]bool{FRAMEWORK_BOW: true}
+
+// Runs the models served by a worker
+type ModelRunner interface {
+	// check a model can be served after it is trained on a dataset
+	Train(ctx context.Context, req *api.TrainRequest) (*api.TrainResponse, error)
+	// load a model, following evaluations use it
+	Serve(ctx context.Context, req *api.ServeModelRequest) (*api.ServeModelResponse, error)
+	// evaluate inputs with the served model
+	Evaluate(ctx context.Context, req *api.EvaluateRequest) (*api.EvaluateResponse, error)
+	// check the runner answers requests
+	Check(ctx context.Context) error
+	// whether the runner passed its last health check, reported to the coordinator
+	Healthy() bool
+	// callback once the runner restarted and lost its models
+	OnRestart(callback func())
+	// stop the runner when the worker exits
+	Stop()
+	Name() string
+}
+
+/*
+ * Create the model runner of a worker
+ *
+ * @param kind: RUNNER_PYTHON or RUNNER_NATIVE
+ * @param port: port of the python inference service
+ * @param filepath: local folder of SDFS files, inputs are read from it
+ * @param memoryBudgetMb: memory each runner keeps models resident in
+ * @return ModelRunner: native runner, or a python runner serving native models in process
+ * @return error: raise error if the kind is unknown or the python runner fails to start
+ */
+func NewModelRunner(kind string, port int, filepath string, memoryBudgetMb int64) (ModelRunner, error) {
+	switch kind {
+	case RUNNER_NATIVE:
+		return NewNativeRunner(memoryBudgetMb), nil
+	case RUNNER_PYTHON:
+		python, err := NewGRPCRunner(port, filepath, memoryBudgetMb)
+		if err != nil {
+			return nil, err
+		}
+		return &RoutingRunner{Native: NewNativeRunner(memoryBudgetMb), Python: python}, nil
+	default:
+		return nil, fmt.Errorf("unknown runner %q, expected %v or %v", kind, RUNNER_PYTHON, RUNNER_NATIVE)
+	}
+}
+
+// Whether workers with a kind of runner serve models of a framework, workers of unknown kind run python
+func RunnerServes(runner string, framework string) bool {
+	return NATIVE_FRAMEWORKS[framework] || runner != RUNNER_NATIVE
+}
+
+// Serves native models in process and any other model on the python runner
+type RoutingRunner struct {
+	Native   *NativeRunner
+	Python   *GRPCRunner
+	active   ModelRunner          // runner of the served model, nil if none
+	resident []*api.ResidentModel // models kept resident by the python runner, least recently used first
+	sync.Mutex
+}
+
+// Runner serving models of a framework
+func (rr *RoutingRunner) route(spec *api.ModelSpec) ModelRunner {
+	if NATIVE_FRAMEWORKS[spec.GetFramework()] {
+		return rr.Native
+	}
+	return rr.Python
+}
+
+func (rr *RoutingRunner) Train(ctx context.Context, req *api.TrainRequest) (*api.TrainResponse, error) {
+	return rr.route(req.GetSpec()).Train(ctx, req)
+}
+
+func (rr *RoutingRunner) Serve(ctx context.Context, req *api.ServeModelRequest) (*api.ServeModelResponse, error) {
+	runner := rr.route(req.GetSpec())
+	res, err := runner.Serve(ctx, req)
+	if err != nil || res.GetStatus() != api.ResponseStatus_OK {
+		return res, err
+	}
+
+	rr.Lock()
+	defer rr.Unlock()
+	rr.active = runner
+
+	// native models are resident next to the python ones, the served model comes last
+	if runner == rr.Python {
+		rr.resident = res.GetResident()
+		res.Resident = append(rr.Native.ResidentModels(), rr.resident...)
+	} else {
+		res.Resident = append(append(make([]*api.ResidentModel, 0), rr.resident...), rr.Native.ResidentModels()...)
+	}
+	return res, nil
+}
+
+func (rr *RoutingRunner) Evaluate(ctx context.Context, req *api.EvaluateRequest) (*api.EvaluateResponse, error) {
+	rr.Lock()
+	runner := rr.active
+	rr.Unlock()
+
+	if runner == nil {
+		logger.Error("No model is served by the runner")
+		return &api.EvaluateResponse{Status: api.ResponseStatus_ERROR}, nil
+	}
+	return runner.Evaluate(ctx, req)
+}
+
+func (rr *RoutingRunner) Check(ctx context.Context) error {
+	return rr.Python.Check(ctx)
+}
+
+// Native models are served while the python runner restarts, but the worker is reported unhealthy until it is back
+func (rr *RoutingRunner) Healthy() bool {
+	return rr.Python.Healthy()
+}
+
+func (rr *RoutingRunner) OnRestart(callback func()) {
+	rr.Python.OnRestart(func() {
+		// restarted python runner keeps no model resident
+		rr.Lock()
+		rr.resident = nil
+		rr.Unlock()
+		callback()
+	})
+}
+
+func (rr *RoutingRunner) Stop() {
+	rr.Python.Stop()
+}
+
+func (rr *RoutingRunner) Name() string {
+	return rr.Python.Name()
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"mp4/api"
+	"mp4/connpool"
+	"mp4/logger"
+	"mp4/sdfs"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const RUNNER_BIN = "python3.10"
+const RUNNER_SCRIPT = "../inference/worker.py"
+const RUNNER_START_TIMEOUT = 60 * time.Second  // runner imports its frameworks before it answers health checks
+const RUNNER_HEALTH_INTERVAL = 5 * time.Second // runner is health checked on every interval
+const RUNNER_HEALTH_TIMEOUT = 2 * time.Second  // health check fails if the runner does not answer in time
+const RUNNER_HEALTH_FAILURES = 3               // consecutive failed health checks before the runner is restarted
+const RUNNER_MIN_BACKOFF = 1 * time.Second     // wait before the first restart, doubled on every restart until the runner is healthy again
+const RUNNER_MAX_BACKOFF = 60 * time.Second
+
+// Bridge to the python inference service, restarted whenever it exits or stops answering health checks
+type GRPCRunner struct {
+	Process   *api.Process // address the inference service listens on
+	Restarts  int          // times the inference service was restarted
+	healthy   bool         // inference service passed its last health check
+	args      []string
+	cmd       *exec.Cmd
+	exited    chan error // receives once the current process exits
+	started   time.Time
+	onRestart func()
+	stopped   bool
+	calls     sync.Mutex // model calls go one at a time, the inference service shares one model between requests
+	sync.Mutex
+}
+
+/*
+ * Start the python inference service and supervise it
+ *
+ * @param port: port the inference service listens on
+ * @param filepath: local folder of SDFS files, inputs are read from it
+ * @param memoryBudgetMb: memory the inference service keeps models resident in
+ * @return *GRPCRunner: supervised runner
+ * @return error: raise error if the inference service cannot be started
+ */
+func NewGRPCRunner(port int, filepath string, memoryBudgetMb int64) (*GRPCRunner, error) {
+	runner := &GRPCRunner{
+		Process: &api.Process{
+			Ip:   RUNNER_HOST,
+			Port: int32(port),
+		},
+		args: []string{RUNNER_SCRIPT, "--port", strconv.Itoa(port), "--filepath", filepath, "--memory-budget", strconv.FormatInt(memoryBudgetMb, 10)},
+	}
+	if err := runner.start(); err != nil {
+		return nil, err
+	}
+
+	go runner.Supervise()
+	return runner, nil
+}
+
+// Start the inference service process
+func (gr *GRPCRunner) start() error {
+	cmd := exec.Command(RUNNER_BIN, gr.args...)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		logger.Error("Failed to start inference service: " + err.Error())
+		return err
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	gr.Lock()
+	defer gr.Unlock()
+	gr.cmd, gr.exited, gr.started = cmd, exited, time.Now()
+	return nil
+}
+
+/*
+ * Health check the inference service and restart it whenever it exits or fails consecutive health checks
+ * after it started. Restarts back off exponentially until the inference service is healthy again,
+ * so that a runner crashing on start does not spin
+ */
+func (gr *GRPCRunner) Supervise() {
+	ticker := time.NewTicker(RUNNER_HEALTH_INTERVAL)
+	defer ticker.Stop()
+
+	failures, backoff := 0, RUNNER_MIN_BACKOFF
+	for {
+		gr.Lock()
+		cmd, exited, started := gr.cmd, gr.exited, gr.started
+		gr.Unlock()
+
+		select {
+		case err := <-exited:
+			if gr.isStopped() {
+				return
+			}
+			gr.setHealthy(false)
+			logger.Error(fmt.Sprintf("Inference service %v exited: %v", gr.Name(), err))
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), RUNNER_HEALTH_TIMEOUT)
+			err := gr.Check(ctx)
+			cancel()
+			gr.setHealthy(err == nil)
+			if err == nil {
+				failures, backoff = 0, RUNNER_MIN_BACKOFF
+				continue
+			}
+			// frameworks are still being imported
+			if time.Since(started) < RUNNER_START_TIMEOUT {
+				continue
+			}
+			if failures++; failures < RUNNER_HEALTH_FAILURES {
+				continue
+			}
+			logger.Error(fmt.Sprintf("Inference service %v failed %v health checks, restarting it: %v", gr.Name(), failures, err))
+			cmd.Process.Kill()
+			<-exited
+		}
+
+		failures = 0
+		for {
+			logger.Info(fmt.Sprintf("Restarting inference service %v in %v", gr.Name(), backoff))
+			time.Sleep(backoff)
+			if backoff *= 2; backoff > RUNNER_MAX_BACKOFF {
+				backoff = RUNNER_MAX_BACKOFF
+			}
+			if gr.isStopped() {
+				return
+			}
+			if err := gr.start(); err == nil {
+				break
+			}
+		}
+
+		// connection to the previous process is never reused
+		connpool.POOL.Evict(gr.Name())
+
+		gr.Lock()
+		gr.Restarts++
+		onRestart := gr.onRestart
+		gr.Unlock()
+		logger.Info(fmt.Sprintf("Inference service %v restarted", gr.Name()))
+		if onRestart != nil {
+			go onRestart()
+		}
+	}
+}
+
+func (gr *GRPCRunner) isStopped() bool {
+	gr.Lock()
+	defer gr.Unlock()
+	return gr.stopped
+}
+
+func (gr *GRPCRunner) Train(ctx context.Context, req *api.TrainRequest) (*api.TrainResponse, error) {
+	client, err := gr.client()
+	if err != nil {
+		return nil, err
+	}
+	gr.calls.Lock()
+	defer gr.calls.Unlock()
+	return client.Train(ctx, req)
+}
+
+func (gr *GRPCRunner) Serve(ctx context.Context, req *api.ServeModelRequest) (*api.ServeModelResponse, error) {
+	client, err := gr.client()
+	if err != nil {
+		return nil, err
+	}
+	gr.calls.Lock()
+	defer gr.calls.Unlock()
+	return client.ServeModel(ctx, req)
+}
+
+// Batches & online requests are evaluated one at a time, health checks go through meanwhile
+func (gr *GRPCRunner) Evaluate(ctx context.Context, req *api.EvaluateRequest) (*api.EvaluateResponse, error) {
+	client, err := gr.client()
+	if err != nil {
+		return nil, err
+	}
+	gr.calls.Lock()
+	defer gr.calls.Unlock()
+	return client.Evaluate(ctx, req)
+}
+
+// Inference service is healthy if it answers the health RPC
+func (gr *GRPCRunner) Check(ctx context.Context) error {
+	client, err := gr.client()
+	if err != nil {
+		return err
+	}
+	res, err := client.Health(ctx, &api.HealthRequest{})
+	if err != nil {
+		return err
+	}
+	if res.GetStatus() != api.ResponseStatus_OK {
+		return fmt.Errorf("inference service responded %v", res.GetStatus())
+	}
+	return nil
+}
+
+// Whether the inference service passed its last health check, false until it first answers after a start
+func (gr *GRPCRunner) Healthy() bool {
+	gr.Lock()
+	defer gr.Unlock()
+	return gr.healthy
+}
+
+func (gr *GRPCRunner) setHealthy(healthy bool) {
+	gr.Lock()
+	defer gr.Unlock()
+	if gr.healthy != healthy {
+		logger.Info(fmt.Sprintf("Inference service %v healthy: %v", gr.Name(), healthy))
+	}
+	gr.healthy = healthy
+}
+
+func (gr *GRPCRunner) OnRestart(callback func()) {
+	gr.Lock()
+	defer gr.Unlock()
+	gr.onRestart = callback
+}
+
+// Stop supervising the inference service and kill it
+func (gr *GRPCRunner) Stop() {
+	gr.Lock()
+	defer gr.Unlock()
+	gr.stopped = true
+	if gr.cmd != nil && gr.cmd.Process != nil {
+		gr.cmd.Process.Kill()
+	}
+}
+
+func (gr *GRPCRunner) Name() string {
+	return gr.Process.Address()
+}
+
+func (gr *GRPCRunner) client() (api.InferenceServiceClient, error) {
+	conn, err := connpool.POOL.Get(gr.Name(), sdfs.RUNNER_GRPC_OPTIONS...)
+	if err != nil {
+		logger.Error("Failed to connect to inference service: " + err.Error())
+		return nil, err
+	}
+	return api.NewInferenceServiceClient(conn), nil
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"mp4/api"
+	"mp4/logger"
+	"mp4/utils"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const LABEL_SEPARATOR = ";" // labeled text is "text;label", as in emotion.txt
+
+// Serves native models in process, models are trained from their artifact when they are served
+type NativeRunner struct {
+	models   map[string]*BagOfWords // model:version -> trained model
+	order    []string               // keys of trained models, least recently used first
+	serving  *BagOfWords            // served model, nil if none
+	budgetMb int64                  // memory trained models are kept resident in, the served model is always kept
+	sync.RWMutex
+}
+
+func NewNativeRunner(memoryBudgetMb int64) *NativeRunner {
+	return &NativeRunner{
+		models:   make(map[string]*BagOfWords),
+		order:    make([]string, 0),
+		budgetMb: memoryBudgetMb,
+	}
+}
+
+// Train a model on its artifact to check it can be served, every worker trains the same model from the same artifact
+func (nr *NativeRunner) Train(ctx context.Context, req *api.TrainRequest) (*api.TrainResponse, error) {
+	if _, err := nr.fit(req.GetSpec(), req.GetArtifactPath()); err != nil {
+		logger.Error(fmt.Sprintf("Native runner failed to train model %v: %v", req.GetSpec().GetName(), err))
+		return &api.TrainResponse{Status: api.ResponseStatus_ERROR}, nil
+	}
+	return &api.TrainResponse{Status: api.ResponseStatus_OK}, nil
+}
+
+func (nr *NativeRunner) Serve(ctx context.Context, req *api.ServeModelRequest) (*api.ServeModelResponse, error) {
+	key := fmt.Sprintf("%v:%v", req.GetModel(), req.GetSpec().GetVersion())
+
+	nr.Lock()
+	model, warm := nr.models[key]
+	nr.Unlock()
+
+	if !warm {
+		fitted, err := nr.fit(req.GetSpec(), req.GetArtifactPath())
+		if err != nil {
+			logger.Error(fmt.Sprintf("Native runner failed to serve model %v: %v", req.GetModel(), err))
+			return &api.ServeModelResponse{Status: api.ResponseStatus_ERROR}, nil
+		}
+		model = fitted
+	}
+
+	nr.Lock()
+	nr.models[key] = model
+	nr.order = append(removeKey(nr.order, key), key)
+	nr.serving = model
+	nr.evict()
+	nr.Unlock()
+
+	return &api.ServeModelResponse{
+		Status:   api.ResponseStatus_OK,
+		Warm:     warm,
+		Resident: nr.ResidentModels(),
+	}, nil
+}
+
+/*
+ * Classify inputs with the served model
+ *
+ * @param req: labeled text "text;label", or local paths of files holding the text if the model takes filenames
+ * @return *api.EvaluateResponse: predicted label of each input, metric is the number of correctly labeled inputs
+ */
+func (nr *NativeRunner) Evaluate(ctx context.Context, req *api.EvaluateRequest) (*api.EvaluateResponse, error) {
+	nr.RLock()
+	model := nr.serving
+	nr.RUnlock()
+
+	if model == nil {
+		logger.Error("No model is served by the native runner")
+		return &api.EvaluateResponse{Status: api.ResponseStatus_ERROR}, nil
+	}
+
+	results, metric := make([]*api.EvalResult, 0), float32(0)
+	for _, input := range req.GetInputs() {
+		text, label := input, utils.EMPTY_STRING
+		if model.Spec.GetInputType() == api.InputType_FilenameInput {
+			content, err := os.ReadFile(input)
+			if err != nil {
+				logger.Error("Native runner failed to read input " + input + ": " + err.Error())
+				return &api.EvaluateResponse{Status: api.ResponseStatus_ERROR}, nil
+			}
+			text = string(content)
+		} else {
+			text, label = SplitLabel(input)
+		}
+
+		output := model.Predict(text)
+		if label != utils.EMPTY_STRING && output == label {
+			metric++
+		}
+		results = append(results, &api.EvalResult{Input: input, Output: output})
+	}
+
+	return &api.EvaluateResponse{Results: results, Metric: metric, Status: api.ResponseStatus_OK}, nil
+}
+
+// Native runner runs in process, it is healthy as long as the worker is
+func (nr *NativeRunner) Check(ctx context.Context) error {
+	return nil
+}
+
+func (nr *NativeRunner) Healthy() bool {
+	return true
+}
+
+// Native runner never restarts
+func (nr *NativeRunner) OnRestart(callback func()) {}
+
+func (nr *NativeRunner) Stop() {}
+
+func (nr *NativeRunner) Name() string {
+	return RUNNER_NATIVE
+}
+
+// Models trained by the runner, least recently used first
+func (nr *NativeRunner) ResidentModels() []*api.ResidentModel {
+	nr.RLock()
+	defer nr.RUnlock()
+
+	resident := make([]*api.ResidentModel, 0)
+	for _, key := range nr.order {
+		model := nr.models[key]
+		resident = append(resident, &api.ResidentModel{
+			Model:    model.Spec.GetName(),
+			Version:  model.Spec.GetVersion(),
+			MemoryMb: model.MemoryMb(),
+		})
+	}
+	return resident
+}
+
+// Drop least recently used models until the resident ones fit the budget, must hold the lock
+func (nr *NativeRunner) evict() {
+	total := int64(0)
+	for _, model := range nr.models {
+		total += model.MemoryMb()
+	}
+
+	for total > nr.budgetMb && len(nr.order) > 1 {
+		key := nr.order[0]
+		logger.Info(fmt.Sprintf("Native runner evicting model %v to fit its %v MB budget", key, nr.budgetMb))
+		total -= nr.models[key].MemoryMb()
+		delete(nr.models, key)
+		nr.order = nr.order[1:]
+	}
+}
+
+func (nr *NativeRunner) fit(spec *api.ModelSpec, artifactPath string) (*BagOfWords, error) {
+	if !NATIVE_FRAMEWORKS[spec.GetFramework()] {
+		return nil, fmt.Errorf("framework %q is not served by the native runner", spec.GetFramework())
+	}
+	if artifactPath == utils.EMPTY_STRING {
+		return nil, fmt.Errorf("model %v has no labeled training set", spec.GetName())
+	}
+	return FitBagOfWords(spec, artifactPath)
+}
+
+// Multinomial naive bayes over the words of a text
+type BagOfWords struct {
+	Spec   *api.ModelSpec
+	Labels []string                  // labels in training order, ties go to the first one
+	docs   map[string]int            // label -> training examples
+	counts map[string]map[string]int // label -> word -> occurrences
+	totals map[string]int            // label -> words
+	vocab  map[string]bool
+	size   int // training examples
+}
+
+/*
+ * Train a bag-of-words classifier on a labeled training set
+ *
+ * @param spec: spec of the model
+ * @param path: local file with one labeled example "text;label" per line
+ * @return *BagOfWords: trained classifier
+ * @return error: raise error if the file cannot be read or has no labeled example
+ */
+func FitBagOfWords(spec *api.ModelSpec, path string) (*BagOfWords, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	model := &BagOfWords{
+		Spec:   spec,
+		Labels: make([]string, 0),
+		docs:   make(map[string]int),
+		counts: make(map[string]map[string]int),
+		totals: make(map[string]int),
+		vocab:  make(map[string]bool),
+	}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		text, label := SplitLabel(scanner.Text())
+		if label == utils.EMPTY_STRING {
+			continue
+		}
+		if _, ok := model.docs[label]; !ok {
+			model.Labels = append(model.Labels, label)
+			model.counts[label] = make(map[string]int)
+		}
+
+		model.docs[label]++
+		model.size++
+		for _, word := range Tokenize(text) {
+			model.counts[label][word]++
+			model.totals[label]++
+			model.vocab[word] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if model.size == 0 {
+		return nil, fmt.Errorf("training set %v has no labeled example", path)
+	}
+	return model, nil
+}
+
+// Most likely label of a text, words are smoothed so that unseen words do not rule a label out
+func (m *BagOfWords) Predict(text string) string {
+	words := Tokenize(text)
+	best, bestScore := utils.EMPTY_STRING, math.Inf(-1)
+	for _, label := range m.Labels {
+		score := math.Log(float64(m.docs[label]) / float64(m.size))
+		denominator := float64(m.totals[label] + len(m.vocab))
+		for _, word := range words {
+			score += math.Log(float64(m.counts[label][word]+1) / denominator)
+		}
+		if score > bestScore {
+			best, bestScore = label, score
+		}
+	}
+	return best
+}
+
+// Rough memory of the word counts, at least 1 MB
+func (m *BagOfWords) MemoryMb() int64 {
+	entries := 0
+	for _, counts := range m.counts {
+		entries += len(counts)
+	}
+	return int64(entries*64)/(1<<20) + 1
+}
+
+// Lowercase words of a text, split on anything but letters & digits
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Split labeled text "text;label" at its last separator, label is empty if the text is unlabeled
+func SplitLabel(raw string) (string, string) {
+	i := strings.LastIndex(raw, LABEL_SEPARATOR)
+	if i < 0 {
+		return raw, utils.EMPTY_STRING
+	}
+	return raw[:i], strings.TrimSpace(raw[i+1:])
+}
+
+func removeKey(keys []string, key string) []string {
+	kept := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k != key {
+			kept = append(kept, k)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"mp4/api"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// labeled training set "text;label" written to a temp file
+func writeTrainingSet(t *testing.T, rows []string) string {
+	path := filepath.Join(t.TempDir(), "train.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(rows, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func Test_NativeRunner_SplitLabel(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		raw   string
+		text  string
+		label string
+	}{
+		{"i feel great;joy", "i feel great", "joy"},
+		{"a;b;sadness", "a;b", "sadness"},
+		{"i feel great; joy ", "i feel great", "joy"},
+		{"unlabeled text", "unlabeled text", ""},
+		{"no label;", "no label", ""},
+		{"", "", ""},
+	}
+
+	for _, test := range tests {
+		text, label := SplitLabel(test.raw)
+		assert.Equal(test.text, text, test.raw)
+		assert.Equal(test.label, label, test.raw)
+	}
+}
+
+func Test_NativeRunner_Tokenize(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]string{"i", "feel", "great", "today"}, Tokenize("I feel GREAT, today!"))
+	assert.Equal([]string{"don", "t", "stop", "2day"}, Tokenize("don't  stop\t2day"))
+	assert.Equal([]string{"café", "über"}, Tokenize("Café-Über"))
+	assert.Empty(Tokenize(" ,.!? "))
+}
+
+func Test_NativeRunner_FitBagOfWords(t *testing.T) {
+	assert := assert.New(t)
+
+	spec := &api.ModelSpec{Name: "sentiment", Version: 1, Framework: FRAMEWORK_BOW}
+	path := writeTrainingSet(t, []string{
+		"i love this movie;positive",
+		"what a great wonderful film;positive",
+		"love the great acting;positive",
+		"i hate this movie;negative",
+		"awful boring terrible film;negative",
+		"unlabeled row is skipped",
+		"",
+	})
+
+	model, err := FitBagOfWords(spec, path)
+	assert.Nil(err)
+	assert.Equal([]string{"positive", "negative"}, model.Labels)
+	assert.Equal(int64(1), model.MemoryMb())
+
+	assert.Equal("positive", model.Predict("a great movie, I love it"))
+	assert.Equal("negative", model.Predict("boring and terrible"))
+	assert.Equal("negative", model.Predict("I HATE it"))
+	// text without words goes to the label with the most examples
+	assert.Equal("positive", model.Predict("?!"))
+
+	_, err = FitBagOfWords(spec, writeTrainingSet(t, []string{"no label at all"}))
+	assert.NotNil(err, "training set without labeled example")
+	_, err = FitBagOfWords(spec, filepath.Join(t.TempDir(), "missing.txt"))
+	assert.NotNil(err, "missing training set")
+}
+
+func Test_NativeRunner_Evict(t *testing.T) {
+	assert := assert.New(t)
+
+	path := writeTrainingSet(t, []string{"i love this movie;positive", "i hate this movie;negative"})
+	runner := NewNativeRunner(2)
+	serve := func(name string) *api.ServeModelResponse {
+		res, err := runner.Serve(context.Background(), &api.ServeModelRequest{
+			Model:        name,
+			Spec:         &api.ModelSpec{Name: name, Version: 1, Framework: FRAMEWORK_BOW, InputType: api.InputType_RawInput},
+			ArtifactPath: path,
+		})
+		assert.Nil(err)
+		assert.Equal(api.ResponseStatus_OK, res.GetStatus(), name)
+		return res
+	}
+	models := func(resident []*api.ResidentModel) []string {
+		names := make([]string, 0)
+		for _, model := range resident {
+			names = append(names, model.GetModel())
+		}
+		return names
+	}
+
+	serve("a")
+	serve("b")
+	assert.True(serve("a").GetWarm())
+	// models take 1 MB each, the least recently used one is evicted
+	assert.Equal([]string{"a", "c"}, models(serve("c").GetResident()))
+	assert.False(serve("b").GetWarm(), "evicted model should be trained again")
+	assert.Equal([]string{"c", "b"}, models(runner.ResidentModels()))
+
+	res, err := runner.Evaluate(context.Background(), &api.EvaluateRequest{Inputs: []string{"i love it;positive", "i hate it;positive"}})
+	assert.Nil(err)
+	assert.Equal(float32(1), res.GetMetric())
+
+	// served model is kept even if it alone exceeds the budget
+	runner = NewNativeRunner(0)
+	serve("a")
+	assert.Equal([]string{"b"}, models(serve("b").GetResident()))
+}
@@ -8,8 +8,14 @@ import (
 	"mp4/ralloc"
 	"mp4/utils"
 	"sort"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// min decrease of relative qps difference between jobs that is worth moving a worker, i.e. reloading a model
+const SWITCHING_PENALTY = 0.05
+
 type IDunnoScheduler struct {
 	ActiveJobs      map[string]*api.Job
 	PendingJobs     *utils.Queue[*api.Job]
@@ -56,94 +62,122 @@ func (is *IDunnoScheduler) RefreshBatchStatus() {
 				continue
 			}
 
-			inProgress := false
-			for _, worker := range workers {
-				if worker.CurrBatchInput != nil && worker.CurrBatchInput.GetBatchId() == int32(id) {
-					inProgress = true
-					break
+			// revoke leases that expired, i.e. worker hangs or straggles, or whose worker no longer works on this batch input
+			leases := make([]*api.BatchLease, 0)
+			for _, lease := range batchState.Leases {
+				var holder *Worker
+				for _, worker := range workers {
+					if worker.Process.Address() == lease.Worker && worker.HoldsBatch(int32(id)) {
+						holder = worker
+						break
+					}
+				}
+
+				if holder != nil && time.Now().Before(lease.Expiry.AsTime()) {
+					leases = append(leases, lease)
+					continue
+				}
+				if holder != nil {
+					logger.Info(fmt.Sprintf("Lease %v of batch %v of job %v expired on worker %v", lease.Id, id, job.Id, lease.Worker))
+					holder.RemoveBatch(int32(id))
 				}
 			}
+			batchState.Leases = leases
 
-			// if no worker is working on this batch input, then it is failed
+			// if no worker holds a lease of this batch input, then it is failed
 			// make it available again
-			if !inProgress {
+			if len(leases) == 0 {
 				batchState.Status = api.BatchStatus_Available
 			}
 		}
 	}
 }
 
-func (is *IDunnoScheduler) RefreshSchedule() map[string][]*Worker {
-	if is.ResourceManager.Len() == 0 || len(is.ActiveJobs) == 0 {
+// Allocate workers to running jobs, leaving reserved workers to online traffic
+func (is *IDunnoScheduler) RefreshSchedule(reserved int) map[string][]*Worker {
+	numWorkers := is.ResourceManager.SchedulableLen() - reserved
+	if numWorkers <= 0 || len(is.ActiveJobs) == 0 {
 		return make(map[string][]*Worker)
 	}
 
-	jobs := make([]*api.Job, 0)      // all jobs
-	ids := make([]string, 0)         // job ids
-	allocMap := make(map[string]int) // #VMs to allocate for each job
+	jobs := make([]*api.Job, 0)      // running jobs
+	ids := make([]string, 0)         // running job ids
+	allJobs := make([]*api.Job, 0)   // all active jobs, including paused & cancelled ones
+	allocMap := make(map[string]int) // #VMs to allocate for each job, 0 if not running
 
+	allIds := make([]string, 0)
 	for id := range is.ActiveJobs {
-		ids = append(ids, id)
+		allIds = append(allIds, id)
 	}
 	// important to sort id first to make ralloc output to be deterministic
-	sort.Strings(ids)
-
-	for _, id := range ids {
-		jobs = append(jobs, is.ActiveJobs[id])
+	sort.Strings(allIds)
+
+	for _, id := range allIds {
+		allJobs = append(allJobs, is.ActiveJobs[id])
+		// completed jobs waiting to be flushed stay active until JobFinished is committed, they get no worker
+		if is.ActiveJobs[id].Status == api.JobStatus_Running && !is.IsPending(id) {
+			ids = append(ids, id)
+			jobs = append(jobs, is.ActiveJobs[id])
+		}
 	}
 
 	var resources []int
-	if utils.LAST_SECONDS == math.MaxFloat64 {
+	if len(jobs) == 0 {
+		resources = make([]int, 0)
+	} else if utils.LAST_SECONDS == math.MaxFloat64 {
 		// Global fair-time scheduling
-		qps := ralloc.JobToQPS(jobs, is.ResourceManager.Len())
-		resources, _ = ralloc.GlobalFairTimeRalloc(len(jobs), is.ResourceManager.Len(), qps)
+		qps := ralloc.JobToQPS(jobs, numWorkers)
+		resources, _ = ralloc.GlobalFairTimeRalloc(len(jobs), numWorkers, qps)
 	} else {
 		// Local fair-time scheduling
-		resources, _ = ralloc.LocalFairTimeRalloc(jobs, is.ResourceManager.Len())
+		resources, _ = ralloc.LocalFairTimeRalloc(jobs, numWorkers)
+	}
+	// keep workers on their jobs unless the fairer allocation is worth the models reloaded
+	current := make([]int, len(jobs))
+	for i := range jobs {
+		current[i] = is.ResourceManager.GetWorkerCountById(ids[i])
 	}
+	resources = ralloc.DampSwitching(jobs, current, resources, SWITCHING_PENALTY)
+	resources = ralloc.GuaranteeMinWorkers(jobs, resources)
 
 	for i := range jobs {
 		allocMap[ids[i]] = resources[i]
 	}
-	// make worker that are no longer needs to be used idle
-	for _, job := range jobs {
+	// make worker that are no longer needs to be used idle, paused & cancelled jobs release all of them
+	for _, job := range allJobs {
 		workers := is.ResourceManager.GetWorkersById(job.Id)
 		sort.Sort(WorkerList(workers)) // sort by lastQueryTime (most recent first)
 
 		for i := 0; i < len(workers)-allocMap[job.Id]; i++ {
 			workers[i].JobId = utils.EMPTY_STRING
 
-			if workers[i].CurrBatchInput == nil {
-				continue
-			}
-
-			// this batch input never finishes, so we make it available again
-			batchId := workers[i].CurrBatchInput.GetBatchId()
-			job.BatchStates[batchId].Status = api.BatchStatus_Available
-			workers[i].CurrBatchInput = nil
+			// these batch inputs never finish, so we make them available again
+			workers[i].ReleaseBatches(job)
 		}
 	}
 
 
-	// allocate idle workers to jobs
-	idleWorkers, idx := is.ResourceManager.GetIdleWorkers(), 0
+	// allocate idle workers to jobs, constrained jobs pick first as fewer workers satisfy them,
+	// and each job prefers workers that already loaded its model
+	idleWorkers := is.ResourceManager.GetIdleWorkers()
 	newSchedule := make(map[string][]*Worker) // job id -> worker list
-	for _, job := range jobs {
+	for _, job := range SortByPlacement(jobs, idleWorkers) {
 		workerCount := is.ResourceManager.GetWorkerCountById(job.Id)
 
 		for workerCount < allocMap[job.Id] {
-			if idx >= len(idleWorkers) {
-				logger.Error("Not enough idle workers to allocate")
+			idx := PickWorker(job, idleWorkers)
+			if idx == -1 {
+				logger.Error(fmt.Sprintf("Not enough idle workers satisfying job %v to allocate", job.Id))
 				break
 			}
 
 			newSchedule[job.Id] = append(newSchedule[job.Id], idleWorkers[idx])
+			idleWorkers = append(idleWorkers[:idx], idleWorkers[idx+1:]...)
 			workerCount++
-			idx++
 		}
 	}
 
-	if idx != len(idleWorkers) {
+	if len(idleWorkers) != 0 {
 		logger.Error("did not use all VM resources")
 	}
 
@@ -161,49 +195,131 @@ func (is *IDunnoScheduler) OnReceiveBatchOutput(jobId string, workerProcess *api
 		return
 	}
 
-	// update job info
 	batchId := batchOutput.GetBatchId()
+	if int(batchId) >= len(job.BatchStates) {
+		logger.Error(fmt.Sprintf("batch %v of job %v not found", batchId, jobId))
+		return
+	}
+
+	// update worker info, worker may be unknown to a standby replaying the log
+	if worker != nil {
+		worker.LastQueryTime = api.CurrentTimestamp()
+		worker.RemoveBatch(batchId)
+	}
+
+	// output is accepted from the lease holder by coordinator, this only guards a log replayed twice
+	if job.BatchStates[batchId].Status == api.BatchStatus_Completed {
+		logger.Info(fmt.Sprintf("Ignored duplicate output of batch %v of job %v", batchId, jobId))
+		return
+	}
+
+	// update job info, leases of speculative copies are released with it
+	job.BatchStates[batchId].Leases = nil
 	job.BatchStates[batchId].BatchOutput = batchOutput
 	job.BatchStates[batchId].Status = api.BatchStatus_Completed
 	job.BatchStates[batchId].ReceiveTime = api.CurrentTimestamp()
 	job.CompletedQueries = int32(job.GetCompletedBatchCount())
 
-	// update worker info
-	worker.LastQueryTime = api.CurrentTimestamp()
-	worker.CurrBatchInput = nil
-
 	if job.CompletedQueries < job.TotalQueries {
 		return
 	}
 
+	if !is.IsPending(jobId) {
+		is.PendingJobs.Push(job)
+	}
+}
+
+// Whether the job is queued to be flushed
+func (is *IDunnoScheduler) IsPending(jobId string) bool {
 	for _, job := range *is.PendingJobs {
 		if job.Id == jobId {
-			return
+			return true
 		}
 	}
+	return false
+}
 
-	is.PendingJobs.Push(job)
+// Count an output submitted without a current lease, i.e. the batch is completed by another worker or the lease expired
+func (is *IDunnoScheduler) OnBatchRejected(jobId string, workerProcess *api.Process, batchId int32) {
+	job := is.GetJob(jobId)
+	if job == nil {
+		logger.Error("job " + jobId + " not found")
+		return
+	}
+	job.DuplicateOutputs++
+
+	if worker := is.ResourceManager.GetWorker(workerProcess.Address()); worker != nil {
+		worker.LastQueryTime = api.CurrentTimestamp()
+		worker.RemoveBatch(batchId)
+	}
 }
 
-func (is *IDunnoScheduler) OnWorkerFailed(process *api.Process) {
-	logger.Info(fmt.Sprintf("Worker %v failed", process.Address()))
+// Release a worker's lease of a batch it failed to evaluate, the batch is available again unless another worker runs it
+func (is *IDunnoScheduler) OnBatchFailed(jobId string, workerProcess *api.Process, batchId int32) {
+	job := is.GetJob(jobId)
+	if job == nil {
+		logger.Error("job " + jobId + " not found")
+		return
+	}
+	job.ReleaseLease(batchId, workerProcess.Address())
 
-	// remove worker from resource manager
-	failedWorker := is.ResourceManager.RemoveWorker(process)
+	if worker := is.ResourceManager.GetWorker(workerProcess.Address()); worker != nil {
+		worker.LastQueryTime = api.CurrentTimestamp()
+		worker.RemoveBatch(batchId)
+	}
+}
 
-	if failedWorker == nil || failedWorker.JobId == utils.EMPTY_STRING || failedWorker.CurrBatchInput == nil {
+// Move a job whose results are written to SDFS to completed jobs
+func (is *IDunnoScheduler) OnJobFinished(jobId string, finishTime *timestamppb.Timestamp) {
+	job, ok := is.ActiveJobs[jobId]
+	delete(is.ActiveJobs, jobId)
+
+	if !is.PendingJobs.Empty() && is.PendingJobs.Top().Id == jobId {
+		job = is.PendingJobs.Top()
+		is.PendingJobs.Pop()
+	} else if !ok {
+		logger.Error("finished job " + jobId + " not found")
 		return
 	}
 
-	batchId := failedWorker.CurrBatchInput.GetBatchId()
-	job := is.GetJob(failedWorker.JobId)
+	// workers of the job are released once it is finished on every replica
+	for _, worker := range is.ResourceManager.GetWorkersById(jobId) {
+		worker.Reset()
+	}
+
+	job.FinishTime = finishTime
+	if job.Status != api.JobStatus_Cancelled {
+		job.Status = api.JobStatus_Finished
+	}
+	is.CompletedJobs[jobId] = job
+}
 
+// Stop a job and queue it to be flushed with the results of its completed batches
+func (is *IDunnoScheduler) OnJobCancelled(jobId string) {
+	job := is.GetJob(jobId)
 	if job == nil {
+		logger.Error("cancelled job " + jobId + " not found")
 		return
 	}
+	job.Status = api.JobStatus_Cancelled
 
-	// make batch input available again
-	job.BatchStates[batchId].Status = api.BatchStatus_Available
+	if !is.IsPending(jobId) {
+		is.PendingJobs.Push(job)
+	}
+}
+
+func (is *IDunnoScheduler) OnWorkerFailed(process *api.Process) {
+	logger.Info(fmt.Sprintf("Worker %v failed", process.Address()))
+
+	// remove worker from resource manager
+	failedWorker := is.ResourceManager.RemoveWorker(process)
+
+	if failedWorker == nil || failedWorker.JobId == utils.EMPTY_STRING {
+		return
+	}
+
+	// make batch inputs available again
+	failedWorker.ReleaseBatches(is.GetJob(failedWorker.JobId))
 }
 
 func (is *IDunnoScheduler) OnWorkerJoined(process *api.Process) {
@@ -213,10 +329,72 @@ func (is *IDunnoScheduler) OnWorkerJoined(process *api.Process) {
 	is.ResourceManager.AddWorker(process)
 }
 
+func (is *IDunnoScheduler) OnWorkerRejoined(process *api.Process) {
+	logger.Info(fmt.Sprintf("Worker %v rejoined", process.Address()))
+
+	// previous incarnation crashed, make the batches it was holding available again
+	if prevWorker := is.ResourceManager.GetWorker(process.Address()); prevWorker != nil {
+		prevWorker.ReleaseBatches(is.GetJob(prevWorker.JobId))
+	}
+
+	// returning worker is schedulable right away
+	is.ResourceManager.AddWorker(process)
+}
+
+func (is *IDunnoScheduler) OnWorkerDraining(worker *Worker) {
+	// make unfinished batch inputs available again
+	worker.ReleaseBatches(is.GetJob(worker.JobId))
+
+	worker.Reset()
+	worker.Draining = true
+	worker.Online = false
+}
+
+/*
+ * Record the health of a worker's runner. A worker whose runner turns unhealthy is released from its job,
+ * so that its batches go to other workers, and it is scheduled again once its runner recovers
+ *
+ * @param worker: worker whose runner changed health
+ * @param unhealthy: whether the runner fails its health checks
+ */
+func (is *IDunnoScheduler) OnWorkerHealth(worker *Worker, unhealthy bool) {
+	if worker.Unhealthy == unhealthy {
+		return
+	}
+
+	worker.Unhealthy = unhealthy
+	if !worker.Unhealthy {
+		logger.Info(fmt.Sprintf("Runner of worker %v recovered", worker.Process.Address()))
+		return
+	}
+
+	logger.Error(fmt.Sprintf("Runner of worker %v is unhealthy, releasing it from job %q", worker.Process.Address(), worker.JobId))
+	worker.ReleaseBatches(is.GetJob(worker.JobId))
+	worker.Reset()
+	worker.Online = false
+}
+
 func (is *IDunnoScheduler) OnWorkerLeaved(process *api.Process) {
 	logger.Info(fmt.Sprintf("Worker %v leaved", process.Address()))
 
-	// clear all workers
+	// worker is already drained, only release batch it may still hold
+	leavedWorker := is.ResourceManager.RemoveWorker(process)
+	if leavedWorker == nil {
+		return
+	}
+	is.OnWorkerDraining(leavedWorker)
+}
+
+// Drop all local scheduler state, i.e. when current process leaves the ring
+func (is *IDunnoScheduler) Reset() {
 	*is.ResourceManager = make(map[string]*Worker)
 	is.ActiveJobs = make(map[string]*api.Job)
+	is.PendingJobs = utils.NewQueue[*api.Job]()
+}
+
+func (is *IDunnoScheduler) OnPartitionMerged() {
+	logger.Info("Partition merged, dropping scheduler state of minority partition")
+
+	// workers are added back as they are discovered in the majority partition
+	is.Reset()
 }
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"mp4/api"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Workers stay on their job unless moving them makes the schedule fairer by more than the cost of reloading models
+func Test_Scheduler_DampSwitching(t *testing.T) {
+	assert := assert.New(t)
+
+	// moving a worker changes the expected query per second of nearly completed jobs marginally
+	tests := []struct {
+		name      string
+		completed int32
+		current   []int
+		expected  []int
+	}{
+		{"marginal gain keeps workers on their job", 9000, []int{6, 4}, []int{6, 4}},
+		{"unfair schedule is rebalanced", 100, []int{6, 4}, []int{5, 5}},
+		{"job without workers gets its share", 9000, []int{10, 0}, []int{5, 5}},
+		{"idle workers are allocated", 9000, []int{4, 4}, []int{5, 5}},
+	}
+
+	for _, test := range tests {
+		coordinator := newStandaloneCoordinator()
+		processes := addWorkers(coordinator, 10)
+		ids := []string{"a", "b"}
+		startTime := timestamppb.New(time.Now().Add(-100 * time.Second))
+		for i, id := range ids {
+			coordinator.Scheduler.AddJob(&api.Job{Id: id, Status: api.JobStatus_Running, TotalQueries: 10000, CompletedQueries: test.completed, StartTime: startTime})
+			for j := 0; j < test.current[i]; j++ {
+				coordinator.ResourceManager.GetWorker(processes[0].Address()).JobId = id
+				processes = processes[1:]
+			}
+		}
+
+		schedule := coordinator.Scheduler.RefreshSchedule(0)
+		for i, id := range ids {
+			assert.Equal(test.expected[i], coordinator.ResourceManager.GetWorkerCountById(id)+len(schedule[id]), test.name+": "+id)
+		}
+	}
+}
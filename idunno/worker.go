@@ -2,212 +2,572 @@ package main
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"mp4/api"
+	"mp4/connpool"
 	"mp4/logger"
 	"mp4/ring"
 	"mp4/sdfs"
 	"mp4/utils"
 	"os"
-	"os/exec"
-	"strconv"
 	"sync"
 	"time"
 
-	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
 )
 
 const RUNNER_PORT_OFFSET = 1000
+const RUNNER_HOST = "localhost"
 const RESTART_QUERY_INTERVAL = 1000 * time.Millisecond
 const QUERY_INTERVAL = 800 * time.Millisecond
 const QUERY_DATA_DEADLINE = 2500 * time.Millisecond
+const DRAIN_TIMEOUT = 60 * time.Second
+const PIPELINE_DEPTH = 2                       // batches a worker holds by default, one evaluating while the next one is prefetched
+const STATE_INTERVAL = 1000 * time.Millisecond // worker reports its state on its dispatch stream & checks the leader
 
 type IDunnoWorker struct {
-	ModelRunner *api.Process
-	JobId       string
-	BatchOutput *api.BatchOutput
-	SDFSClient  *sdfs.SDFSClient
-	Ring        *ring.RingServer
+	Runner       ModelRunner
+	JobId        string
+	Generation   int64                                 // incremented whenever the worker is assigned a job, written holding both locks
+	Draining     bool                                  // flag to indicate if this worker is leaving and no longer accepts new batches
+	Pipeline     *Pipeline                             // batches leased from the coordinator, from prefetching to submitting their output
+	Stream       api.CoordinatorService_DispatchClient // dispatch stream to the coordinator, nil while disconnected
+	StreamLock   sync.Mutex                            // guards stream, messages are sent one at a time
+	StateChanged chan struct{}                         // report state on the stream right away, i.e. job switched or draining
+	Model        string                                // model loaded by the runner, empty if none
+	Version      int32                                 // version of the loaded model
+	Spec         *api.ModelSpec                        // spec of the loaded model, reloaded if the runner restarts
+	Resident     []*api.ResidentModel                  // models kept resident by the runner, least recently used first
+	Capabilities *api.WorkerCapabilities               // resources & labels of the machine, advertised when it joins
+	Artifacts    map[string]int                        // local artifact -> fetches still loading it, deleted once the last one is done
+	ArtifactLock sync.Mutex                            // guards artifacts, train requests fetch them without the worker lock
+	ModelLock    sync.RWMutex                          // guards model, so that online requests never wait for the batch in progress
+	SDFSClient   *sdfs.SDFSClient
+	Ring         *ring.RingServer
 	api.WorkerServiceServer
 	sync.Mutex
 }
 
-func NewIDunnoWorker(hostname string, port int, ring *ring.RingServer, sdfsClient *sdfs.SDFSClient) *IDunnoWorker {
-	// initiate python gRPC server for model inference
-	runnerPort := port + RUNNER_PORT_OFFSET
-	runnerCmd := exec.Command("python3.10", "../inference/worker.py", "--port", strconv.Itoa(runnerPort), "--filepath", sdfsClient.GetLocalFilePath(""))
-	runnerCmd.Stderr = os.Stderr
-	// runnerCmd.Stdout = os.Stdout
-
-	// start python inference server
-	err := runnerCmd.Start()
+/*
+ * Create a worker with its model runner
+ *
+ * @param runnerKind: RUNNER_PYTHON or RUNNER_NATIVE
+ * @param memoryBudgetMb: memory the python runner keeps models resident in
+ * @return error: raise error if the model runner cannot be started
+ */
+func NewIDunnoWorker(hostname string, port int, ring *ring.RingServer, sdfsClient *sdfs.SDFSClient, runnerKind string, memoryBudgetMb int64) (*IDunnoWorker, error) {
+	runner, err := NewModelRunner(runnerKind, port+RUNNER_PORT_OFFSET, sdfsClient.GetLocalFilePath(""), memoryBudgetMb)
 	if err != nil {
-		fmt.Println("Failed to start inference service: " + err.Error())
-		panic(err)
+		logger.Error("Failed to start model runner: " + err.Error())
+		return nil, err
 	}
 
-	runner := &api.Process{
-		Ip:   hostname,
-		Port: int32(runnerPort),
+	worker := &IDunnoWorker{
+		Runner:       runner,
+		JobId:        utils.EMPTY_STRING,
+		Pipeline:     NewPipeline(PIPELINE_DEPTH),
+		StateChanged: make(chan struct{}, 1),
+		Artifacts:    make(map[string]int),
+		SDFSClient:   sdfsClient,
+		Ring:         ring,
 	}
+	runner.OnRestart(worker.ReloadModel)
+	return worker, nil
+}
+
+// Capabilities registered with coordinators, with the models the runner keeps resident, most recently used first
+func (iw *IDunnoWorker) AdvertisedCapabilities() *api.WorkerCapabilities {
+	iw.ModelLock.RLock()
+	defer iw.ModelLock.RUnlock()
 
-	return &IDunnoWorker{
-		ModelRunner: runner,
-		JobId:       utils.EMPTY_STRING,
-		BatchOutput: nil,
-		SDFSClient:  sdfsClient,
-		Ring:        ring,
+	capabilities := proto.Clone(iw.Capabilities).(*api.WorkerCapabilities)
+	for i := len(iw.Resident) - 1; i >= 0; i-- {
+		capabilities.Models = append(capabilities.Models, iw.Resident[i].GetModel())
 	}
+	return capabilities
 }
 
 func (iw *IDunnoWorker) Cron() {
-	go iw.QueryDataCycle()
+	go iw.DispatchCycle()
+	go iw.EvaluateCycle()
+	go iw.SubmitCycle()
 }
 
-func (iw *IDunnoWorker) QueryDataCycle() {
+// Hold a dispatch stream to the coordinator, reconnecting whenever it breaks or another coordinator is elected
+func (iw *IDunnoWorker) DispatchCycle() {
 	for {
-		err := iw.QueryData()
-		time.Sleep(QUERY_INTERVAL)
+		err := iw.Dispatch()
 		if err != nil {
-			time.Sleep(RESTART_QUERY_INTERVAL)
+			logger.Error("Dispatch stream closed: " + err.Error())
 		}
+		time.Sleep(RESTART_QUERY_INTERVAL)
+	}
+}
+
+// Evaluate leased batches one at a time, so that the runner never waits for SDFS or the coordinator
+func (iw *IDunnoWorker) EvaluateCycle() {
+	for batch := range iw.Pipeline.Leased {
+		iw.EvaluateBatch(batch)
+		iw.Pipeline.Evaluated <- batch
+	}
+}
+
+// Submit outputs of evaluated batches to the coordinator, apart from receiving new batches
+func (iw *IDunnoWorker) SubmitCycle() {
+	for batch := range iw.Pipeline.Evaluated {
+		iw.SubmitBatch(batch)
+		iw.Pipeline.Release()
+		iw.NotifyState()
 	}
 }
 
-func (iw *IDunnoWorker) QueryData() error {
-	// coordinator client
-	coordClient, closeCoord, err := iw.CreateCoordinatorClient()
+/*
+ * Open a dispatch stream to the coordinator and receive the batches it pushes, prefetching their inputs
+ * while earlier batches evaluate
+ *
+ * @return error: reason the stream ended, i.e. the coordinator failed or is no longer the leader
+ */
+func (iw *IDunnoWorker) Dispatch() error {
+	leaderAddr, err := iw.Ring.LookupLeader()
 	if err != nil {
-		logger.Error("Failed to create coordinator client: " + err.Error())
+		logger.Error("Failed to lookup leader")
 		return err
 	}
-	defer closeCoord()
 
-	// inference client
-	runnerClient, closeRunner, err := iw.CreateInferenceClient()
+	conn, err := connpool.POOL.Get(leaderAddr, sdfs.GRPC_OPTIONS...)
+	if err != nil {
+		logger.Error("Failed to connect to coordinator: " + err.Error())
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := api.NewCoordinatorServiceClient(conn).Dispatch(ctx)
 	if err != nil {
-		logger.Error("Failed to create inference client: " + err.Error())
+		logger.Error("Failed to open dispatch stream: " + err.Error())
 		return err
 	}
-	defer closeRunner()
+	iw.SetStream(stream)
+	defer iw.SetStream(nil)
+	logger.Info(fmt.Sprintf("Worker %v opened dispatch stream to %v", iw.Ring.Address(), leaderAddr))
+
+	// report state periodically & when it changes, and reconnect once another coordinator is elected
+	go func() {
+		ticker := time.NewTicker(STATE_INTERVAL)
+		defer ticker.Stop()
+		for {
+			if err := iw.SendState(); err != nil {
+				cancel()
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-iw.StateChanged:
+			case <-ticker.C:
+				if addr, err := iw.Ring.LookupLeader(); err == nil && addr != leaderAddr {
+					logger.Info(fmt.Sprintf("Coordinator moved from %v to %v, reconnecting", leaderAddr, addr))
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		res, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		if res.GetDrained() {
+			iw.OnDrained(res.GetJobId())
+			continue
+		}
+		if res.GetReleased() {
+			iw.OnReleased(res.GetJobId())
+			continue
+		}
+		if res.GetBatchInput() == nil {
+			continue
+		}
+
+		iw.Pipeline.Acquire()
+		batch := iw.PrepareBatch(res)
+		if batch == nil {
+			iw.Pipeline.Release()
+			continue
+		}
+		iw.Pipeline.Leased <- batch
+	}
+}
 
+/*
+ * Report the worker's state on its dispatch stream. A draining worker takes no new batch,
+ * and asks to be released from its job once its pipeline is empty
+ *
+ * @return error: raise error if the stream is broken
+ */
+func (iw *IDunnoWorker) SendState() error {
+	iw.Lock()
+	jobId, draining := iw.JobId, iw.Draining
+	iw.Unlock()
+
+	return iw.Send(&api.QueryDataRequest{
+		JobId:           jobId,
+		Worker:          iw.Ring.Process,
+		Resident:        iw.ResidentModels(),
+		PipelineDepth:   int32(iw.Pipeline.Depth),
+		SubmitOnly:      draining,
+		Draining:        draining && jobId != utils.EMPTY_STRING && iw.Pipeline.InFlight() == 0,
+		RunnerUnhealthy: !iw.Runner.Healthy(),
+	})
+}
+
+// Report state on the stream right away, without blocking the caller
+func (iw *IDunnoWorker) NotifyState() {
+	notify(iw.StateChanged)
+}
+
+func (iw *IDunnoWorker) SetStream(stream api.CoordinatorService_DispatchClient) {
+	iw.StreamLock.Lock()
+	defer iw.StreamLock.Unlock()
+	iw.Stream = stream
+}
+
+// Send a message on the dispatch stream, messages of concurrent senders never interleave
+func (iw *IDunnoWorker) Send(req *api.QueryDataRequest) error {
+	iw.StreamLock.Lock()
+	defer iw.StreamLock.Unlock()
+
+	if iw.Stream == nil {
+		return fmt.Errorf("no dispatch stream to coordinator")
+	}
+	return iw.Stream.Send(req)
+}
+
+// Draining worker is released from its job by the coordinator
+func (iw *IDunnoWorker) OnDrained(jobId string) {
 	iw.Lock()
 	defer iw.Unlock()
 
-	// stop querying if no job is assigned
-	if iw.JobId == utils.EMPTY_STRING {
-		time.Sleep(time.Second)
-		return nil
+	if iw.Draining && iw.JobId == jobId {
+		logger.Info(fmt.Sprintf("Worker %v drained from job %v", iw.Ring.Address(), jobId))
+		iw.JobId = utils.EMPTY_STRING
 	}
+}
 
-	// set up context deadline
-	ctx, cancel := context.WithTimeout(context.Background(), QUERY_DATA_DEADLINE)
-	defer cancel()
+// Worker is released from its job by the coordinator, i.e. its runner turned unhealthy or its version has no batch left,
+// and waits to be scheduled again
+func (iw *IDunnoWorker) OnReleased(jobId string) {
+	iw.Lock()
+	defer iw.Unlock()
 
-	// query data from coordinator
-	res, err := coordClient.QueryData(ctx, &api.QueryDataRequest{
-		JobId:       iw.JobId,
-		Worker:      iw.Ring.Process,
-		BatchOutput: iw.BatchOutput,
-	})
-	if err != nil {
-		return err
+	if iw.JobId == jobId {
+		logger.Info(fmt.Sprintf("Worker %v released from job %v", iw.Ring.Address(), jobId))
+		iw.JobId = utils.EMPTY_STRING
+		iw.NotifyState()
 	}
+}
 
-	// no new batch is given, skip current round
-	if res.GetBatchInput() == nil {
+/*
+ * Fetch the inputs of a batch pushed by the coordinator
+ *
+ * @param res: batch pushed on the dispatch stream with its lease
+ * @return *LeasedBatch: batch with its inputs ready to evaluate, nil if it is not of the worker's current job
+ */
+func (iw *IDunnoWorker) PrepareBatch(res *api.QueryDataResponse) *LeasedBatch {
+	iw.Lock()
+	jobId, generation := iw.JobId, iw.Generation
+	iw.Unlock()
+
+	if res.GetJobId() != jobId {
+		logger.Info(fmt.Sprintf("Dropped batch %v of job %v, worker is assigned to job %q", res.GetBatchInput().GetBatchId(), res.GetJobId(), jobId))
 		return nil
 	}
 
-	if res.GetBatchInput().GetInputs() == nil {
-		iw.BatchOutput = &api.BatchOutput{
-			BatchId: res.GetBatchInput().GetBatchId(),
-			Results: nil,
-			Metric:  0,
-		}
-		return nil
+	batch := &LeasedBatch{
+		JobId:      jobId,
+		Generation: generation,
+		BatchInput: res.GetBatchInput(),
+		Lease:      res.GetLease(),
+		IsFilename: res.GetIsFilename(),
+		Inputs:     res.GetBatchInput().GetInputs(),
+	}
+	if batch.IsFilename && batch.Inputs != nil {
+		batch.Inputs = iw.FetchInputs(res.GetBatchInput().GetInputs())
 	}
+	return batch
+}
 
-	modelInputs := make([]string, 0)
-	if res.GetIsFilename() {
-		wg := sync.WaitGroup{}
-
-		// fetch all SDFS filenmaes into local file system
-		for _, input := range res.GetBatchInput().GetInputs() {
-			wg.Add(1)
-			go func(input string) {
-				defer wg.Done()
-
-				// fetch file from SDFS
-				err := iw.SDFSClient.Get(input, input, sdfs.LATEST_VERSION)
-				if err != nil {
-					logger.Error("Worker failed to fetch SDFS file " + input + ": " + err.Error())
-					return
-				}
+// Evaluate a leased batch with the loaded model, batches leased before the worker switched job are dropped
+func (iw *IDunnoWorker) EvaluateBatch(batch *LeasedBatch) {
+	// runner must not switch to another model while evaluating the inputs
+	iw.ModelLock.RLock()
+	defer iw.ModelLock.RUnlock()
+
+	// batches leased before the worker switched job are dropped, generation only changes with the model lock held
+	if iw.Generation != batch.Generation {
+		logger.Info(fmt.Sprintf("Dropped batch %v of job %v, worker switched job", batch.BatchInput.GetBatchId(), batch.JobId))
+		return
+	}
 
-				modelInputs = append(modelInputs, iw.SDFSClient.GetLocalFilePath(input))
-			}(input)
+	if batch.Inputs == nil {
+		batch.Output = &api.BatchOutput{
+			BatchId: batch.BatchInput.GetBatchId(),
+			Results: nil,
+			Metric:  0,
 		}
-		wg.Wait()
-
-		defer func() {
-			logger.Info("Cleaning up temp files")
-			// delete all files in local file system
-			for _, file := range res.GetBatchInput().GetInputs() {
-				err := iw.SDFSClient.DeleteLocalFile(file)
-				if err != nil {
-					logger.Error("Worker failed to delete local file " + file + ": " + err.Error())
-				}
-			}
-			logger.Info("Done cleaning up temp files")
-		}()
-	} else {
-		modelInputs = res.GetBatchInput().GetInputs()
+		return
 	}
 
-	evalRes, err := runnerClient.Evaluate(context.Background(), &api.EvaluateRequest{
-		Inputs: modelInputs,
+	evalRes, err := iw.Runner.Evaluate(context.Background(), &api.EvaluateRequest{
+		Inputs: batch.Inputs,
 	})
 	if err != nil {
 		logger.Error("Worker failed to evaluate model: " + err.Error())
-		return err
+		batch.Failed = true
+		return
 	}
 	if evalRes.GetStatus() != api.ResponseStatus_OK {
 		logger.Error("Worker failed to evaluate model: " + evalRes.GetStatus().String())
-		return errors.New(evalRes.GetStatus().String())
+		batch.Failed = true
+		return
 	}
 
-	iw.BatchOutput = &api.BatchOutput{
-		BatchId: res.GetBatchInput().GetBatchId(),
+	batch.Output = &api.BatchOutput{
+		BatchId: batch.BatchInput.GetBatchId(),
 		Results: evalRes.GetResults(),
 		Metric:  evalRes.GetMetric(),
 	}
-	return nil
 }
 
-func (iw *IDunnoWorker) CreateInferenceClient() (api.InferenceServiceClient, func(), error) {
-	conn, err := grpc.Dial(iw.ModelRunner.Address(), sdfs.GRPC_OPTIONS...)
+// Submit the output of an evaluated batch with its lease on the dispatch stream, the lease of a failed batch is
+// released so that the batch is handed out again right away. Batches dropped on a job switch are left to expire
+func (iw *IDunnoWorker) SubmitBatch(batch *LeasedBatch) {
+	if batch.IsFilename {
+		defer iw.CleanUpInputs(batch.BatchInput.GetInputs())
+	}
+	if batch.Output == nil && !batch.Failed {
+		return
+	}
+
+	output := batch.Output
+	if batch.Failed {
+		logger.Info(fmt.Sprintf("Worker releasing lease of failed batch %v of job %v", batch.BatchInput.GetBatchId(), batch.JobId))
+		output = &api.BatchOutput{BatchId: batch.BatchInput.GetBatchId()}
+	}
+
+	// output is accepted or rejected by coordinator, never submit it again
+	req := &api.QueryDataRequest{
+		JobId:           batch.JobId,
+		Worker:          iw.Ring.Process,
+		BatchOutput:     output,
+		LeaseId:         batch.Lease.GetId(),
+		Resident:        iw.ResidentModels(),
+		SubmitOnly:      true,
+		RunnerUnhealthy: !iw.Runner.Healthy(),
+		Failed:          batch.Failed,
+	}
+	if err := iw.Send(req); err == nil {
+		return
+	}
+
+	// stream is reconnecting, submit the output before its lease expires
+	coordClient, err := iw.CreateCoordinatorClient()
 	if err != nil {
-		logger.Error("Failed to connect to inference service: " + err.Error())
-		return nil, nil, err
+		logger.Error("Failed to create coordinator client: " + err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), QUERY_DATA_DEADLINE)
+	defer cancel()
+
+	_, err = coordClient.QueryData(ctx, req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Worker failed to submit output of batch %v of job %v: %v", batch.BatchInput.GetBatchId(), batch.JobId, err))
+	}
+}
+
+// Models kept resident by the runner, least recently used first
+func (iw *IDunnoWorker) ResidentModels() []*api.ResidentModel {
+	iw.ModelLock.RLock()
+	defer iw.ModelLock.RUnlock()
+	return iw.Resident
+}
+
+// Fetch SDFS files into local file system, return local paths of the fetched files
+func (iw *IDunnoWorker) FetchInputs(filenames []string) []string {
+	modelInputs := make([]string, 0)
+	wg, mu := sync.WaitGroup{}, sync.Mutex{}
+
+	for _, input := range filenames {
+		wg.Add(1)
+		go func(input string) {
+			defer wg.Done()
+
+			// fetch file from SDFS
+			err := iw.SDFSClient.Get(input, input, sdfs.LATEST_VERSION)
+			if err != nil {
+				logger.Error("Worker failed to fetch SDFS file " + input + ": " + err.Error())
+				return
+			}
+
+			mu.Lock()
+			modelInputs = append(modelInputs, iw.SDFSClient.GetLocalFilePath(input))
+			mu.Unlock()
+		}(input)
+	}
+	wg.Wait()
+
+	return modelInputs
+}
+
+/*
+ * Fetch the artifact of a registered model from SDFS, so that the runner can load it
+ *
+ * @param spec: spec of the model, built-in models have no artifact, native models are trained on it
+ * @return string: local path of the artifact, empty if the model has none
+ * @return func(): delete the local artifact once the runner loaded it
+ */
+func (iw *IDunnoWorker) FetchArtifact(spec *api.ModelSpec) (string, func(), error) {
+	if spec.GetArtifact() == utils.EMPTY_STRING {
+		return utils.EMPTY_STRING, func() {}, nil
+	}
+
+	// native models are trained on their artifact, python models import it
+	localFile := fmt.Sprintf("%v-v%v.py", spec.GetName(), spec.GetVersion())
+	if NATIVE_FRAMEWORKS[spec.GetFramework()] {
+		localFile = fmt.Sprintf("%v-v%v.txt", spec.GetName(), spec.GetVersion())
+	}
+
+	// fetched under a temp name and renamed into place, the runner never reads a partially written artifact
+	tempFile := fmt.Sprintf("%v.%v.tmp", localFile, time.Now().UnixNano())
+	err := iw.SDFSClient.Get(tempFile, spec.GetArtifact(), sdfs.LATEST_VERSION)
+	if err != nil {
+		logger.Error("Worker failed to fetch model artifact " + spec.GetArtifact() + ": " + err.Error())
+		iw.SDFSClient.DeleteLocalFile(tempFile)
+		return utils.EMPTY_STRING, nil, err
+	}
+
+	iw.ArtifactLock.Lock()
+	defer iw.ArtifactLock.Unlock()
+	err = os.Rename(iw.SDFSClient.GetLocalFilePath(tempFile), iw.SDFSClient.GetLocalFilePath(localFile))
+	if err != nil {
+		logger.Error("Worker failed to move model artifact " + spec.GetArtifact() + " into place: " + err.Error())
+		iw.SDFSClient.DeleteLocalFile(tempFile)
+		return utils.EMPTY_STRING, nil, err
+	}
+	iw.Artifacts[localFile]++
+
+	return iw.SDFSClient.GetLocalFilePath(localFile), func() { iw.ReleaseArtifact(localFile) }, nil
+}
+
+// Delete a local artifact once no fetch is loading it anymore
+func (iw *IDunnoWorker) ReleaseArtifact(localFile string) {
+	iw.ArtifactLock.Lock()
+	defer iw.ArtifactLock.Unlock()
+
+	iw.Artifacts[localFile]--
+	if iw.Artifacts[localFile] > 0 {
+		return
+	}
+	delete(iw.Artifacts, localFile)
+	iw.SDFSClient.DeleteLocalFile(localFile)
+}
+
+// Load the model of the assigned job again once the runner restarted without it
+func (iw *IDunnoWorker) ReloadModel() {
+	iw.Lock()
+	defer iw.Unlock()
+	iw.ModelLock.Lock()
+	defer iw.ModelLock.Unlock()
+
+	iw.Resident = nil
+	if iw.Model == utils.EMPTY_STRING {
+		return
+	}
+
+	artifactPath, cleanUp, err := iw.FetchArtifact(iw.Spec)
+	if err == nil {
+		defer cleanUp()
+		var res *api.ServeModelResponse
+		res, err = iw.Runner.Serve(context.Background(), &api.ServeModelRequest{Model: iw.Model, Spec: iw.Spec, ArtifactPath: artifactPath})
+		if err == nil && res.GetStatus() != api.ResponseStatus_OK {
+			err = fmt.Errorf("runner responded %v", res.GetStatus())
+		}
+		if err == nil {
+			iw.Resident = res.GetResident()
+			logger.Info(fmt.Sprintf("Worker %v reloaded model %v version %v after its runner restarted", iw.Ring.Address(), iw.Model, iw.Version))
+			return
+		}
+	}
+
+	// batches fail to evaluate and their leases expire, so that other workers of the job take them
+	logger.Error(fmt.Sprintf("Worker %v failed to reload model %v after its runner restarted: %v", iw.Ring.Address(), iw.Model, err))
+	iw.Model = utils.EMPTY_STRING
+	iw.Spec = nil
+}
+
+// Delete fetched SDFS files from local file system
+func (iw *IDunnoWorker) CleanUpInputs(filenames []string) {
+	logger.Info("Cleaning up temp files")
+	for _, file := range filenames {
+		err := iw.SDFSClient.DeleteLocalFile(file)
+		if err != nil {
+			logger.Error("Worker failed to delete local file " + file + ": " + err.Error())
+		}
+	}
+	logger.Info("Done cleaning up temp files")
+}
+
+func (iw *IDunnoWorker) SetDraining(draining bool) {
+	iw.Lock()
+	defer iw.Unlock()
+
+	iw.Draining = draining
+	iw.NotifyState()
+}
+
+/*
+ * Stop accepting new batches and wait for the current batch to be processed and
+ * its output to be submitted to the coordinator
+ *
+ * @return error: raise error if the worker is not drained before DRAIN_TIMEOUT
+ */
+func (iw *IDunnoWorker) Drain() error {
+	iw.SetDraining(true)
+	logger.Info("Draining worker " + iw.Ring.Address())
+
+	deadline := time.Now().Add(DRAIN_TIMEOUT)
+	for time.Now().Before(deadline) {
+		iw.Lock()
+		drained := iw.JobId == utils.EMPTY_STRING
+		iw.Unlock()
+
+		if drained {
+			return nil
+		}
+		time.Sleep(QUERY_INTERVAL)
 	}
 
-	return api.NewInferenceServiceClient(conn), func() { conn.Close() }, nil
+	return fmt.Errorf("worker %v is not drained after %v", iw.Ring.Address(), DRAIN_TIMEOUT)
 }
 
-func (iw *IDunnoWorker) CreateCoordinatorClient() (api.CoordinatorServiceClient, func(), error) {
+func (iw *IDunnoWorker) CreateCoordinatorClient() (api.CoordinatorServiceClient, error) {
 	leaderAddr, err := iw.Ring.LookupLeader()
 	if err != nil {
 		logger.Error("Failed to lookup leader")
-		return nil, nil, err
+		return nil, err
 	}
 
-	conn, err := grpc.Dial(leaderAddr, sdfs.GRPC_OPTIONS...)
+	conn, err := connpool.POOL.Get(leaderAddr, sdfs.GRPC_OPTIONS...)
 	if err != nil {
 		logger.Error("Failed to connect to inference service: " + err.Error())
-		return nil, nil, err
+		return nil, err
 	}
 
-	return api.NewCoordinatorServiceClient(conn), func() { conn.Close() }, nil
+	return api.NewCoordinatorServiceClient(conn), nil
 }
@@ -8,24 +8,30 @@ import (
 	"mp4/utils"
 )
 
+// Coordinators fetch capabilities once the worker joins, they are not gossiped with the membership list
+func (iw *IDunnoWorker) FetchCapabilities(ctx context.Context, req *api.FetchCapabilitiesRequest) (*api.FetchCapabilitiesResponse, error) {
+	return &api.FetchCapabilitiesResponse{Capabilities: iw.AdvertisedCapabilities()}, nil
+}
+
 func (iw *IDunnoWorker) Train(ctx context.Context, req *api.TrainRequest) (*api.TrainResponse, error) {
 	logger.Info(fmt.Sprintf("Worker %v received Train request - Model: %v, Dataset: %v", iw.Ring.Address(), req.GetTrainTask().GetModel(), req.GetTrainTask().GetDataset()))
 
-	// Create runner client
-	client, close, err := iw.CreateInferenceClient()
+	// registered models are loaded from their artifact
+	artifactPath, cleanUp, err := iw.FetchArtifact(req.GetSpec())
 	if err != nil {
 		return nil, err
 	}
-	defer close()
+	defer cleanUp()
+	req.ArtifactPath = artifactPath
 
 	// Send train request to runner
-	logger.Info(fmt.Sprintf("Worker sending Train request to runner: %v", iw.ModelRunner.Address()))
-	res, err := client.Train(context.Background(), req)
+	logger.Info(fmt.Sprintf("Worker sending Train request to runner: %v", iw.Runner.Name()))
+	res, err := iw.Runner.Train(context.Background(), req)
 	if err != nil || res.GetStatus() == api.ResponseStatus_ERROR {
 		logger.Error(fmt.Sprintf("Error sending Train request to runner: %v", res.GetStatus()))
 		return nil, fmt.Errorf("error sending train request to inference service")
 	}
-	logger.Info(fmt.Sprintf("Worker %v completed training", iw.ModelRunner.Address()))
+	logger.Info(fmt.Sprintf("Worker %v completed training", iw.Runner.Name()))
 
 	return res, nil
 }
@@ -37,35 +43,141 @@ func (iw *IDunnoWorker) Inference(ctx context.Context, req *api.InferenceRequest
 	task := req.GetInferenceTask()
 	logger.Info(fmt.Sprintf("Worker %v received Inference request - Model: %v, BatchSize: %v", iw.Ring.Address(), task.GetModel(), task.GetBatchSize()))
 
-	client, close, err := iw.CreateInferenceClient()
+	// leaving worker does not take new jobs
+	if iw.Draining {
+		logger.Error(fmt.Sprintf("Worker %v is draining, refuse inference request", iw.Ring.Address()))
+		return &api.InferenceResponse{Status: api.ResponseStatus_ERROR}, nil
+	}
+
+	// wait for online requests on the current model before the runner switches model
+	iw.ModelLock.Lock()
+	defer iw.ModelLock.Unlock()
+
+	res, err := iw.LoadModel(task.GetModel(), req.GetSpec())
+	if err != nil {
+		return nil, err
+	}
+
+	// update inference job & reset previous batch output
+	iw.JobId = req.GetJobId()
+	iw.Generation++
+	iw.NotifyState()
+
+	logger.Info(fmt.Sprintf("Worker %v started inference task, model was resident: %v", iw.Ring.Address(), res.GetWarm()))
+	return &api.InferenceResponse{Status: api.ResponseStatus_OK}, nil
+}
+
+// Load a model to serve online requests, coordinators only reserve idle workers so the job & generation are kept
+func (iw *IDunnoWorker) ServeModel(ctx context.Context, req *api.ServeModelRequest) (*api.ServeModelResponse, error) {
+	iw.Lock()
+	defer iw.Unlock()
+
+	logger.Info(fmt.Sprintf("Worker %v received ServeModel request - Model: %v, Version: %v", iw.Ring.Address(), req.GetModel(), req.GetSpec().GetVersion()))
+	if iw.Draining {
+		logger.Error(fmt.Sprintf("Worker %v is draining, refuse to serve model %v", iw.Ring.Address(), req.GetModel()))
+		return &api.ServeModelResponse{Status: api.ResponseStatus_ERROR}, nil
+	}
+
+	// wait for online requests on the current model before the runner switches model
+	iw.ModelLock.Lock()
+	defer iw.ModelLock.Unlock()
+
+	return iw.LoadModel(req.GetModel(), req.GetSpec())
+}
+
+/*
+ * Load a model on the runner, must hold the worker & model locks
+ *
+ * @param model: name of the model
+ * @param spec: version of the model to load
+ * @return *api.ServeModelResponse: response of the runner, with the models it keeps resident
+ * @return error: raise error if the artifact cannot be fetched or the runner fails to load the model
+ */
+func (iw *IDunnoWorker) LoadModel(model string, spec *api.ModelSpec) (*api.ServeModelResponse, error) {
+	artifactPath, cleanUp, err := iw.FetchArtifact(spec)
 	if err != nil {
 		return nil, err
 	}
-	defer close()
+	defer cleanUp()
 
-	logger.Info(fmt.Sprintf("Worker %v sending Inference request to runner", iw.ModelRunner.Address()))
-	res, err := client.ServeModel(context.Background(), &api.ServeModelRequest{
-		Model: task.GetModel(),
+	logger.Info(fmt.Sprintf("Worker sending ServeModel request to runner: %v", iw.Runner.Name()))
+	res, err := iw.Runner.Serve(context.Background(), &api.ServeModelRequest{
+		Model:        model,
+		Spec:         spec,
+		ArtifactPath: artifactPath,
 	})
 	if err != nil {
-		logger.Error(fmt.Sprintf("Error sending Inference request to runner: %v", err))
-		return nil, fmt.Errorf("error sending inference request to inference service")
+		logger.Error(fmt.Sprintf("Error sending ServeModel request to runner: %v", err))
+		return nil, fmt.Errorf("error sending serve request to inference service")
 	}
 	if res.GetStatus() != api.ResponseStatus_OK {
-		logger.Error(fmt.Sprintf("Error sending Inference request to runner: %v", res.GetStatus()))
-		return nil, fmt.Errorf("error sending inference request to inference service")
+		iw.Model = utils.EMPTY_STRING
+		iw.Spec = nil
+		logger.Error(fmt.Sprintf("Error sending ServeModel request to runner: %v", res.GetStatus()))
+		return nil, fmt.Errorf("error sending serve request to inference service")
 	}
 
-	// update inference job & reset previous batch output
-	iw.JobId = req.GetJobId()
-	iw.BatchOutput = nil
-
-	logger.Info(fmt.Sprintf("Worker %v started inference task", iw.Ring.Address()))
-	return &api.InferenceResponse{Status: api.ResponseStatus_OK}, nil
+	iw.Model = model
+	iw.Version = spec.GetVersion()
+	iw.Spec = spec
+	iw.Resident = res.GetResident()
+	return res, nil
 }
 
 func (iw *IDunnoWorker) FinishInference(ctx context.Context, req *api.FinishInferenceRequest) (*api.FinishInferenceResponse, error) {
-	iw.BatchOutput = nil
+	iw.Lock()
+	defer iw.Unlock()
+	iw.ModelLock.Lock()
+	defer iw.ModelLock.Unlock()
+
+	// batches left in the pipeline are dropped
 	iw.JobId = utils.EMPTY_STRING
+	iw.Generation++
+	iw.NotifyState()
 	return &api.FinishInferenceResponse{}, nil
 }
+
+/*
+ * Evaluate online inputs with the loaded model. Batch inputs are processed with the worker lock held,
+ * so online requests only hold the model lock and run on the runner next to the batch in progress
+ *
+ * @param req: model & inputs to evaluate, inputs are SDFS filenames if isFilename is set
+ * @return *api.PredictResponse: results of the inputs, ERROR if the model is not loaded
+ */
+func (iw *IDunnoWorker) Predict(ctx context.Context, req *api.PredictRequest) (*api.PredictResponse, error) {
+	logger.Info(fmt.Sprintf("Worker %v received Predict request - Model: %v, Inputs: %v", iw.Ring.Address(), req.GetModel(), len(req.GetInputs())))
+
+	// runner must not switch to another model while evaluating the inputs
+	iw.ModelLock.RLock()
+	defer iw.ModelLock.RUnlock()
+
+	if iw.Model != req.GetModel() || iw.Version != req.GetVersion() {
+		logger.Error(fmt.Sprintf("Worker %v is serving model %q version %v, refuse predict request of model %v version %v", iw.Ring.Address(), iw.Model, iw.Version, req.GetModel(), req.GetVersion()))
+		return &api.PredictResponse{
+			Status:  api.ResponseStatus_ERROR,
+			Message: fmt.Sprintf("worker is not serving version %v of model %v", req.GetVersion(), req.GetModel()),
+		}, nil
+	}
+
+	modelInputs := req.GetInputs()
+	if req.GetIsFilename() {
+		modelInputs = iw.FetchInputs(req.GetInputs())
+		defer iw.CleanUpInputs(req.GetInputs())
+	}
+
+	evalRes, err := iw.Runner.Evaluate(ctx, &api.EvaluateRequest{Inputs: modelInputs})
+	if err != nil {
+		logger.Error("Worker failed to evaluate online inputs: " + err.Error())
+		return nil, err
+	}
+	if evalRes.GetStatus() != api.ResponseStatus_OK {
+		logger.Error("Worker failed to evaluate online inputs: " + evalRes.GetStatus().String())
+		return &api.PredictResponse{Status: evalRes.GetStatus()}, nil
+	}
+
+	return &api.PredictResponse{
+		Status:  api.ResponseStatus_OK,
+		Results: evalRes.GetResults(),
+		Worker:  iw.Ring.Address(),
+	}, nil
+}
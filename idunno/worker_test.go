@@ -0,0 +1,93 @@
+package main
+
+import (
+	"mp4/api"
+	"mp4/ring"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// worker without a runner, holding the given job if any
+func newWorker(jobId string) *IDunnoWorker {
+	return &IDunnoWorker{
+		JobId:        jobId,
+		Pipeline:     NewPipeline(PIPELINE_DEPTH),
+		StateChanged: make(chan struct{}, 1),
+		Artifacts:    make(map[string]int),
+		Ring:         ring.NewRingServer(nil, "127.0.0.1", 3000),
+		Runner:       NewNativeRunner(0),
+	}
+}
+
+// dispatch stream recording the states a worker sends to the coordinator
+type recordingStream struct {
+	api.CoordinatorService_DispatchClient
+	sent []*api.QueryDataRequest
+}
+
+func (rs *recordingStream) Send(req *api.QueryDataRequest) error {
+	rs.sent = append(rs.sent, req)
+	return nil
+}
+
+func Test_Worker_Drain(t *testing.T) {
+	assert := assert.New(t)
+
+	idle := newWorker("")
+	assert.Nil(idle.Drain())
+	assert.True(idle.Draining)
+	assert.Len(idle.StateChanged, 1, "coordinator should be told the worker is draining")
+
+	// drain waits for the coordinator to release the worker from its job
+	busy := newWorker("job")
+	released := make(chan struct{})
+	go func() {
+		defer close(released)
+		time.Sleep(QUERY_INTERVAL / 2)
+		busy.OnDrained("job")
+	}()
+	start := time.Now()
+	assert.Nil(busy.Drain())
+	<-released
+	assert.Empty(busy.JobId)
+	assert.GreaterOrEqual(time.Since(start), QUERY_INTERVAL/2)
+
+	// worker serves again once it rejoins
+	busy.SetDraining(false)
+	assert.False(busy.Draining)
+}
+
+// Draining worker keeps submitting the batches in its pipeline, and asks to be released once they are submitted
+func Test_Worker_DrainWithBatchesInFlight(t *testing.T) {
+	assert := assert.New(t)
+
+	worker := newWorker("job")
+	stream := &recordingStream{}
+	worker.SetStream(stream)
+	worker.Pipeline.Acquire()
+	worker.Pipeline.Acquire()
+	worker.SetDraining(true)
+
+	assert.Nil(worker.SendState())
+	worker.Pipeline.Release()
+	assert.Nil(worker.SendState())
+	worker.Pipeline.Release()
+	assert.Nil(worker.SendState())
+
+	if assert.Len(stream.sent, 3) {
+		for _, state := range stream.sent {
+			assert.True(state.GetSubmitOnly(), "draining worker should take no new batch")
+			assert.Equal("job", state.GetJobId())
+		}
+		assert.False(stream.sent[0].GetDraining(), "batches are still in flight")
+		assert.False(stream.sent[1].GetDraining(), "a batch is still in flight")
+		assert.True(stream.sent[2].GetDraining(), "pipeline is empty")
+	}
+
+	// idle worker has nothing to be released from
+	worker.OnDrained("job")
+	assert.Nil(worker.SendState())
+	assert.False(stream.sent[3].GetDraining())
+}
@@ -14,7 +14,7 @@ _sym_db = _symbol_database.Default()
 from google.protobuf import timestamp_pb2 as google_dot_protobuf_dot_timestamp__pb2
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\tapi.proto\x12\x03\x61pi\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa2\x01\n\x07Process\x12\n\n\x02ip\x18\x01 \x01(\t\x12\x0c\n\x04port\x18\x02 \x01(\x05\x12,\n\x08joinTime\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x32\n\x0elastUpdateTime\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x1b\n\x06status\x18\x05 \x01(\x0e\x32\x0b.api.Status\"S\n\x07WriteId\x12\n\n\x02ip\x18\x01 \x01(\t\x12\x0c\n\x04port\x18\x02 \x01(\x05\x12.\n\ncreateTime\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\".\n\x0bPingMessage\x12\x1f\n\tprocesses\x18\x01 \x03(\x0b\x32\x0c.api.Process\"\x1e\n\nAckMessage\x12\x10\n\x08received\x18\x01 \x01(\t\",\n\x0bJoinMessage\x12\x1d\n\x07process\x18\x01 \x01(\x0b\x32\x0c.api.Process\"-\n\x0cLeaveMessage\x12\x1d\n\x07process\x18\x01 \x01(\x0b\x32\x0c.api.Process\"\xbd\x01\n\x08Metadata\x12\x1e\n\x04type\x18\x01 \x01(\x0e\x32\x10.api.MessageType\x12 \n\x04ping\x18\x02 \x01(\x0b\x32\x10.api.PingMessageH\x00\x12\x1e\n\x03\x61\x63k\x18\x03 \x01(\x0b\x32\x0f.api.AckMessageH\x00\x12 \n\x04join\x18\x04 \x01(\x0b\x32\x10.api.JoinMessageH\x00\x12\"\n\x05leave\x18\x05 \x01(\x0b\x32\x11.api.LeaveMessageH\x00\x42\t\n\x07message\"C\n\x08Sequence\x12(\n\x04time\x18\x01 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\r\n\x05\x63ount\x18\x02 \x01(\x05\"p\n\x0bReadRequest\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x0f\n\x07version\x18\x02 \x01(\x05\x12\x15\n\rlocalFilename\x18\x04 \x01(\t\x12\x1f\n\x03seq\x18\x03 \x01(\x0b\x32\r.api.SequenceH\x00\x88\x01\x01\x42\x06\n\x04_seq\"j\n\x0cReadResponse\x12\x0c\n\x04\x64\x61ta\x18\x01 \x01(\x0c\x12#\n\x06status\x18\x02 \x01(\x0e\x32\x13.api.ResponseStatus\x12\x1f\n\x03seq\x18\x03 \x01(\x0b\x32\r.api.SequenceH\x00\x88\x01\x01\x42\x06\n\x04_seq\"v\n\x0cWriteRequest\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x0c\n\x04\x64\x61ta\x18\x02 \x01(\x0c\x12\x1d\n\x07writeId\x18\x03 \x01(\x0b\x32\x0c.api.WriteId\x12\x1f\n\x03seq\x18\x04 \x01(\x0b\x32\r.api.SequenceH\x00\x88\x01\x01\x42\x06\n\x04_seq\"4\n\rWriteResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\"J\n\rDeleteRequest\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x1f\n\x03seq\x18\x02 \x01(\x0b\x32\r.api.SequenceH\x00\x88\x01\x01\x42\x06\n\x04_seq\"5\n\x0e\x44\x65leteResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\"J\n\rLookupRequest\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x1f\n\x03seq\x18\x02 \x01(\x0b\x32\r.api.SequenceH\x00\x88\x01\x01\x42\x06\n\x04_seq\"O\n\x0eLookupResponse\x12\n\n\x02ip\x18\x01 \x01(\t\x12\x0c\n\x04port\x18\x02 \x01(\x05\x12#\n\x06status\x18\x03 \x01(\x0e\x32\x13.api.ResponseStatus\"O\n\x11\x42ulkLookupRequest\x12\x11\n\tfilenames\x18\x01 \x03(\t\x12\x1f\n\x03seq\x18\x02 \x01(\x0b\x32\r.api.SequenceH\x00\x88\x01\x01\x42\x06\n\x04_seq\"D\n\x12\x42ulkLookupResponse\x12\n\n\x02ip\x18\x01 \x01(\t\x12\x0c\n\x04port\x18\x02 \x01(\x05\x12\x14\n\x0cmissingFiles\x18\x03 \x03(\t\"\x16\n\x14\x46\x65tchSequenceRequest\"X\n\x15\x46\x65tchSequenceResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\x12\x1a\n\x03seq\x18\x02 \x01(\x0b\x32\r.api.Sequence\"\x15\n\x13LookupLeaderRequest\"\'\n\x14LookupLeaderResponse\x12\x0f\n\x07\x61\x64\x64ress\x18\x01 \x01(\t\"3\n\x13UpdateLeaderRequest\x12\x1c\n\x06leader\x18\x01 \x01(\x0b\x32\x0c.api.Process\";\n\x14UpdateLeaderResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\"+\n\nEvalResult\x12\r\n\x05input\x18\x01 \x01(\t\x12\x0e\n\x06output\x18\x02 \x01(\t\"-\n\nBatchInput\x12\x0f\n\x07\x62\x61tchId\x18\x01 \x01(\x05\x12\x0e\n\x06inputs\x18\x02 \x03(\t\"P\n\x0b\x42\x61tchOutput\x12\x0f\n\x07\x62\x61tchId\x18\x01 \x01(\x05\x12 \n\x07results\x18\x02 \x03(\x0b\x32\x0f.api.EvalResult\x12\x0e\n\x06metric\x18\x03 \x01(\x02\"\xda\x01\n\nBatchState\x12 \n\x06status\x18\x01 \x01(\x0e\x32\x10.api.BatchStatus\x12#\n\nbatchInput\x18\x02 \x01(\x0b\x32\x0f.api.BatchInput\x12%\n\x0b\x62\x61tchOutput\x18\x03 \x01(\x0b\x32\x10.api.BatchOutput\x12-\n\tqueryTime\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12/\n\x0breceiveTime\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\xac\x02\n\x03Job\x12\n\n\x02id\x18\x01 \x01(\t\x12\x11\n\tmodelType\x18\x02 \x01(\t\x12\x0f\n\x07\x64\x61taset\x18\x03 \x01(\t\x12\x11\n\tbatchSize\x18\x04 \x01(\x05\x12-\n\tstartTime\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12.\n\nfinishTime\x18\x06 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x14\n\x0ctotalQueries\x18\x07 \x01(\x05\x12\x18\n\x10\x63ompletedQueries\x18\x08 \x01(\x05\x12$\n\x0b\x62\x61tchStates\x18\t \x03(\x0b\x32\x0f.api.BatchState\x12\x12\n\nqueryRates\x18\n \x03(\x02\x12\x19\n\x11queryProcessTimes\x18\x0b \x03(\x02\"\xe0\x01\n\x11\x43oordinatorBackup\x12:\n\nmodelStore\x18\x01 \x03(\x0b\x32&.api.CoordinatorBackup.ModelStoreEntry\x12\x1c\n\nactiveJobs\x18\x02 \x03(\x0b\x32\x08.api.Job\x12\x1f\n\rcompletedJobs\x18\x03 \x03(\x0b\x32\x08.api.Job\x12\x1d\n\x0bpendingJobs\x18\x04 \x03(\x0b\x32\x08.api.Job\x1a\x31\n\x0fModelStoreEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"+\n\tTrainTask\x12\r\n\x05model\x18\x01 \x01(\t\x12\x0f\n\x07\x64\x61taset\x18\x02 \x01(\t\"1\n\rInferenceTask\x12\r\n\x05model\x18\x01 \x01(\t\x12\x11\n\tbatchSize\x18\x02 \x01(\x05\"1\n\x0cTrainRequest\x12!\n\ttrainTask\x18\x01 \x01(\x0b\x32\x0e.api.TrainTask\"4\n\rTrainResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\"L\n\x10InferenceRequest\x12)\n\rinferenceTask\x18\x01 \x01(\x0b\x32\x12.api.InferenceTask\x12\r\n\x05jobId\x18\x02 \x01(\t\"8\n\x11InferenceResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\"f\n\x10QueryDataRequest\x12\r\n\x05jobId\x18\x01 \x01(\t\x12\x1c\n\x06worker\x18\x02 \x01(\x0b\x32\x0c.api.Process\x12%\n\x0b\x62\x61tchOutput\x18\x03 \x01(\x0b\x32\x10.api.BatchOutput\"L\n\x11QueryDataResponse\x12#\n\nbatchInput\x18\x01 \x01(\x0b\x32\x0f.api.BatchInput\x12\x12\n\nisFilename\x18\x02 \x01(\x08\"5\n\x13IDunnoStatusRequest\x12\r\n\x05which\x18\x01 \x01(\t\x12\x0f\n\x07payload\x18\x02 \x01(\t\"\'\n\x14IDunnoStatusResponse\x12\x0f\n\x07message\x18\x01 \x01(\t\"7\n\rBackupRequest\x12&\n\x06\x62\x61\x63kup\x18\x01 \x01(\x0b\x32\x16.api.CoordinatorBackup\"\x10\n\x0e\x42\x61\x63kupResponse\"\x18\n\x16\x46inishInferenceRequest\"\x19\n\x17\x46inishInferenceResponse\"\x12\n\x10HeartbeatRequest\"8\n\x11HeartbeatResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\"\x1c\n\x0cGreetRequest\x12\x0c\n\x04name\x18\x01 \x01(\t\" \n\rGreetResponse\x12\x0f\n\x07message\x18\x01 \x01(\t\"\"\n\x11ServeModelRequest\x12\r\n\x05model\x18\x01 \x01(\t\"9\n\x12ServeModelResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\"!\n\x0f\x45valuateRequest\x12\x0e\n\x06inputs\x18\x01 \x03(\t\"i\n\x10\x45valuateResponse\x12 \n\x07results\x18\x01 \x03(\x0b\x32\x0f.api.EvalResult\x12\x0e\n\x06metric\x18\x02 \x01(\x02\x12#\n\x06status\x18\x03 \x01(\x0e\x32\x13.api.ResponseStatus*,\n\x06Status\x12\t\n\x05\x41live\x10\x00\x12\x0b\n\x07Timeout\x10\x01\x12\n\n\x06Leaved\x10\x02*5\n\x0bMessageType\x12\x08\n\x04Ping\x10\x00\x12\x07\n\x03\x41\x63k\x10\x01\x12\x08\n\x04Join\x10\x02\x12\t\n\x05Leave\x10\x03*E\n\x0eResponseStatus\x12\x06\n\x02OK\x10\x00\x12\t\n\x05\x45RROR\x10\x01\x12\r\n\tNOT_FOUND\x10\x02\x12\x11\n\rNOT_CONVERGED\x10\x03*;\n\x0b\x42\x61tchStatus\x12\r\n\tAvailable\x10\x00\x12\x0e\n\nInProgress\x10\x01\x12\r\n\tCompleted\x10\x02\x32\xe3\x02\n\x0bSDFSService\x12H\n\rFetchSequence\x12\x19.api.FetchSequenceRequest\x1a\x1a.api.FetchSequenceResponse\"\x00\x12-\n\x04Read\x12\x10.api.ReadRequest\x1a\x11.api.ReadResponse\"\x00\x12\x30\n\x05Write\x12\x11.api.WriteRequest\x1a\x12.api.WriteResponse\"\x00\x12\x33\n\x06\x44\x65lete\x12\x12.api.DeleteRequest\x1a\x13.api.DeleteResponse\"\x00\x12\x33\n\x06Lookup\x12\x12.api.LookupRequest\x1a\x13.api.LookupResponse\"\x00\x12?\n\nBulkLookup\x12\x16.api.BulkLookupRequest\x1a\x17.api.BulkLookupResponse\"\x00\x32\x8e\x01\n\nDNSService\x12?\n\x06Lookup\x12\x18.api.LookupLeaderRequest\x1a\x19.api.LookupLeaderResponse\"\x00\x12?\n\x06Update\x12\x18.api.UpdateLeaderRequest\x1a\x19.api.UpdateLeaderResponse\"\x00\x32\xbe\x02\n\x12\x43oordinatorService\x12\x30\n\x05Train\x12\x11.api.TrainRequest\x1a\x12.api.TrainResponse\"\x00\x12<\n\tInference\x12\x15.api.InferenceRequest\x1a\x16.api.InferenceResponse\"\x00\x12<\n\tQueryData\x12\x15.api.QueryDataRequest\x1a\x16.api.QueryDataResponse\"\x00\x12\x45\n\x0cIDunnoStatus\x12\x18.api.IDunnoStatusRequest\x1a\x19.api.IDunnoStatusResponse\"\x00\x12\x33\n\x06\x42\x61\x63kup\x12\x12.api.BackupRequest\x1a\x13.api.BackupResponse\"\x00\x32\xcf\x01\n\rWorkerService\x12\x30\n\x05Train\x12\x11.api.TrainRequest\x1a\x12.api.TrainResponse\"\x00\x12<\n\tInference\x12\x15.api.InferenceRequest\x1a\x16.api.InferenceResponse\"\x00\x12N\n\x0f\x46inishInference\x12\x1b.api.FinishInferenceRequest\x1a\x1c.api.FinishInferenceResponse\"\x00\x32\xf2\x01\n\x10InferenceService\x12\x30\n\x05Greet\x12\x11.api.GreetRequest\x1a\x12.api.GreetResponse\"\x00\x12\x30\n\x05Train\x12\x11.api.TrainRequest\x1a\x12.api.TrainResponse\"\x00\x12?\n\nServeModel\x12\x16.api.ServeModelRequest\x1a\x17.api.ServeModelResponse\"\x00\x12\x39\n\x08\x45valuate\x12\x14.api.EvaluateRequest\x1a\x15.api.EvaluateResponse\"\x00\x42\tZ\x07mp4/apib\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\tapi.proto\x12\x03\x61pi\x1a\x1fgoogle/protobuf/timestamp.proto\"\xb8\x01\n\x07Process\x12\n\n\x02ip\x18\x01 \x01(\t\x12\x0c\n\x04port\x18\x02 \x01(\x05\x12,\n\x08joinTime\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x32\n\x0elastUpdateTime\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x1b\n\x06status\x18\x05 \x01(\x0e\x32\x0b.api.Status\x12\x0e\n\x06nodeId\x18\x06 \x01(\tJ\x04\x08\x07\x10\x08\"\xb8\x01\n\x12WorkerCapabilities\x12\x10\n\x08memoryMb\x18\x01 \x01(\x03\x12\x0c\n\x04\x63pus\x18\x02 \x01(\x05\x12\x0e\n\x06models\x18\x03 \x03(\t\x12\x33\n\x06labels\x18\x04 \x03(\x0b\x32#.api.WorkerCapabilities.LabelsEntry\x12\x0e\n\x06runner\x18\x05 \x01(\t\x1a-\n\x0bLabelsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"\xb5\x01\n\x14PlacementConstraints\x12\x13\n\x0bminMemoryMb\x18\x01 \x01(\x03\x12\x0f\n\x07minCpus\x18\x02 \x01(\x05\x12\x35\n\x06labels\x18\x03 \x03(\x0b\x32%.api.PlacementConstraints.LabelsEntry\x12\x11\n\tframework\x18\x04 \x01(\t\x1a-\n\x0bLabelsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"S\n\x07WriteId\x12\n\n\x02ip\x18\x01 \x01(\t\x12\x0c\n\x04port\x18\x02 \x01(\x05\x12.\n\ncreateTime\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\".\n\x0bPingMessage\x12\x1f\n\tprocesses\x18\x01 \x03(\x0b\x32\x0c.api.Process\"\x1e\n\nAckMessage\x12\x10\n\x08received\x18\x01 \x01(\t\",\n\x0bJoinMessage\x12\x1d\n\x07process\x18\x01 \x01(\x0b\x32\x0c.api.Process\"-\n\x0cLeaveMessage\x12\x1d\n\x07process\x18\x01 \x01(\x0b\x32\x0c.api.Process\"\xbd\x01\n\x08Metadata\x12\x1e\n\x04type\x18\x01 \x01(\x0e\x32\x10.api.MessageType\x12 \n\x04ping\x18\x02 \x01(\x0b\x32\x10.api.PingMessageH\x00\x12\x1e\n\x03\x61\x63k\x18\x03 \x01(\x0b\x32\x0f.api.AckMessageH\x00\x12 \n\x04join\x18\x04 \x01(\x0b\x32\x10.api.JoinMessageH\x00\x12\"\n\x05leave\x18\x05 \x01(\x0b\x32\x11.api.LeaveMessageH\x00\x42\t\n\x07message\"C\n\x08Sequence\x12(\n\x04time\x18\x01 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\r\n\x05\x63ount\x18\x02 \x01(\x05\"p\n\x0bReadRequest\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x0f\n\x07version\x18\x02 \x01(\x05\x12\x15\n\rlocalFilename\x18\x04 \x01(\t\x12\x1f\n\x03seq\x18\x03 \x01(\x0b\x32\r.api.SequenceH\x00\x88\x01\x01\x42\x06\n\x04_seq\"j\n\x0cReadResponse\x12\x0c\n\x04\x64\x61ta\x18\x01 \x01(\x0c\x12#\n\x06status\x18\x02 \x01(\x0e\x32\x13.api.ResponseStatus\x12\x1f\n\x03seq\x18\x03 \x01(\x0b\x32\r.api.SequenceH\x00\x88\x01\x01\x42\x06\n\x04_seq\"v\n\x0cWriteRequest\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x0c\n\x04\x64\x61ta\x18\x02 \x01(\x0c\x12\x1d\n\x07writeId\x18\x03 \x01(\x0b\x32\x0c.api.WriteId\x12\x1f\n\x03seq\x18\x04 \x01(\x0b\x32\r.api.SequenceH\x00\x88\x01\x01\x42\x06\n\x04_seq\"4\n\rWriteResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\"J\n\rDeleteRequest\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x1f\n\x03seq\x18\x02 \x01(\x0b\x32\r.api.SequenceH\x00\x88\x01\x01\x42\x06\n\x04_seq\"5\n\x0e\x44\x65leteResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\"J\n\rLookupRequest\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x1f\n\x03seq\x18\x02 \x01(\x0b\x32\r.api.SequenceH\x00\x88\x01\x01\x42\x06\n\x04_seq\"O\n\x0eLookupResponse\x12\n\n\x02ip\x18\x01 \x01(\t\x12\x0c\n\x04port\x18\x02 \x01(\x05\x12#\n\x06status\x18\x03 \x01(\x0e\x32\x13.api.ResponseStatus\"O\n\x11\x42ulkLookupRequest\x12\x11\n\tfilenames\x18\x01 \x03(\t\x12\x1f\n\x03seq\x18\x02 \x01(\x0b\x32\r.api.SequenceH\x00\x88\x01\x01\x42\x06\n\x04_seq\"D\n\x12\x42ulkLookupResponse\x12\n\n\x02ip\x18\x01 \x01(\t\x12\x0c\n\x04port\x18\x02 \x01(\x05\x12\x14\n\x0cmissingFiles\x18\x03 \x03(\t\"r\n\x0f\x46ileTableRecord\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x12\n\nconcatName\x18\x02 \x01(\t\x12\x1a\n\x03seq\x18\x03 \x01(\x0b\x32\r.api.Sequence\x12\x1d\n\x07writeId\x18\x04 \x01(\x0b\x32\x0c.api.WriteId\":\n\x11\x46ileTableSnapshot\x12%\n\x07records\x18\x01 \x03(\x0b\x32\x14.api.FileTableRecord\"\x16\n\x14\x46\x65tchSequenceRequest\"X\n\x15\x46\x65tchSequenceResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\x12\x1a\n\x03seq\x18\x02 \x01(\x0b\x32\r.api.Sequence\"\x15\n\x13LookupLeaderRequest\"\'\n\x14LookupLeaderResponse\x12\x0f\n\x07\x61\x64\x64ress\x18\x01 \x01(\t\"3\n\x13UpdateLeaderRequest\x12\x1c\n\x06leader\x18\x01 \x01(\x0b\x32\x0c.api.Process\";\n\x14UpdateLeaderResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\"+\n\nEvalResult\x12\r\n\x05input\x18\x01 \x01(\t\x12\x0e\n\x06output\x18\x02 \x01(\t\">\n\nBatchInput\x12\x0f\n\x07\x62\x61tchId\x18\x01 \x01(\x05\x12\x0e\n\x06inputs\x18\x02 \x03(\t\x12\x0f\n\x07version\x18\x03 \x01(\x05\"P\n\x0b\x42\x61tchOutput\x12\x0f\n\x07\x62\x61tchId\x18\x01 \x01(\x05\x12 \n\x07results\x18\x02 \x03(\x0b\x32\x0f.api.EvalResult\x12\x0e\n\x06metric\x18\x03 \x01(\x02\"\xfb\x01\n\nBatchState\x12 \n\x06status\x18\x01 \x01(\x0e\x32\x10.api.BatchStatus\x12#\n\nbatchInput\x18\x02 \x01(\x0b\x32\x0f.api.BatchInput\x12%\n\x0b\x62\x61tchOutput\x18\x03 \x01(\x0b\x32\x10.api.BatchOutput\x12-\n\tqueryTime\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12/\n\x0breceiveTime\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x1f\n\x06leases\x18\x06 \x03(\x0b\x32\x0f.api.BatchLease\"T\n\nBatchLease\x12\n\n\x02id\x18\x01 \x01(\x03\x12\x0e\n\x06worker\x18\x02 \x01(\t\x12*\n\x06\x65xpiry\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\xa4\x05\n\x03Job\x12\n\n\x02id\x18\x01 \x01(\t\x12\x11\n\tmodelType\x18\x02 \x01(\t\x12\x0f\n\x07\x64\x61taset\x18\x03 \x01(\t\x12\x11\n\tbatchSize\x18\x04 \x01(\x05\x12-\n\tstartTime\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12.\n\nfinishTime\x18\x06 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x14\n\x0ctotalQueries\x18\x07 \x01(\x05\x12\x18\n\x10\x63ompletedQueries\x18\x08 \x01(\x05\x12$\n\x0b\x62\x61tchStates\x18\t \x03(\x0b\x32\x0f.api.BatchState\x12\x12\n\nqueryRates\x18\n \x03(\x02\x12\x19\n\x11queryProcessTimes\x18\x0b \x03(\x02\x12\x1e\n\x06status\x18\x0c \x01(\x0e\x32\x0e.api.JobStatus\x12\x0e\n\x06weight\x18\r \x01(\x02\x12\x12\n\nminWorkers\x18\x0e \x01(\x05\x12,\n\x08\x64\x65\x61\x64line\x18\x0f \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x18\n\x10\x64uplicateOutputs\x18\x10 \x01(\x05\x12\x12\n\nleaseCount\x18\x11 \x01(\x03\x12\x13\n\x0bupstreamJob\x18\x12 \x01(\t\x12\x0e\n\x06\x66ilter\x18\x13 \x01(\t\x12\x0f\n\x07graphId\x18\x14 \x01(\t\x12\x0c\n\x04node\x18\x15 \x01(\t\x12\x13\n\x0binvalidRows\x18\x16 \x01(\x05\x12*\n\x11invalidRowSamples\x18\x17 \x03(\x0b\x32\x0f.api.InvalidRow\x12#\n\x08versions\x18\x18 \x03(\x0b\x32\x11.api.VersionShare\x12,\n\tplacement\x18\x19 \x01(\x0b\x32\x19.api.PlacementConstraints\"0\n\x0cVersionShare\x12\x0f\n\x07version\x18\x01 \x01(\x05\x12\x0f\n\x07percent\x18\x02 \x01(\x05\"7\n\nInvalidRow\x12\x0c\n\x04line\x18\x01 \x01(\x05\x12\x0b\n\x03row\x18\x02 \x01(\t\x12\x0e\n\x06reason\x18\x03 \x01(\t\"\xbf\x03\n\x11\x43oordinatorBackup\x12:\n\nmodelStore\x18\x0b \x03(\x0b\x32&.api.CoordinatorBackup.ModelStoreEntry\x12\x1c\n\nactiveJobs\x18\x02 \x03(\x0b\x32\x08.api.Job\x12\x1f\n\rcompletedJobs\x18\x03 \x03(\x0b\x32\x08.api.Job\x12\x1d\n\x0bpendingJobs\x18\x04 \x03(\x0b\x32\x08.api.Job\x12%\n\ttaskQueue\x18\x05 \x03(\x0b\x32\x12.api.InferenceTask\x12\x10\n\x08logIndex\x18\x06 \x01(\x03\x12\r\n\x05\x65poch\x18\x07 \x01(\x03\x12\x1d\n\x06graphs\x18\x08 \x03(\x0b\x32\r.api.JobGraph\x12\x1e\n\x06models\x18\t \x03(\x0b\x32\x0e.api.ModelSpec\x12\"\n\x08\x64\x61tasets\x18\n \x03(\x0b\x32\x10.api.DatasetSpec\x12\x18\n\x10unhealthyWorkers\x18\x0c \x03(\t\x1a\x45\n\x0fModelStoreEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12!\n\x05value\x18\x02 \x01(\x0b\x32\x12.api.ModelVersions:\x02\x38\x01J\x04\x08\x01\x10\x02\"\xde\x03\n\x08LogEntry\x12\r\n\x05index\x18\x01 \x01(\x03\x12\r\n\x05\x65poch\x18\x02 \x01(\x03\x12\x1f\n\x04type\x18\x03 \x01(\x0e\x32\x11.api.LogEntryType\x12!\n\ttrainTask\x18\x04 \x01(\x0b\x32\x0e.api.TrainTask\x12)\n\rinferenceTask\x18\x05 \x01(\x0b\x32\x12.api.InferenceTask\x12\x15\n\x03job\x18\x06 \x01(\x0b\x32\x08.api.Job\x12\r\n\x05jobId\x18\x07 \x01(\t\x12\x1c\n\x06worker\x18\x08 \x01(\x0b\x32\x0c.api.Process\x12\x0f\n\x07\x62\x61tchId\x18\t \x01(\x05\x12%\n\x0b\x62\x61tchOutput\x18\n \x01(\x0b\x32\x10.api.BatchOutput\x12(\n\x04time\x18\x0b \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x1e\n\x05lease\x18\x0c \x01(\x0b\x32\x0f.api.BatchLease\x12\x1c\n\x05graph\x18\r \x01(\x0b\x32\r.api.JobGraph\x12!\n\tmodelSpec\x18\x0e \x01(\x0b\x32\x0e.api.ModelSpec\x12%\n\x0b\x64\x61tasetSpec\x18\x0f \x01(\x0b\x32\x10.api.DatasetSpec\x12\x17\n\x0frunnerUnhealthy\x18\x10 \x01(\x08\"\xba\x01\n\tModelSpec\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\x0f\n\x07version\x18\x02 \x01(\x05\x12\x11\n\tframework\x18\x03 \x01(\t\x12!\n\tinputType\x18\x04 \x01(\x0e\x32\x0e.api.InputType\x12\x14\n\x0cinputPattern\x18\x05 \x01(\t\x12\x10\n\x08\x61rtifact\x18\x06 \x01(\t\x12\x30\n\x0cregisterTime\x18\x07 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"4\n\x14RegisterModelRequest\x12\x1c\n\x04spec\x18\x01 \x01(\x0b\x32\x0e.api.ModelSpec\"k\n\x15RegisterModelResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\x12\x1c\n\x04spec\x18\x02 \x01(\x0b\x32\x0e.api.ModelSpec\x12\x0f\n\x07message\x18\x03 \x01(\t\"!\n\x11ListModelsRequest\x12\x0c\n\x04name\x18\x01 \x01(\t\"\xb5\x01\n\x12ListModelsResponse\x12\x1e\n\x06models\x18\x01 \x03(\x0b\x32\x0e.api.ModelSpec\x12\x35\n\x07trained\x18\x03 \x03(\x0b\x32$.api.ListModelsResponse.TrainedEntry\x1a\x42\n\x0cTrainedEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12!\n\x05value\x18\x02 \x01(\x0b\x32\x12.api.ModelVersions:\x02\x38\x01J\x04\x08\x02\x10\x03\"l\n\x0cTrainedModel\x12\x1c\n\x04spec\x18\x01 \x01(\x0b\x32\x0e.api.ModelSpec\x12\x0f\n\x07\x64\x61taset\x18\x02 \x01(\t\x12-\n\ttrainTime\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\x99\x01\n\rModelVersions\x12\x32\n\x08versions\x18\x01 \x03(\x0b\x32 .api.ModelVersions.VersionsEntry\x12\x10\n\x08promoted\x18\x02 \x01(\x05\x1a\x42\n\rVersionsEntry\x12\x0b\n\x03key\x18\x01 \x01(\x05\x12 \n\x05value\x18\x02 \x01(\x0b\x32\x11.api.TrainedModel:\x02\x38\x01\"5\n\x13PromoteModelRequest\x12\r\n\x05model\x18\x01 \x01(\t\x12\x0f\n\x07version\x18\x02 \x01(\x05\"L\n\x14PromoteModelResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\x12\x0f\n\x07message\x18\x02 \x01(\t\"\xf8\x01\n\x0b\x44\x61tasetSpec\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\"\n\x06\x66ormat\x18\x02 \x01(\x0e\x32\x12.api.DatasetFormat\x12\x11\n\tdelimiter\x18\x03 \x01(\t\x12\x0f\n\x07\x63olumns\x18\x04 \x01(\x05\x12\x13\n\x0blabelColumn\x18\x05 \x01(\x05\x12\x12\n\ninputField\x18\x06 \x01(\t\x12\x12\n\nlabelField\x18\x07 \x01(\t\x12\x0e\n\x06labels\x18\x08 \x03(\t\x12\x14\n\x0cinputPattern\x18\t \x01(\t\x12\x30\n\x0cregisterTime\x18\n \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"8\n\x16RegisterDatasetRequest\x12\x1e\n\x04spec\x18\x01 \x01(\x0b\x32\x10.api.DatasetSpec\"O\n\x17RegisterDatasetResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\x12\x0f\n\x07message\x18\x02 \x01(\t\"\x15\n\x13ListDatasetsRequest\":\n\x14ListDatasetsResponse\x12\"\n\x08\x64\x61tasets\x18\x01 \x03(\x0b\x32\x10.api.DatasetSpec\"+\n\tTrainTask\x12\r\n\x05model\x18\x01 \x01(\t\x12\x0f\n\x07\x64\x61taset\x18\x02 \x01(\t\"\xe5\x02\n\rInferenceTask\x12\r\n\x05model\x18\x01 \x01(\t\x12\x11\n\tbatchSize\x18\x02 \x01(\x05\x12\x0e\n\x06weight\x18\x03 \x01(\x02\x12\x12\n\nminWorkers\x18\x04 \x01(\x05\x12,\n\x08\x64\x65\x61\x64line\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x0c\n\x04user\x18\x06 \x01(\t\x12-\n\tqueueTime\x18\x07 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x13\n\x0bupstreamJob\x18\x08 \x01(\t\x12\x0e\n\x06\x66ilter\x18\t \x01(\t\x12\x0f\n\x07graphId\x18\n \x01(\t\x12\x0c\n\x04node\x18\x0b \x01(\t\x12\x0f\n\x07version\x18\x0c \x01(\x05\x12 \n\x05split\x18\r \x03(\x0b\x32\x11.api.VersionShare\x12,\n\tplacement\x18\x0e \x01(\x0b\x32\x19.api.PlacementConstraints\"e\n\x0cTrainRequest\x12!\n\ttrainTask\x18\x01 \x01(\x0b\x32\x0e.api.TrainTask\x12\x1c\n\x04spec\x18\x02 \x01(\x0b\x32\x0e.api.ModelSpec\x12\x14\n\x0c\x61rtifactPath\x18\x03 \x01(\t\"E\n\rTrainResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\x12\x0f\n\x07version\x18\x02 \x01(\x05\"j\n\x10InferenceRequest\x12)\n\rinferenceTask\x18\x01 \x01(\x0b\x32\x12.api.InferenceTask\x12\r\n\x05jobId\x18\x02 \x01(\t\x12\x1c\n\x04spec\x18\x03 \x01(\x0b\x32\x0e.api.ModelSpec\"]\n\x11InferenceResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\x12\x12\n\nretryAfter\x18\x02 \x01(\x05\x12\x0f\n\x07message\x18\x03 \x01(\t\"\x83\x02\n\x10QueryDataRequest\x12\r\n\x05jobId\x18\x01 \x01(\t\x12\x1c\n\x06worker\x18\x02 \x01(\x0b\x32\x0c.api.Process\x12%\n\x0b\x62\x61tchOutput\x18\x03 \x01(\x0b\x32\x10.api.BatchOutput\x12\x10\n\x08\x64raining\x18\x04 \x01(\x08\x12\x0f\n\x07leaseId\x18\x05 \x01(\x03\x12$\n\x08resident\x18\x06 \x03(\x0b\x32\x12.api.ResidentModel\x12\x15\n\rpipelineDepth\x18\x07 \x01(\x05\x12\x12\n\nsubmitOnly\x18\x08 \x01(\x08\x12\x17\n\x0frunnerUnhealthy\x18\t \x01(\x08\x12\x0e\n\x06\x66\x61iled\x18\n \x01(\x08\"\x9e\x01\n\x11QueryDataResponse\x12#\n\nbatchInput\x18\x01 \x01(\x0b\x32\x0f.api.BatchInput\x12\x12\n\nisFilename\x18\x02 \x01(\x08\x12\x1e\n\x05lease\x18\x03 \x01(\x0b\x32\x0f.api.BatchLease\x12\r\n\x05jobId\x18\x04 \x01(\t\x12\x0f\n\x07\x64rained\x18\x05 \x01(\x08\x12\x10\n\x08released\x18\x06 \x01(\x08\"5\n\x13IDunnoStatusRequest\x12\r\n\x05which\x18\x01 \x01(\t\x12\x0f\n\x07payload\x18\x02 \x01(\t\"\'\n\x14IDunnoStatusResponse\x12\x0f\n\x07message\x18\x01 \x01(\t\"7\n\rBackupRequest\x12&\n\x06\x62\x61\x63kup\x18\x01 \x01(\x0b\x32\x16.api.CoordinatorBackup\"5\n\x0e\x42\x61\x63kupResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\"T\n\x10\x41ppendLogRequest\x12\r\n\x05\x65poch\x18\x01 \x01(\x03\x12\x11\n\tprevIndex\x18\x02 \x01(\x03\x12\x1e\n\x07\x65ntries\x18\x03 \x03(\x0b\x32\r.api.LogEntry\"Z\n\x11\x41ppendLogResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\x12\x11\n\tlastIndex\x18\x02 \x01(\x03\x12\r\n\x05\x65poch\x18\x03 \x01(\x03\"\"\n\x11JobControlRequest\x12\r\n\x05jobId\x18\x01 \x01(\t\"9\n\x12JobControlResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\"\x92\x01\n\tGraphNode\x12\x0c\n\x04name\x18\x01 \x01(\t\x12 \n\x04task\x18\x02 \x01(\x0b\x32\x12.api.InferenceTask\x12\x10\n\x08upstream\x18\x03 \x01(\t\x12\x0e\n\x06\x66ilter\x18\x04 \x01(\t\x12$\n\x06status\x18\x05 \x01(\x0e\x32\x14.api.GraphNodeStatus\x12\r\n\x05jobId\x18\x06 \x01(\t\"e\n\x08JobGraph\x12\n\n\x02id\x18\x01 \x01(\t\x12\x1d\n\x05nodes\x18\x02 \x03(\x0b\x32\x0e.api.GraphNode\x12.\n\nsubmitTime\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"2\n\x12SubmitGraphRequest\x12\x1c\n\x05graph\x18\x01 \x01(\x0b\x32\r.api.JobGraph\"\\\n\x13SubmitGraphResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\x12\x0f\n\x07graphId\x18\x02 \x01(\t\x12\x0f\n\x07message\x18\x03 \x01(\t\"}\n\x0ePredictRequest\x12\r\n\x05model\x18\x01 \x01(\t\x12\x0e\n\x06inputs\x18\x02 \x03(\t\x12\x0e\n\x06images\x18\x03 \x03(\x0c\x12\x11\n\tsloMillis\x18\x05 \x01(\x05\x12\x12\n\nisFilename\x18\x06 \x01(\x08\x12\x0f\n\x07version\x18\x07 \x01(\x05J\x04\x08\x04\x10\x05\"\xb4\x01\n\x0fPredictResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\x12 \n\x07results\x18\x02 \x03(\x0b\x32\x0f.api.EvalResult\x12\x15\n\rlatencyMillis\x18\x03 \x01(\x03\x12\x0e\n\x06sloMet\x18\x04 \x01(\x08\x12\x0e\n\x06worker\x18\x05 \x01(\t\x12\x12\n\nretryAfter\x18\x06 \x01(\x05\x12\x0f\n\x07message\x18\x07 \x01(\t\"\x16\n\x14\x46\x65tchSnapshotRequest\"A\n\x15\x46\x65tchSnapshotResponse\x12(\n\x08snapshot\x18\x01 \x01(\x0b\x32\x16.api.CoordinatorBackup\"\x18\n\x16\x46inishInferenceRequest\"\x19\n\x17\x46inishInferenceResponse\"\x1a\n\x18\x46\x65tchCapabilitiesRequest\"J\n\x19\x46\x65tchCapabilitiesResponse\x12-\n\x0c\x63\x61pabilities\x18\x01 \x01(\x0b\x32\x17.api.WorkerCapabilities\"\x12\n\x10HeartbeatRequest\"8\n\x11HeartbeatResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\"\x1c\n\x0cGreetRequest\x12\x0c\n\x04name\x18\x01 \x01(\t\" \n\rGreetResponse\x12\x0f\n\x07message\x18\x01 \x01(\t\"\x0f\n\rHealthRequest\"G\n\x0eHealthResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\x12\x10\n\x08memoryMb\x18\x02 \x01(\x03\"V\n\x11ServeModelRequest\x12\r\n\x05model\x18\x01 \x01(\t\x12\x1c\n\x04spec\x18\x02 \x01(\x0b\x32\x0e.api.ModelSpec\x12\x14\n\x0c\x61rtifactPath\x18\x03 \x01(\t\"m\n\x12ServeModelResponse\x12#\n\x06status\x18\x01 \x01(\x0e\x32\x13.api.ResponseStatus\x12\x0c\n\x04warm\x18\x02 \x01(\x08\x12$\n\x08resident\x18\x03 \x03(\x0b\x32\x12.api.ResidentModel\"A\n\rResidentModel\x12\r\n\x05model\x18\x01 \x01(\t\x12\x0f\n\x07version\x18\x02 \x01(\x05\x12\x10\n\x08memoryMb\x18\x03 \x01(\x03\"!\n\x0f\x45valuateRequest\x12\x0e\n\x06inputs\x18\x01 \x03(\t\"i\n\x10\x45valuateResponse\x12 \n\x07results\x18\x01 \x03(\x0b\x32\x0f.api.EvalResult\x12\x0e\n\x06metric\x18\x02 \x01(\x02\x12#\n\x06status\x18\x03 \x01(\x0e\x32\x13.api.ResponseStatus*,\n\x06Status\x12\t\n\x05\x41live\x10\x00\x12\x0b\n\x07Timeout\x10\x01\x12\n\n\x06Leaved\x10\x02*5\n\x0bMessageType\x12\x08\n\x04Ping\x10\x00\x12\x07\n\x03\x41\x63k\x10\x01\x12\x08\n\x04Join\x10\x02\x12\t\n\x05Leave\x10\x03*u\n\x0eResponseStatus\x12\x06\n\x02OK\x10\x00\x12\t\n\x05\x45RROR\x10\x01\x12\r\n\tNOT_FOUND\x10\x02\x12\x11\n\rNOT_CONVERGED\x10\x03\x12\r\n\tNO_QUORUM\x10\x04\x12\x11\n\rOVER_CAPACITY\x10\x05\x12\x0c\n\x08\x43ONFLICT\x10\x06*;\n\x0b\x42\x61tchStatus\x12\r\n\tAvailable\x10\x00\x12\x0e\n\nInProgress\x10\x01\x12\r\n\tCompleted\x10\x02*A\n\tJobStatus\x12\x0b\n\x07Running\x10\x00\x12\n\n\x06Paused\x10\x01\x12\r\n\tCancelled\x10\x02\x12\x0c\n\x08\x46inished\x10\x03*\xdb\x02\n\x0cLogEntryType\x12\x0e\n\nModelAdded\x10\x00\x12\x0e\n\nTaskQueued\x10\x01\x12\x0f\n\x0bTaskDropped\x10\x02\x12\x0e\n\nJobCreated\x10\x03\x12\x11\n\rBatchAssigned\x10\x04\x12\x12\n\x0e\x42\x61tchCompleted\x10\x05\x12\x0f\n\x0bJobFinished\x10\x06\x12\r\n\tJobPaused\x10\x07\x12\x0e\n\nJobResumed\x10\x08\x12\x10\n\x0cJobCancelled\x10\t\x12\x11\n\rBatchRejected\x10\n\x12\x12\n\x0eGraphSubmitted\x10\x0b\x12\x13\n\x0fModelRegistered\x10\x0c\x12\x15\n\x11\x44\x61tasetRegistered\x10\r\x12\x11\n\rModelPromoted\x10\x0e\x12\x16\n\x12WorkerStateChanged\x10\x0f\x12\x12\n\x0eWorkerReleased\x10\x10\x12\x0f\n\x0b\x42\x61tchFailed\x10\x11*,\n\tInputType\x12\x11\n\rFilenameInput\x10\x00\x12\x0c\n\x08RawInput\x10\x01*?\n\rDatasetFormat\x12\x0c\n\x08\x46ileList\x10\x00\x12\x11\n\rDelimitedText\x10\x01\x12\r\n\tJSONLines\x10\x02*r\n\x0fGraphNodeStatus\x12\x0f\n\x0bNodeWaiting\x10\x00\x12\x0e\n\nNodeQueued\x10\x01\x12\x0f\n\x0bNodeRunning\x10\x02\x12\x0c\n\x08NodeDone\x10\x03\x12\x0e\n\nNodeFailed\x10\x04\x12\x0f\n\x0bNodeSkipped\x10\x05\x32\xe3\x02\n\x0bSDFSService\x12H\n\rFetchSequence\x12\x19.api.FetchSequenceRequest\x1a\x1a.api.FetchSequenceResponse\"\x00\x12-\n\x04Read\x12\x10.api.ReadRequest\x1a\x11.api.ReadResponse\"\x00\x12\x30\n\x05Write\x12\x11.api.WriteRequest\x1a\x12.api.WriteResponse\"\x00\x12\x33\n\x06\x44\x65lete\x12\x12.api.DeleteRequest\x1a\x13.api.DeleteResponse\"\x00\x12\x33\n\x06Lookup\x12\x12.api.LookupRequest\x1a\x13.api.LookupResponse\"\x00\x12?\n\nBulkLookup\x12\x16.api.BulkLookupRequest\x1a\x17.api.BulkLookupResponse\"\x00\x32\x8e\x01\n\nDNSService\x12?\n\x06Lookup\x12\x18.api.LookupLeaderRequest\x1a\x19.api.LookupLeaderResponse\"\x00\x12?\n\x06Update\x12\x18.api.UpdateLeaderRequest\x1a\x19.api.UpdateLeaderResponse\"\x00\x32\xab\t\n\x12\x43oordinatorService\x12\x30\n\x05Train\x12\x11.api.TrainRequest\x1a\x12.api.TrainResponse\"\x00\x12<\n\tInference\x12\x15.api.InferenceRequest\x1a\x16.api.InferenceResponse\"\x00\x12<\n\tQueryData\x12\x15.api.QueryDataRequest\x1a\x16.api.QueryDataResponse\"\x00\x12?\n\x08\x44ispatch\x12\x15.api.QueryDataRequest\x1a\x16.api.QueryDataResponse\"\x00(\x01\x30\x01\x12>\n\tCancelJob\x12\x16.api.JobControlRequest\x1a\x17.api.JobControlResponse\"\x00\x12=\n\x08PauseJob\x12\x16.api.JobControlRequest\x1a\x17.api.JobControlResponse\"\x00\x12>\n\tResumeJob\x12\x16.api.JobControlRequest\x1a\x17.api.JobControlResponse\"\x00\x12\x42\n\x0bSubmitGraph\x12\x17.api.SubmitGraphRequest\x1a\x18.api.SubmitGraphResponse\"\x00\x12\x36\n\x07Predict\x12\x13.api.PredictRequest\x1a\x14.api.PredictResponse\"\x00\x12H\n\rRegisterModel\x12\x19.api.RegisterModelRequest\x1a\x1a.api.RegisterModelResponse\"\x00\x12?\n\nListModels\x12\x16.api.ListModelsRequest\x1a\x17.api.ListModelsResponse\"\x00\x12\x45\n\x0cPromoteModel\x12\x18.api.PromoteModelRequest\x1a\x19.api.PromoteModelResponse\"\x00\x12N\n\x0fRegisterDataset\x12\x1b.api.RegisterDatasetRequest\x1a\x1c.api.RegisterDatasetResponse\"\x00\x12\x45\n\x0cListDatasets\x12\x18.api.ListDatasetsRequest\x1a\x19.api.ListDatasetsResponse\"\x00\x12\x45\n\x0cIDunnoStatus\x12\x18.api.IDunnoStatusRequest\x1a\x19.api.IDunnoStatusResponse\"\x00\x12\x33\n\x06\x42\x61\x63kup\x12\x12.api.BackupRequest\x1a\x13.api.BackupResponse\"\x00\x12<\n\tAppendLog\x12\x15.api.AppendLogRequest\x1a\x16.api.AppendLogResponse\"\x00\x12H\n\rFetchSnapshot\x12\x19.api.FetchSnapshotRequest\x1a\x1a.api.FetchSnapshotResponse\"\x00\x32\x9e\x03\n\rWorkerService\x12\x30\n\x05Train\x12\x11.api.TrainRequest\x1a\x12.api.TrainResponse\"\x00\x12<\n\tInference\x12\x15.api.InferenceRequest\x1a\x16.api.InferenceResponse\"\x00\x12N\n\x0f\x46inishInference\x12\x1b.api.FinishInferenceRequest\x1a\x1c.api.FinishInferenceResponse\"\x00\x12\x36\n\x07Predict\x12\x13.api.PredictRequest\x1a\x14.api.PredictResponse\"\x00\x12?\n\nServeModel\x12\x16.api.ServeModelRequest\x1a\x17.api.ServeModelResponse\"\x00\x12T\n\x11\x46\x65tchCapabilities\x12\x1d.api.FetchCapabilitiesRequest\x1a\x1e.api.FetchCapabilitiesResponse\"\x00\x32\xa7\x02\n\x10InferenceService\x12\x30\n\x05Greet\x12\x11.api.GreetRequest\x1a\x12.api.GreetResponse\"\x00\x12\x33\n\x06Health\x12\x12.api.HealthRequest\x1a\x13.api.HealthResponse\"\x00\x12\x30\n\x05Train\x12\x11.api.TrainRequest\x1a\x12.api.TrainResponse\"\x00\x12?\n\nServeModel\x12\x16.api.ServeModelRequest\x1a\x17.api.ServeModelResponse\"\x00\x12\x39\n\x08\x45valuate\x12\x14.api.EvaluateRequest\x1a\x15.api.EvaluateResponse\"\x00\x42\tZ\x07mp4/apib\x06proto3')
 
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, globals())
 _builder.BuildTopDescriptorsAndMessages(DESCRIPTOR, 'api_pb2', globals())
@@ -22,130 +22,234 @@ if _descriptor._USE_C_DESCRIPTORS == False:
 
   DESCRIPTOR._options = None
   DESCRIPTOR._serialized_options = b'Z\007mp4/api'
+  _WORKERCAPABILITIES_LABELSENTRY._options = None
+  _WORKERCAPABILITIES_LABELSENTRY._serialized_options = b'8\001'
+  _PLACEMENTCONSTRAINTS_LABELSENTRY._options = None
+  _PLACEMENTCONSTRAINTS_LABELSENTRY._serialized_options = b'8\001'
   _COORDINATORBACKUP_MODELSTOREENTRY._options = None
   _COORDINATORBACKUP_MODELSTOREENTRY._serialized_options = b'8\001'
-  _STATUS._serialized_start=3909
-  _STATUS._serialized_end=3953
-  _MESSAGETYPE._serialized_start=3955
-  _MESSAGETYPE._serialized_end=4008
-  _RESPONSESTATUS._serialized_start=4010
-  _RESPONSESTATUS._serialized_end=4079
-  _BATCHSTATUS._serialized_start=4081
-  _BATCHSTATUS._serialized_end=4140
+  _LISTMODELSRESPONSE_TRAINEDENTRY._options = None
+  _LISTMODELSRESPONSE_TRAINEDENTRY._serialized_options = b'8\001'
+  _MODELVERSIONS_VERSIONSENTRY._options = None
+  _MODELVERSIONS_VERSIONSENTRY._serialized_options = b'8\001'
+  _STATUS._serialized_start=9404
+  _STATUS._serialized_end=9448
+  _MESSAGETYPE._serialized_start=9450
+  _MESSAGETYPE._serialized_end=9503
+  _RESPONSESTATUS._serialized_start=9505
+  _RESPONSESTATUS._serialized_end=9622
+  _BATCHSTATUS._serialized_start=9624
+  _BATCHSTATUS._serialized_end=9683
+  _JOBSTATUS._serialized_start=9685
+  _JOBSTATUS._serialized_end=9750
+  _LOGENTRYTYPE._serialized_start=9753
+  _LOGENTRYTYPE._serialized_end=10100
+  _INPUTTYPE._serialized_start=10102
+  _INPUTTYPE._serialized_end=10146
+  _DATASETFORMAT._serialized_start=10148
+  _DATASETFORMAT._serialized_end=10211
+  _GRAPHNODESTATUS._serialized_start=10213
+  _GRAPHNODESTATUS._serialized_end=10327
   _PROCESS._serialized_start=52
-  _PROCESS._serialized_end=214
-  _WRITEID._serialized_start=216
-  _WRITEID._serialized_end=299
-  _PINGMESSAGE._serialized_start=301
-  _PINGMESSAGE._serialized_end=347
-  _ACKMESSAGE._serialized_start=349
-  _ACKMESSAGE._serialized_end=379
-  _JOINMESSAGE._serialized_start=381
-  _JOINMESSAGE._serialized_end=425
-  _LEAVEMESSAGE._serialized_start=427
-  _LEAVEMESSAGE._serialized_end=472
-  _METADATA._serialized_start=475
-  _METADATA._serialized_end=664
-  _SEQUENCE._serialized_start=666
-  _SEQUENCE._serialized_end=733
-  _READREQUEST._serialized_start=735
-  _READREQUEST._serialized_end=847
-  _READRESPONSE._serialized_start=849
-  _READRESPONSE._serialized_end=955
-  _WRITEREQUEST._serialized_start=957
-  _WRITEREQUEST._serialized_end=1075
-  _WRITERESPONSE._serialized_start=1077
-  _WRITERESPONSE._serialized_end=1129
-  _DELETEREQUEST._serialized_start=1131
-  _DELETEREQUEST._serialized_end=1205
-  _DELETERESPONSE._serialized_start=1207
-  _DELETERESPONSE._serialized_end=1260
-  _LOOKUPREQUEST._serialized_start=1262
-  _LOOKUPREQUEST._serialized_end=1336
-  _LOOKUPRESPONSE._serialized_start=1338
-  _LOOKUPRESPONSE._serialized_end=1417
-  _BULKLOOKUPREQUEST._serialized_start=1419
-  _BULKLOOKUPREQUEST._serialized_end=1498
-  _BULKLOOKUPRESPONSE._serialized_start=1500
-  _BULKLOOKUPRESPONSE._serialized_end=1568
-  _FETCHSEQUENCEREQUEST._serialized_start=1570
-  _FETCHSEQUENCEREQUEST._serialized_end=1592
-  _FETCHSEQUENCERESPONSE._serialized_start=1594
-  _FETCHSEQUENCERESPONSE._serialized_end=1682
-  _LOOKUPLEADERREQUEST._serialized_start=1684
-  _LOOKUPLEADERREQUEST._serialized_end=1705
-  _LOOKUPLEADERRESPONSE._serialized_start=1707
-  _LOOKUPLEADERRESPONSE._serialized_end=1746
-  _UPDATELEADERREQUEST._serialized_start=1748
-  _UPDATELEADERREQUEST._serialized_end=1799
-  _UPDATELEADERRESPONSE._serialized_start=1801
-  _UPDATELEADERRESPONSE._serialized_end=1860
-  _EVALRESULT._serialized_start=1862
-  _EVALRESULT._serialized_end=1905
-  _BATCHINPUT._serialized_start=1907
-  _BATCHINPUT._serialized_end=1952
-  _BATCHOUTPUT._serialized_start=1954
-  _BATCHOUTPUT._serialized_end=2034
-  _BATCHSTATE._serialized_start=2037
-  _BATCHSTATE._serialized_end=2255
-  _JOB._serialized_start=2258
-  _JOB._serialized_end=2558
-  _COORDINATORBACKUP._serialized_start=2561
-  _COORDINATORBACKUP._serialized_end=2785
-  _COORDINATORBACKUP_MODELSTOREENTRY._serialized_start=2736
-  _COORDINATORBACKUP_MODELSTOREENTRY._serialized_end=2785
-  _TRAINTASK._serialized_start=2787
-  _TRAINTASK._serialized_end=2830
-  _INFERENCETASK._serialized_start=2832
-  _INFERENCETASK._serialized_end=2881
-  _TRAINREQUEST._serialized_start=2883
-  _TRAINREQUEST._serialized_end=2932
-  _TRAINRESPONSE._serialized_start=2934
-  _TRAINRESPONSE._serialized_end=2986
-  _INFERENCEREQUEST._serialized_start=2988
-  _INFERENCEREQUEST._serialized_end=3064
-  _INFERENCERESPONSE._serialized_start=3066
-  _INFERENCERESPONSE._serialized_end=3122
-  _QUERYDATAREQUEST._serialized_start=3124
-  _QUERYDATAREQUEST._serialized_end=3226
-  _QUERYDATARESPONSE._serialized_start=3228
-  _QUERYDATARESPONSE._serialized_end=3304
-  _IDUNNOSTATUSREQUEST._serialized_start=3306
-  _IDUNNOSTATUSREQUEST._serialized_end=3359
-  _IDUNNOSTATUSRESPONSE._serialized_start=3361
-  _IDUNNOSTATUSRESPONSE._serialized_end=3400
-  _BACKUPREQUEST._serialized_start=3402
-  _BACKUPREQUEST._serialized_end=3457
-  _BACKUPRESPONSE._serialized_start=3459
-  _BACKUPRESPONSE._serialized_end=3475
-  _FINISHINFERENCEREQUEST._serialized_start=3477
-  _FINISHINFERENCEREQUEST._serialized_end=3501
-  _FINISHINFERENCERESPONSE._serialized_start=3503
-  _FINISHINFERENCERESPONSE._serialized_end=3528
-  _HEARTBEATREQUEST._serialized_start=3530
-  _HEARTBEATREQUEST._serialized_end=3548
-  _HEARTBEATRESPONSE._serialized_start=3550
-  _HEARTBEATRESPONSE._serialized_end=3606
-  _GREETREQUEST._serialized_start=3608
-  _GREETREQUEST._serialized_end=3636
-  _GREETRESPONSE._serialized_start=3638
-  _GREETRESPONSE._serialized_end=3670
-  _SERVEMODELREQUEST._serialized_start=3672
-  _SERVEMODELREQUEST._serialized_end=3706
-  _SERVEMODELRESPONSE._serialized_start=3708
-  _SERVEMODELRESPONSE._serialized_end=3765
-  _EVALUATEREQUEST._serialized_start=3767
-  _EVALUATEREQUEST._serialized_end=3800
-  _EVALUATERESPONSE._serialized_start=3802
-  _EVALUATERESPONSE._serialized_end=3907
-  _SDFSSERVICE._serialized_start=4143
-  _SDFSSERVICE._serialized_end=4498
-  _DNSSERVICE._serialized_start=4501
-  _DNSSERVICE._serialized_end=4643
-  _COORDINATORSERVICE._serialized_start=4646
-  _COORDINATORSERVICE._serialized_end=4964
-  _WORKERSERVICE._serialized_start=4967
-  _WORKERSERVICE._serialized_end=5174
-  _INFERENCESERVICE._serialized_start=5177
-  _INFERENCESERVICE._serialized_end=5419
+  _PROCESS._serialized_end=236
+  _WORKERCAPABILITIES._serialized_start=239
+  _WORKERCAPABILITIES._serialized_end=423
+  _WORKERCAPABILITIES_LABELSENTRY._serialized_start=378
+  _WORKERCAPABILITIES_LABELSENTRY._serialized_end=423
+  _PLACEMENTCONSTRAINTS._serialized_start=426
+  _PLACEMENTCONSTRAINTS._serialized_end=607
+  _PLACEMENTCONSTRAINTS_LABELSENTRY._serialized_start=562
+  _PLACEMENTCONSTRAINTS_LABELSENTRY._serialized_end=607
+  _WRITEID._serialized_start=609
+  _WRITEID._serialized_end=692
+  _PINGMESSAGE._serialized_start=694
+  _PINGMESSAGE._serialized_end=740
+  _ACKMESSAGE._serialized_start=742
+  _ACKMESSAGE._serialized_end=772
+  _JOINMESSAGE._serialized_start=774
+  _JOINMESSAGE._serialized_end=818
+  _LEAVEMESSAGE._serialized_start=820
+  _LEAVEMESSAGE._serialized_end=865
+  _METADATA._serialized_start=868
+  _METADATA._serialized_end=1057
+  _SEQUENCE._serialized_start=1059
+  _SEQUENCE._serialized_end=1126
+  _READREQUEST._serialized_start=1128
+  _READREQUEST._serialized_end=1240
+  _READRESPONSE._serialized_start=1242
+  _READRESPONSE._serialized_end=1348
+  _WRITEREQUEST._serialized_start=1350
+  _WRITEREQUEST._serialized_end=1468
+  _WRITERESPONSE._serialized_start=1470
+  _WRITERESPONSE._serialized_end=1522
+  _DELETEREQUEST._serialized_start=1524
+  _DELETEREQUEST._serialized_end=1598
+  _DELETERESPONSE._serialized_start=1600
+  _DELETERESPONSE._serialized_end=1653
+  _LOOKUPREQUEST._serialized_start=1655
+  _LOOKUPREQUEST._serialized_end=1729
+  _LOOKUPRESPONSE._serialized_start=1731
+  _LOOKUPRESPONSE._serialized_end=1810
+  _BULKLOOKUPREQUEST._serialized_start=1812
+  _BULKLOOKUPREQUEST._serialized_end=1891
+  _BULKLOOKUPRESPONSE._serialized_start=1893
+  _BULKLOOKUPRESPONSE._serialized_end=1961
+  _FILETABLERECORD._serialized_start=1963
+  _FILETABLERECORD._serialized_end=2077
+  _FILETABLESNAPSHOT._serialized_start=2079
+  _FILETABLESNAPSHOT._serialized_end=2137
+  _FETCHSEQUENCEREQUEST._serialized_start=2139
+  _FETCHSEQUENCEREQUEST._serialized_end=2161
+  _FETCHSEQUENCERESPONSE._serialized_start=2163
+  _FETCHSEQUENCERESPONSE._serialized_end=2251
+  _LOOKUPLEADERREQUEST._serialized_start=2253
+  _LOOKUPLEADERREQUEST._serialized_end=2274
+  _LOOKUPLEADERRESPONSE._serialized_start=2276
+  _LOOKUPLEADERRESPONSE._serialized_end=2315
+  _UPDATELEADERREQUEST._serialized_start=2317
+  _UPDATELEADERREQUEST._serialized_end=2368
+  _UPDATELEADERRESPONSE._serialized_start=2370
+  _UPDATELEADERRESPONSE._serialized_end=2429
+  _EVALRESULT._serialized_start=2431
+  _EVALRESULT._serialized_end=2474
+  _BATCHINPUT._serialized_start=2476
+  _BATCHINPUT._serialized_end=2538
+  _BATCHOUTPUT._serialized_start=2540
+  _BATCHOUTPUT._serialized_end=2620
+  _BATCHSTATE._serialized_start=2623
+  _BATCHSTATE._serialized_end=2874
+  _BATCHLEASE._serialized_start=2876
+  _BATCHLEASE._serialized_end=2960
+  _JOB._serialized_start=2963
+  _JOB._serialized_end=3639
+  _VERSIONSHARE._serialized_start=3641
+  _VERSIONSHARE._serialized_end=3689
+  _INVALIDROW._serialized_start=3691
+  _INVALIDROW._serialized_end=3746
+  _COORDINATORBACKUP._serialized_start=3749
+  _COORDINATORBACKUP._serialized_end=4196
+  _COORDINATORBACKUP_MODELSTOREENTRY._serialized_start=4121
+  _COORDINATORBACKUP_MODELSTOREENTRY._serialized_end=4190
+  _LOGENTRY._serialized_start=4199
+  _LOGENTRY._serialized_end=4677
+  _MODELSPEC._serialized_start=4680
+  _MODELSPEC._serialized_end=4866
+  _REGISTERMODELREQUEST._serialized_start=4868
+  _REGISTERMODELREQUEST._serialized_end=4920
+  _REGISTERMODELRESPONSE._serialized_start=4922
+  _REGISTERMODELRESPONSE._serialized_end=5029
+  _LISTMODELSREQUEST._serialized_start=5031
+  _LISTMODELSREQUEST._serialized_end=5064
+  _LISTMODELSRESPONSE._serialized_start=5067
+  _LISTMODELSRESPONSE._serialized_end=5248
+  _LISTMODELSRESPONSE_TRAINEDENTRY._serialized_start=5176
+  _LISTMODELSRESPONSE_TRAINEDENTRY._serialized_end=5242
+  _TRAINEDMODEL._serialized_start=5250
+  _TRAINEDMODEL._serialized_end=5358
+  _MODELVERSIONS._serialized_start=5361
+  _MODELVERSIONS._serialized_end=5514
+  _MODELVERSIONS_VERSIONSENTRY._serialized_start=5448
+  _MODELVERSIONS_VERSIONSENTRY._serialized_end=5514
+  _PROMOTEMODELREQUEST._serialized_start=5516
+  _PROMOTEMODELREQUEST._serialized_end=5569
+  _PROMOTEMODELRESPONSE._serialized_start=5571
+  _PROMOTEMODELRESPONSE._serialized_end=5647
+  _DATASETSPEC._serialized_start=5650
+  _DATASETSPEC._serialized_end=5898
+  _REGISTERDATASETREQUEST._serialized_start=5900
+  _REGISTERDATASETREQUEST._serialized_end=5956
+  _REGISTERDATASETRESPONSE._serialized_start=5958
+  _REGISTERDATASETRESPONSE._serialized_end=6037
+  _LISTDATASETSREQUEST._serialized_start=6039
+  _LISTDATASETSREQUEST._serialized_end=6060
+  _LISTDATASETSRESPONSE._serialized_start=6062
+  _LISTDATASETSRESPONSE._serialized_end=6120
+  _TRAINTASK._serialized_start=6122
+  _TRAINTASK._serialized_end=6165
+  _INFERENCETASK._serialized_start=6168
+  _INFERENCETASK._serialized_end=6525
+  _TRAINREQUEST._serialized_start=6527
+  _TRAINREQUEST._serialized_end=6628
+  _TRAINRESPONSE._serialized_start=6630
+  _TRAINRESPONSE._serialized_end=6699
+  _INFERENCEREQUEST._serialized_start=6701
+  _INFERENCEREQUEST._serialized_end=6807
+  _INFERENCERESPONSE._serialized_start=6809
+  _INFERENCERESPONSE._serialized_end=6902
+  _QUERYDATAREQUEST._serialized_start=6905
+  _QUERYDATAREQUEST._serialized_end=7164
+  _QUERYDATARESPONSE._serialized_start=7167
+  _QUERYDATARESPONSE._serialized_end=7325
+  _IDUNNOSTATUSREQUEST._serialized_start=7327
+  _IDUNNOSTATUSREQUEST._serialized_end=7380
+  _IDUNNOSTATUSRESPONSE._serialized_start=7382
+  _IDUNNOSTATUSRESPONSE._serialized_end=7421
+  _BACKUPREQUEST._serialized_start=7423
+  _BACKUPREQUEST._serialized_end=7478
+  _BACKUPRESPONSE._serialized_start=7480
+  _BACKUPRESPONSE._serialized_end=7533
+  _APPENDLOGREQUEST._serialized_start=7535
+  _APPENDLOGREQUEST._serialized_end=7619
+  _APPENDLOGRESPONSE._serialized_start=7621
+  _APPENDLOGRESPONSE._serialized_end=7711
+  _JOBCONTROLREQUEST._serialized_start=7713
+  _JOBCONTROLREQUEST._serialized_end=7747
+  _JOBCONTROLRESPONSE._serialized_start=7749
+  _JOBCONTROLRESPONSE._serialized_end=7806
+  _GRAPHNODE._serialized_start=7809
+  _GRAPHNODE._serialized_end=7955
+  _JOBGRAPH._serialized_start=7957
+  _JOBGRAPH._serialized_end=8058
+  _SUBMITGRAPHREQUEST._serialized_start=8060
+  _SUBMITGRAPHREQUEST._serialized_end=8110
+  _SUBMITGRAPHRESPONSE._serialized_start=8112
+  _SUBMITGRAPHRESPONSE._serialized_end=8204
+  _PREDICTREQUEST._serialized_start=8206
+  _PREDICTREQUEST._serialized_end=8331
+  _PREDICTRESPONSE._serialized_start=8334
+  _PREDICTRESPONSE._serialized_end=8514
+  _FETCHSNAPSHOTREQUEST._serialized_start=8516
+  _FETCHSNAPSHOTREQUEST._serialized_end=8538
+  _FETCHSNAPSHOTRESPONSE._serialized_start=8540
+  _FETCHSNAPSHOTRESPONSE._serialized_end=8605
+  _FINISHINFERENCEREQUEST._serialized_start=8607
+  _FINISHINFERENCEREQUEST._serialized_end=8631
+  _FINISHINFERENCERESPONSE._serialized_start=8633
+  _FINISHINFERENCERESPONSE._serialized_end=8658
+  _FETCHCAPABILITIESREQUEST._serialized_start=8660
+  _FETCHCAPABILITIESREQUEST._serialized_end=8686
+  _FETCHCAPABILITIESRESPONSE._serialized_start=8688
+  _FETCHCAPABILITIESRESPONSE._serialized_end=8762
+  _HEARTBEATREQUEST._serialized_start=8764
+  _HEARTBEATREQUEST._serialized_end=8782
+  _HEARTBEATRESPONSE._serialized_start=8784
+  _HEARTBEATRESPONSE._serialized_end=8840
+  _GREETREQUEST._serialized_start=8842
+  _GREETREQUEST._serialized_end=8870
+  _GREETRESPONSE._serialized_start=8872
+  _GREETRESPONSE._serialized_end=8904
+  _HEALTHREQUEST._serialized_start=8906
+  _HEALTHREQUEST._serialized_end=8921
+  _HEALTHRESPONSE._serialized_start=8923
+  _HEALTHRESPONSE._serialized_end=8994
+  _SERVEMODELREQUEST._serialized_start=8996
+  _SERVEMODELREQUEST._serialized_end=9082
+  _SERVEMODELRESPONSE._serialized_start=9084
+  _SERVEMODELRESPONSE._serialized_end=9193
+  _RESIDENTMODEL._serialized_start=9195
+  _RESIDENTMODEL._serialized_end=9260
+  _EVALUATEREQUEST._serialized_start=9262
+  _EVALUATEREQUEST._serialized_end=9295
+  _EVALUATERESPONSE._serialized_start=9297
+  _EVALUATERESPONSE._serialized_end=9402
+  _SDFSSERVICE._serialized_start=10330
+  _SDFSSERVICE._serialized_end=10685
+  _DNSSERVICE._serialized_start=10688
+  _DNSSERVICE._serialized_end=10830
+  _COORDINATORSERVICE._serialized_start=10833
+  _COORDINATORSERVICE._serialized_end=12028
+  _WORKERSERVICE._serialized_start=12031
+  _WORKERSERVICE._serialized_end=12445
+  _INFERENCESERVICE._serialized_start=12448
+  _INFERENCESERVICE._serialized_end=12743
 # @@protoc_insertion_point(module_scope)
@@ -8,18 +8,54 @@ from typing import ClassVar as _ClassVar, Iterable as _Iterable, Mapping as _Map
 Ack: MessageType
 Alive: Status
 Available: BatchStatus
+BatchAssigned: LogEntryType
+BatchCompleted: LogEntryType
+BatchFailed: LogEntryType
+BatchRejected: LogEntryType
+CONFLICT: ResponseStatus
+Cancelled: JobStatus
 Completed: BatchStatus
 DESCRIPTOR: _descriptor.FileDescriptor
+DatasetRegistered: LogEntryType
+DelimitedText: DatasetFormat
 ERROR: ResponseStatus
+FileList: DatasetFormat
+FilenameInput: InputType
+Finished: JobStatus
+GraphSubmitted: LogEntryType
 InProgress: BatchStatus
+JSONLines: DatasetFormat
+JobCancelled: LogEntryType
+JobCreated: LogEntryType
+JobFinished: LogEntryType
+JobPaused: LogEntryType
+JobResumed: LogEntryType
 Join: MessageType
 Leave: MessageType
 Leaved: Status
+ModelAdded: LogEntryType
+ModelPromoted: LogEntryType
+ModelRegistered: LogEntryType
 NOT_CONVERGED: ResponseStatus
 NOT_FOUND: ResponseStatus
+NO_QUORUM: ResponseStatus
+NodeDone: GraphNodeStatus
+NodeFailed: GraphNodeStatus
+NodeQueued: GraphNodeStatus
+NodeRunning: GraphNodeStatus
+NodeSkipped: GraphNodeStatus
+NodeWaiting: GraphNodeStatus
 OK: ResponseStatus
+OVER_CAPACITY: ResponseStatus
+Paused: JobStatus
 Ping: MessageType
+RawInput: InputType
+Running: JobStatus
+TaskDropped: LogEntryType
+TaskQueued: LogEntryType
 Timeout: Status
+WorkerReleased: LogEntryType
+WorkerStateChanged: LogEntryType
 
 class AckMessage(_message.Message):
     __slots__ = ["received"]
@@ -27,6 +63,26 @@ class AckMessage(_message.Message):
     received: str
     def __init__(self, received: _Optional[str] = ...) -> None: ...
 
+class AppendLogRequest(_message.Message):
+    __slots__ = ["entries", "epoch", "prevIndex"]
+    ENTRIES_FIELD_NUMBER: _ClassVar[int]
+    EPOCH_FIELD_NUMBER: _ClassVar[int]
+    PREVINDEX_FIELD_NUMBER: _ClassVar[int]
+    entries: _containers.RepeatedCompositeFieldContainer[LogEntry]
+    epoch: int
+    prevIndex: int
+    def __init__(self, epoch: _Optional[int] = ..., prevIndex: _Optional[int] = ..., entries: _Optional[_Iterable[_Union[LogEntry, _Mapping]]] = ...) -> None: ...
+
+class AppendLogResponse(_message.Message):
+    __slots__ = ["epoch", "lastIndex", "status"]
+    EPOCH_FIELD_NUMBER: _ClassVar[int]
+    LASTINDEX_FIELD_NUMBER: _ClassVar[int]
+    STATUS_FIELD_NUMBER: _ClassVar[int]
+    epoch: int
+    lastIndex: int
+    status: ResponseStatus
+    def __init__(self, status: _Optional[_Union[ResponseStatus, str]] = ..., lastIndex: _Optional[int] = ..., epoch: _Optional[int] = ...) -> None: ...
+
 class BackupRequest(_message.Message):
     __slots__ = ["backup"]
     BACKUP_FIELD_NUMBER: _ClassVar[int]
@@ -34,16 +90,30 @@ class BackupRequest(_message.Message):
     def __init__(self, backup: _Optional[_Union[CoordinatorBackup, _Mapping]] = ...) -> None: ...
 
 class BackupResponse(_message.Message):
-    __slots__ = []
-    def __init__(self) -> None: ...
+    __slots__ = ["status"]
+    STATUS_FIELD_NUMBER: _ClassVar[int]
+    status: ResponseStatus
+    def __init__(self, status: _Optional[_Union[ResponseStatus, str]] = ...) -> None: ...
 
 class BatchInput(_message.Message):
-    __slots__ = ["batchId", "inputs"]
+    __slots__ = ["batchId", "inputs", "version"]
     BATCHID_FIELD_NUMBER: _ClassVar[int]
     INPUTS_FIELD_NUMBER: _ClassVar[int]
+    VERSION_FIELD_NUMBER: _ClassVar[int]
     batchId: int
     inputs: _containers.RepeatedScalarFieldContainer[str]
-    def __init__(self, batchId: _Optional[int] = ..., inputs: _Optional[_Iterable[str]] = ...) -> None: ...
+    version: int
+    def __init__(self, batchId: _Optional[int] = ..., inputs: _Optional[_Iterable[str]] = ..., version: _Optional[int] = ...) -> None: ...
+
+class BatchLease(_message.Message):
+    __slots__ = ["expiry", "id", "worker"]
+    EXPIRY_FIELD_NUMBER: _ClassVar[int]
+    ID_FIELD_NUMBER: _ClassVar[int]
+    WORKER_FIELD_NUMBER: _ClassVar[int]
+    expiry: _timestamp_pb2.Timestamp
+    id: int
+    worker: str
+    def __init__(self, id: _Optional[int] = ..., worker: _Optional[str] = ..., expiry: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ...) -> None: ...
 
 class BatchOutput(_message.Message):
     __slots__ = ["batchId", "metric", "results"]
@@ -56,18 +126,20 @@ class BatchOutput(_message.Message):
     def __init__(self, batchId: _Optional[int] = ..., results: _Optional[_Iterable[_Union[EvalResult, _Mapping]]] = ..., metric: _Optional[float] = ...) -> None: ...
 
 class BatchState(_message.Message):
-    __slots__ = ["batchInput", "batchOutput", "queryTime", "receiveTime", "status"]
+    __slots__ = ["batchInput", "batchOutput", "leases", "queryTime", "receiveTime", "status"]
     BATCHINPUT_FIELD_NUMBER: _ClassVar[int]
     BATCHOUTPUT_FIELD_NUMBER: _ClassVar[int]
+    LEASES_FIELD_NUMBER: _ClassVar[int]
     QUERYTIME_FIELD_NUMBER: _ClassVar[int]
     RECEIVETIME_FIELD_NUMBER: _ClassVar[int]
     STATUS_FIELD_NUMBER: _ClassVar[int]
     batchInput: BatchInput
     batchOutput: BatchOutput
+    leases: _containers.RepeatedCompositeFieldContainer[BatchLease]
     queryTime: _timestamp_pb2.Timestamp
     receiveTime: _timestamp_pb2.Timestamp
     status: BatchStatus
-    def __init__(self, status: _Optional[_Union[BatchStatus, str]] = ..., batchInput: _Optional[_Union[BatchInput, _Mapping]] = ..., batchOutput: _Optional[_Union[BatchOutput, _Mapping]] = ..., queryTime: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., receiveTime: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ...) -> None: ...
+    def __init__(self, status: _Optional[_Union[BatchStatus, str]] = ..., batchInput: _Optional[_Union[BatchInput, _Mapping]] = ..., batchOutput: _Optional[_Union[BatchOutput, _Mapping]] = ..., queryTime: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., receiveTime: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., leases: _Optional[_Iterable[_Union[BatchLease, _Mapping]]] = ...) -> None: ...
 
 class BulkLookupRequest(_message.Message):
     __slots__ = ["filenames", "seq"]
@@ -88,23 +160,61 @@ class BulkLookupResponse(_message.Message):
     def __init__(self, ip: _Optional[str] = ..., port: _Optional[int] = ..., missingFiles: _Optional[_Iterable[str]] = ...) -> None: ...
 
 class CoordinatorBackup(_message.Message):
-    __slots__ = ["activeJobs", "completedJobs", "modelStore", "pendingJobs"]
+    __slots__ = ["activeJobs", "completedJobs", "datasets", "epoch", "graphs", "logIndex", "modelStore", "models", "pendingJobs", "taskQueue", "unhealthyWorkers"]
     class ModelStoreEntry(_message.Message):
         __slots__ = ["key", "value"]
         KEY_FIELD_NUMBER: _ClassVar[int]
         VALUE_FIELD_NUMBER: _ClassVar[int]
         key: str
-        value: str
-        def __init__(self, key: _Optional[str] = ..., value: _Optional[str] = ...) -> None: ...
+        value: ModelVersions
+        def __init__(self, key: _Optional[str] = ..., value: _Optional[_Union[ModelVersions, _Mapping]] = ...) -> None: ...
     ACTIVEJOBS_FIELD_NUMBER: _ClassVar[int]
     COMPLETEDJOBS_FIELD_NUMBER: _ClassVar[int]
+    DATASETS_FIELD_NUMBER: _ClassVar[int]
+    EPOCH_FIELD_NUMBER: _ClassVar[int]
+    GRAPHS_FIELD_NUMBER: _ClassVar[int]
+    LOGINDEX_FIELD_NUMBER: _ClassVar[int]
     MODELSTORE_FIELD_NUMBER: _ClassVar[int]
+    MODELS_FIELD_NUMBER: _ClassVar[int]
     PENDINGJOBS_FIELD_NUMBER: _ClassVar[int]
+    TASKQUEUE_FIELD_NUMBER: _ClassVar[int]
+    UNHEALTHYWORKERS_FIELD_NUMBER: _ClassVar[int]
     activeJobs: _containers.RepeatedCompositeFieldContainer[Job]
     completedJobs: _containers.RepeatedCompositeFieldContainer[Job]
-    modelStore: _containers.ScalarMap[str, str]
+    datasets: _containers.RepeatedCompositeFieldContainer[DatasetSpec]
+    epoch: int
+    graphs: _containers.RepeatedCompositeFieldContainer[JobGraph]
+    logIndex: int
+    modelStore: _containers.MessageMap[str, ModelVersions]
+    models: _containers.RepeatedCompositeFieldContainer[ModelSpec]
     pendingJobs: _containers.RepeatedCompositeFieldContainer[Job]
-    def __init__(self, modelStore: _Optional[_Mapping[str, str]] = ..., activeJobs: _Optional[_Iterable[_Union[Job, _Mapping]]] = ..., completedJobs: _Optional[_Iterable[_Union[Job, _Mapping]]] = ..., pendingJobs: _Optional[_Iterable[_Union[Job, _Mapping]]] = ...) -> None: ...
+    taskQueue: _containers.RepeatedCompositeFieldContainer[InferenceTask]
+    unhealthyWorkers: _containers.RepeatedScalarFieldContainer[str]
+    def __init__(self, modelStore: _Optional[_Mapping[str, ModelVersions]] = ..., activeJobs: _Optional[_Iterable[_Union[Job, _Mapping]]] = ..., completedJobs: _Optional[_Iterable[_Union[Job, _Mapping]]] = ..., pendingJobs: _Optional[_Iterable[_Union[Job, _Mapping]]] = ..., taskQueue: _Optional[_Iterable[_Union[InferenceTask, _Mapping]]] = ..., logIndex: _Optional[int] = ..., epoch: _Optional[int] = ..., graphs: _Optional[_Iterable[_Union[JobGraph, _Mapping]]] = ..., models: _Optional[_Iterable[_Union[ModelSpec, _Mapping]]] = ..., datasets: _Optional[_Iterable[_Union[DatasetSpec, _Mapping]]] = ..., unhealthyWorkers: _Optional[_Iterable[str]] = ...) -> None: ...
+
+class DatasetSpec(_message.Message):
+    __slots__ = ["columns", "delimiter", "format", "inputField", "inputPattern", "labelColumn", "labelField", "labels", "name", "registerTime"]
+    COLUMNS_FIELD_NUMBER: _ClassVar[int]
+    DELIMITER_FIELD_NUMBER: _ClassVar[int]
+    FORMAT_FIELD_NUMBER: _ClassVar[int]
+    INPUTFIELD_FIELD_NUMBER: _ClassVar[int]
+    INPUTPATTERN_FIELD_NUMBER: _ClassVar[int]
+    LABELCOLUMN_FIELD_NUMBER: _ClassVar[int]
+    LABELFIELD_FIELD_NUMBER: _ClassVar[int]
+    LABELS_FIELD_NUMBER: _ClassVar[int]
+    NAME_FIELD_NUMBER: _ClassVar[int]
+    REGISTERTIME_FIELD_NUMBER: _ClassVar[int]
+    columns: int
+    delimiter: str
+    format: DatasetFormat
+    inputField: str
+    inputPattern: str
+    labelColumn: int
+    labelField: str
+    labels: _containers.RepeatedScalarFieldContainer[str]
+    name: str
+    registerTime: _timestamp_pb2.Timestamp
+    def __init__(self, name: _Optional[str] = ..., format: _Optional[_Union[DatasetFormat, str]] = ..., delimiter: _Optional[str] = ..., columns: _Optional[int] = ..., labelColumn: _Optional[int] = ..., inputField: _Optional[str] = ..., labelField: _Optional[str] = ..., labels: _Optional[_Iterable[str]] = ..., inputPattern: _Optional[str] = ..., registerTime: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ...) -> None: ...
 
 class DeleteRequest(_message.Message):
     __slots__ = ["filename", "seq"]
@@ -144,6 +254,16 @@ class EvaluateResponse(_message.Message):
     status: ResponseStatus
     def __init__(self, results: _Optional[_Iterable[_Union[EvalResult, _Mapping]]] = ..., metric: _Optional[float] = ..., status: _Optional[_Union[ResponseStatus, str]] = ...) -> None: ...
 
+class FetchCapabilitiesRequest(_message.Message):
+    __slots__ = []
+    def __init__(self) -> None: ...
+
+class FetchCapabilitiesResponse(_message.Message):
+    __slots__ = ["capabilities"]
+    CAPABILITIES_FIELD_NUMBER: _ClassVar[int]
+    capabilities: WorkerCapabilities
+    def __init__(self, capabilities: _Optional[_Union[WorkerCapabilities, _Mapping]] = ...) -> None: ...
+
 class FetchSequenceRequest(_message.Message):
     __slots__ = []
     def __init__(self) -> None: ...
@@ -156,6 +276,34 @@ class FetchSequenceResponse(_message.Message):
     status: ResponseStatus
     def __init__(self, status: _Optional[_Union[ResponseStatus, str]] = ..., seq: _Optional[_Union[Sequence, _Mapping]] = ...) -> None: ...
 
+class FetchSnapshotRequest(_message.Message):
+    __slots__ = []
+    def __init__(self) -> None: ...
+
+class FetchSnapshotResponse(_message.Message):
+    __slots__ = ["snapshot"]
+    SNAPSHOT_FIELD_NUMBER: _ClassVar[int]
+    snapshot: CoordinatorBackup
+    def __init__(self, snapshot: _Optional[_Union[CoordinatorBackup, _Mapping]] = ...) -> None: ...
+
+class FileTableRecord(_message.Message):
+    __slots__ = ["concatName", "filename", "seq", "writeId"]
+    CONCATNAME_FIELD_NUMBER: _ClassVar[int]
+    FILENAME_FIELD_NUMBER: _ClassVar[int]
+    SEQ_FIELD_NUMBER: _ClassVar[int]
+    WRITEID_FIELD_NUMBER: _ClassVar[int]
+    concatName: str
+    filename: str
+    seq: Sequence
+    writeId: WriteId
+    def __init__(self, filename: _Optional[str] = ..., concatName: _Optional[str] = ..., seq: _Optional[_Union[Sequence, _Mapping]] = ..., writeId: _Optional[_Union[WriteId, _Mapping]] = ...) -> None: ...
+
+class FileTableSnapshot(_message.Message):
+    __slots__ = ["records"]
+    RECORDS_FIELD_NUMBER: _ClassVar[int]
+    records: _containers.RepeatedCompositeFieldContainer[FileTableRecord]
+    def __init__(self, records: _Optional[_Iterable[_Union[FileTableRecord, _Mapping]]] = ...) -> None: ...
+
 class FinishInferenceRequest(_message.Message):
     __slots__ = []
     def __init__(self) -> None: ...
@@ -164,6 +312,22 @@ class FinishInferenceResponse(_message.Message):
     __slots__ = []
     def __init__(self) -> None: ...
 
+class GraphNode(_message.Message):
+    __slots__ = ["filter", "jobId", "name", "status", "task", "upstream"]
+    FILTER_FIELD_NUMBER: _ClassVar[int]
+    JOBID_FIELD_NUMBER: _ClassVar[int]
+    NAME_FIELD_NUMBER: _ClassVar[int]
+    STATUS_FIELD_NUMBER: _ClassVar[int]
+    TASK_FIELD_NUMBER: _ClassVar[int]
+    UPSTREAM_FIELD_NUMBER: _ClassVar[int]
+    filter: str
+    jobId: str
+    name: str
+    status: GraphNodeStatus
+    task: InferenceTask
+    upstream: str
+    def __init__(self, name: _Optional[str] = ..., task: _Optional[_Union[InferenceTask, _Mapping]] = ..., upstream: _Optional[str] = ..., filter: _Optional[str] = ..., status: _Optional[_Union[GraphNodeStatus, str]] = ..., jobId: _Optional[str] = ...) -> None: ...
+
 class GreetRequest(_message.Message):
     __slots__ = ["name"]
     NAME_FIELD_NUMBER: _ClassVar[int]
@@ -176,6 +340,18 @@ class GreetResponse(_message.Message):
     message: str
     def __init__(self, message: _Optional[str] = ...) -> None: ...
 
+class HealthRequest(_message.Message):
+    __slots__ = []
+    def __init__(self) -> None: ...
+
+class HealthResponse(_message.Message):
+    __slots__ = ["memoryMb", "status"]
+    MEMORYMB_FIELD_NUMBER: _ClassVar[int]
+    STATUS_FIELD_NUMBER: _ClassVar[int]
+    memoryMb: int
+    status: ResponseStatus
+    def __init__(self, status: _Optional[_Union[ResponseStatus, str]] = ..., memoryMb: _Optional[int] = ...) -> None: ...
+
 class HeartbeatRequest(_message.Message):
     __slots__ = []
     def __init__(self) -> None: ...
@@ -201,52 +377,142 @@ class IDunnoStatusResponse(_message.Message):
     def __init__(self, message: _Optional[str] = ...) -> None: ...
 
 class InferenceRequest(_message.Message):
-    __slots__ = ["inferenceTask", "jobId"]
+    __slots__ = ["inferenceTask", "jobId", "spec"]
     INFERENCETASK_FIELD_NUMBER: _ClassVar[int]
     JOBID_FIELD_NUMBER: _ClassVar[int]
+    SPEC_FIELD_NUMBER: _ClassVar[int]
     inferenceTask: InferenceTask
     jobId: str
-    def __init__(self, inferenceTask: _Optional[_Union[InferenceTask, _Mapping]] = ..., jobId: _Optional[str] = ...) -> None: ...
+    spec: ModelSpec
+    def __init__(self, inferenceTask: _Optional[_Union[InferenceTask, _Mapping]] = ..., jobId: _Optional[str] = ..., spec: _Optional[_Union[ModelSpec, _Mapping]] = ...) -> None: ...
 
 class InferenceResponse(_message.Message):
-    __slots__ = ["status"]
+    __slots__ = ["message", "retryAfter", "status"]
+    MESSAGE_FIELD_NUMBER: _ClassVar[int]
+    RETRYAFTER_FIELD_NUMBER: _ClassVar[int]
     STATUS_FIELD_NUMBER: _ClassVar[int]
+    message: str
+    retryAfter: int
     status: ResponseStatus
-    def __init__(self, status: _Optional[_Union[ResponseStatus, str]] = ...) -> None: ...
+    def __init__(self, status: _Optional[_Union[ResponseStatus, str]] = ..., retryAfter: _Optional[int] = ..., message: _Optional[str] = ...) -> None: ...
 
 class InferenceTask(_message.Message):
-    __slots__ = ["batchSize", "model"]
+    __slots__ = ["batchSize", "deadline", "filter", "graphId", "minWorkers", "model", "node", "placement", "queueTime", "split", "upstreamJob", "user", "version", "weight"]
     BATCHSIZE_FIELD_NUMBER: _ClassVar[int]
+    DEADLINE_FIELD_NUMBER: _ClassVar[int]
+    FILTER_FIELD_NUMBER: _ClassVar[int]
+    GRAPHID_FIELD_NUMBER: _ClassVar[int]
+    MINWORKERS_FIELD_NUMBER: _ClassVar[int]
     MODEL_FIELD_NUMBER: _ClassVar[int]
+    NODE_FIELD_NUMBER: _ClassVar[int]
+    PLACEMENT_FIELD_NUMBER: _ClassVar[int]
+    QUEUETIME_FIELD_NUMBER: _ClassVar[int]
+    SPLIT_FIELD_NUMBER: _ClassVar[int]
+    UPSTREAMJOB_FIELD_NUMBER: _ClassVar[int]
+    USER_FIELD_NUMBER: _ClassVar[int]
+    VERSION_FIELD_NUMBER: _ClassVar[int]
+    WEIGHT_FIELD_NUMBER: _ClassVar[int]
     batchSize: int
+    deadline: _timestamp_pb2.Timestamp
+    filter: str
+    graphId: str
+    minWorkers: int
     model: str
-    def __init__(self, model: _Optional[str] = ..., batchSize: _Optional[int] = ...) -> None: ...
+    node: str
+    placement: PlacementConstraints
+    queueTime: _timestamp_pb2.Timestamp
+    split: _containers.RepeatedCompositeFieldContainer[VersionShare]
+    upstreamJob: str
+    user: str
+    version: int
+    weight: float
+    def __init__(self, model: _Optional[str] = ..., batchSize: _Optional[int] = ..., weight: _Optional[float] = ..., minWorkers: _Optional[int] = ..., deadline: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., user: _Optional[str] = ..., queueTime: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., upstreamJob: _Optional[str] = ..., filter: _Optional[str] = ..., graphId: _Optional[str] = ..., node: _Optional[str] = ..., version: _Optional[int] = ..., split: _Optional[_Iterable[_Union[VersionShare, _Mapping]]] = ..., placement: _Optional[_Union[PlacementConstraints, _Mapping]] = ...) -> None: ...
+
+class InvalidRow(_message.Message):
+    __slots__ = ["line", "reason", "row"]
+    LINE_FIELD_NUMBER: _ClassVar[int]
+    REASON_FIELD_NUMBER: _ClassVar[int]
+    ROW_FIELD_NUMBER: _ClassVar[int]
+    line: int
+    reason: str
+    row: str
+    def __init__(self, line: _Optional[int] = ..., row: _Optional[str] = ..., reason: _Optional[str] = ...) -> None: ...
 
 class Job(_message.Message):
-    __slots__ = ["batchSize", "batchStates", "completedQueries", "dataset", "finishTime", "id", "modelType", "queryProcessTimes", "queryRates", "startTime", "totalQueries"]
+    __slots__ = ["batchSize", "batchStates", "completedQueries", "dataset", "deadline", "duplicateOutputs", "filter", "finishTime", "graphId", "id", "invalidRowSamples", "invalidRows", "leaseCount", "minWorkers", "modelType", "node", "placement", "queryProcessTimes", "queryRates", "startTime", "status", "totalQueries", "upstreamJob", "versions", "weight"]
     BATCHSIZE_FIELD_NUMBER: _ClassVar[int]
     BATCHSTATES_FIELD_NUMBER: _ClassVar[int]
     COMPLETEDQUERIES_FIELD_NUMBER: _ClassVar[int]
     DATASET_FIELD_NUMBER: _ClassVar[int]
+    DEADLINE_FIELD_NUMBER: _ClassVar[int]
+    DUPLICATEOUTPUTS_FIELD_NUMBER: _ClassVar[int]
+    FILTER_FIELD_NUMBER: _ClassVar[int]
     FINISHTIME_FIELD_NUMBER: _ClassVar[int]
+    GRAPHID_FIELD_NUMBER: _ClassVar[int]
     ID_FIELD_NUMBER: _ClassVar[int]
+    INVALIDROWSAMPLES_FIELD_NUMBER: _ClassVar[int]
+    INVALIDROWS_FIELD_NUMBER: _ClassVar[int]
+    LEASECOUNT_FIELD_NUMBER: _ClassVar[int]
+    MINWORKERS_FIELD_NUMBER: _ClassVar[int]
     MODELTYPE_FIELD_NUMBER: _ClassVar[int]
+    NODE_FIELD_NUMBER: _ClassVar[int]
+    PLACEMENT_FIELD_NUMBER: _ClassVar[int]
     QUERYPROCESSTIMES_FIELD_NUMBER: _ClassVar[int]
     QUERYRATES_FIELD_NUMBER: _ClassVar[int]
     STARTTIME_FIELD_NUMBER: _ClassVar[int]
+    STATUS_FIELD_NUMBER: _ClassVar[int]
     TOTALQUERIES_FIELD_NUMBER: _ClassVar[int]
+    UPSTREAMJOB_FIELD_NUMBER: _ClassVar[int]
+    VERSIONS_FIELD_NUMBER: _ClassVar[int]
+    WEIGHT_FIELD_NUMBER: _ClassVar[int]
     batchSize: int
     batchStates: _containers.RepeatedCompositeFieldContainer[BatchState]
     completedQueries: int
     dataset: str
+    deadline: _timestamp_pb2.Timestamp
+    duplicateOutputs: int
+    filter: str
     finishTime: _timestamp_pb2.Timestamp
+    graphId: str
     id: str
+    invalidRowSamples: _containers.RepeatedCompositeFieldContainer[InvalidRow]
+    invalidRows: int
+    leaseCount: int
+    minWorkers: int
     modelType: str
+    node: str
+    placement: PlacementConstraints
     queryProcessTimes: _containers.RepeatedScalarFieldContainer[float]
     queryRates: _containers.RepeatedScalarFieldContainer[float]
     startTime: _timestamp_pb2.Timestamp
+    status: JobStatus
     totalQueries: int
-    def __init__(self, id: _Optional[str] = ..., modelType: _Optional[str] = ..., dataset: _Optional[str] = ..., batchSize: _Optional[int] = ..., startTime: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., finishTime: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., totalQueries: _Optional[int] = ..., completedQueries: _Optional[int] = ..., batchStates: _Optional[_Iterable[_Union[BatchState, _Mapping]]] = ..., queryRates: _Optional[_Iterable[float]] = ..., queryProcessTimes: _Optional[_Iterable[float]] = ...) -> None: ...
+    upstreamJob: str
+    versions: _containers.RepeatedCompositeFieldContainer[VersionShare]
+    weight: float
+    def __init__(self, id: _Optional[str] = ..., modelType: _Optional[str] = ..., dataset: _Optional[str] = ..., batchSize: _Optional[int] = ..., startTime: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., finishTime: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., totalQueries: _Optional[int] = ..., completedQueries: _Optional[int] = ..., batchStates: _Optional[_Iterable[_Union[BatchState, _Mapping]]] = ..., queryRates: _Optional[_Iterable[float]] = ..., queryProcessTimes: _Optional[_Iterable[float]] = ..., status: _Optional[_Union[JobStatus, str]] = ..., weight: _Optional[float] = ..., minWorkers: _Optional[int] = ..., deadline: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., duplicateOutputs: _Optional[int] = ..., leaseCount: _Optional[int] = ..., upstreamJob: _Optional[str] = ..., filter: _Optional[str] = ..., graphId: _Optional[str] = ..., node: _Optional[str] = ..., invalidRows: _Optional[int] = ..., invalidRowSamples: _Optional[_Iterable[_Union[InvalidRow, _Mapping]]] = ..., versions: _Optional[_Iterable[_Union[VersionShare, _Mapping]]] = ..., placement: _Optional[_Union[PlacementConstraints, _Mapping]] = ...) -> None: ...
+
+class JobControlRequest(_message.Message):
+    __slots__ = ["jobId"]
+    JOBID_FIELD_NUMBER: _ClassVar[int]
+    jobId: str
+    def __init__(self, jobId: _Optional[str] = ...) -> None: ...
+
+class JobControlResponse(_message.Message):
+    __slots__ = ["status"]
+    STATUS_FIELD_NUMBER: _ClassVar[int]
+    status: ResponseStatus
+    def __init__(self, status: _Optional[_Union[ResponseStatus, str]] = ...) -> None: ...
+
+class JobGraph(_message.Message):
+    __slots__ = ["id", "nodes", "submitTime"]
+    ID_FIELD_NUMBER: _ClassVar[int]
+    NODES_FIELD_NUMBER: _ClassVar[int]
+    SUBMITTIME_FIELD_NUMBER: _ClassVar[int]
+    id: str
+    nodes: _containers.RepeatedCompositeFieldContainer[GraphNode]
+    submitTime: _timestamp_pb2.Timestamp
+    def __init__(self, id: _Optional[str] = ..., nodes: _Optional[_Iterable[_Union[GraphNode, _Mapping]]] = ..., submitTime: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ...) -> None: ...
 
 class JoinMessage(_message.Message):
     __slots__ = ["process"]
@@ -260,6 +526,73 @@ class LeaveMessage(_message.Message):
     process: Process
     def __init__(self, process: _Optional[_Union[Process, _Mapping]] = ...) -> None: ...
 
+class ListDatasetsRequest(_message.Message):
+    __slots__ = []
+    def __init__(self) -> None: ...
+
+class ListDatasetsResponse(_message.Message):
+    __slots__ = ["datasets"]
+    DATASETS_FIELD_NUMBER: _ClassVar[int]
+    datasets: _containers.RepeatedCompositeFieldContainer[DatasetSpec]
+    def __init__(self, datasets: _Optional[_Iterable[_Union[DatasetSpec, _Mapping]]] = ...) -> None: ...
+
+class ListModelsRequest(_message.Message):
+    __slots__ = ["name"]
+    NAME_FIELD_NUMBER: _ClassVar[int]
+    name: str
+    def __init__(self, name: _Optional[str] = ...) -> None: ...
+
+class ListModelsResponse(_message.Message):
+    __slots__ = ["models", "trained"]
+    class TrainedEntry(_message.Message):
+        __slots__ = ["key", "value"]
+        KEY_FIELD_NUMBER: _ClassVar[int]
+        VALUE_FIELD_NUMBER: _ClassVar[int]
+        key: str
+        value: ModelVersions
+        def __init__(self, key: _Optional[str] = ..., value: _Optional[_Union[ModelVersions, _Mapping]] = ...) -> None: ...
+    MODELS_FIELD_NUMBER: _ClassVar[int]
+    TRAINED_FIELD_NUMBER: _ClassVar[int]
+    models: _containers.RepeatedCompositeFieldContainer[ModelSpec]
+    trained: _containers.MessageMap[str, ModelVersions]
+    def __init__(self, models: _Optional[_Iterable[_Union[ModelSpec, _Mapping]]] = ..., trained: _Optional[_Mapping[str, ModelVersions]] = ...) -> None: ...
+
+class LogEntry(_message.Message):
+    __slots__ = ["batchId", "batchOutput", "datasetSpec", "epoch", "graph", "index", "inferenceTask", "job", "jobId", "lease", "modelSpec", "runnerUnhealthy", "time", "trainTask", "type", "worker"]
+    BATCHID_FIELD_NUMBER: _ClassVar[int]
+    BATCHOUTPUT_FIELD_NUMBER: _ClassVar[int]
+    DATASETSPEC_FIELD_NUMBER: _ClassVar[int]
+    EPOCH_FIELD_NUMBER: _ClassVar[int]
+    GRAPH_FIELD_NUMBER: _ClassVar[int]
+    INDEX_FIELD_NUMBER: _ClassVar[int]
+    INFERENCETASK_FIELD_NUMBER: _ClassVar[int]
+    JOB_FIELD_NUMBER: _ClassVar[int]
+    JOBID_FIELD_NUMBER: _ClassVar[int]
+    LEASE_FIELD_NUMBER: _ClassVar[int]
+    MODELSPEC_FIELD_NUMBER: _ClassVar[int]
+    RUNNERUNHEALTHY_FIELD_NUMBER: _ClassVar[int]
+    TIME_FIELD_NUMBER: _ClassVar[int]
+    TRAINTASK_FIELD_NUMBER: _ClassVar[int]
+    TYPE_FIELD_NUMBER: _ClassVar[int]
+    WORKER_FIELD_NUMBER: _ClassVar[int]
+    batchId: int
+    batchOutput: BatchOutput
+    datasetSpec: DatasetSpec
+    epoch: int
+    graph: JobGraph
+    index: int
+    inferenceTask: InferenceTask
+    job: Job
+    jobId: str
+    lease: BatchLease
+    modelSpec: ModelSpec
+    runnerUnhealthy: bool
+    time: _timestamp_pb2.Timestamp
+    trainTask: TrainTask
+    type: LogEntryType
+    worker: Process
+    def __init__(self, index: _Optional[int] = ..., epoch: _Optional[int] = ..., type: _Optional[_Union[LogEntryType, str]] = ..., trainTask: _Optional[_Union[TrainTask, _Mapping]] = ..., inferenceTask: _Optional[_Union[InferenceTask, _Mapping]] = ..., job: _Optional[_Union[Job, _Mapping]] = ..., jobId: _Optional[str] = ..., worker: _Optional[_Union[Process, _Mapping]] = ..., batchId: _Optional[int] = ..., batchOutput: _Optional[_Union[BatchOutput, _Mapping]] = ..., time: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., lease: _Optional[_Union[BatchLease, _Mapping]] = ..., graph: _Optional[_Union[JobGraph, _Mapping]] = ..., modelSpec: _Optional[_Union[ModelSpec, _Mapping]] = ..., datasetSpec: _Optional[_Union[DatasetSpec, _Mapping]] = ..., runnerUnhealthy: bool = ...) -> None: ...
+
 class LookupLeaderRequest(_message.Message):
     __slots__ = []
     def __init__(self) -> None: ...
@@ -302,43 +635,169 @@ class Metadata(_message.Message):
     type: MessageType
     def __init__(self, type: _Optional[_Union[MessageType, str]] = ..., ping: _Optional[_Union[PingMessage, _Mapping]] = ..., ack: _Optional[_Union[AckMessage, _Mapping]] = ..., join: _Optional[_Union[JoinMessage, _Mapping]] = ..., leave: _Optional[_Union[LeaveMessage, _Mapping]] = ...) -> None: ...
 
+class ModelSpec(_message.Message):
+    __slots__ = ["artifact", "framework", "inputPattern", "inputType", "name", "registerTime", "version"]
+    ARTIFACT_FIELD_NUMBER: _ClassVar[int]
+    FRAMEWORK_FIELD_NUMBER: _ClassVar[int]
+    INPUTPATTERN_FIELD_NUMBER: _ClassVar[int]
+    INPUTTYPE_FIELD_NUMBER: _ClassVar[int]
+    NAME_FIELD_NUMBER: _ClassVar[int]
+    REGISTERTIME_FIELD_NUMBER: _ClassVar[int]
+    VERSION_FIELD_NUMBER: _ClassVar[int]
+    artifact: str
+    framework: str
+    inputPattern: str
+    inputType: InputType
+    name: str
+    registerTime: _timestamp_pb2.Timestamp
+    version: int
+    def __init__(self, name: _Optional[str] = ..., version: _Optional[int] = ..., framework: _Optional[str] = ..., inputType: _Optional[_Union[InputType, str]] = ..., inputPattern: _Optional[str] = ..., artifact: _Optional[str] = ..., registerTime: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ...) -> None: ...
+
+class ModelVersions(_message.Message):
+    __slots__ = ["promoted", "versions"]
+    class VersionsEntry(_message.Message):
+        __slots__ = ["key", "value"]
+        KEY_FIELD_NUMBER: _ClassVar[int]
+        VALUE_FIELD_NUMBER: _ClassVar[int]
+        key: int
+        value: TrainedModel
+        def __init__(self, key: _Optional[int] = ..., value: _Optional[_Union[TrainedModel, _Mapping]] = ...) -> None: ...
+    PROMOTED_FIELD_NUMBER: _ClassVar[int]
+    VERSIONS_FIELD_NUMBER: _ClassVar[int]
+    promoted: int
+    versions: _containers.MessageMap[int, TrainedModel]
+    def __init__(self, versions: _Optional[_Mapping[int, TrainedModel]] = ..., promoted: _Optional[int] = ...) -> None: ...
+
 class PingMessage(_message.Message):
     __slots__ = ["processes"]
     PROCESSES_FIELD_NUMBER: _ClassVar[int]
     processes: _containers.RepeatedCompositeFieldContainer[Process]
     def __init__(self, processes: _Optional[_Iterable[_Union[Process, _Mapping]]] = ...) -> None: ...
 
+class PlacementConstraints(_message.Message):
+    __slots__ = ["framework", "labels", "minCpus", "minMemoryMb"]
+    class LabelsEntry(_message.Message):
+        __slots__ = ["key", "value"]
+        KEY_FIELD_NUMBER: _ClassVar[int]
+        VALUE_FIELD_NUMBER: _ClassVar[int]
+        key: str
+        value: str
+        def __init__(self, key: _Optional[str] = ..., value: _Optional[str] = ...) -> None: ...
+    FRAMEWORK_FIELD_NUMBER: _ClassVar[int]
+    LABELS_FIELD_NUMBER: _ClassVar[int]
+    MINCPUS_FIELD_NUMBER: _ClassVar[int]
+    MINMEMORYMB_FIELD_NUMBER: _ClassVar[int]
+    framework: str
+    labels: _containers.ScalarMap[str, str]
+    minCpus: int
+    minMemoryMb: int
+    def __init__(self, minMemoryMb: _Optional[int] = ..., minCpus: _Optional[int] = ..., labels: _Optional[_Mapping[str, str]] = ..., framework: _Optional[str] = ...) -> None: ...
+
+class PredictRequest(_message.Message):
+    __slots__ = ["images", "inputs", "isFilename", "model", "sloMillis", "version"]
+    IMAGES_FIELD_NUMBER: _ClassVar[int]
+    INPUTS_FIELD_NUMBER: _ClassVar[int]
+    ISFILENAME_FIELD_NUMBER: _ClassVar[int]
+    MODEL_FIELD_NUMBER: _ClassVar[int]
+    SLOMILLIS_FIELD_NUMBER: _ClassVar[int]
+    VERSION_FIELD_NUMBER: _ClassVar[int]
+    images: _containers.RepeatedScalarFieldContainer[bytes]
+    inputs: _containers.RepeatedScalarFieldContainer[str]
+    isFilename: bool
+    model: str
+    sloMillis: int
+    version: int
+    def __init__(self, model: _Optional[str] = ..., inputs: _Optional[_Iterable[str]] = ..., images: _Optional[_Iterable[bytes]] = ..., sloMillis: _Optional[int] = ..., isFilename: bool = ..., version: _Optional[int] = ...) -> None: ...
+
+class PredictResponse(_message.Message):
+    __slots__ = ["latencyMillis", "message", "results", "retryAfter", "sloMet", "status", "worker"]
+    LATENCYMILLIS_FIELD_NUMBER: _ClassVar[int]
+    MESSAGE_FIELD_NUMBER: _ClassVar[int]
+    RESULTS_FIELD_NUMBER: _ClassVar[int]
+    RETRYAFTER_FIELD_NUMBER: _ClassVar[int]
+    SLOMET_FIELD_NUMBER: _ClassVar[int]
+    STATUS_FIELD_NUMBER: _ClassVar[int]
+    WORKER_FIELD_NUMBER: _ClassVar[int]
+    latencyMillis: int
+    message: str
+    results: _containers.RepeatedCompositeFieldContainer[EvalResult]
+    retryAfter: int
+    sloMet: bool
+    status: ResponseStatus
+    worker: str
+    def __init__(self, status: _Optional[_Union[ResponseStatus, str]] = ..., results: _Optional[_Iterable[_Union[EvalResult, _Mapping]]] = ..., latencyMillis: _Optional[int] = ..., sloMet: bool = ..., worker: _Optional[str] = ..., retryAfter: _Optional[int] = ..., message: _Optional[str] = ...) -> None: ...
+
 class Process(_message.Message):
-    __slots__ = ["ip", "joinTime", "lastUpdateTime", "port", "status"]
+    __slots__ = ["ip", "joinTime", "lastUpdateTime", "nodeId", "port", "status"]
     IP_FIELD_NUMBER: _ClassVar[int]
     JOINTIME_FIELD_NUMBER: _ClassVar[int]
     LASTUPDATETIME_FIELD_NUMBER: _ClassVar[int]
+    NODEID_FIELD_NUMBER: _ClassVar[int]
     PORT_FIELD_NUMBER: _ClassVar[int]
     STATUS_FIELD_NUMBER: _ClassVar[int]
     ip: str
     joinTime: _timestamp_pb2.Timestamp
     lastUpdateTime: _timestamp_pb2.Timestamp
+    nodeId: str
     port: int
     status: Status
-    def __init__(self, ip: _Optional[str] = ..., port: _Optional[int] = ..., joinTime: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., lastUpdateTime: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., status: _Optional[_Union[Status, str]] = ...) -> None: ...
+    def __init__(self, ip: _Optional[str] = ..., port: _Optional[int] = ..., joinTime: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., lastUpdateTime: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., status: _Optional[_Union[Status, str]] = ..., nodeId: _Optional[str] = ...) -> None: ...
+
+class PromoteModelRequest(_message.Message):
+    __slots__ = ["model", "version"]
+    MODEL_FIELD_NUMBER: _ClassVar[int]
+    VERSION_FIELD_NUMBER: _ClassVar[int]
+    model: str
+    version: int
+    def __init__(self, model: _Optional[str] = ..., version: _Optional[int] = ...) -> None: ...
+
+class PromoteModelResponse(_message.Message):
+    __slots__ = ["message", "status"]
+    MESSAGE_FIELD_NUMBER: _ClassVar[int]
+    STATUS_FIELD_NUMBER: _ClassVar[int]
+    message: str
+    status: ResponseStatus
+    def __init__(self, status: _Optional[_Union[ResponseStatus, str]] = ..., message: _Optional[str] = ...) -> None: ...
 
 class QueryDataRequest(_message.Message):
-    __slots__ = ["batchOutput", "jobId", "worker"]
+    __slots__ = ["batchOutput", "draining", "failed", "jobId", "leaseId", "pipelineDepth", "resident", "runnerUnhealthy", "submitOnly", "worker"]
     BATCHOUTPUT_FIELD_NUMBER: _ClassVar[int]
+    DRAINING_FIELD_NUMBER: _ClassVar[int]
+    FAILED_FIELD_NUMBER: _ClassVar[int]
     JOBID_FIELD_NUMBER: _ClassVar[int]
+    LEASEID_FIELD_NUMBER: _ClassVar[int]
+    PIPELINEDEPTH_FIELD_NUMBER: _ClassVar[int]
+    RESIDENT_FIELD_NUMBER: _ClassVar[int]
+    RUNNERUNHEALTHY_FIELD_NUMBER: _ClassVar[int]
+    SUBMITONLY_FIELD_NUMBER: _ClassVar[int]
     WORKER_FIELD_NUMBER: _ClassVar[int]
     batchOutput: BatchOutput
+    draining: bool
+    failed: bool
     jobId: str
+    leaseId: int
+    pipelineDepth: int
+    resident: _containers.RepeatedCompositeFieldContainer[ResidentModel]
+    runnerUnhealthy: bool
+    submitOnly: bool
     worker: Process
-    def __init__(self, jobId: _Optional[str] = ..., worker: _Optional[_Union[Process, _Mapping]] = ..., batchOutput: _Optional[_Union[BatchOutput, _Mapping]] = ...) -> None: ...
+    def __init__(self, jobId: _Optional[str] = ..., worker: _Optional[_Union[Process, _Mapping]] = ..., batchOutput: _Optional[_Union[BatchOutput, _Mapping]] = ..., draining: bool = ..., leaseId: _Optional[int] = ..., resident: _Optional[_Iterable[_Union[ResidentModel, _Mapping]]] = ..., pipelineDepth: _Optional[int] = ..., submitOnly: bool = ..., runnerUnhealthy: bool = ..., failed: bool = ...) -> None: ...
 
 class QueryDataResponse(_message.Message):
-    __slots__ = ["batchInput", "isFilename"]
+    __slots__ = ["batchInput", "drained", "isFilename", "jobId", "lease", "released"]
     BATCHINPUT_FIELD_NUMBER: _ClassVar[int]
+    DRAINED_FIELD_NUMBER: _ClassVar[int]
     ISFILENAME_FIELD_NUMBER: _ClassVar[int]
+    JOBID_FIELD_NUMBER: _ClassVar[int]
+    LEASE_FIELD_NUMBER: _ClassVar[int]
+    RELEASED_FIELD_NUMBER: _ClassVar[int]
     batchInput: BatchInput
+    drained: bool
     isFilename: bool
-    def __init__(self, batchInput: _Optional[_Union[BatchInput, _Mapping]] = ..., isFilename: bool = ...) -> None: ...
+    jobId: str
+    lease: BatchLease
+    released: bool
+    def __init__(self, batchInput: _Optional[_Union[BatchInput, _Mapping]] = ..., isFilename: bool = ..., lease: _Optional[_Union[BatchLease, _Mapping]] = ..., jobId: _Optional[str] = ..., drained: bool = ..., released: bool = ...) -> None: ...
 
 class ReadRequest(_message.Message):
     __slots__ = ["filename", "localFilename", "seq", "version"]
@@ -362,6 +821,46 @@ class ReadResponse(_message.Message):
     status: ResponseStatus
     def __init__(self, data: _Optional[bytes] = ..., status: _Optional[_Union[ResponseStatus, str]] = ..., seq: _Optional[_Union[Sequence, _Mapping]] = ...) -> None: ...
 
+class RegisterDatasetRequest(_message.Message):
+    __slots__ = ["spec"]
+    SPEC_FIELD_NUMBER: _ClassVar[int]
+    spec: DatasetSpec
+    def __init__(self, spec: _Optional[_Union[DatasetSpec, _Mapping]] = ...) -> None: ...
+
+class RegisterDatasetResponse(_message.Message):
+    __slots__ = ["message", "status"]
+    MESSAGE_FIELD_NUMBER: _ClassVar[int]
+    STATUS_FIELD_NUMBER: _ClassVar[int]
+    message: str
+    status: ResponseStatus
+    def __init__(self, status: _Optional[_Union[ResponseStatus, str]] = ..., message: _Optional[str] = ...) -> None: ...
+
+class RegisterModelRequest(_message.Message):
+    __slots__ = ["spec"]
+    SPEC_FIELD_NUMBER: _ClassVar[int]
+    spec: ModelSpec
+    def __init__(self, spec: _Optional[_Union[ModelSpec, _Mapping]] = ...) -> None: ...
+
+class RegisterModelResponse(_message.Message):
+    __slots__ = ["message", "spec", "status"]
+    MESSAGE_FIELD_NUMBER: _ClassVar[int]
+    SPEC_FIELD_NUMBER: _ClassVar[int]
+    STATUS_FIELD_NUMBER: _ClassVar[int]
+    message: str
+    spec: ModelSpec
+    status: ResponseStatus
+    def __init__(self, status: _Optional[_Union[ResponseStatus, str]] = ..., spec: _Optional[_Union[ModelSpec, _Mapping]] = ..., message: _Optional[str] = ...) -> None: ...
+
+class ResidentModel(_message.Message):
+    __slots__ = ["memoryMb", "model", "version"]
+    MEMORYMB_FIELD_NUMBER: _ClassVar[int]
+    MODEL_FIELD_NUMBER: _ClassVar[int]
+    VERSION_FIELD_NUMBER: _ClassVar[int]
+    memoryMb: int
+    model: str
+    version: int
+    def __init__(self, model: _Optional[str] = ..., version: _Optional[int] = ..., memoryMb: _Optional[int] = ...) -> None: ...
+
 class Sequence(_message.Message):
     __slots__ = ["count", "time"]
     COUNT_FIELD_NUMBER: _ClassVar[int]
@@ -371,28 +870,58 @@ class Sequence(_message.Message):
     def __init__(self, time: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., count: _Optional[int] = ...) -> None: ...
 
 class ServeModelRequest(_message.Message):
-    __slots__ = ["model"]
+    __slots__ = ["artifactPath", "model", "spec"]
+    ARTIFACTPATH_FIELD_NUMBER: _ClassVar[int]
     MODEL_FIELD_NUMBER: _ClassVar[int]
+    SPEC_FIELD_NUMBER: _ClassVar[int]
+    artifactPath: str
     model: str
-    def __init__(self, model: _Optional[str] = ...) -> None: ...
+    spec: ModelSpec
+    def __init__(self, model: _Optional[str] = ..., spec: _Optional[_Union[ModelSpec, _Mapping]] = ..., artifactPath: _Optional[str] = ...) -> None: ...
 
 class ServeModelResponse(_message.Message):
-    __slots__ = ["status"]
+    __slots__ = ["resident", "status", "warm"]
+    RESIDENT_FIELD_NUMBER: _ClassVar[int]
     STATUS_FIELD_NUMBER: _ClassVar[int]
+    WARM_FIELD_NUMBER: _ClassVar[int]
+    resident: _containers.RepeatedCompositeFieldContainer[ResidentModel]
     status: ResponseStatus
-    def __init__(self, status: _Optional[_Union[ResponseStatus, str]] = ...) -> None: ...
+    warm: bool
+    def __init__(self, status: _Optional[_Union[ResponseStatus, str]] = ..., warm: bool = ..., resident: _Optional[_Iterable[_Union[ResidentModel, _Mapping]]] = ...) -> None: ...
+
+class SubmitGraphRequest(_message.Message):
+    __slots__ = ["graph"]
+    GRAPH_FIELD_NUMBER: _ClassVar[int]
+    graph: JobGraph
+    def __init__(self, graph: _Optional[_Union[JobGraph, _Mapping]] = ...) -> None: ...
+
+class SubmitGraphResponse(_message.Message):
+    __slots__ = ["graphId", "message", "status"]
+    GRAPHID_FIELD_NUMBER: _ClassVar[int]
+    MESSAGE_FIELD_NUMBER: _ClassVar[int]
+    STATUS_FIELD_NUMBER: _ClassVar[int]
+    graphId: str
+    message: str
+    status: ResponseStatus
+    def __init__(self, status: _Optional[_Union[ResponseStatus, str]] = ..., graphId: _Optional[str] = ..., message: _Optional[str] = ...) -> None: ...
 
 class TrainRequest(_message.Message):
-    __slots__ = ["trainTask"]
+    __slots__ = ["artifactPath", "spec", "trainTask"]
+    ARTIFACTPATH_FIELD_NUMBER: _ClassVar[int]
+    SPEC_FIELD_NUMBER: _ClassVar[int]
     TRAINTASK_FIELD_NUMBER: _ClassVar[int]
+    artifactPath: str
+    spec: ModelSpec
     trainTask: TrainTask
-    def __init__(self, trainTask: _Optional[_Union[TrainTask, _Mapping]] = ...) -> None: ...
+    def __init__(self, trainTask: _Optional[_Union[TrainTask, _Mapping]] = ..., spec: _Optional[_Union[ModelSpec, _Mapping]] = ..., artifactPath: _Optional[str] = ...) -> None: ...
 
 class TrainResponse(_message.Message):
-    __slots__ = ["status"]
+    __slots__ = ["status", "version"]
     STATUS_FIELD_NUMBER: _ClassVar[int]
+    VERSION_FIELD_NUMBER: _ClassVar[int]
     status: ResponseStatus
-    def __init__(self, status: _Optional[_Union[ResponseStatus, str]] = ...) -> None: ...
+    version: int
+    def __init__(self, status: _Optional[_Union[ResponseStatus, str]] = ..., version: _Optional[int] = ...) -> None: ...
 
 class TrainTask(_message.Message):
     __slots__ = ["dataset", "model"]
@@ -402,6 +931,16 @@ class TrainTask(_message.Message):
     model: str
     def __init__(self, model: _Optional[str] = ..., dataset: _Optional[str] = ...) -> None: ...
 
+class TrainedModel(_message.Message):
+    __slots__ = ["dataset", "spec", "trainTime"]
+    DATASET_FIELD_NUMBER: _ClassVar[int]
+    SPEC_FIELD_NUMBER: _ClassVar[int]
+    TRAINTIME_FIELD_NUMBER: _ClassVar[int]
+    dataset: str
+    spec: ModelSpec
+    trainTime: _timestamp_pb2.Timestamp
+    def __init__(self, spec: _Optional[_Union[ModelSpec, _Mapping]] = ..., dataset: _Optional[str] = ..., trainTime: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ...) -> None: ...
+
 class UpdateLeaderRequest(_message.Message):
     __slots__ = ["leader"]
     LEADER_FIELD_NUMBER: _ClassVar[int]
@@ -414,6 +953,35 @@ class UpdateLeaderResponse(_message.Message):
     status: ResponseStatus
     def __init__(self, status: _Optional[_Union[ResponseStatus, str]] = ...) -> None: ...
 
+class VersionShare(_message.Message):
+    __slots__ = ["percent", "version"]
+    PERCENT_FIELD_NUMBER: _ClassVar[int]
+    VERSION_FIELD_NUMBER: _ClassVar[int]
+    percent: int
+    version: int
+    def __init__(self, version: _Optional[int] = ..., percent: _Optional[int] = ...) -> None: ...
+
+class WorkerCapabilities(_message.Message):
+    __slots__ = ["cpus", "labels", "memoryMb", "models", "runner"]
+    class LabelsEntry(_message.Message):
+        __slots__ = ["key", "value"]
+        KEY_FIELD_NUMBER: _ClassVar[int]
+        VALUE_FIELD_NUMBER: _ClassVar[int]
+        key: str
+        value: str
+        def __init__(self, key: _Optional[str] = ..., value: _Optional[str] = ...) -> None: ...
+    CPUS_FIELD_NUMBER: _ClassVar[int]
+    LABELS_FIELD_NUMBER: _ClassVar[int]
+    MEMORYMB_FIELD_NUMBER: _ClassVar[int]
+    MODELS_FIELD_NUMBER: _ClassVar[int]
+    RUNNER_FIELD_NUMBER: _ClassVar[int]
+    cpus: int
+    labels: _containers.ScalarMap[str, str]
+    memoryMb: int
+    models: _containers.RepeatedScalarFieldContainer[str]
+    runner: str
+    def __init__(self, memoryMb: _Optional[int] = ..., cpus: _Optional[int] = ..., models: _Optional[_Iterable[str]] = ..., labels: _Optional[_Mapping[str, str]] = ..., runner: _Optional[str] = ...) -> None: ...
+
 class WriteId(_message.Message):
     __slots__ = ["createTime", "ip", "port"]
     CREATETIME_FIELD_NUMBER: _ClassVar[int]
@@ -453,3 +1021,18 @@ class ResponseStatus(int, metaclass=_enum_type_wrapper.EnumTypeWrapper):
 
 class BatchStatus(int, metaclass=_enum_type_wrapper.EnumTypeWrapper):
     __slots__ = []
+
+class JobStatus(int, metaclass=_enum_type_wrapper.EnumTypeWrapper):
+    __slots__ = []
+
+class LogEntryType(int, metaclass=_enum_type_wrapper.EnumTypeWrapper):
+    __slots__ = []
+
+class InputType(int, metaclass=_enum_type_wrapper.EnumTypeWrapper):
+    __slots__ = []
+
+class DatasetFormat(int, metaclass=_enum_type_wrapper.EnumTypeWrapper):
+    __slots__ = []
+
+class GraphNodeStatus(int, metaclass=_enum_type_wrapper.EnumTypeWrapper):
+    __slots__ = []
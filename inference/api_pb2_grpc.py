@@ -355,6 +355,61 @@ class CoordinatorServiceStub(object):
                 request_serializer=api__pb2.QueryDataRequest.SerializeToString,
                 response_deserializer=api__pb2.QueryDataResponse.FromString,
                 )
+        self.Dispatch = channel.stream_stream(
+                '/api.CoordinatorService/Dispatch',
+                request_serializer=api__pb2.QueryDataRequest.SerializeToString,
+                response_deserializer=api__pb2.QueryDataResponse.FromString,
+                )
+        self.CancelJob = channel.unary_unary(
+                '/api.CoordinatorService/CancelJob',
+                request_serializer=api__pb2.JobControlRequest.SerializeToString,
+                response_deserializer=api__pb2.JobControlResponse.FromString,
+                )
+        self.PauseJob = channel.unary_unary(
+                '/api.CoordinatorService/PauseJob',
+                request_serializer=api__pb2.JobControlRequest.SerializeToString,
+                response_deserializer=api__pb2.JobControlResponse.FromString,
+                )
+        self.ResumeJob = channel.unary_unary(
+                '/api.CoordinatorService/ResumeJob',
+                request_serializer=api__pb2.JobControlRequest.SerializeToString,
+                response_deserializer=api__pb2.JobControlResponse.FromString,
+                )
+        self.SubmitGraph = channel.unary_unary(
+                '/api.CoordinatorService/SubmitGraph',
+                request_serializer=api__pb2.SubmitGraphRequest.SerializeToString,
+                response_deserializer=api__pb2.SubmitGraphResponse.FromString,
+                )
+        self.Predict = channel.unary_unary(
+                '/api.CoordinatorService/Predict',
+                request_serializer=api__pb2.PredictRequest.SerializeToString,
+                response_deserializer=api__pb2.PredictResponse.FromString,
+                )
+        self.RegisterModel = channel.unary_unary(
+                '/api.CoordinatorService/RegisterModel',
+                request_serializer=api__pb2.RegisterModelRequest.SerializeToString,
+                response_deserializer=api__pb2.RegisterModelResponse.FromString,
+                )
+        self.ListModels = channel.unary_unary(
+                '/api.CoordinatorService/ListModels',
+                request_serializer=api__pb2.ListModelsRequest.SerializeToString,
+                response_deserializer=api__pb2.ListModelsResponse.FromString,
+                )
+        self.PromoteModel = channel.unary_unary(
+                '/api.CoordinatorService/PromoteModel',
+                request_serializer=api__pb2.PromoteModelRequest.SerializeToString,
+                response_deserializer=api__pb2.PromoteModelResponse.FromString,
+                )
+        self.RegisterDataset = channel.unary_unary(
+                '/api.CoordinatorService/RegisterDataset',
+                request_serializer=api__pb2.RegisterDatasetRequest.SerializeToString,
+                response_deserializer=api__pb2.RegisterDatasetResponse.FromString,
+                )
+        self.ListDatasets = channel.unary_unary(
+                '/api.CoordinatorService/ListDatasets',
+                request_serializer=api__pb2.ListDatasetsRequest.SerializeToString,
+                response_deserializer=api__pb2.ListDatasetsResponse.FromString,
+                )
         self.IDunnoStatus = channel.unary_unary(
                 '/api.CoordinatorService/IDunnoStatus',
                 request_serializer=api__pb2.IDunnoStatusRequest.SerializeToString,
@@ -365,6 +420,16 @@ class CoordinatorServiceStub(object):
                 request_serializer=api__pb2.BackupRequest.SerializeToString,
                 response_deserializer=api__pb2.BackupResponse.FromString,
                 )
+        self.AppendLog = channel.unary_unary(
+                '/api.CoordinatorService/AppendLog',
+                request_serializer=api__pb2.AppendLogRequest.SerializeToString,
+                response_deserializer=api__pb2.AppendLogResponse.FromString,
+                )
+        self.FetchSnapshot = channel.unary_unary(
+                '/api.CoordinatorService/FetchSnapshot',
+                request_serializer=api__pb2.FetchSnapshotRequest.SerializeToString,
+                response_deserializer=api__pb2.FetchSnapshotResponse.FromString,
+                )
 
 
 class CoordinatorServiceServicer(object):
@@ -391,6 +456,83 @@ class CoordinatorServiceServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def Dispatch(self, request_iterator, context):
+        """long-lived stream of a worker, batches are pushed to the worker as they are available & outputs streamed back
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def CancelJob(self, request, context):
+        """stop a job and write its partial results to SDFS
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def PauseJob(self, request, context):
+        """stop scheduling workers to a job, batch states are kept
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ResumeJob(self, request, context):
+        """resume scheduling workers to a paused job
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def SubmitGraph(self, request, context):
+        """submit a graph of jobs, downstream jobs start when their upstream job completes
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def Predict(self, request, context):
+        """evaluate a few inputs synchronously on a worker already serving the model
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RegisterModel(self, request, context):
+        """add a model to the registry, or a new version of a registered model
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListModels(self, request, context):
+        """list registered models & the datasets they are trained on
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def PromoteModel(self, request, context):
+        """make a trained version of a model the one served by default
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RegisterDataset(self, request, context):
+        """add the schema of a dataset to the registry, or replace it
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListDatasets(self, request, context):
+        """list registered datasets
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
     def IDunnoStatus(self, request, context):
         """get real-time updates on workers & jobs status
         """
@@ -399,7 +541,21 @@ class CoordinatorServiceServicer(object):
         raise NotImplementedError('Method not implemented!')
 
     def Backup(self, request, context):
-        """backup coordinator state
+        """install a snapshot of coordinator state on a standby
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def AppendLog(self, request, context):
+        """replicate coordinator log entries to a standby
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def FetchSnapshot(self, request, context):
+        """fetch current state of a standby, i.e. when it is about to be promoted
         """
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
@@ -423,6 +579,61 @@ def add_CoordinatorServiceServicer_to_server(servicer, server):
                     request_deserializer=api__pb2.QueryDataRequest.FromString,
                     response_serializer=api__pb2.QueryDataResponse.SerializeToString,
             ),
+            'Dispatch': grpc.stream_stream_rpc_method_handler(
+                    servicer.Dispatch,
+                    request_deserializer=api__pb2.QueryDataRequest.FromString,
+                    response_serializer=api__pb2.QueryDataResponse.SerializeToString,
+            ),
+            'CancelJob': grpc.unary_unary_rpc_method_handler(
+                    servicer.CancelJob,
+                    request_deserializer=api__pb2.JobControlRequest.FromString,
+                    response_serializer=api__pb2.JobControlResponse.SerializeToString,
+            ),
+            'PauseJob': grpc.unary_unary_rpc_method_handler(
+                    servicer.PauseJob,
+                    request_deserializer=api__pb2.JobControlRequest.FromString,
+                    response_serializer=api__pb2.JobControlResponse.SerializeToString,
+            ),
+            'ResumeJob': grpc.unary_unary_rpc_method_handler(
+                    servicer.ResumeJob,
+                    request_deserializer=api__pb2.JobControlRequest.FromString,
+                    response_serializer=api__pb2.JobControlResponse.SerializeToString,
+            ),
+            'SubmitGraph': grpc.unary_unary_rpc_method_handler(
+                    servicer.SubmitGraph,
+                    request_deserializer=api__pb2.SubmitGraphRequest.FromString,
+                    response_serializer=api__pb2.SubmitGraphResponse.SerializeToString,
+            ),
+            'Predict': grpc.unary_unary_rpc_method_handler(
+                    servicer.Predict,
+                    request_deserializer=api__pb2.PredictRequest.FromString,
+                    response_serializer=api__pb2.PredictResponse.SerializeToString,
+            ),
+            'RegisterModel': grpc.unary_unary_rpc_method_handler(
+                    servicer.RegisterModel,
+                    request_deserializer=api__pb2.RegisterModelRequest.FromString,
+                    response_serializer=api__pb2.RegisterModelResponse.SerializeToString,
+            ),
+            'ListModels': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListModels,
+                    request_deserializer=api__pb2.ListModelsRequest.FromString,
+                    response_serializer=api__pb2.ListModelsResponse.SerializeToString,
+            ),
+            'PromoteModel': grpc.unary_unary_rpc_method_handler(
+                    servicer.PromoteModel,
+                    request_deserializer=api__pb2.PromoteModelRequest.FromString,
+                    response_serializer=api__pb2.PromoteModelResponse.SerializeToString,
+            ),
+            'RegisterDataset': grpc.unary_unary_rpc_method_handler(
+                    servicer.RegisterDataset,
+                    request_deserializer=api__pb2.RegisterDatasetRequest.FromString,
+                    response_serializer=api__pb2.RegisterDatasetResponse.SerializeToString,
+            ),
+            'ListDatasets': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListDatasets,
+                    request_deserializer=api__pb2.ListDatasetsRequest.FromString,
+                    response_serializer=api__pb2.ListDatasetsResponse.SerializeToString,
+            ),
             'IDunnoStatus': grpc.unary_unary_rpc_method_handler(
                     servicer.IDunnoStatus,
                     request_deserializer=api__pb2.IDunnoStatusRequest.FromString,
@@ -433,6 +644,16 @@ def add_CoordinatorServiceServicer_to_server(servicer, server):
                     request_deserializer=api__pb2.BackupRequest.FromString,
                     response_serializer=api__pb2.BackupResponse.SerializeToString,
             ),
+            'AppendLog': grpc.unary_unary_rpc_method_handler(
+                    servicer.AppendLog,
+                    request_deserializer=api__pb2.AppendLogRequest.FromString,
+                    response_serializer=api__pb2.AppendLogResponse.SerializeToString,
+            ),
+            'FetchSnapshot': grpc.unary_unary_rpc_method_handler(
+                    servicer.FetchSnapshot,
+                    request_deserializer=api__pb2.FetchSnapshotRequest.FromString,
+                    response_serializer=api__pb2.FetchSnapshotResponse.SerializeToString,
+            ),
     }
     generic_handler = grpc.method_handlers_generic_handler(
             'api.CoordinatorService', rpc_method_handlers)
@@ -494,6 +715,193 @@ class CoordinatorService(object):
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
 
+    @staticmethod
+    def Dispatch(request_iterator,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.stream_stream(request_iterator, target, '/api.CoordinatorService/Dispatch',
+            api__pb2.QueryDataRequest.SerializeToString,
+            api__pb2.QueryDataResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def CancelJob(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/api.CoordinatorService/CancelJob',
+            api__pb2.JobControlRequest.SerializeToString,
+            api__pb2.JobControlResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def PauseJob(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/api.CoordinatorService/PauseJob',
+            api__pb2.JobControlRequest.SerializeToString,
+            api__pb2.JobControlResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ResumeJob(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/api.CoordinatorService/ResumeJob',
+            api__pb2.JobControlRequest.SerializeToString,
+            api__pb2.JobControlResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def SubmitGraph(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/api.CoordinatorService/SubmitGraph',
+            api__pb2.SubmitGraphRequest.SerializeToString,
+            api__pb2.SubmitGraphResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def Predict(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/api.CoordinatorService/Predict',
+            api__pb2.PredictRequest.SerializeToString,
+            api__pb2.PredictResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def RegisterModel(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/api.CoordinatorService/RegisterModel',
+            api__pb2.RegisterModelRequest.SerializeToString,
+            api__pb2.RegisterModelResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ListModels(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/api.CoordinatorService/ListModels',
+            api__pb2.ListModelsRequest.SerializeToString,
+            api__pb2.ListModelsResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def PromoteModel(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/api.CoordinatorService/PromoteModel',
+            api__pb2.PromoteModelRequest.SerializeToString,
+            api__pb2.PromoteModelResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def RegisterDataset(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/api.CoordinatorService/RegisterDataset',
+            api__pb2.RegisterDatasetRequest.SerializeToString,
+            api__pb2.RegisterDatasetResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ListDatasets(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/api.CoordinatorService/ListDatasets',
+            api__pb2.ListDatasetsRequest.SerializeToString,
+            api__pb2.ListDatasetsResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
     @staticmethod
     def IDunnoStatus(request,
             target,
@@ -528,6 +936,40 @@ class CoordinatorService(object):
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
 
+    @staticmethod
+    def AppendLog(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/api.CoordinatorService/AppendLog',
+            api__pb2.AppendLogRequest.SerializeToString,
+            api__pb2.AppendLogResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def FetchSnapshot(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/api.CoordinatorService/FetchSnapshot',
+            api__pb2.FetchSnapshotRequest.SerializeToString,
+            api__pb2.FetchSnapshotResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
 
 class WorkerServiceStub(object):
     """Missing associated documentation comment in .proto file."""
@@ -553,6 +995,21 @@ class WorkerServiceStub(object):
                 request_serializer=api__pb2.FinishInferenceRequest.SerializeToString,
                 response_deserializer=api__pb2.FinishInferenceResponse.FromString,
                 )
+        self.Predict = channel.unary_unary(
+                '/api.WorkerService/Predict',
+                request_serializer=api__pb2.PredictRequest.SerializeToString,
+                response_deserializer=api__pb2.PredictResponse.FromString,
+                )
+        self.ServeModel = channel.unary_unary(
+                '/api.WorkerService/ServeModel',
+                request_serializer=api__pb2.ServeModelRequest.SerializeToString,
+                response_deserializer=api__pb2.ServeModelResponse.FromString,
+                )
+        self.FetchCapabilities = channel.unary_unary(
+                '/api.WorkerService/FetchCapabilities',
+                request_serializer=api__pb2.FetchCapabilitiesRequest.SerializeToString,
+                response_deserializer=api__pb2.FetchCapabilitiesResponse.FromString,
+                )
 
 
 class WorkerServiceServicer(object):
@@ -579,6 +1036,27 @@ class WorkerServiceServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def Predict(self, request, context):
+        """evaluate online inputs with the loaded model, alongside the batch being processed
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ServeModel(self, request, context):
+        """load a model for online requests, without assigning the worker a job
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def FetchCapabilities(self, request, context):
+        """capabilities of the worker, fetched by coordinators once it joins
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
 
 def add_WorkerServiceServicer_to_server(servicer, server):
     rpc_method_handlers = {
@@ -597,6 +1075,21 @@ def add_WorkerServiceServicer_to_server(servicer, server):
                     request_deserializer=api__pb2.FinishInferenceRequest.FromString,
                     response_serializer=api__pb2.FinishInferenceResponse.SerializeToString,
             ),
+            'Predict': grpc.unary_unary_rpc_method_handler(
+                    servicer.Predict,
+                    request_deserializer=api__pb2.PredictRequest.FromString,
+                    response_serializer=api__pb2.PredictResponse.SerializeToString,
+            ),
+            'ServeModel': grpc.unary_unary_rpc_method_handler(
+                    servicer.ServeModel,
+                    request_deserializer=api__pb2.ServeModelRequest.FromString,
+                    response_serializer=api__pb2.ServeModelResponse.SerializeToString,
+            ),
+            'FetchCapabilities': grpc.unary_unary_rpc_method_handler(
+                    servicer.FetchCapabilities,
+                    request_deserializer=api__pb2.FetchCapabilitiesRequest.FromString,
+                    response_serializer=api__pb2.FetchCapabilitiesResponse.SerializeToString,
+            ),
     }
     generic_handler = grpc.method_handlers_generic_handler(
             'api.WorkerService', rpc_method_handlers)
@@ -658,6 +1151,57 @@ class WorkerService(object):
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
 
+    @staticmethod
+    def Predict(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/api.WorkerService/Predict',
+            api__pb2.PredictRequest.SerializeToString,
+            api__pb2.PredictResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ServeModel(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/api.WorkerService/ServeModel',
+            api__pb2.ServeModelRequest.SerializeToString,
+            api__pb2.ServeModelResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def FetchCapabilities(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/api.WorkerService/FetchCapabilities',
+            api__pb2.FetchCapabilitiesRequest.SerializeToString,
+            api__pb2.FetchCapabilitiesResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
 
 class InferenceServiceStub(object):
     """Missing associated documentation comment in .proto file."""
@@ -673,6 +1217,11 @@ class InferenceServiceStub(object):
                 request_serializer=api__pb2.GreetRequest.SerializeToString,
                 response_deserializer=api__pb2.GreetResponse.FromString,
                 )
+        self.Health = channel.unary_unary(
+                '/api.InferenceService/Health',
+                request_serializer=api__pb2.HealthRequest.SerializeToString,
+                response_deserializer=api__pb2.HealthResponse.FromString,
+                )
         self.Train = channel.unary_unary(
                 '/api.InferenceService/Train',
                 request_serializer=api__pb2.TrainRequest.SerializeToString,
@@ -699,6 +1248,13 @@ class InferenceServiceServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def Health(self, request, context):
+        """answered as long as the runner is serving requests, used by the worker to supervise it
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
     def Train(self, request, context):
         """pretrain model on specified dataset
         """
@@ -728,6 +1284,11 @@ def add_InferenceServiceServicer_to_server(servicer, server):
                     request_deserializer=api__pb2.GreetRequest.FromString,
                     response_serializer=api__pb2.GreetResponse.SerializeToString,
             ),
+            'Health': grpc.unary_unary_rpc_method_handler(
+                    servicer.Health,
+                    request_deserializer=api__pb2.HealthRequest.FromString,
+                    response_serializer=api__pb2.HealthResponse.SerializeToString,
+            ),
             'Train': grpc.unary_unary_rpc_method_handler(
                     servicer.Train,
                     request_deserializer=api__pb2.TrainRequest.FromString,
@@ -770,6 +1331,23 @@ class InferenceService(object):
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
 
+    @staticmethod
+    def Health(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/api.InferenceService/Health',
+            api__pb2.HealthRequest.SerializeToString,
+            api__pb2.HealthResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
     @staticmethod
     def Train(request,
             target,
@@ -1,4 +1,8 @@
-from typing import Optional
+import gc
+import os
+import importlib.util
+from collections import OrderedDict
+from typing import Optional, Tuple
 from enum import Enum
 
 from model_service import IDunnoModelService
@@ -10,6 +14,8 @@ from api_pb2 import TrainTask
 from api_pb2 import (
     GreetRequest,
     GreetResponse,
+    HealthRequest,
+    HealthResponse,
     TrainRequest,
     TrainResponse,
     ServeModelRequest,
@@ -17,15 +23,26 @@ from api_pb2 import (
     EvaluateRequest,
     EvaluateResponse,
     EvalResult,
+    ResidentModel,
 )
 
+PAGE_SIZE = os.sysconf("SC_PAGE_SIZE")
+
 
 class ModelType(Enum):
     Resnet50 = "resnet50"
     Albert = "albert"
 
 
-def start_inference_service(model: str) -> IDunnoModelService:
+def start_inference_service(model: str, artifact_path: str = "") -> IDunnoModelService:
+    # registered models ship a python module defining ModelService
+    if artifact_path:
+        print("Starting %s model service from %s" % (model, artifact_path))
+        module_spec = importlib.util.spec_from_file_location("idunno_model_%s" % model, artifact_path)
+        module = importlib.util.module_from_spec(module_spec)
+        module_spec.loader.exec_module(module)
+        return module.ModelService()
+
     match model:
         case ModelType.Resnet50.value:
             print("Starting Resnet50 model service")
@@ -38,17 +55,61 @@ def start_inference_service(model: str) -> IDunnoModelService:
             raise Exception("Unknown model type")
 
 
+def resident_memory_mb() -> int:
+    # resident set size of the runner
+    with open("/proc/self/statm") as f:
+        return int(f.read().split()[1]) * PAGE_SIZE // (1024 * 1024)
+
+
+class WarmPool:
+    # models kept loaded so that switching jobs does not reload them, least recently used first
+    def __init__(self, budget_mb: int):
+        self.budget_mb = budget_mb
+        self.models: "OrderedDict[Tuple[str, int], Tuple[IDunnoModelService, int]]" = OrderedDict()
+
+    def get(self, key: Tuple[str, int]) -> Optional[IDunnoModelService]:
+        if key not in self.models:
+            return None
+        self.models.move_to_end(key)
+        return self.models[key][0]
+
+    def add(self, key: Tuple[str, int], model_service: IDunnoModelService, memory_mb: int):
+        self.models[key] = (model_service, memory_mb)
+        self.models.move_to_end(key)
+        self.evict()
+
+    def evict(self):
+        # the most recently used model is being served, it is never evicted
+        while len(self.models) > 1 and self.used_mb() > self.budget_mb:
+            (model, version), _ = self.models.popitem(last=False)
+            print("Evicted %s v%d from warm pool" % (model, version))
+        gc.collect()
+
+    def used_mb(self) -> int:
+        return sum(memory_mb for _, memory_mb in self.models.values())
+
+    def resident(self):
+        return [
+            ResidentModel(model=model, version=version, memoryMb=memory_mb)
+            for (model, version), (_, memory_mb) in self.models.items()
+        ]
+
+
 class InferenceServiceServer(InferenceService):
-    def __init__(self):
+    def __init__(self, memory_budget_mb: int = 0):
         super().__init__()
         self.model_service: Optional[IDunnoModelService] = None
+        self.warm_pool = WarmPool(memory_budget_mb)
 
     def Greet(self, request: GreetRequest, context) -> GreetResponse:
         return GreetResponse(message="Hello, %s!" % request.name)
 
+    def Health(self, request: HealthRequest, context) -> HealthResponse:
+        return HealthResponse(status=OK, memoryMb=resident_memory_mb())
+
     def Train(self, request: TrainRequest, context) -> TrainResponse:
         try:
-            start_inference_service(request.trainTask.model)
+            start_inference_service(request.trainTask.model, request.artifactPath)
             print("Training model completed")
             return TrainResponse(status=OK)
         except Exception as e:
@@ -56,10 +117,19 @@ class InferenceServiceServer(InferenceService):
             return TrainResponse(status=ERROR)
 
     def ServeModel(self, request: ServeModelRequest, context) -> ServeModelResponse:
+        key = (request.model, request.spec.version)
+        model_service = self.warm_pool.get(key)
+        if model_service is not None:
+            print("Serving %s v%d from warm pool" % key)
+            self.model_service = model_service
+            return ServeModelResponse(status=OK, warm=True, resident=self.warm_pool.resident())
+
         try:
-            self.model_service = start_inference_service(request.model)
+            before = resident_memory_mb()
+            self.model_service = start_inference_service(request.model, request.artifactPath)
+            self.warm_pool.add(key, self.model_service, max(resident_memory_mb() - before, 0))
             print("Serving model completed")
-            return ServeModelResponse(status=OK)
+            return ServeModelResponse(status=OK, resident=self.warm_pool.resident())
         except Exception as e:
             print("Serving model failed " + str(e))
             return ServeModelResponse(status=ERROR)
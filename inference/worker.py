@@ -12,6 +12,8 @@ def main():
     parser = argparse.ArgumentParser()
     parser.add_argument("--port", type=int, default=6000)
     parser.add_argument("--filepath", type=str, default="images/")
+    # memory in MB the runner keeps models resident in, 0 keeps only the served model
+    parser.add_argument("--memory-budget", type=int, default=0)
     args = parser.parse_args()
 
     # max receive size = 100MB
@@ -19,8 +21,9 @@ def main():
         ('grpc.max_receive_message_length', 100 * 1024 * 1024),
         ('grpc.max_send_message_length', 100 * 1024 * 1024),
     ])
-    add_InferenceServiceServicer_to_server(InferenceServiceServer(), server)
-    server.add_insecure_port("[::]:%d" % args.port)
+    add_InferenceServiceServicer_to_server(InferenceServiceServer(args.memory_budget), server)
+    # only reachable from the local IDunno worker
+    server.add_insecure_port("localhost:%d" % args.port)
     server.start()
     server.wait_for_termination()
 
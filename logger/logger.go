@@ -15,6 +15,7 @@ type LogStats struct {
 	BytesRead    int
 	NumFailures  int
 	NumPings     int
+	NumRejected  int
 }
 
 func NewLogStats() *LogStats {
@@ -24,6 +25,7 @@ func NewLogStats() *LogStats {
 		BytesRead:    0,
 		NumFailures:  0,
 		NumPings:     0,
+		NumRejected:  0,
 	}
 }
 
@@ -47,6 +49,7 @@ const (
 	NEW_JOB  = "NEW_JOB"
 	SCHEDULE = "SCHEDULE"
 	QUERY    = "QUERY"
+	REJECT   = "REJECT"
 )
 
 // logger global states
@@ -108,6 +111,10 @@ func Join(process *api.Process) {
 	appendLog(JOIN, formatServiceMessage(process, "joined"))
 }
 
+func Rejoin(process *api.Process) {
+	appendLog(JOIN, formatServiceMessage(process, "rejoined"))
+}
+
 func Ping(process *api.Process) {
 	STAT.NumPings++
 	appendLog(PING, formatServiceMessage(process, "pinged"))
@@ -126,6 +133,11 @@ func Failure(process *api.Process) {
 	appendLog(FAILURE, formatServiceMessage(process, "failed"))
 }
 
+func Reject(address string, reason string) {
+	STAT.NumRejected++
+	appendLog(REJECT, fmt.Sprintf("Rejected packet from %v: %v", address, reason))
+}
+
 func Delete(process *api.Process) {
 	appendLog(DELETE, formatServiceMessage(process, "deleted"))
 }
@@ -173,6 +185,7 @@ func Stats() {
 	fmt.Println("Bytes read: ", STAT.BytesRead)
 	fmt.Println("Number of failures: ", STAT.NumFailures)
 	fmt.Println("Number of pings: ", STAT.NumPings)
+	fmt.Println("Number of rejected packets: ", STAT.NumRejected)
 	fmt.Println("Bps write: ", float64(STAT.BytesWritten)/elapsedTime.Seconds())
 	fmt.Println("Bps read: ", float64(STAT.BytesRead)/elapsedTime.Seconds())
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"mp4/api"
+	"sort"
 )
 
 // Ralloc is a resource allocation algorithm that allocates resources
@@ -18,6 +19,7 @@ import (
 //   - 1D array of resource allocated for each job that sums up to m
 //     (i.e. the total number of resources) while minimizing the
 //     pair-wise difference of query per second between each job.
+//     Pass weighted qps (see JobToQPS) to favor jobs with higher weight.
 //   - min absolute pair-wise difference of qps for each job under
 //     the optimal resource assignment.
 func GlobalFairTimeRalloc(n int, m int, qps [][]float64) ([]int, float64) {
@@ -69,6 +71,9 @@ func GlobalFairTimeRalloc(n int, m int, qps [][]float64) ([]int, float64) {
 	return resources, GetRelQPSDiff(optimalQPS)
 }
 
+// LocalFairTimeRalloc allocates resources to each job proportionally to its
+// local average processing time scaled by its weight, so that weighted query
+// per second of each job is roughly equal.
 func LocalFairTimeRalloc(jobs []*api.Job, totalResources int) ([]int, float64) {
 	time := make([]float64, len(jobs)) // second per query (local average)
 	rawAlloc := make([]float64, len(jobs))
@@ -77,12 +82,12 @@ func LocalFairTimeRalloc(jobs []*api.Job, totalResources int) ([]int, float64) {
 	totalTime := 0.0
 	for i, job := range jobs {
 		time[i] = job.QueryProcessingTime()
-		totalTime += time[i]
+		totalTime += time[i] * job.SchedulingWeight()
 	}
 
 	// allocate resources to each job
 	for i := 0; i < len(jobs); i++ {
-		rawAlloc[i] = float64(totalResources) * time[i] / totalTime
+		rawAlloc[i] = float64(totalResources) * time[i] * jobs[i].SchedulingWeight() / totalTime
 	}
 
 	// ceil up the allocation
@@ -114,18 +119,132 @@ func GetRelQPSDiff(qps []float64) float64 {
 	return maxErr
 }
 
+// JobToQPS returns the expected query per second of each job given 0...m
+// resources, divided by the job's weight. Equalizing weighted qps gives a job
+// with twice the weight roughly twice the qps.
 func JobToQPS(jobs []*api.Job, totalResources int) [][]float64 {
 	qps := make2d[float64](len(jobs), totalResources+1)
 
 	for i, job := range jobs {
 		for j := 0; j <= totalResources; j++ {
-			qps[i][j] = job.GetExpectedQPS(j)
+			qps[i][j] = job.GetExpectedQPS(j) / job.SchedulingWeight()
 		}
 	}
 
 	return qps
 }
 
+// GuaranteeMinWorkers moves resources from jobs holding more than their
+// required workers to jobs below it, so that low-weight jobs are not starved
+// and jobs with a deadline get enough workers to meet it. The required
+// workers of a job is the larger of its minimum workers and the workers
+// needed to meet its deadline. Jobs with the earliest deadline are served
+// first when there are not enough resources to satisfy every job, followed
+// by jobs without a deadline in order.
+//
+// Args:
+//   - jobs: jobs in the same order as alloc
+//   - alloc: resource allocated for each job, updated in place
+//
+// Returns:
+//   - alloc after guaranteeing required resources
+func GuaranteeMinWorkers(jobs []*api.Job, alloc []int) []int {
+	totalResources := 0
+	for _, resource := range alloc {
+		totalResources += resource
+	}
+
+	required := make([]int, len(jobs))
+	order := make([]int, len(jobs))
+	for i, job := range jobs {
+		required[i] = job.RequiredWorkers(totalResources)
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		da, db := jobs[order[a]].GetDeadline(), jobs[order[b]].GetDeadline()
+		if da == nil || db == nil {
+			return da != nil && db == nil
+		}
+		return da.AsTime().Before(db.AsTime())
+	})
+
+	rank := make([]int, len(jobs))
+	for r, i := range order {
+		rank[i] = r
+	}
+
+	for _, i := range order {
+		for alloc[i] < required[i] {
+			// take from the job with the most resources above its requirement
+			donor, surplus := -1, 0
+			for j := range jobs {
+				if extra := alloc[j] - required[j]; j != i && extra > surplus {
+					donor, surplus = j, extra
+				}
+			}
+			// otherwise from the last served job, which gives up its requirement
+			for r := len(order) - 1; donor == -1 && r > rank[i]; r-- {
+				if alloc[order[r]] > 0 {
+					donor = order[r]
+				}
+			}
+
+			if donor == -1 {
+				break
+			}
+			alloc[donor]--
+			alloc[i]++
+		}
+	}
+
+	return alloc
+}
+
+// DampSwitching keeps the current allocation unless the proposed one makes
+// weighted query per second fairer by more than penalty per worker moved
+// between jobs. A moved worker loads another model and loses its warm batch,
+// so moving workers for a marginal gain costs more than it brings.
+//
+// Args:
+//   - jobs: jobs in the same order as current and proposed
+//   - current: resource currently allocated to each job
+//   - proposed: resource allocated to each job by fair-time ralloc
+//   - penalty: min decrease of relative qps difference per moved resource
+//
+// Returns:
+//   - current if it uses the same resources and the gain of the proposed
+//     allocation does not cover its switching cost, otherwise proposed
+func DampSwitching(jobs []*api.Job, current []int, proposed []int, penalty float64) []int {
+	moved, currentTotal, proposedTotal := 0, 0, 0
+	for i := range jobs {
+		currentTotal += current[i]
+		proposedTotal += proposed[i]
+		if current[i] > proposed[i] {
+			moved += current[i] - proposed[i]
+		}
+	}
+	// idle or missing resources are always reallocated
+	if moved == 0 || currentTotal != proposedTotal {
+		return proposed
+	}
+
+	gain := GetRelQPSDiff(weightedQPS(jobs, current)) - GetRelQPSDiff(weightedQPS(jobs, proposed))
+	if gain > penalty*float64(moved) {
+		return proposed
+	}
+	return append(make([]int, 0, len(current)), current...)
+}
+
+// weightedQPS returns the expected query per second of each job under an
+// allocation, divided by the job's weight.
+func weightedQPS(jobs []*api.Job, alloc []int) []float64 {
+	qps := make([]float64, len(jobs))
+	for i, job := range jobs {
+		qps[i] = job.GetExpectedQPS(alloc[i]) / job.SchedulingWeight()
+	}
+	return qps
+}
+
 func make2d[T any](rows int, cols int) [][]T {
 	arr := make([][]T, rows)
 	for i := 0; i < rows; i++ {
@@ -122,3 +122,104 @@ func Test_Ralloc_ThreeJobs(t *testing.T) {
 	assert.LessOrEqual(math.Abs(float64(40-alloc[1])), 10.)
 	assert.LessOrEqual(math.Abs(float64(60-alloc[2])), 10.)
 }
+
+func Test_Ralloc_WeightedJobs(t *testing.T) {
+	assert := assert.New(t)
+
+	startTime := timestamppb.New(time.Now().Add(-1 * time.Second))
+	jobs := []*api.Job{{
+		TotalQueries:     10000,
+		CompletedQueries: 2,
+		StartTime:        startTime,
+	}, {
+		TotalQueries:     10000,
+		CompletedQueries: 2,
+		StartTime:        startTime,
+		Weight:           2,
+	}}
+
+	qps := ralloc.JobToQPS(jobs, 120)
+	alloc, _ := ralloc.GlobalFairTimeRalloc(2, 120, qps)
+	assert.Equal(120, alloc[0]+alloc[1])
+	assert.LessOrEqual(math.Abs(float64(40-alloc[0])), 5.)
+	assert.LessOrEqual(math.Abs(float64(80-alloc[1])), 5.)
+
+	// no completed query yet, both jobs have the same processing time
+	jobs[0].CompletedQueries, jobs[1].CompletedQueries = 0, 0
+	alloc, _ = ralloc.LocalFairTimeRalloc(jobs, 120)
+	assert.Equal(40, alloc[0])
+	assert.Equal(80, alloc[1])
+}
+
+func Test_Ralloc_GuaranteeMinWorkers(t *testing.T) {
+	assert := assert.New(t)
+
+	jobs := []*api.Job{{MinWorkers: 0}, {MinWorkers: 2}, {MinWorkers: 3}}
+
+	alloc := ralloc.GuaranteeMinWorkers(jobs, []int{10, 0, 0})
+	assert.Equal([]int{5, 2, 3}, alloc)
+
+	// minimum already satisfied, allocation is kept as is
+	alloc = ralloc.GuaranteeMinWorkers(jobs, []int{4, 3, 3})
+	assert.Equal([]int{4, 3, 3}, alloc)
+
+	// not enough resources, earlier jobs are served first
+	alloc = ralloc.GuaranteeMinWorkers(jobs, []int{3, 0, 0})
+	assert.Equal([]int{0, 2, 1}, alloc)
+}
+
+func Test_Ralloc_GuaranteeDeadlineWorkers(t *testing.T) {
+	assert := assert.New(t)
+
+	// 1 second per query, 900 queries left to be done in 120 seconds
+	jobs := []*api.Job{{
+		TotalQueries:     1000,
+		CompletedQueries: 100,
+		StartTime:        timestamppb.New(time.Now().Add(-100 * time.Second)),
+	}, {
+		TotalQueries:     1000,
+		CompletedQueries: 100,
+		StartTime:        timestamppb.New(time.Now().Add(-100 * time.Second)),
+		Deadline:         timestamppb.New(time.Now().Add(120 * time.Second)),
+	}}
+
+	assert.Equal(8, jobs[1].RequiredWorkers(10))
+	alloc := ralloc.GuaranteeMinWorkers(jobs, []int{5, 5})
+	assert.Equal([]int{2, 8}, alloc)
+
+	// earliest deadline is served first when resources are not enough
+	jobs[0].Deadline = timestamppb.New(time.Now().Add(60 * time.Second))
+	alloc = ralloc.GuaranteeMinWorkers(jobs, []int{5, 5})
+	assert.Equal([]int{10, 0}, alloc)
+}
+
+func Test_Ralloc_DampSwitching(t *testing.T) {
+	assert := assert.New(t)
+
+	startTime := timestamppb.New(time.Now().Add(-100 * time.Second))
+	jobs := []*api.Job{{
+		TotalQueries:     10000,
+		CompletedQueries: 100,
+		StartTime:        startTime,
+	}, {
+		TotalQueries:     10000,
+		CompletedQueries: 100,
+		StartTime:        startTime,
+	}}
+
+	// moving a worker for a marginal gain is not worth reloading a model
+	alloc := ralloc.DampSwitching(jobs, []int{5, 5}, []int{6, 4}, 0.05)
+	assert.Equal([]int{5, 5}, alloc)
+
+	// unfair allocation is rebalanced
+	alloc = ralloc.DampSwitching(jobs, []int{9, 1}, []int{5, 5}, 0.05)
+	assert.Equal([]int{5, 5}, alloc)
+
+	// a job without workers always gets its share
+	alloc = ralloc.DampSwitching(jobs, []int{10, 0}, []int{5, 5}, 0.05)
+	assert.Equal([]int{5, 5}, alloc)
+
+	// idle workers are always allocated
+	alloc = ralloc.DampSwitching(jobs, []int{4, 4}, []int{5, 5}, 0.05)
+	assert.Equal([]int{5, 5}, alloc)
+}
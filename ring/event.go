@@ -3,6 +3,7 @@ package ring
 import (
 	"mp4/api"
 	"mp4/logger"
+	"mp4/security"
 	"net"
 	"time"
 )
@@ -26,8 +27,8 @@ func (server *RingServer) OnPing(remoteAddr *net.UDPAddr, processes []*api.Proce
 
 	server.Lock()
 	for _, process := range processes {
-		// skip self process
-		if api.IsSameProcess(process, server.Process) {
+		// skip self process, including previous incarnations of current machine
+		if api.IsSameNode(process, server.Process) {
 			continue
 		}
 
@@ -38,6 +39,14 @@ func (server *RingServer) OnPing(remoteAddr *net.UDPAddr, processes []*api.Proce
 				continue
 			}
 
+			// a machine restarted, replace its previous incarnation unless this one is even older
+			if nodeIndex := server.FindNodeIndex(process); nodeIndex != -1 {
+				if process.JoinTime.AsTime().After(server.MembershipList[nodeIndex].JoinTime.AsTime()) {
+					server.ReplaceIncarnation(nodeIndex, process)
+				}
+				continue
+			}
+
 			// process is alive and not in current membership list
 			// meaning a new process, add process to current membership list
 			logger.Join(process)
@@ -105,10 +114,11 @@ func (server *RingServer) OnJoin(remoteAddr *net.UDPAddr, process *api.Process)
 	server.Lock()
 	defer server.Unlock()
 
-	// check existence of process in membership list
+	// check existence of process in membership list, a previous incarnation of the machine
+	// (i.e. crashed and restarted, or failed and merging back) is replaced instead
 	addr := process.Address()
-	processIndex := server.FindProcessIndex(process)
-	if processIndex != -1 {
+	nodeIndex := server.FindNodeIndex(process)
+	if nodeIndex != -1 && api.IsSameProcess(server.MembershipList[nodeIndex], process) && server.MembershipList[nodeIndex].Status == api.Status_Alive {
 		logger.Error("Trying to add process " + addr + " to membership list: process already exists")
 		return
 	}
@@ -128,6 +138,7 @@ func (server *RingServer) OnJoin(remoteAddr *net.UDPAddr, process *api.Process)
 		logger.Error("Failed to marshal join message: " + err.Error())
 		return
 	}
+	res = security.Sign(res)
 
 	// send the newly joined process info to new node
 	server.UDPConn.SetWriteDeadline(time.Now().Add(WRITE_TIMEOUT))
@@ -137,6 +148,11 @@ func (server *RingServer) OnJoin(remoteAddr *net.UDPAddr, process *api.Process)
 		return
 	}
 
+	if nodeIndex != -1 {
+		server.ReplaceIncarnation(nodeIndex, process)
+		return
+	}
+
 	// add process to membership list
 	logger.Join(process)
 	server.MembershipList = append(server.MembershipList, process)
@@ -157,10 +173,14 @@ func (server *RingServer) OnLeave(process *api.Process) {
 		return
 	}
 
-	// update process status in membership list
+	// update process status in membership list, it is removed from the ring once expired
 	server.MembershipList[processIndex].Status = api.Status_Leaved
+	server.MembershipList[processIndex].LastUpdateTime = api.CurrentTimestamp()
 	server.ExpirationPool[addr] = api.CurrentTimestamp().AsTime().Add(EXPIRATION_TIME)
 	logger.Leave(process)
+
+	// process is drained before leaving, so callbacks can release it right away
+	go server.OnMemberUpdate(server.MembershipList[processIndex], MEMBER_LEAVED)
 }
 
 /*
@@ -0,0 +1,47 @@
+package ring
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"mp4/logger"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const NODE_ID_FILE = "node_id"
+
+/*
+ * Load node id persisted in data directory, a new id is generated and persisted if not exists,
+ * so that a restarted process keeps the identity of the machine
+ *
+ * @param dir: data directory of current process
+ * @return string: node id
+ * @return error: raise error if node id cannot be read or persisted
+ */
+func LoadNodeId(dir string) (string, error) {
+	path := filepath.Join(dir, NODE_ID_FILE)
+
+	data, err := os.ReadFile(path)
+	if err == nil && len(strings.TrimSpace(string(data))) > 0 {
+		return strings.TrimSpace(string(data)), nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		logger.Error("Failed to read node id: " + err.Error())
+		return "", err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		logger.Error("Failed to generate node id: " + err.Error())
+		return "", err
+	}
+	nodeId := hex.EncodeToString(id)
+
+	if err := os.WriteFile(path, []byte(nodeId+"\n"), 0644); err != nil {
+		logger.Error("Failed to persist node id: " + err.Error())
+		return "", err
+	}
+	logger.Info("Generated node id " + nodeId)
+	return nodeId, nil
+}
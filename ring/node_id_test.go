@@ -0,0 +1,34 @@
+package ring_test
+
+import (
+	"mp4/ring"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadNodeId(t *testing.T) {
+	dir := t.TempDir()
+
+	// generated once, then kept across restarts
+	nodeId, err := ring.LoadNodeId(dir)
+	assert.Nil(t, err)
+	assert.Len(t, nodeId, 32)
+	reloaded, err := ring.LoadNodeId(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, nodeId, reloaded)
+
+	// persisted id is read as is, an empty file is replaced by a new id
+	path := filepath.Join(dir, ring.NODE_ID_FILE)
+	assert.Nil(t, os.WriteFile(path, []byte(" machine-1\n"), 0644))
+	nodeId, _ = ring.LoadNodeId(dir)
+	assert.Equal(t, "machine-1", nodeId)
+	assert.Nil(t, os.WriteFile(path, []byte("\n"), 0644))
+	nodeId, _ = ring.LoadNodeId(dir)
+	assert.Len(t, nodeId, 32)
+
+	_, err = ring.LoadNodeId(filepath.Join(dir, "missing"))
+	assert.NotNil(t, err, "directory does not exist")
+}
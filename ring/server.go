@@ -15,8 +15,6 @@ import (
 	"github.com/jedib0t/go-pretty/text"
 )
 
-var DROP_PROB float64 = 0.00
-
 // const HOSTNAME = "fa22-cs425-2401.cs.illinois.edu:8889"
 
 const DNS_ADDR = "fa22-cs425-2401.cs.illinois.edu:8889"
@@ -30,6 +28,7 @@ const READ_TIMEOUT time.Duration = time.Duration(700) * time.Millisecond     //
 const WRITE_TIMEOUT time.Duration = time.Duration(700) * time.Millisecond    // 500 milliseconds
 const EXPIRATION_TIME time.Duration = time.Duration(6000) * time.Millisecond // 5000 milliseconds
 const INTERVAL time.Duration = time.Duration(1450) * time.Millisecond        // 800 milliseconds
+const MERGE_INTERVAL time.Duration = time.Duration(5000) * time.Millisecond  // 5000 milliseconds
 
 // Action flag for process being added/deleted from the membership list
 type MemAction int
@@ -38,6 +37,8 @@ const (
 	MEMBER_DELETE MemAction = iota
 	MEMBER_INSERT
 	MEMBER_LEAVED
+	MEMBER_MERGED   // current process dropped its minority partition and is rejoining the majority
+	MEMBER_REJOINED // a restarted machine replaced its previous incarnation in the membership list
 )
 
 // Pool contains a list of processes to be deleted in the future
@@ -51,14 +52,16 @@ type OnMemberUpdate func(process *api.Process, action MemAction)
  * - Implements RingServerEvent interface
  */
 type RingServer struct {
-	*net.UDPConn      // udp connection
-	*api.Process      // current process
-	MembershipList    // RingServer.process must be in the list
-	ExpirationPool    // list of processes to be deleted
-	OnMemberUpdate    // callback function when membership list is updated
-	RingServerService // service interface
-	RingServerEvent   // event interface
-	sync.Mutex        // lock for concurrent access
+	*net.UDPConn                // udp connection
+	*api.Process                // current process
+	MembershipList              // RingServer.process must be in the list
+	ExpirationPool              // list of processes to be deleted
+	OnMemberUpdate              // callback function when membership list is updated
+	ClusterSize       int       // expected number of processes in the ring, quorum is disabled if 0
+	LastMergeTime     time.Time // last time this process tried to merge
+	RingServerService           // service interface
+	RingServerEvent             // event interface
+	sync.Mutex                  // lock for concurrent access
 }
 
 func NewRingServer(conn *net.UDPConn, ip string, port int32) *RingServer {
@@ -79,6 +82,36 @@ func (server *RingServer) SetMemberUpdateCallback(callback OnMemberUpdate) {
 	server.OnMemberUpdate = callback
 }
 
+func (server *RingServer) SetNodeId(nodeId string) {
+	server.Lock()
+	defer server.Unlock()
+	server.Process.NodeId = nodeId
+}
+
+func (server *RingServer) SetClusterSize(size int) {
+	server.ClusterSize = size
+}
+
+/**
+ * Check if the partition this process belongs to contains a strict majority of the expected cluster,
+ * a minority partition must refuse leadership, SDFS writes and job scheduling until it heals
+ *
+ * @return bool: true if quorum is disabled or more than half of the expected processes are alive
+ */
+func (server *RingServer) HasQuorum() bool {
+	server.Lock()
+	defer server.Unlock()
+
+	if server.ClusterSize <= 0 {
+		return true
+	}
+
+	alive := server.MembershipList.Filter(func(p *api.Process) bool {
+		return p.Status == api.Status_Alive
+	})
+	return alive.Len() > server.ClusterSize/2
+}
+
 /**
  * The server handler to recycle the expiration pool and initiate the ring stabilization machanism
  * 1. Check if there any process that has passed its timeout time and delete it
@@ -160,6 +193,31 @@ func (server *RingServer) FindProcessIndex(process *api.Process) int {
 	return -1
 }
 
+// Find index of any incarnation of the same machine as given process
+func (server *RingServer) FindNodeIndex(process *api.Process) int {
+	for i, p := range server.MembershipList {
+		if api.IsSameNode(p, process) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+/*
+ * Replace previous incarnation of a restarted machine with its new process in place,
+ * instead of waiting for the old one to expire and adding the new one as a stranger. Must hold the lock
+ *
+ * @param index: index of the previous incarnation in membership list
+ * @param process: new incarnation
+ */
+func (server *RingServer) ReplaceIncarnation(index int, process *api.Process) {
+	delete(server.ExpirationPool, server.MembershipList[index].Address())
+	server.MembershipList[index] = process
+	logger.Rejoin(process)
+	server.NotifyMemberUpdate(process, MEMBER_REJOINED)
+}
+
 func (server *RingServer) GetMembershipList() []*api.Process {
 	server.Lock()
 	defer server.Unlock()
@@ -171,6 +229,16 @@ func (server *RingServer) GetMembershipList() []*api.Process {
 	return membershipList
 }
 
+// Members of the ring other than current process
+func (server *RingServer) Members() []*api.Process {
+	server.Lock()
+	defer server.Unlock()
+
+	return server.MembershipList.Filter(func(p *api.Process) bool {
+		return !api.IsSameProcess(p, server.Process)
+	})
+}
+
 /**
  * A delegate(a group of actions) that initiates the SDFS file re-replication and leader election
  * Both callbacks get called are asynchronous.
@@ -191,6 +259,7 @@ func (server *RingServer) ListMembers() {
 	t.SetOutputMirror(os.Stdout)
 	t.AppendHeader(table.Row{
 		"Machine Address",
+		"Node Id",
 		"Join Time",
 		"Status",
 	})
@@ -198,6 +267,7 @@ func (server *RingServer) ListMembers() {
 	for _, process := range server.MembershipList {
 		t.AppendRow(table.Row{
 			process.Address(),
+			process.NodeId,
 			process.JoinTime.AsTime().Format("2006-01-02 15:04:05"),
 			process.Status.String(),
 		})
@@ -216,12 +286,14 @@ func (server *RingServer) ListSelf() {
 	t.SetOutputMirror(os.Stdout)
 	t.AppendHeader(table.Row{
 		"Machine Address",
+		"Node Id",
 		"Join Time",
 		"Status",
 	})
 
 	t.AppendRow(table.Row{
 		server.Address(),
+		server.NodeId,
 		server.JoinTime.AsTime().Format("2006-01-02 15:04:05"),
 		server.Status.String(),
 	})
@@ -231,7 +303,3 @@ func (server *RingServer) ListSelf() {
 	t.Style().Format.Header = text.FormatTitle
 	t.Render()
 }
-
-func SetNetworkDropRate(prob float64) {
-	DROP_PROB = prob
-}
@@ -0,0 +1,135 @@
+package ring_test
+
+import (
+	"mp4/api"
+	"mp4/logger"
+	"mp4/ring"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ring server of the first process, whose membership list holds the given processes
+func newPartition(processes ...*api.Process) *ring.RingServer {
+	server := ring.NewRingServer(nil, processes[0].Ip, processes[0].Port)
+	server.Process = processes[0]
+	server.MembershipList = append(ring.MembershipList{}, processes...)
+	return server
+}
+
+// alive processes listening on consecutive ports
+func newProcesses(n int) []*api.Process {
+	processes := make([]*api.Process, 0)
+	for i := 0; i < n; i++ {
+		processes = append(processes, &api.Process{Ip: "127.0.0.1", Port: int32(8000 + i), Status: api.Status_Alive})
+	}
+	return processes
+}
+
+func TestHasQuorum(t *testing.T) {
+	tests := []struct {
+		name        string
+		clusterSize int
+		alive       int
+		timedOut    int
+		expected    bool
+	}{
+		{"quorum disabled", 0, 1, 0, true},
+		{"negative cluster size disables quorum", -1, 1, 0, true},
+		{"whole cluster", 5, 5, 0, true},
+		{"majority", 5, 3, 0, true},
+		{"minority", 5, 2, 0, false},
+		{"exactly half", 4, 2, 0, false},
+		{"majority of an even cluster", 4, 3, 0, true},
+		{"timed out members do not count", 5, 2, 1, false},
+		{"more members than expected", 3, 4, 0, true},
+	}
+
+	for _, test := range tests {
+		processes := newProcesses(test.alive + test.timedOut)
+		for _, process := range processes[test.alive:] {
+			process.Status = api.Status_Timeout
+		}
+		server := newPartition(processes...)
+		server.SetClusterSize(test.clusterSize)
+
+		assert.Equal(t, test.expected, server.HasQuorum(), test.name)
+	}
+}
+
+func TestShouldMerge(t *testing.T) {
+	processes := newProcesses(5)
+	majority, minority := newPartition(processes[:3]...), newPartition(processes[3:]...)
+	for _, server := range []*ring.RingServer{majority, minority} {
+		server.SetClusterSize(len(processes))
+	}
+	assert.True(t, majority.HasQuorum())
+	assert.False(t, minority.HasQuorum())
+
+	// only the majority partition registers its leader, the minority merges into it
+	leader := processes[0].Address()
+	assert.False(t, majority.ShouldMerge(leader))
+	assert.True(t, minority.ShouldMerge(leader))
+	assert.False(t, minority.ShouldMerge(""), "no leader is registered")
+
+	assert.False(t, minority.ShouldMerge(processes[3].Address()), "leader in current partition")
+}
+
+// UDP connection on a free local port
+func listenUDP(t *testing.T) *net.UDPConn {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// Restarted machine replaces its previous incarnation instead of being added as a second member
+func TestRejoin(t *testing.T) {
+	logger.STAT = logger.NewLogStats()
+	joinTime := time.Now()
+	incarnation := func(nodeId string, port int32, joined time.Duration) *api.Process {
+		return &api.Process{Ip: "127.0.0.1", Port: port, NodeId: nodeId, Status: api.Status_Alive, JoinTime: timestamppb.New(joinTime.Add(joined))}
+	}
+
+	self, old := incarnation("self", 8000, 0), incarnation("node-1", 8001, time.Second)
+	server := newPartition(self, old)
+	server.UDPConn = listenUDP(t)
+	remote := listenUDP(t).LocalAddr().(*net.UDPAddr)
+	actions := make(chan ring.MemAction, 10)
+	server.SetMemberUpdateCallback(func(process *api.Process, action ring.MemAction) { actions <- action })
+
+	tests := []struct {
+		name     string
+		process  *api.Process
+		expected []*api.Process
+		action   ring.MemAction
+	}{
+		{"restarted at the same address", incarnation("node-1", 8001, time.Minute), []*api.Process{self, incarnation("node-1", 8001, time.Minute)}, ring.MEMBER_REJOINED},
+		{"restarted at another address", incarnation("node-1", 8002, time.Hour), []*api.Process{self, incarnation("node-1", 8002, time.Hour)}, ring.MEMBER_REJOINED},
+		{"new machine", incarnation("node-2", 8003, 2*time.Hour), []*api.Process{self, incarnation("node-1", 8002, time.Hour), incarnation("node-2", 8003, 2*time.Hour)}, ring.MEMBER_INSERT},
+	}
+
+	for _, test := range tests {
+		server.OnPing(remote, []*api.Process{test.process})
+		assert.Equal(t, test.action, <-actions, test.name)
+
+		members := server.GetMembershipList()
+		if assert.Len(t, members, len(test.expected), test.name) {
+			for i, process := range test.expected {
+				assert.Equal(t, process.Address(), members[i].Address(), test.name)
+				assert.True(t, process.JoinTime.AsTime().Equal(members[i].JoinTime.AsTime()), test.name)
+			}
+		}
+	}
+
+	// pings still carrying an older incarnation are ignored
+	server.OnPing(remote, []*api.Process{incarnation("node-1", 8001, time.Minute)})
+	assert.Len(t, server.GetMembershipList(), 3)
+	assert.Equal(t, int32(8002), server.GetMembershipList()[1].Port)
+	assert.Empty(t, actions)
+}
@@ -2,17 +2,20 @@ package ring
 
 import (
 	"context"
+	"fmt"
 
 	"mp4/api"
+	"mp4/connpool"
+	"mp4/fault"
 	"mp4/logger"
-	"mp4/utils"
+	"mp4/security"
 
 	"net"
 	"sort"
+	"sync"
 	"time"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 type RingServerService interface {
@@ -21,28 +24,95 @@ type RingServerService interface {
 	Ping(process *api.Process) error
 	Ack(remoteAddr *net.UDPAddr) error
 	Join() error
-	Leave() error
+	Introduce(leaderAddr string) (*api.Process, error)
+	Leave() []*api.Process
+	SendLeave(process *api.Process) error
+	Merge() error
 	LookupLeader() (string, error)
+	LookupDNSLeader() (string, error)
 	UpdateLeader() error
 }
 
 /**
  * Leave the ring
+ * 1) mark current process as leaved and broadcast the leave message to all members,
+ *    so that they remove current process without waiting for failure detection
+ * 2) wipe local membership list and notify callbacks
  *
+ * @return []*api.Process: members of the ring before leaving, excluding current process
  */
-func (server *RingServer) Leave() {
+func (server *RingServer) Leave() []*api.Process {
 	server.Lock()
 	server.Status = api.Status_Leaved
+	server.Process.LastUpdateTime = api.CurrentTimestamp()
+	members := server.MembershipList.Filter(func(p *api.Process) bool {
+		return !api.IsSameProcess(p, server.Process)
+	})
 	server.MembershipList = make(MembershipList, 0)
+	server.ExpirationPool = make(ExpirationPool, 0)
 	server.Unlock()
 
+	wg := sync.WaitGroup{}
+	for _, member := range members {
+		wg.Add(1)
+		go func(process *api.Process) {
+			defer wg.Done()
+			if err := server.SendLeave(process); err != nil {
+				logger.Error(err.Error())
+			}
+		}(member)
+	}
+	wg.Wait()
+	logger.Leave(server.Process)
+
 	go server.OnMemberUpdate(server.Process, MEMBER_LEAVED)
+	return members
+}
+
+/*
+ * Send leave message of current process to another process
+ *
+ * @param process: process to notify
+ * @return error: raise error if sending fails
+ */
+func (server *RingServer) SendLeave(process *api.Process) error {
+	server.Lock()
+	leaveMeta, err := api.MarshalMeta(api.MessageType_Leave, &api.Metadata_Leave{
+		Leave: &api.LeaveMessage{
+			Process: server.Process,
+		},
+	})
+	server.Unlock()
+	if err != nil {
+		logger.Error("Error marshalling Leave message")
+		return err
+	}
+	leaveMeta = security.Sign(leaveMeta)
+
+	addr := process.Address()
+	conn, err := net.DialTimeout("udp", addr, PING_TIMEOUT)
+	if err != nil {
+		logger.Error("Timeout when dialing UDP connection to address " + addr)
+		return err
+	}
+
+	_, err = fault.INJECTOR.Send(server.Address(), addr, func() (int, error) {
+		conn.SetWriteDeadline(time.Now().Add(WRITE_TIMEOUT))
+		return conn.Write(leaveMeta)
+	})
+	if err != nil {
+		logger.Error("Failed to send leave message to address " + addr)
+		return err
+	}
+
+	return nil
 }
 
 /**
  * Cron job running periodically, it has following main functionalities at each period:
  * 1) First recycle suspected process and initiate the ring stabilization, see stabalization details in NotifyMemberUpdate() callback
  * 2) ping the next 4 successors in the ring and put any failed process into expiration pool
+ * 3) try to merge back into the majority partition if current partition has lost quorum
  *
  * @return error: raise error if leave fails
  */
@@ -70,9 +140,83 @@ func (server *RingServer) Cron() {
 				}
 			}(successor)
 		}
+
+		// merge into majority partition once the partition heals
+		server.Lock()
+		inRing := server.Status == api.Status_Alive && len(server.MembershipList) > 0
+		shouldMerge := inRing && time.Sub(server.LastMergeTime) > MERGE_INTERVAL
+		if shouldMerge {
+			server.LastMergeTime = time
+		}
+		server.Unlock()
+
+		if shouldMerge && !server.HasQuorum() {
+			go func() {
+				if err := server.Merge(); err != nil {
+					logger.Error("Failed to merge into majority partition: " + err.Error())
+				}
+			}()
+		}
 	}
 }
 
+/**
+ * Merge procedure of a minority partition, which is ran periodically until the partition heals:
+ * 1) Lookup the leader registered in DNS, only a partition with quorum can register its leader
+ * 2) If the leader is not in current membership list, then it belongs to the majority partition that
+ *    has already removed current process, so rejoin through the leader
+ * 3) Only after the leader accepts the join, drop the minority membership list and let callbacks drop stale state
+ *
+ * @return error: raise error if DNS lookup or join fails
+ */
+func (server *RingServer) Merge() error {
+	leaderAddr, err := server.LookupDNSLeader()
+	if err != nil {
+		return err
+	}
+	if !server.ShouldMerge(leaderAddr) {
+		return nil
+	}
+
+	logger.Info("Partition healed, merging into the ring of leader " + leaderAddr)
+	process, err := server.Introduce(leaderAddr)
+	if err != nil {
+		return err
+	}
+
+	// state built up by the minority partition is stale, drop it before rejoining
+	server.OnMemberUpdate(server.Process, MEMBER_MERGED)
+
+	server.Lock()
+	server.Process = process
+	server.MembershipList = MembershipList{server.Process}
+	server.ExpirationPool = make(ExpirationPool, 0)
+	logger.Join(server.Process)
+	server.Unlock()
+
+	go server.OnMemberUpdate(server.Process, MEMBER_INSERT)
+	logger.Info("Merged into the ring of leader " + leaderAddr)
+	return nil
+}
+
+// Whether the registered leader is outside current partition, i.e. current partition is the minority one that the majority has removed
+func (server *RingServer) ShouldMerge(leaderAddr string) bool {
+	if leaderAddr == "" {
+		return false
+	}
+
+	server.Lock()
+	defer server.Unlock()
+
+	// leader is in current partition, nothing to merge
+	for _, process := range server.MembershipList {
+		if process.Address() == leaderAddr {
+			return false
+		}
+	}
+	return true
+}
+
 /**
  * Listen is responsible for handling incoming messages from other servers or introduce
  *
@@ -92,11 +236,11 @@ func (server *RingServer) Listen() error {
 			continue
 		}
 
-		// unmarshal metadata
-		meta, err := api.UnmarshalMeta(buffer[:n])
+		// verify & unmarshal metadata, drop the packet instead of stop listening if it is invalid
+		meta, err := UnmarshalSignedMeta(remoteAddr.String(), buffer[:n])
 		if err != nil {
 			logger.Error("Failed to unmarshal message from address " + remoteAddr.String())
-			return err
+			continue
 		}
 
 		// handle metadata
@@ -132,6 +276,7 @@ func (server *RingServer) Ping(process *api.Process) error {
 		logger.Error("Error marshalling Ping message")
 		return err
 	}
+	pingMeta = security.Sign(pingMeta)
 
 	addr := process.Address()
 	// establish UDP connection
@@ -142,10 +287,9 @@ func (server *RingServer) Ping(process *api.Process) error {
 		return err
 	}
 	// logger.Info("Successfully dialed UDP connection to address " + addr)
-	defer conn.Close()
 
 	// send ping metadata
-	n, err := utils.WithDropProb(DROP_PROB, func() (int, error) {
+	n, err := fault.INJECTOR.Send(server.Address(), addr, func() (int, error) {
 		conn.SetWriteDeadline(time.Now().Add(WRITE_TIMEOUT))
 		return conn.Write(pingMeta)
 	})
@@ -163,8 +307,8 @@ func (server *RingServer) Ping(process *api.Process) error {
 		return err
 	}
 
-	// unmarshal ack metadata
-	ackMeta, err := api.UnmarshalMeta(buffer[:n])
+	// verify & unmarshal ack metadata
+	ackMeta, err := UnmarshalSignedMeta(addr, buffer[:n])
 	if err != nil {
 		logger.Error("Failed to unmarshal ack message from address " + addr)
 		return err
@@ -198,9 +342,10 @@ func (server *RingServer) Ack(remoteAddr *net.UDPAddr) error {
 		logger.Error("Error marshalling Ack message")
 		return err
 	}
+	ackMeta = security.Sign(ackMeta)
 
 	// write ack metadata
-	_, err = utils.WithDropProb(DROP_PROB, func() (int, error) {
+	_, err = fault.INJECTOR.Send(server.Address(), remoteAddr.String(), func() (int, error) {
 		server.UDPConn.SetWriteDeadline(time.Now().Add(WRITE_TIMEOUT))
 		return server.UDPConn.WriteToUDP(ackMeta, remoteAddr)
 	})
@@ -223,17 +368,6 @@ func (server *RingServer) Join() error {
 		return nil
 	}
 
-	// marshal join metadata
-	joinMeta, err := api.MarshalMeta(api.MessageType_Join, &api.Metadata_Join{
-		Join: &api.JoinMessage{
-			Process: server.Process,
-		},
-	})
-	if err != nil {
-		logger.Error("Error marshalling Join message")
-		return err
-	}
-
 	leaderAddr, err := server.LookupLeader()
 	if err != nil {
 		logger.Error("Failed to lookup leader address")
@@ -255,22 +389,62 @@ func (server *RingServer) Join() error {
 		return nil
 	}
 
+	process, err := server.Introduce(leaderAddr)
+	if err != nil {
+		return err
+	}
+
+	// update process join time & last update time
+	server.Lock()
+	server.Process = process
+	server.MembershipList = append(server.MembershipList, server.Process)
+	logger.Join(server.Process)
+	server.Unlock()
+
+	// edge case: should not call NotifyMemberUpdate here, since we don't want to call
+	// UpdateLeader for newly joined node (as it would cause error to DNS server)
+	go server.OnMemberUpdate(server.Process, MEMBER_INSERT)
+
+	return nil
+}
+
+/*
+ * Send join message to the introducer and wait for the process info it assigns (i.e. join time)
+ *
+ * @param leaderAddr: address of the introducer
+ * @return *api.Process: current process with join time assigned by the introducer
+ * @return error: raise error if introducer does not accept the join
+ */
+func (server *RingServer) Introduce(leaderAddr string) (*api.Process, error) {
+	// marshal join metadata
+	server.Lock()
+	joinMeta, err := api.MarshalMeta(api.MessageType_Join, &api.Metadata_Join{
+		Join: &api.JoinMessage{
+			Process: server.Process,
+		},
+	})
+	server.Unlock()
+	if err != nil {
+		logger.Error("Error marshalling Join message")
+		return nil, err
+	}
+	joinMeta = security.Sign(joinMeta)
+
 	// establish UDP connection
 	conn, err := net.DialTimeout("udp", leaderAddr, PING_TIMEOUT)
 	if err != nil {
 		logger.Error("Timeout when dialing UDP connection to introducer")
-		return err
+		return nil, err
 	}
-	defer conn.Close()
 
 	// send join metadata
-	_, err = utils.WithDropProb(DROP_PROB, func() (int, error) {
+	_, err = fault.INJECTOR.Send(server.Address(), leaderAddr, func() (int, error) {
 		conn.SetWriteDeadline(time.Now().Add(WRITE_TIMEOUT))
 		return conn.Write(joinMeta)
 	})
 	if err != nil {
 		logger.Error("Failed to send data to introducer: " + err.Error())
-		return err
+		return nil, err
 	}
 
 	// receive join time metadata
@@ -279,28 +453,21 @@ func (server *RingServer) Join() error {
 	n, err := conn.Read(buffer)
 	if err != nil {
 		logger.Error("Failed to receive data from introducer: " + err.Error())
-		return err
+		return nil, err
 	}
 
-	// unmarshal process join metadata
-	processMeta, err := api.UnmarshalMeta(buffer[:n])
+	// verify & unmarshal process join metadata
+	processMeta, err := UnmarshalSignedMeta(leaderAddr, buffer[:n])
+	if err != nil {
+		logger.Error("Failed to unmarshal join time message from introducer: " + leaderAddr)
+		return nil, err
+	}
 	if processMeta.GetType() != api.MessageType_Join || processMeta.GetJoin().Process == nil {
 		logger.Error("Received invalid join time message from introducer: " + leaderAddr)
-		return err
+		return nil, fmt.Errorf("invalid join time message from introducer %v", leaderAddr)
 	}
 
-	// update process join time & last update time
-	server.Lock()
-	server.Process = processMeta.GetJoin().Process
-	server.MembershipList = append(server.MembershipList, server.Process)
-	logger.Join(server.Process)
-	server.Unlock()
-
-	// edge case: should not call NotifyMemberUpdate here, since we don't want to call
-	// UpdateLeader for newly joined node (as it would cause error to DNS server)
-	go server.OnMemberUpdate(server.Process, MEMBER_INSERT)
-
-	return nil
+	return processMeta.GetJoin().Process, nil
 }
 
 func (server *RingServer) LookupLeader() (string, error) {
@@ -314,14 +481,22 @@ func (server *RingServer) LookupLeader() (string, error) {
 		return server.MembershipList[0].Address(), nil
 	}
 
-	// new process join, lookup leader in DNS table
+	return server.LookupDNSLeader()
+}
+
+/*
+ * Lookup leader registered in DNS table, ignoring current membership list
+ *
+ * @return string: address of leader, empty if no leader is registered
+ * @return error: raise error if lookup fails
+ */
+func (server *RingServer) LookupDNSLeader() (string, error) {
 	// logger.Info("Looking up leader in DNS table...")
-	conn, err := grpc.Dial(DNS_ADDR, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := connpool.POOL.Get(DNS_ADDR, grpc.WithTransportCredentials(security.CLIENT_CREDENTIALS), connpool.KEEPALIVE)
 	if err != nil {
 		logger.Error("Failed to dial DNS server")
 		return "", err
 	}
-	defer conn.Close()
 
 	DNSClient := api.NewDNSServiceClient(conn)
 	res, err := DNSClient.Lookup(context.Background(), &api.LookupLeaderRequest{})
@@ -335,7 +510,8 @@ func (server *RingServer) LookupLeader() (string, error) {
 }
 
 /*
- * Update leader in DNS table, only the process that has the earliest join time can elect itself to be the leader and update the DNS table
+ * Update leader in DNS table, only the process that has the earliest join time can elect itself to be the leader and update the DNS table.
+ * A partition without quorum never takes over leadership, unless no leader is registered yet (i.e. the ring is bootstrapping)
  *
  * @return error: raise error if update fails
  */
@@ -351,13 +527,24 @@ func (server *RingServer) UpdateLeader() error {
 		return nil
 	}
 
+	// minority partition must not overwrite leader of majority partition
+	if !server.HasQuorum() {
+		leaderAddr, err := server.LookupDNSLeader()
+		if err != nil {
+			return err
+		}
+		if leaderAddr != "" {
+			logger.Info("No quorum in current partition, refuse to update leader")
+			return nil
+		}
+	}
+
 	// update leader process in DNS table
-	conn, err := grpc.Dial(DNS_ADDR, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := connpool.POOL.Get(DNS_ADDR, grpc.WithTransportCredentials(security.CLIENT_CREDENTIALS), connpool.KEEPALIVE)
 	if err != nil {
 		logger.Error("Failed to dial DNS server")
 		return err
 	}
-	defer conn.Close()
 
 	DNSClient := api.NewDNSServiceClient(conn)
 	res, err := DNSClient.Update(context.Background(), &api.UpdateLeaderRequest{
@@ -371,3 +558,22 @@ func (server *RingServer) UpdateLeader() error {
 	// logger.Info("Updated leader address to " + server.Process.Address())
 	return nil
 }
+
+/*
+ * Verify the signature of a received ring packet and unmarshal it into a Metadata struct,
+ * unsigned or mis-signed packets are rejected and counted
+ *
+ * @param address: address of the sender
+ * @param packet: signed packet
+ * @return *api.Metadata: unmarshalled metadata
+ * @return error: raise error if verification or unmarshalling fails
+ */
+func UnmarshalSignedMeta(address string, packet []byte) (*api.Metadata, error) {
+	message, err := security.Verify(packet)
+	if err != nil {
+		logger.Reject(address, err.Error())
+		return nil, err
+	}
+
+	return api.UnmarshalMeta(message)
+}
@@ -3,11 +3,14 @@ package sdfs
 import (
 	"fmt"
 	"math"
+	"mp4/connpool"
+	"mp4/fault"
 	"mp4/utils"
 
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
@@ -16,7 +19,17 @@ const INTERVAL = 300 * time.Millisecond
 const MAX_BUFFER_SIZE = 100 * utils.MegaByte
 const REPLICA_COUNT = 4
 
-var GRPC_OPTIONS = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(MAX_BUFFER_SIZE), grpc.MaxCallSendMsgSize(MAX_BUFFER_SIZE))}
+var CALL_OPTIONS = grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(MAX_BUFFER_SIZE), grpc.MaxCallSendMsgSize(MAX_BUFFER_SIZE))
+
+// calls & streams between cluster nodes go through the fault injection layer
+var FAULT_INTERCEPTOR = grpc.WithChainUnaryInterceptor(fault.INJECTOR.UnaryClientInterceptor)
+var FAULT_STREAM_INTERCEPTOR = grpc.WithChainStreamInterceptor(fault.INJECTOR.StreamClientInterceptor)
+
+// dial options between cluster nodes, transport credentials must be set with SetTransportCredentials() before dialing
+var GRPC_OPTIONS = clusterOptions()
+
+// dial options for the model runner, which only listens on the loopback interface of the same machine
+var RUNNER_GRPC_OPTIONS = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials()), CALL_OPTIONS}
 
 // Timeout
 const GET_TIMEOUT = 12 * time.Second
@@ -30,6 +43,16 @@ const WRITE_CONSISTENCY = 3
 const DELETE_CONSISTENCY = REPLICA_COUNT
 const LOOKUP_CONSISTENCY = REPLICA_COUNT
 
+// Set transport credentials (i.e. mTLS) used by all gRPC connections between cluster nodes
+func SetTransportCredentials(creds credentials.TransportCredentials) {
+	GRPC_OPTIONS = clusterOptions(grpc.WithTransportCredentials(creds))
+}
+
+// Dial options between cluster nodes, followed by the given ones
+func clusterOptions(extra ...grpc.DialOption) []grpc.DialOption {
+	return append([]grpc.DialOption{CALL_OPTIONS, FAULT_INTERCEPTOR, FAULT_STREAM_INTERCEPTOR, connpool.KEEPALIVE}, extra...)
+}
+
 type SDFSClient struct {
 	SDFSServer *SDFSServer
 	Printf     func(format string, a ...any) (n int, err error)
@@ -5,13 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"mp4/api"
+	"mp4/connpool"
 	"mp4/logger"
 	"strings"
 
 	"strconv"
 	"time"
-
-	"google.golang.org/grpc"
 )
 
 type SDFSClientAction interface {
@@ -31,6 +30,9 @@ func (c *SDFSClient) ExecuteTask(task SDFSTask) (SDFSTaskResult, error) {
 	if res.GetStatus() == api.ResponseStatus_NOT_CONVERGED {
 		return nil, nil
 	}
+	if res.GetStatus() == api.ResponseStatus_NO_QUORUM {
+		return nil, errors.New("leader has no quorum, partition has not healed yet")
+	}
 
 	// Find replica set
 	replicas := c.SDFSServer.HashRing.FindReplicas(task.GetSDFSFile(), REPLICA_COUNT)
@@ -120,12 +122,11 @@ func (c *SDFSClient) HandleTaskResults(reqType SDFSTaskType, results []SDFSTaskR
 
 func (c *SDFSClient) RouteTask(task SDFSTask, seq *api.Sequence, replica *api.Process) (SDFSTaskResult, error) {
 	// Make grpc connection
-	conn, err := grpc.Dial(replica.Address(), GRPC_OPTIONS...)
+	conn, err := connpool.POOL.Get(replica.Address(), GRPC_OPTIONS...)
 	if err != nil {
 		logger.Error("Failed to dial " + replica.Address() + ": " + err.Error())
 		return nil, err
 	}
-	defer conn.Close()
 
 	// Create grpc client
 	client := api.NewSDFSServiceClient(conn)
@@ -199,12 +200,11 @@ func (c *SDFSClient) FetchSequence(task SDFSTask) (*api.FetchSequenceResponse, e
 	}
 
 	// Dial to leader
-	conn, err := grpc.Dial(leaderAddr, GRPC_OPTIONS...)
+	conn, err := connpool.POOL.Get(leaderAddr, GRPC_OPTIONS...)
 	if err != nil {
 		logger.Error("Failed to dial leader while trying to to send request " + task.GetSDFSFile())
 		return nil, err
 	}
-	defer conn.Close()
 
 	// Fetch sequence from leader
 	client := api.NewSDFSServiceClient(conn)
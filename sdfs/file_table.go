@@ -103,3 +103,37 @@ func (ft FileTable) GetStoredFiles() []string {
 
 	return filenames
 }
+
+func (ft FileTable) ToProto() *api.FileTableSnapshot {
+	records := make([]*api.FileTableRecord, 0)
+
+	for filename, versions := range ft {
+		for _, v := range versions {
+			records = append(records, &api.FileTableRecord{
+				Filename:   filename,
+				ConcatName: v.ConcatName,
+				Seq:        v.Seq,
+				WriteId:    v.Id,
+			})
+		}
+	}
+
+	return &api.FileTableSnapshot{Records: records}
+}
+
+func FileTableFromProto(snapshot *api.FileTableSnapshot) *FileTable {
+	ft := NewFileTable()
+
+	for _, record := range snapshot.GetRecords() {
+		if record.GetSeq() == nil || record.GetWriteId() == nil {
+			continue
+		}
+		ft.Insert(record.GetFilename(), FileVersion{
+			ConcatName: record.GetConcatName(),
+			Seq:        record.GetSeq(),
+			Id:         record.GetWriteId(),
+		})
+	}
+
+	return ft
+}
@@ -4,19 +4,24 @@ import (
 	"context"
 	"fmt"
 	"mp4/api"
+	"mp4/connpool"
 	"mp4/logger"
 	"mp4/ring"
 	"mp4/utils"
 
 	"os"
+	"path/filepath"
 	"strconv"
 	"sync"
 	"time"
 
-	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
 )
 
 const MAX_RETRY = 5
+const HANDOFF_TIMEOUT = 120 * time.Second
+const HANDOFF_RETRY_INTERVAL = 2 * time.Second
+const FILE_TABLE_NAME = ".file_table" // file table persisted along with SDFS files
 
 type SignalEvent struct {
 	EventType ring.MemAction
@@ -31,6 +36,7 @@ type SDFSServer struct {
 	Signal                *utils.Queue[*SignalEvent] // signal queue
 	DeletePool            *utils.Queue[string]       // delete pool
 	SeqCounter            int                        // sequence counter
+	FileTableDirty        bool                       // file table changed since it is persisted
 	sync.Mutex                                       // lock for concurrent access
 	api.SDFSServiceServer                            // service interface
 }
@@ -49,6 +55,13 @@ func NewSDFSServer() *SDFSServer {
 func (server *SDFSServer) Cron() {
 	for {
 		time.Sleep(200 * time.Millisecond)
+		server.PersistFileTable()
+
+		// freeze file placement in a minority partition, since it would re-replicate and
+		// delete files based on a partial hash ring, converge after partition heals instead
+		if !server.Ring.HasQuorum() {
+			continue
+		}
 
 		server.Recycle()
 		server.Converge()
@@ -153,6 +166,84 @@ func (server *SDFSServer) ClearSDFSFiles() {
 	}
 }
 
+// Write file table to local disk if it has changed, so that stored files survive a restart
+func (server *SDFSServer) PersistFileTable() {
+	server.Lock()
+	if !server.FileTableDirty {
+		server.Unlock()
+		return
+	}
+	data, err := proto.Marshal(server.FileTable.ToProto())
+	server.FileTableDirty = false
+	server.Unlock()
+
+	if err != nil {
+		logger.Error("Failed to marshal file table: " + err.Error())
+		return
+	}
+
+	// write to a temporary file first, so a crash never leaves a partially written table
+	path := filepath.Join(strconv.Itoa(int(server.Ring.Process.GetPort())), FILE_TABLE_NAME)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		logger.Error("Failed to persist file table: " + err.Error())
+		return
+	}
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		logger.Error("Failed to persist file table: " + err.Error())
+		return
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		logger.Error("Failed to persist file table: " + err.Error())
+	}
+}
+
+/*
+ * Restore file table persisted by a previous run, so that a restarted process keeps serving its files.
+ * Versions whose data is missing are dropped, and files that are not in the table are deleted.
+ * All local SDFS files are cleared if no file table is persisted
+ */
+func (server *SDFSServer) RestoreFileTable() {
+	dir := strconv.Itoa(int(server.Ring.Process.GetPort()))
+
+	data, err := os.ReadFile(filepath.Join(dir, FILE_TABLE_NAME))
+	if err != nil {
+		server.ClearSDFSFiles()
+		return
+	}
+
+	snapshot := &api.FileTableSnapshot{}
+	if err := proto.Unmarshal(data, snapshot); err != nil {
+		logger.Error("Failed to unmarshal persisted file table: " + err.Error())
+		server.ClearSDFSFiles()
+		return
+	}
+
+	// keep versions that still have their data on disk
+	stored := make(map[string]bool)
+	records := make([]*api.FileTableRecord, 0)
+	for _, record := range snapshot.GetRecords() {
+		if _, err := os.Stat(filepath.Join(dir, record.GetConcatName())); err != nil {
+			continue
+		}
+		stored[record.GetConcatName()] = true
+		records = append(records, record)
+	}
+
+	// delete files that are not tracked by the table
+	files, _ := os.ReadDir(dir)
+	for _, file := range files {
+		if file.Name() != FILE_TABLE_NAME && !stored[file.Name()] {
+			os.Remove(filepath.Join(dir, file.Name()))
+		}
+	}
+
+	server.Lock()
+	server.FileTable = FileTableFromProto(&api.FileTableSnapshot{Records: records})
+	server.FileTableDirty = true
+	server.Unlock()
+	logger.Info(fmt.Sprintf("Restored %d SDFS files from previous run", len(server.FileTable.GetStoredFiles())))
+}
+
 func (server *SDFSServer) Recycle() {
 	if !server.DeletePool.Empty() {
 		server.Lock()
@@ -196,6 +287,7 @@ func (server *SDFSServer) Recycle() {
 			// remove key from file table
 			logger.Info("Removing file " + file + " from file table")
 			server.FileTable.Delete(file)
+			server.FileTableDirty = true
 		}
 	}
 }
@@ -275,12 +367,11 @@ func (server *SDFSServer) Converge() {
 
 func (server *SDFSServer) TransferFiles(p *api.Process, files []string) {
 	// dial to replica
-	conn, err := grpc.Dial(p.Address(), GRPC_OPTIONS...)
+	conn, err := connpool.POOL.Get(p.Address(), GRPC_OPTIONS...)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to dial to %v while trying to converge: %v", p.Address(), err))
 		return
 	}
-	defer conn.Close()
 
 	// create client
 	client := api.NewSDFSServiceClient(conn)
@@ -346,3 +437,105 @@ func (server *SDFSServer) TransferFiles(p *api.Process, files []string) {
 
 	logger.Info(fmt.Sprintf("Successfully transferred %d files to %v", numTransfered, p.Address()))
 }
+
+/*
+ * Hand off all locally stored files before current process leaves the ring. Each file is transferred
+ * to its replicas in the ring formed by the remaining members, and this call only returns once all of
+ * them hold the file, i.e. replica counts are restored without current process
+ *
+ * @param members: members of the ring excluding current process
+ * @return error: raise error if replicas are not restored before HANDOFF_TIMEOUT
+ */
+func (server *SDFSServer) Handoff(members []*api.Process) error {
+	alive := make([]*api.Process, 0)
+	for _, member := range members {
+		if member.Status == api.Status_Alive {
+			alive = append(alive, member)
+		}
+	}
+
+	server.Lock()
+	numFiles := len(server.FileTable.GetStoredFiles())
+	server.Unlock()
+	if len(alive) == 0 && numFiles > 0 {
+		return fmt.Errorf("no remaining members to hand off %d files", numFiles)
+	}
+
+	hashRing := NewHashRing()
+	hashRing.Refresh(alive)
+
+	deadline := time.Now().Add(HANDOFF_TIMEOUT)
+	for {
+		// group files by their replicas in the remaining ring
+		server.Lock()
+		replicaFiles := make(map[string][]string)
+		replicaProcesses := make(map[string]*api.Process)
+		for _, file := range server.FileTable.GetStoredFiles() {
+			for _, replica := range hashRing.FindReplicas(file, REPLICA_COUNT) {
+				replicaFiles[replica.Address()] = append(replicaFiles[replica.Address()], file)
+				replicaProcesses[replica.Address()] = replica
+			}
+		}
+		server.Unlock()
+
+		// transfer files that replicas are missing, and check whether they are all in place
+		numMissing := 0
+		mutex, wg := sync.Mutex{}, sync.WaitGroup{}
+		for addr, files := range replicaFiles {
+			wg.Add(1)
+			go func(p *api.Process, files []string) {
+				defer wg.Done()
+				server.TransferFiles(p, files)
+
+				missing, err := server.LookupMissingFiles(p, files)
+				if err != nil {
+					missing = files
+				}
+				mutex.Lock()
+				numMissing += len(missing)
+				mutex.Unlock()
+			}(replicaProcesses[addr], files)
+		}
+		wg.Wait()
+
+		if numMissing == 0 {
+			logger.Info(fmt.Sprintf("Handed off files to %d replicas", len(replicaFiles)))
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%d file replicas are still missing after %v", numMissing, HANDOFF_TIMEOUT)
+		}
+
+		logger.Info(fmt.Sprintf("Waiting for %d file replicas to be restored...", numMissing))
+		time.Sleep(HANDOFF_RETRY_INTERVAL)
+	}
+}
+
+// Ask a process which of the given files it does not store
+func (server *SDFSServer) LookupMissingFiles(p *api.Process, files []string) ([]string, error) {
+	conn, err := connpool.POOL.Get(p.Address(), GRPC_OPTIONS...)
+	if err != nil {
+		return nil, err
+	}
+
+	client := api.NewSDFSServiceClient(conn)
+	res, err := client.BulkLookup(context.Background(), &api.BulkLookupRequest{
+		Filenames: files,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.GetMissingFiles(), nil
+}
+
+// Drop local file table and files, i.e. after they are handed off to other replicas
+func (server *SDFSServer) ClearFileTable() {
+	server.Lock()
+	server.FileTable = NewFileTable()
+	server.FileCache = utils.NewLFUCache(100 * utils.MegaByte)
+	server.FileTableDirty = true
+	server.Unlock()
+
+	server.ClearSDFSFiles()
+}
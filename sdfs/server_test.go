@@ -0,0 +1,144 @@
+package sdfs_test
+
+import (
+	"context"
+	"mp4/api"
+	"mp4/logger"
+	"mp4/ring"
+	"mp4/sdfs"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// files of every server are stored under a temporary working directory, in a folder named by its port
+func setup(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	logger.STAT = logger.NewLogStats()
+	sdfs.SetTransportCredentials(insecure.NewCredentials())
+}
+
+// SDFS server that is not reachable by other processes
+func newServer(port int32) *sdfs.SDFSServer {
+	server := sdfs.NewSDFSServer()
+	server.Ring = ring.NewRingServer(nil, "127.0.0.1", port)
+	return server
+}
+
+// SDFS server serving requests on a free local port
+func startServer(t *testing.T) *sdfs.SDFSServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newServer(int32(listener.Addr().(*net.TCPAddr).Port))
+
+	grpcServer := grpc.NewServer()
+	api.RegisterSDFSServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+	return server
+}
+
+// store a version of the file on the server
+func writeFile(t *testing.T, server *sdfs.SDFSServer, filename string, count int32) {
+	now := timestamppb.New(time.Now())
+	_, err := server.Write(context.Background(), &api.WriteRequest{
+		Filename: filename,
+		Data:     []byte(filename),
+		WriteId:  &api.WriteId{Ip: server.Ring.Process.Ip, Port: server.Ring.Process.Port, CreateTime: now},
+		Seq:      &api.Sequence{Time: now, Count: count},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLookupMissingFiles(t *testing.T) {
+	setup(t)
+	server, replica := newServer(9000), startServer(t)
+	writeFile(t, replica, "a.txt", 1)
+
+	missing, err := server.LookupMissingFiles(replica.Ring.Process, []string{"a.txt", "b.txt"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"b.txt"}, missing)
+
+	missing, err = server.LookupMissingFiles(replica.Ring.Process, []string{"a.txt"})
+	assert.Nil(t, err)
+	assert.Empty(t, missing)
+
+	// replica that cannot be reached is reported, rather than taken as holding the files
+	unreachable := &api.Process{Ip: "127.0.0.1", Port: 1}
+	_, err = server.LookupMissingFiles(unreachable, []string{"a.txt"})
+	assert.NotNil(t, err)
+}
+
+func TestHandoff(t *testing.T) {
+	setup(t)
+	server, first, second := newServer(9000), startServer(t), startServer(t)
+	writeFile(t, server, "a.txt", 1)
+	writeFile(t, server, "a.txt", 2)
+	writeFile(t, server, "b.txt", 1)
+	timedOut := &api.Process{Ip: "127.0.0.1", Port: 1, Status: api.Status_Timeout}
+
+	// members that timed out are not handed any file
+	assert.Nil(t, server.Handoff([]*api.Process{first.Ring.Process, timedOut, second.Ring.Process}))
+	for _, replica := range []*sdfs.SDFSServer{first, second} {
+		missing, err := server.LookupMissingFiles(replica.Ring.Process, []string{"a.txt", "b.txt"})
+		assert.Nil(t, err)
+		assert.Empty(t, missing)
+		assert.Equal(t, 2, replica.FileTable.NumVersions("a.txt"), "every version should be handed off")
+	}
+
+	// files are kept if no other member remains
+	assert.NotNil(t, server.Handoff([]*api.Process{timedOut}))
+	assert.Nil(t, newServer(9001).Handoff(nil), "nothing to hand off")
+}
+
+// Stored files survive a restart through the persisted file table
+func TestRestoreFileTable(t *testing.T) {
+	setup(t)
+	server := newServer(9000)
+	writeFile(t, server, "a.txt", 1)
+	writeFile(t, server, "a.txt", 2)
+	writeFile(t, server, "b.txt", 1)
+	server.PersistFileTable()
+
+	// data of a version lost on disk, and a file the table does not track
+	lost, _ := server.FileTable.GetLatestVersion("b.txt")
+	assert.Nil(t, os.Remove(filepath.Join("9000", lost.ConcatName)))
+	assert.Nil(t, os.WriteFile(filepath.Join("9000", "untracked"), []byte("x"), 0644))
+
+	restarted := newServer(9000)
+	restarted.RestoreFileTable()
+	assert.Equal(t, 2, restarted.FileTable.NumVersions("a.txt"))
+	assert.False(t, restarted.FileTable.Contains("b.txt"), "version without data should be dropped")
+	_, err := os.Stat(filepath.Join("9000", "untracked"))
+	assert.True(t, os.IsNotExist(err), "untracked file should be deleted")
+	data, err := restarted.ReadSDFSFile(restarted.FileTable.GetVersions("a.txt")[0].ConcatName)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("a.txt"), data)
+
+	// files are cleared if no table is persisted
+	assert.Nil(t, os.Remove(filepath.Join("9000", sdfs.FILE_TABLE_NAME)))
+	cleared := newServer(9000)
+	cleared.RestoreFileTable()
+	assert.Empty(t, cleared.FileTable.GetStoredFiles())
+	files, _ := os.ReadDir("9000")
+	assert.Empty(t, files)
+}
@@ -44,6 +44,12 @@ func (server *SDFSServer) Read(ctx context.Context, req *api.ReadRequest) (*api.
 func (server *SDFSServer) Write(ctx context.Context, req *api.WriteRequest) (*api.WriteResponse, error) {
 	logger.Put(req.GetFilename())
 
+	// minority partition refuses writes until it heals
+	if !server.Ring.HasQuorum() {
+		logger.Error("Refuse to write file " + req.GetFilename() + ": no quorum")
+		return &api.WriteResponse{Status: api.ResponseStatus_NO_QUORUM}, fmt.Errorf("no quorum")
+	}
+
 	server.Lock()
 	concatFileName := utils.ConcatFilename(req.GetFilename(), req.GetSeq())
 
@@ -61,6 +67,8 @@ func (server *SDFSServer) Write(ctx context.Context, req *api.WriteRequest) (*ap
 		return &api.WriteResponse{Status: api.ResponseStatus_OK}, nil
 	}
 
+	server.FileTableDirty = true
+
 	// insert file data into cache if file size is smaller than 10 MB
 	if len(req.GetData()) <= 10*utils.MegaByte {
 		server.FileCache.Put(utils.DataKey(concatFileName), req.GetData())
@@ -82,6 +90,12 @@ func (server *SDFSServer) Write(ctx context.Context, req *api.WriteRequest) (*ap
 func (server *SDFSServer) Delete(ctx context.Context, req *api.DeleteRequest) (*api.DeleteResponse, error) {
 	logger.Remove(req.GetFilename())
 
+	// minority partition refuses deletes until it heals
+	if !server.Ring.HasQuorum() {
+		logger.Error("Refuse to delete file " + req.GetFilename() + ": no quorum")
+		return &api.DeleteResponse{Status: api.ResponseStatus_NO_QUORUM}, fmt.Errorf("no quorum")
+	}
+
 	server.Lock()
 	// check if file exists in file table
 	if !server.FileTable.Contains(req.GetFilename()) {
@@ -99,6 +113,7 @@ func (server *SDFSServer) Delete(ctx context.Context, req *api.DeleteRequest) (*
 	// remove key from file table
 	logger.Info("Removing file " + req.GetFilename() + " from file table")
 	server.FileTable.Delete(req.GetFilename())
+	server.FileTableDirty = true
 	server.Unlock()
 
 	return &api.DeleteResponse{Status: api.ResponseStatus_OK}, nil
@@ -143,6 +158,11 @@ func (server *SDFSServer) BulkLookup(ctx context.Context, req *api.BulkLookupReq
 }
 
 func (server *SDFSServer) FetchSequence(ctx context.Context, req *api.FetchSequenceRequest) (*api.FetchSequenceResponse, error) {
+	// leader of a minority partition must not hand out sequences, otherwise they would conflict with the majority
+	if !server.Ring.HasQuorum() {
+		return &api.FetchSequenceResponse{Status: api.ResponseStatus_NO_QUORUM}, nil
+	}
+
 	server.Lock()
 	defer server.Unlock()
 
@@ -0,0 +1,170 @@
+package security
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// size of the HMAC-SHA256 signature prepended to every ring message
+const SIGNATURE_SIZE = sha256.Size
+
+// minimum length of the shared cluster key in bytes
+const MIN_CLUSTER_KEY_SIZE = 16
+
+/* Config
+ * Security configuration loaded from a JSON file, relative paths are resolved against the config file directory
+ * - CACert: certificate of the CA that signs all node certificates
+ * - Cert, Key: certificate & private key presented by this node, both as gRPC server and client
+ * - ClusterKeyFile: file containing the shared key used to sign ring messages
+ */
+type Config struct {
+	CACert         string `json:"caCert"`
+	Cert           string `json:"cert"`
+	Key            string `json:"key"`
+	ClusterKeyFile string `json:"clusterKeyFile"`
+}
+
+// security global states
+var CLUSTER_KEY []byte = nil
+var SERVER_CREDENTIALS credentials.TransportCredentials = nil
+var CLIENT_CREDENTIALS credentials.TransportCredentials = nil
+
+/*
+ * Load security config and initialize mTLS credentials & cluster key
+ *
+ * @param path: path to the JSON config file
+ * @return error: raise error if any of the certificates or the cluster key cannot be loaded
+ */
+func Init(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read security config %v: %v", path, err)
+	}
+
+	config := &Config{}
+	if err := json.Unmarshal(content, config); err != nil {
+		return fmt.Errorf("failed to parse security config %v: %v", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	resolve := func(p string) string {
+		if filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+
+	// load certificate authority
+	caCert, err := os.ReadFile(resolve(config.CACert))
+	if err != nil {
+		return fmt.Errorf("failed to read CA certificate: %v", err)
+	}
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(caCert) {
+		return fmt.Errorf("failed to parse CA certificate %v", config.CACert)
+	}
+
+	// load node certificate
+	cert, err := tls.LoadX509KeyPair(resolve(config.Cert), resolve(config.Key))
+	if err != nil {
+		return fmt.Errorf("failed to load node certificate: %v", err)
+	}
+
+	// load cluster key
+	key, err := os.ReadFile(resolve(config.ClusterKeyFile))
+	if err != nil {
+		return fmt.Errorf("failed to read cluster key: %v", err)
+	}
+	if len(key) < MIN_CLUSTER_KEY_SIZE {
+		return fmt.Errorf("cluster key must be at least %d bytes", MIN_CLUSTER_KEY_SIZE)
+	}
+
+	SERVER_CREDENTIALS = credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    certPool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})
+	CLIENT_CREDENTIALS = credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      certPool,
+		MinVersion:   tls.VersionTLS12,
+	})
+	CLUSTER_KEY = key
+
+	return nil
+}
+
+/*
+ * Identity of the client of a gRPC call, taken from the certificate it presented during the mTLS handshake
+ *
+ * @param ctx: context of the call
+ * @return string: common name of the verified client certificate
+ * @return error: raise error if the client presented no verified certificate, or one without common name
+ */
+func PeerIdentity(ctx context.Context) (string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", fmt.Errorf("no peer in call context")
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return "", fmt.Errorf("peer %v is not authenticated with mTLS", p.Addr)
+	}
+	if len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return "", fmt.Errorf("peer %v presented no verified certificate", p.Addr)
+	}
+
+	name := info.State.VerifiedChains[0][0].Subject.CommonName
+	if name == "" {
+		return "", fmt.Errorf("certificate of peer %v has no common name", p.Addr)
+	}
+	return name, nil
+}
+
+/*
+ * Sign a ring message with the cluster key
+ *
+ * @param message: marshalled message
+ * @return []byte: signature followed by the message
+ */
+func Sign(message []byte) []byte {
+	mac := hmac.New(sha256.New, CLUSTER_KEY)
+	mac.Write(message)
+	return append(mac.Sum(nil), message...)
+}
+
+/*
+ * Verify a signed ring message and strip its signature
+ *
+ * @param packet: signature followed by the message
+ * @return []byte: the original message
+ * @return error: raise error if the packet is unsigned or mis-signed
+ */
+func Verify(packet []byte) ([]byte, error) {
+	if CLUSTER_KEY == nil {
+		return nil, fmt.Errorf("cluster key is not initialized")
+	}
+	if len(packet) < SIGNATURE_SIZE {
+		return nil, fmt.Errorf("packet is not signed")
+	}
+
+	signature, message := packet[:SIGNATURE_SIZE], packet[SIGNATURE_SIZE:]
+	mac := hmac.New(sha256.New, CLUSTER_KEY)
+	mac.Write(message)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, fmt.Errorf("invalid packet signature")
+	}
+
+	return message, nil
+}
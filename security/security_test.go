@@ -0,0 +1,79 @@
+package security_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"mp4/security"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func TestSignVerify(t *testing.T) {
+	security.CLUSTER_KEY = []byte("0123456789abcdef")
+
+	message := []byte("ping")
+	packet := security.Sign(message)
+	assert.Equal(t, security.SIGNATURE_SIZE+len(message), len(packet))
+
+	verified, err := security.Verify(packet)
+	assert.Nil(t, err)
+	assert.Equal(t, message, verified)
+}
+
+func TestVerifyRejectsInvalidPacket(t *testing.T) {
+	security.CLUSTER_KEY = []byte("0123456789abcdef")
+
+	// unsigned packet
+	_, err := security.Verify([]byte("ping"))
+	assert.NotNil(t, err)
+
+	// tampered message
+	packet := security.Sign([]byte("ping"))
+	packet[len(packet)-1] ^= 1
+	_, err = security.Verify(packet)
+	assert.NotNil(t, err)
+
+	// signed with a different cluster key
+	packet = security.Sign([]byte("ping"))
+	security.CLUSTER_KEY = []byte("fedcba9876543210")
+	_, err = security.Verify(packet)
+	assert.NotNil(t, err)
+}
+
+// context of a call from a peer that authenticated with the given verified certificate chains
+func peerContext(authInfo credentials.AuthInfo) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		Addr:     &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7000},
+		AuthInfo: authInfo,
+	})
+}
+
+func TestPeerIdentity(t *testing.T) {
+	verified := func(names ...string) credentials.TLSInfo {
+		chain := make([]*x509.Certificate, 0)
+		for _, name := range names {
+			chain = append(chain, &x509.Certificate{Subject: pkix.Name{CommonName: name}})
+		}
+		return credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{chain}}}
+	}
+
+	// common name of the client certificate, not of its CA
+	name, err := security.PeerIdentity(peerContext(verified("alice", "IDunno CA")))
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", name)
+
+	_, err = security.PeerIdentity(context.Background())
+	assert.NotNil(t, err, "call without peer")
+	_, err = security.PeerIdentity(peerContext(nil))
+	assert.NotNil(t, err, "peer without mTLS")
+	_, err = security.PeerIdentity(peerContext(credentials.TLSInfo{}))
+	assert.NotNil(t, err, "peer without verified certificate")
+	_, err = security.PeerIdentity(peerContext(verified("")))
+	assert.NotNil(t, err, "certificate without common name")
+}
@@ -4,11 +4,8 @@ import (
 	"fmt"
 	"hash/fnv"
 	"math"
-	"math/rand"
 	"mp4/api"
 
-	"time"
-
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -22,24 +19,6 @@ var DEFAULT_TIMESTAMP = timestamppb.Timestamp{
 	Nanos:   0,
 }
 
-var source = rand.NewSource(time.Now().UnixNano())
-var r = rand.New(source)
-
-/*
- * Drop a message with a given probability
- *
- * @param prob: probability of dropping a message
- * @param callback: callback function to write a message
- * @return int: number of bytes written
- * @return error: raise error if writing fails
- */
-func WithDropProb(prob float64, callback func() (int, error)) (int, error) {
-	if r.Float64() <= prob {
-		return 0, fmt.Errorf("network packet dropped, fail probability: %v", prob)
-	}
-	return callback()
-}
-
 func ConcatFilename(filename string, seq *api.Sequence) string {
 	return fmt.Sprintf("[%v][%v][%d]", filename, seq.GetTime().AsTime(), seq.GetCount())
 }
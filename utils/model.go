@@ -7,13 +7,9 @@ const (
 	Albert   ModelType = "albert"
 )
 
-var SupportedModelTypes = []ModelType{ResNet50, Albert}
-
 type DatasetType string
 
 const (
 	Imagenet DatasetType = "imagenet"
 	Emotion  DatasetType = "emotion.txt"
 )
-
-var SupportedDatasetTypes = []DatasetType{Imagenet, Emotion}
@@ -32,6 +32,18 @@ func (t *Queue[T]) Pop() {
 	*t = (*t)[1:]
 }
 
+// Remove the first item matching, returns false if there is none
+func (t *Queue[T]) Remove(match func(item T) bool) (T, bool) {
+	for i, item := range *t {
+		if match(item) {
+			*t = append((*t)[:i:i], (*t)[i+1:]...)
+			return item, true
+		}
+	}
+	var empty T
+	return empty, false
+}
+
 func (t *Queue[T]) Push(item T) {
 	*t = append(*t, item)
 }